@@ -3,18 +3,30 @@ package main
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 
 	"github.com/sonirico/go-hyperliquid"
 )
 
 type Account struct {
-	ctx        context.Context
-	info       *hyperliquid.Info
-	exchange   *hyperliquid.Exchange
-	address    string
-	privateKey *ecdsa.PrivateKey
+	id           string
+	name         string
+	kind         AccountKind
+	ctx          context.Context
+	info         *hyperliquid.Info
+	exchange     *hyperliquid.Exchange
+	url          string
+	address      string
+	agentAddress string
+	privateKey   *ecdsa.PrivateKey
+	risk         *RiskManager
+	algos        *AlgoManager
+	assets       *AssetMetaCache
+	// stopFills stops watchFills; nil until it is started.
+	stopFills context.CancelFunc
 }
 
 type AccountBalance struct {
@@ -26,6 +38,7 @@ type AccountBalance struct {
 }
 
 type ActivePosition struct {
+	AccountID      string
 	Coin           string
 	Side           string
 	Size           string
@@ -40,38 +53,142 @@ type ActivePosition struct {
 }
 
 type PortfolioSummary struct {
+	AccountID      string
 	Balance        AccountBalance
 	Positions      []ActivePosition
 	TotalPositions int
 	TotalPnL       float64
 	OpenOrders     []hyperliquid.OpenOrder
+	// OrderAccounts maps each open order id to the account holding it.
+	OrderAccounts map[int64]string
 }
 
 type OrderResponse struct {
-	Success bool
-	OrderID string
-	Message string
-	Status  string
+	Success    bool
+	OrderID    string
+	Message    string
+	Status     string
+	AvgPrice   float64
+	FilledSize float64
 }
 
 func NewAccount(ctx context.Context, config Config) *Account {
-	exchange := hyperliquid.NewExchange(ctx, config.PrivateKey, config.URL, nil, "", "", nil, hyperliquid.ExchangeOptClientOptions())
+	var exchange *hyperliquid.Exchange
+	if !config.ReadOnly {
+		exchange = hyperliquid.NewExchange(ctx, config.PrivateKey, config.URL, nil, "", config.AgentAccount(), nil, hyperliquid.ExchangeOptClientOptions())
+	}
+	info := hyperliquid.NewInfo(ctx, config.URL, true, nil, nil, hyperliquid.InfoOptClientOptions())
+
+	account := &Account{
+		id:           mainAccountID,
+		name:         "Main",
+		kind:         AccountMain,
+		ctx:          ctx,
+		exchange:     exchange,
+		url:          config.URL,
+		address:      config.Address,
+		agentAddress: config.AgentAddress,
+		privateKey:   config.PrivateKey,
+		info:         info,
+		assets:       NewAssetMetaCache(ctx, info),
+	}
+	account.algos = NewAlgoManager(account)
+	return account
+}
+
+// NewVaultAccount builds an account for a sub-account or vault. State is
+// queried for the vault address and orders are signed by the main key on its
+// behalf.
+func NewVaultAccount(ctx context.Context, config Config, ac AccountConfig) *Account {
+	var exchange *hyperliquid.Exchange
+	if !config.ReadOnly {
+		exchange = hyperliquid.NewExchange(ctx, config.PrivateKey, config.URL, nil, ac.Address, ac.Address, nil, hyperliquid.ExchangeOptClientOptions())
+	}
 	info := hyperliquid.NewInfo(ctx, config.URL, true, nil, nil, hyperliquid.InfoOptClientOptions())
 
-	return &Account{
+	name := ac.Name
+	if name == "" {
+		name = ac.ID
+	}
+	account := &Account{
+		id:         ac.ID,
+		name:       name,
+		kind:       ac.Kind,
 		ctx:        ctx,
 		exchange:   exchange,
-		address:    config.Address,
+		url:        config.URL,
+		address:    ac.Address,
 		privateKey: config.PrivateKey,
 		info:       info,
+		assets:     NewAssetMetaCache(ctx, info),
 	}
+	account.algos = NewAlgoManager(account)
+	return account
 }
 
 func (a *Account) GetAddress() string {
 	return a.address
 }
 
+func (a *Account) ID() string {
+	return a.id
+}
+
+func (a *Account) Info() AccountInfo {
+	return AccountInfo{
+		ID:       a.id,
+		Name:     a.name,
+		Address:  a.address,
+		Kind:     a.kind,
+		ReadOnly: a.IsReadOnly(),
+	}
+}
+
+func (a *Account) IsReadOnly() bool {
+	return a.exchange == nil
+}
+
+func (a *Account) requireSigner() error {
+	if a.exchange == nil {
+		return fmt.Errorf("wallet is read-only, unlock a keystore to trade")
+	}
+	return nil
+}
+
+func (a *Account) SetRiskManager(risk *RiskManager) {
+	a.risk = risk
+}
+
+// startFills starts watchFills for an account with an address.
+func (a *Account) startFills() {
+	if a.address == "" || a.stopFills != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.stopFills = cancel
+	go a.watchFills(ctx)
+}
+
+// Close stops the account's background work.
+func (a *Account) Close() {
+	if a.stopFills != nil {
+		a.stopFills()
+	}
+}
+
+func (a *Account) Algos() *AlgoManager {
+	return a.algos
+}
+
+func (a *Account) Assets() *AssetMetaCache {
+	return a.assets
+}
+
 func (a *Account) GetPortfolioSummary() (PortfolioSummary, error) {
+	if a.address == "" {
+		return PortfolioSummary{}, fmt.Errorf("no wallet address configured")
+	}
+
 	userState, err := a.info.UserState(a.ctx, a.address)
 	if err != nil {
 		return PortfolioSummary{}, fmt.Errorf("failed to fetch user state: %w", err)
@@ -111,6 +228,7 @@ func (a *Account) GetPortfolioSummary() (PortfolioSummary, error) {
 		}
 
 		positions = append(positions, ActivePosition{
+			AccountID:      a.id,
 			Coin:           pos.Coin,
 			Side:           side,
 			Size:           fmt.Sprintf("%.8f", sizeF),
@@ -131,12 +249,19 @@ func (a *Account) GetPortfolioSummary() (PortfolioSummary, error) {
 		return PortfolioSummary{}, fmt.Errorf("failed to fetch open orders: %w", err)
 	}
 
+	orderAccounts := make(map[int64]string, len(openOrders))
+	for _, order := range openOrders {
+		orderAccounts[order.Oid] = a.id
+	}
+
 	return PortfolioSummary{
+		AccountID:      a.id,
 		Balance:        bal,
 		Positions:      positions,
 		TotalPositions: len(positions),
 		TotalPnL:       totalPnL,
 		OpenOrders:     openOrders,
+		OrderAccounts:  orderAccounts,
 	}, nil
 }
 
@@ -149,7 +274,47 @@ func (a *Account) GetActivePositions() ([]ActivePosition, error) {
 }
 
 func (a *Account) OpenPosition(coin string, isBuy bool, size float64, leverage int) (OrderResponse, error) {
-	_, err := a.exchange.UpdateLeverage(a.ctx, leverage, coin, false)
+	return a.OpenPositionWith(coin, isBuy, size, leverage, DefaultExecutionConfig())
+}
+
+func (a *Account) OpenPositionWith(coin string, isBuy bool, size float64, leverage int, exec ExecutionConfig) (OrderResponse, error) {
+	if err := a.requireSigner(); err != nil {
+		return OrderResponse{
+			Success: false,
+			Message: err.Error(),
+			Status:  "rejected",
+		}, err
+	}
+
+	if err := exec.Validate(); err != nil {
+		return OrderResponse{
+			Success: false,
+			Message: err.Error(),
+			Status:  "error",
+		}, err
+	}
+
+	size, err := a.roundSize(coin, size)
+	if err != nil {
+		return OrderResponse{
+			Success: false,
+			Message: err.Error(),
+			Status:  "rejected",
+		}, err
+	}
+
+	// Algo orders are risk-checked per slice as they go.
+	if !exec.isAlgo() {
+		if err := a.checkRisk(coin, size); err != nil {
+			return OrderResponse{
+				Success: false,
+				Message: err.Error(),
+				Status:  "rejected",
+			}, err
+		}
+	}
+
+	_, err = a.exchange.UpdateLeverage(a.ctx, leverage, coin, false)
 	if err != nil {
 		return OrderResponse{
 			Success: false,
@@ -158,6 +323,13 @@ func (a *Account) OpenPosition(coin string, isBuy bool, size float64, leverage i
 		}, err
 	}
 
+	if exec.isAlgo() {
+		return a.algos.Execute(exec.algoRequest(coin, isBuy, size, false))
+	}
+	if exec.Mode != ExecutionMarket {
+		return a.executeLimit(a.ctx, coin, isBuy, size, false, exec)
+	}
+
 	resp, err := a.exchange.MarketOpen(a.ctx, coin, isBuy, size, nil, 0.05, nil, nil)
 	if err != nil {
 		return OrderResponse{
@@ -171,6 +343,26 @@ func (a *Account) OpenPosition(coin string, isBuy bool, size float64, leverage i
 }
 
 func (a *Account) ClosePosition(coin string, size float64) (OrderResponse, error) {
+	return a.ClosePositionWith(coin, size, DefaultExecutionConfig())
+}
+
+func (a *Account) ClosePositionWith(coin string, size float64, exec ExecutionConfig) (OrderResponse, error) {
+	if err := a.requireSigner(); err != nil {
+		return OrderResponse{
+			Success: false,
+			Message: err.Error(),
+			Status:  "rejected",
+		}, err
+	}
+
+	if err := exec.Validate(); err != nil {
+		return OrderResponse{
+			Success: false,
+			Message: err.Error(),
+			Status:  "error",
+		}, err
+	}
+
 	userState, err := a.info.UserState(a.ctx, a.address)
 	if err != nil {
 		return OrderResponse{
@@ -196,10 +388,16 @@ func (a *Account) ClosePosition(coin string, size float64) (OrderResponse, error
 			}
 
 			isBuy = szi < 0
-			if size > 0 {
-				positionSize = size
-			} else {
-				positionSize = abs(szi)
+			positionSize = abs(szi)
+			if size > 0 && size < positionSize {
+				positionSize, err = a.roundSize(coin, size)
+				if err != nil {
+					return OrderResponse{
+						Success: false,
+						Message: err.Error(),
+						Status:  "rejected",
+					}, err
+				}
 			}
 			found = true
 			break
@@ -214,33 +412,131 @@ func (a *Account) ClosePosition(coin string, size float64) (OrderResponse, error
 		}, fmt.Errorf("position not found for %s", coin)
 	}
 
-	slippagePrice, err := a.exchange.SlippagePrice(a.ctx, coin, isBuy, 0.05, nil)
+	if a.risk != nil && !exec.isAlgo() {
+		if err := a.risk.CheckReduceOrder(coin); err != nil {
+			return OrderResponse{
+				Success: false,
+				Message: err.Error(),
+				Status:  "rejected",
+			}, err
+		}
+	}
+
+	var out OrderResponse
+	if exec.isAlgo() {
+		out, err = a.algos.Execute(exec.algoRequest(coin, isBuy, positionSize, true))
+		if err != nil {
+			return out, err
+		}
+	} else if exec.Mode != ExecutionMarket {
+		out, err = a.executeLimit(a.ctx, coin, isBuy, positionSize, true, exec)
+		if err != nil {
+			return out, err
+		}
+	} else {
+		slippagePrice, err := a.exchange.SlippagePrice(a.ctx, coin, isBuy, 0.05, nil)
+		if err != nil {
+			return OrderResponse{
+				Success: false,
+				Message: fmt.Sprintf("failed to get slippage price: %v", err),
+				Status:  "error",
+			}, err
+		}
+
+		resp, err := a.exchange.Order(a.ctx, hyperliquid.CreateOrderRequest{
+			Coin:       coin,
+			IsBuy:      isBuy,
+			Size:       positionSize,
+			Price:      slippagePrice,
+			OrderType:  hyperliquid.OrderType{Limit: &hyperliquid.LimitOrderType{Tif: hyperliquid.TifIoc}},
+			ReduceOnly: true,
+		}, nil)
+
+		if err != nil {
+			return OrderResponse{
+				Success: false,
+				Message: err.Error(),
+				Status:  "error",
+			}, err
+		}
+		out = parseOrderResponse(resp)
+	}
+
+	return out, nil
+}
+
+func (a *Account) CancelAllOrders() (int, error) {
+	if err := a.requireSigner(); err != nil {
+		return 0, err
+	}
+
+	openOrders, err := a.info.OpenOrders(a.ctx, a.address)
 	if err != nil {
-		return OrderResponse{
-			Success: false,
-			Message: fmt.Sprintf("failed to get slippage price: %v", err),
-			Status:  "error",
-		}, err
+		return 0, fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	if len(openOrders) == 0 {
+		return 0, nil
+	}
+
+	requests := make([]hyperliquid.CancelOrderRequest, 0, len(openOrders))
+	for _, order := range openOrders {
+		requests = append(requests, hyperliquid.CancelOrderRequest{
+			Coin:    order.Coin,
+			OrderID: order.Oid,
+		})
 	}
 
-	resp, err := a.exchange.Order(a.ctx, hyperliquid.CreateOrderRequest{
-		Coin:       coin,
-		IsBuy:      isBuy,
-		Size:       positionSize,
-		Price:      slippagePrice,
-		OrderType:  hyperliquid.OrderType{Limit: &hyperliquid.LimitOrderType{Tif: hyperliquid.TifIoc}},
-		ReduceOnly: true,
-	}, nil)
+	if _, err := a.exchange.BulkCancel(a.ctx, requests); err != nil {
+		return 0, fmt.Errorf("failed to cancel orders: %w", err)
+	}
+	return len(requests), nil
+}
 
+func (a *Account) CloseAllPositions(reason string) error {
+	positions, err := a.GetActivePositions()
 	if err != nil {
-		return OrderResponse{
-			Success: false,
-			Message: err.Error(),
-			Status:  "error",
-		}, err
+		return err
 	}
 
-	return parseOrderResponse(resp), nil
+	var errs []error
+	for _, pos := range positions {
+		slog.Warn("closing position", "account", a.id, "coin", pos.Coin, "side", pos.Side, "reason", reason)
+		if _, err := a.ClosePosition(pos.Coin, 0); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pos.Coin, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *Account) checkRisk(coin string, size float64) error {
+	if a.risk == nil {
+		return nil
+	}
+
+	userState, err := a.info.UserState(a.ctx, a.address)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user state: %w", err)
+	}
+
+	var symbolNotional, totalNotional float64
+	for _, assetPos := range userState.AssetPositions {
+		value := abs(parseFloatSafe(assetPos.Position.PositionValue))
+		totalNotional += value
+		if assetPos.Position.Coin == coin {
+			symbolNotional = value
+		}
+	}
+
+	mids, err := a.info.AllMids(a.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch mid prices: %w", err)
+	}
+	mid, ok := mids[coin]
+	if !ok {
+		return fmt.Errorf("no mid price for %s", coin)
+	}
+
+	return a.risk.CheckOrder(coin, size*parseFloatSafe(mid), symbolNotional, totalNotional)
 }
 
 func parseFloatSafe(s string) float64 {
@@ -263,6 +559,8 @@ func parseOrderResponse(resp hyperliquid.OrderStatus) OrderResponse {
 	} else if resp.Filled != nil {
 		out.Status = "filled"
 		out.Message = fmt.Sprintf("filled avgPx=%s size=%s", resp.Filled.AvgPx, resp.Filled.TotalSz)
+		out.AvgPrice = parseFloatSafe(resp.Filled.AvgPx)
+		out.FilledSize = parseFloatSafe(resp.Filled.TotalSz)
 	} else if resp.Error != nil {
 		out.Success = false
 		out.Status = "error"
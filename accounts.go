@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	mainAccountID = "main"
+	allAccountsID = "all"
+)
+
+type AccountKind string
+
+const (
+	AccountMain       AccountKind = "main"
+	AccountSubAccount AccountKind = "subAccount"
+	AccountVault      AccountKind = "vault"
+)
+
+// AccountConfig describes a sub-account or vault traded with the main signing
+// key. Orders for it are signed by the main key with the vault address set.
+type AccountConfig struct {
+	ID      string      `yaml:"id" toml:"id"`
+	Name    string      `yaml:"name" toml:"name"`
+	Address string      `yaml:"address" toml:"address"`
+	Kind    AccountKind `yaml:"kind" toml:"kind"`
+}
+
+type AccountInfo struct {
+	ID       string
+	Name     string
+	Address  string
+	Kind     AccountKind
+	ReadOnly bool
+}
+
+// AccountRegistry holds every account the app can trade, keyed by a short id.
+// The main account is always registered as "main".
+type AccountRegistry struct {
+	mu       sync.RWMutex
+	accounts map[string]*Account
+}
+
+func NewAccountRegistry() *AccountRegistry {
+	return &AccountRegistry{
+		accounts: make(map[string]*Account),
+	}
+}
+
+func (r *AccountRegistry) Add(account *Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.accounts[account.id]; exists && account.id != mainAccountID {
+		return fmt.Errorf("account %s already registered", account.id)
+	}
+	r.accounts[account.id] = account
+	return nil
+}
+
+func (r *AccountRegistry) Remove(id string) error {
+	if id == mainAccountID {
+		return fmt.Errorf("the main account cannot be removed")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.accounts[id]; !exists {
+		return fmt.Errorf("account %s not found", id)
+	}
+	r.accounts[id].Close()
+	delete(r.accounts, id)
+	return nil
+}
+
+// Get returns the account with the given id. An empty id selects the main
+// account so single-account callers keep working.
+func (r *AccountRegistry) Get(id string) (*Account, error) {
+	if id == "" {
+		id = mainAccountID
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, fmt.Errorf("account %s not found", id)
+	}
+	return account, nil
+}
+
+func (r *AccountRegistry) Main() *Account {
+	account, _ := r.Get(mainAccountID)
+	return account
+}
+
+// All returns the registered accounts ordered by id with the main account
+// first.
+func (r *AccountRegistry) All() []*Account {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	accounts := make([]*Account, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].id == mainAccountID || accounts[j].id == mainAccountID {
+			return accounts[i].id == mainAccountID
+		}
+		return accounts[i].id < accounts[j].id
+	})
+	return accounts
+}
+
+func (r *AccountRegistry) List() []AccountInfo {
+	accounts := r.All()
+	result := make([]AccountInfo, 0, len(accounts))
+	for _, account := range accounts {
+		result = append(result, account.Info())
+	}
+	return result
+}
+
+func (r *AccountRegistry) SetRiskManager(risk *RiskManager) {
+	for _, account := range r.All() {
+		account.SetRiskManager(risk)
+	}
+}
+
+// Load rebuilds the registry from config: the main account, the configured
+// sub-accounts and vaults, and any sub-accounts discovered for the master
+// address. The accounts it replaces are closed.
+func (r *AccountRegistry) Load(ctx context.Context, config Config, risk *RiskManager) {
+	primary := NewAccount(ctx, config)
+	primary.SetRiskManager(risk)
+	primary.startFills()
+
+	r.mu.Lock()
+	for _, account := range r.accounts {
+		account.Close()
+	}
+	r.accounts = map[string]*Account{mainAccountID: primary}
+	r.mu.Unlock()
+
+	for _, ac := range config.Settings.Accounts {
+		if err := r.addConfigured(ctx, config, ac, risk); err != nil {
+			slog.Error("add account failed", "account", ac.ID, "error", err)
+		}
+	}
+
+	if config.Address == "" {
+		return
+	}
+	subAccounts, err := primary.info.QuerySubAccounts(ctx, config.Address)
+	if err != nil {
+		slog.Error("query sub-accounts failed", "address", config.Address, "error", err)
+		return
+	}
+	for _, sub := range subAccounts {
+		if r.hasAddress(sub.User) {
+			continue
+		}
+		ac := AccountConfig{ID: accountID(sub.Name), Name: sub.Name, Address: sub.User, Kind: AccountSubAccount}
+		if err := r.addConfigured(ctx, config, ac, risk); err != nil {
+			slog.Error("add sub-account failed", "account", ac.ID, "error", err)
+		}
+	}
+}
+
+func (ac AccountConfig) Validate() error {
+	if ac.ID == "" || ac.ID == allAccountsID || ac.ID == mainAccountID {
+		return fmt.Errorf("invalid account id %q", ac.ID)
+	}
+	if !common.IsHexAddress(ac.Address) {
+		return fmt.Errorf("account %s has an invalid address %q", ac.ID, ac.Address)
+	}
+	if ac.Kind != AccountSubAccount && ac.Kind != AccountVault {
+		return fmt.Errorf("account %s has unknown kind %q", ac.ID, ac.Kind)
+	}
+	return nil
+}
+
+func (r *AccountRegistry) addConfigured(ctx context.Context, config Config, ac AccountConfig, risk *RiskManager) error {
+	if err := ac.Validate(); err != nil {
+		return err
+	}
+	account := NewVaultAccount(ctx, config, ac)
+	account.SetRiskManager(risk)
+	if err := r.Add(account); err != nil {
+		return err
+	}
+	account.startFills()
+	return nil
+}
+
+func (r *AccountRegistry) hasAddress(address string) bool {
+	for _, account := range r.All() {
+		if strings.EqualFold(account.address, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// accountID turns a sub-account name into an id usable in bindings.
+func accountID(name string) string {
+	id := strings.ToLower(strings.TrimSpace(name))
+	id = strings.Join(strings.Fields(id), "-")
+	if id == "" || id == mainAccountID || id == allAccountsID {
+		id = "sub-" + id
+	}
+	return id
+}
+
+// AggregatePortfolio sums balances and merges positions and open orders across
+// accounts. Positions keep their AccountID so the UI can route close orders.
+func AggregatePortfolio(summaries []PortfolioSummary) PortfolioSummary {
+	total := PortfolioSummary{
+		AccountID:     allAccountsID,
+		Positions:     []ActivePosition{},
+		OrderAccounts: make(map[int64]string),
+	}
+	var value, raw, withdrawable, margin, notional float64
+	for _, sum := range summaries {
+		value += parseFloatSafe(sum.Balance.AccountValue)
+		raw += parseFloatSafe(sum.Balance.TotalRawUsd)
+		withdrawable += parseFloatSafe(sum.Balance.Withdrawable)
+		margin += parseFloatSafe(sum.Balance.TotalMargin)
+		for _, pos := range sum.Positions {
+			notional += parseFloatSafe(pos.PositionValue)
+		}
+
+		total.Positions = append(total.Positions, sum.Positions...)
+		total.OpenOrders = append(total.OpenOrders, sum.OpenOrders...)
+		for oid, id := range sum.OrderAccounts {
+			total.OrderAccounts[oid] = id
+		}
+		total.TotalPnL += sum.TotalPnL
+	}
+
+	total.Balance = AccountBalance{
+		AccountValue: fmt.Sprintf("%.2f", value),
+		TotalRawUsd:  fmt.Sprintf("%.2f", raw),
+		Withdrawable: fmt.Sprintf("%.2f", withdrawable),
+		TotalMargin:  fmt.Sprintf("%.2f", margin),
+	}
+	if value > 0 {
+		total.Balance.AccountLeverage = notional / value
+	}
+	total.TotalPositions = len(total.Positions)
+	return total
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// agentExpiryWarning is how far ahead of expiry the agent is reported as
+// expiring so bots can be rotated before orders start failing.
+const agentExpiryWarning = 7 * 24 * time.Hour
+
+type AgentStatus struct {
+	Enabled       bool
+	MasterAddress string
+	AgentAddress  string
+	Name          string
+	Approved      bool
+	ValidUntil    time.Time
+	Expired       bool
+	ExpiringSoon  bool
+	Message       string
+}
+
+type extraAgent struct {
+	Address    string `json:"address"`
+	Name       string `json:"name"`
+	ValidUntil int64  `json:"validUntil"`
+}
+
+type userRole struct {
+	Role string `json:"role"`
+	Data struct {
+		User string `json:"user"`
+	} `json:"data"`
+}
+
+// AgentStatus reports whether the signing key is an approved API wallet of
+// the master account and when that approval expires.
+func (a *Account) AgentStatus() (AgentStatus, error) {
+	status := AgentStatus{
+		Enabled:       a.agentAddress != "",
+		MasterAddress: a.address,
+		AgentAddress:  a.agentAddress,
+	}
+	if !status.Enabled {
+		return status, nil
+	}
+
+	var role userRole
+	if err := a.infoRequest(map[string]any{"type": "userRole", "user": a.agentAddress}, &role); err != nil {
+		return status, fmt.Errorf("failed to fetch agent role: %w", err)
+	}
+	status.Approved = role.Role == "agent" && strings.EqualFold(role.Data.User, a.address)
+	if !status.Approved {
+		status.Message = fmt.Sprintf("%s is not an approved agent of %s", a.agentAddress, a.address)
+		return status, nil
+	}
+
+	var agents []extraAgent
+	if err := a.infoRequest(map[string]any{"type": "extraAgents", "user": a.address}, &agents); err != nil {
+		return status, fmt.Errorf("failed to fetch agents: %w", err)
+	}
+	for _, agent := range agents {
+		if !strings.EqualFold(agent.Address, a.agentAddress) {
+			continue
+		}
+		status.Name = agent.Name
+		if agent.ValidUntil > 0 {
+			status.ValidUntil = time.UnixMilli(agent.ValidUntil)
+			remaining := time.Until(status.ValidUntil)
+			status.Expired = remaining <= 0
+			status.ExpiringSoon = !status.Expired && remaining < agentExpiryWarning
+		}
+		break
+	}
+
+	switch {
+	case status.Expired:
+		status.Message = fmt.Sprintf("agent expired on %s", status.ValidUntil.Format(time.DateOnly))
+	case status.ExpiringSoon:
+		status.Message = fmt.Sprintf("agent expires on %s", status.ValidUntil.Format(time.DateOnly))
+	}
+	return status, nil
+}
+
+// infoRequest posts a raw request to the info endpoint for queries the SDK
+// does not wrap.
+func (a *Account) infoRequest(payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(a.ctx, http.MethodPost, a.url+"/info", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("info request failed with status %d: %s", resp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, out)
+}
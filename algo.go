@@ -0,0 +1,514 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+type AlgoType string
+
+const (
+	AlgoTWAP    AlgoType = "twap"
+	AlgoIceberg AlgoType = "iceberg"
+)
+
+type AlgoStatus string
+
+const (
+	AlgoRunning   AlgoStatus = "running"
+	AlgoPaused    AlgoStatus = "paused"
+	AlgoCompleted AlgoStatus = "completed"
+	AlgoCancelled AlgoStatus = "cancelled"
+	AlgoFailed    AlgoStatus = "failed"
+)
+
+// AlgoRequest describes a parent order that is split into child orders.
+// TWAP spreads Size over DurationSeconds in Slices market slices, jittering
+// slice size and timing by Randomize (0..1). Iceberg works VisibleSize clips
+// at the touch until Size is filled.
+type AlgoRequest struct {
+	Type            AlgoType
+	Coin            string
+	IsBuy           bool
+	Size            float64
+	ReduceOnly      bool
+	DurationSeconds float64
+	Slices          int
+	Randomize       float64
+	VisibleSize     float64
+	PostOnly        bool
+	MaxChaseBps     float64
+	ClipTimeoutSecs float64
+}
+
+type AlgoOrder struct {
+	ID          string
+	Request     AlgoRequest
+	Status      AlgoStatus
+	FilledSize  float64
+	AvgPrice    float64
+	SlicesDone  int
+	SlicesTotal int
+	StartedAt   int64
+	UpdatedAt   int64
+	Error       string
+}
+
+type algoRun struct {
+	order    AlgoOrder
+	notional float64
+	paused   bool
+	resume   chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	// stopClip stops the iceberg clip in flight, nil between clips.
+	stopClip context.CancelFunc
+}
+
+// algoRetention is how long a finished algo stays listed.
+const algoRetention = time.Hour
+
+type AlgoManager struct {
+	mu      sync.Mutex
+	account *Account
+	runs    map[string]*algoRun
+	nextID  int
+}
+
+func NewAlgoManager(account *Account) *AlgoManager {
+	return &AlgoManager{
+		account: account,
+		runs:    make(map[string]*algoRun),
+	}
+}
+
+func (req AlgoRequest) Validate() error {
+	if req.Coin == "" {
+		return fmt.Errorf("coin is required")
+	}
+	if req.Size <= 0 {
+		return fmt.Errorf("size must be positive")
+	}
+	if req.Randomize < 0 || req.Randomize > 1 {
+		return fmt.Errorf("randomize must be between 0 and 1")
+	}
+	switch req.Type {
+	case AlgoTWAP:
+		if req.DurationSeconds <= 0 {
+			return fmt.Errorf("twap duration must be positive")
+		}
+		if req.Slices < 1 {
+			return fmt.Errorf("twap needs at least one slice")
+		}
+	case AlgoIceberg:
+		if req.VisibleSize <= 0 || req.VisibleSize > req.Size {
+			return fmt.Errorf("iceberg visible size must be between 0 and the total size")
+		}
+	default:
+		return fmt.Errorf("unknown algo type %q", req.Type)
+	}
+	return nil
+}
+
+// Start launches an algo in the background and returns its id.
+func (m *AlgoManager) Start(req AlgoRequest) (string, error) {
+	run, err := m.start(req)
+	if err != nil {
+		return "", err
+	}
+	return run.order.ID, nil
+}
+
+// Execute runs an algo to completion and reports the aggregated fill. It is
+// what strategies use when their execution mode is an algo.
+func (m *AlgoManager) Execute(req AlgoRequest) (OrderResponse, error) {
+	run, err := m.start(req)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+	}
+	<-run.done
+
+	order, _ := m.Get(run.order.ID)
+	if order.FilledSize == 0 {
+		err := fmt.Errorf("%s %s not filled: %s", order.Request.Type, order.ID, order.Error)
+		return OrderResponse{Success: false, OrderID: order.ID, Message: err.Error(), Status: string(order.Status)}, err
+	}
+	return OrderResponse{
+		Success:    true,
+		OrderID:    order.ID,
+		Status:     string(order.Status),
+		Message:    fmt.Sprintf("%s filled avgPx=%.6f size=%.8f", order.Request.Type, order.AvgPrice, order.FilledSize),
+		AvgPrice:   order.AvgPrice,
+		FilledSize: order.FilledSize,
+	}, nil
+}
+
+func (m *AlgoManager) start(req AlgoRequest) (*algoRun, error) {
+	if err := m.account.requireSigner(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if req.Size, err = m.account.roundSize(req.Coin, req.Size); err != nil {
+		return nil, err
+	}
+	if req.Type == AlgoTWAP {
+		if _, err := m.account.roundSize(req.Coin, req.Size/float64(req.Slices)); err != nil {
+			return nil, fmt.Errorf("twap slice too small, use fewer slices: %w", err)
+		}
+	}
+	if req.Type == AlgoIceberg {
+		if req.VisibleSize, err = m.account.roundSize(req.Coin, req.VisibleSize); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune(time.Now())
+	m.nextID++
+	ctx, cancel := context.WithCancel(m.account.ctx)
+	now := time.Now().UnixMilli()
+	run := &algoRun{
+		order: AlgoOrder{
+			ID:        fmt.Sprintf("%s-%s-%d", m.account.id, req.Type, m.nextID),
+			Request:   req,
+			Status:    AlgoRunning,
+			StartedAt: now,
+			UpdatedAt: now,
+		},
+		resume: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.runs[run.order.ID] = run
+
+	go m.run(run)
+	return run, nil
+}
+
+func (m *AlgoManager) Pause(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[id]
+	if !ok {
+		return fmt.Errorf("algo %s not found", id)
+	}
+	if run.order.Status != AlgoRunning {
+		return fmt.Errorf("algo %s is %s", id, run.order.Status)
+	}
+	run.paused = true
+	run.order.Status = AlgoPaused
+	run.order.UpdatedAt = time.Now().UnixMilli()
+	if run.stopClip != nil {
+		run.stopClip()
+	}
+	return nil
+}
+
+func (m *AlgoManager) Resume(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[id]
+	if !ok {
+		return fmt.Errorf("algo %s not found", id)
+	}
+	if run.order.Status != AlgoPaused {
+		return fmt.Errorf("algo %s is %s", id, run.order.Status)
+	}
+	run.paused = false
+	run.order.Status = AlgoRunning
+	run.order.UpdatedAt = time.Now().UnixMilli()
+	close(run.resume)
+	run.resume = make(chan struct{})
+	return nil
+}
+
+func (m *AlgoManager) Cancel(id string) error {
+	m.mu.Lock()
+	run, ok := m.runs[id]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("algo %s not found", id)
+	}
+	run.cancel()
+	return nil
+}
+
+func (m *AlgoManager) CancelAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, run := range m.runs {
+		run.cancel()
+	}
+}
+
+func (m *AlgoManager) Get(id string) (AlgoOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[id]
+	if !ok {
+		return AlgoOrder{}, fmt.Errorf("algo %s not found", id)
+	}
+	return run.order, nil
+}
+
+func (m *AlgoManager) List() []AlgoOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune(time.Now())
+	result := make([]AlgoOrder, 0, len(m.runs))
+	for _, run := range m.runs {
+		result = append(result, run.order)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt > result[j].StartedAt
+	})
+	return result
+}
+
+// prune drops the algos that finished more than algoRetention before now.
+// The caller holds m.mu.
+func (m *AlgoManager) prune(now time.Time) {
+	cutoff := now.Add(-algoRetention).UnixMilli()
+	for id, run := range m.runs {
+		if run.order.finished() && run.order.UpdatedAt < cutoff {
+			delete(m.runs, id)
+		}
+	}
+}
+
+func (o AlgoOrder) finished() bool {
+	return o.Status == AlgoCompleted || o.Status == AlgoCancelled || o.Status == AlgoFailed
+}
+
+func (m *AlgoManager) run(run *algoRun) {
+	defer close(run.done)
+	defer run.cancel()
+
+	var err error
+	switch run.order.Request.Type {
+	case AlgoTWAP:
+		err = m.runTWAP(run)
+	case AlgoIceberg:
+		err = m.runIceberg(run)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case err != nil && run.ctx.Err() != nil:
+		run.order.Status = AlgoCancelled
+	case err != nil:
+		run.order.Status = AlgoFailed
+		run.order.Error = err.Error()
+	default:
+		run.order.Status = AlgoCompleted
+	}
+	run.order.UpdatedAt = time.Now().UnixMilli()
+	slog.Info("algo finished", "algo", run.order.ID, "account", m.account.id, "coin", run.order.Request.Coin,
+		"status", run.order.Status, "filled", run.order.FilledSize, "avgPx", run.order.AvgPrice, "error", run.order.Error)
+}
+
+func (m *AlgoManager) runTWAP(run *algoRun) error {
+	req := run.order.Request
+	m.setTotal(run, req.Slices)
+
+	interval := time.Duration(req.DurationSeconds * float64(time.Second) / float64(req.Slices))
+	sliceSize := req.Size / float64(req.Slices)
+
+	for i := 0; i < req.Slices; i++ {
+		if i > 0 {
+			wait := jitter(interval, req.Randomize)
+			if err := m.wait(run, wait); err != nil {
+				return err
+			}
+		}
+		if err := m.waitResumed(run); err != nil {
+			return err
+		}
+
+		remaining := req.Size - m.filled(run)
+		size := remaining
+		if i < req.Slices-1 {
+			size = min(remaining, sliceSize*(1+req.Randomize*(2*rand.Float64()-1)))
+		}
+		size, err := m.account.roundSize(req.Coin, size)
+		if err != nil {
+			if i == req.Slices-1 {
+				break
+			}
+			continue
+		}
+
+		resp, err := m.account.marketOrder(run.ctx, req.Coin, req.IsBuy, size, req.ReduceOnly)
+		if err != nil {
+			return err
+		}
+		m.recordFill(run, resp)
+	}
+	return nil
+}
+
+func (m *AlgoManager) runIceberg(run *algoRun) error {
+	req := run.order.Request
+	clips := int((req.Size + req.VisibleSize - 1e-9) / req.VisibleSize)
+	m.setTotal(run, clips)
+
+	exec := DefaultExecutionConfig()
+	exec.Mode = ExecutionLimit
+	if req.PostOnly {
+		exec.Mode = ExecutionPostOnly
+	}
+	exec.MaxChaseBps = req.MaxChaseBps
+	if req.ClipTimeoutSecs > 0 {
+		exec.Timeout = time.Duration(req.ClipTimeoutSecs * float64(time.Second))
+	}
+
+	for {
+		if err := m.waitResumed(run); err != nil {
+			return err
+		}
+		clip, err := m.account.roundSize(req.Coin, min(req.VisibleSize, req.Size-m.filled(run)))
+		if err != nil {
+			return nil
+		}
+
+		if err := m.account.checkOrderRisk(req.Coin, clip, req.ReduceOnly); err != nil {
+			return err
+		}
+
+		// Pausing or cancelling stops the clip's resting order; a paused
+		// iceberg works the rest once resumed.
+		ctx, stop := context.WithCancel(run.ctx)
+		m.mu.Lock()
+		run.stopClip = stop
+		if run.paused {
+			stop()
+		}
+		m.mu.Unlock()
+		resp, err := m.account.executeLimit(ctx, req.Coin, req.IsBuy, clip, req.ReduceOnly, exec)
+		m.mu.Lock()
+		run.stopClip = nil
+		m.mu.Unlock()
+		stop()
+
+		if err == nil || resp.FilledSize > 0 {
+			m.recordFill(run, resp)
+		}
+		if err != nil && (run.ctx.Err() != nil || ctx.Err() == nil) {
+			return err
+		}
+	}
+}
+
+func (m *AlgoManager) wait(run *algoRun, d time.Duration) error {
+	select {
+	case <-run.ctx.Done():
+		return run.ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (m *AlgoManager) waitResumed(run *algoRun) error {
+	for {
+		m.mu.Lock()
+		paused := run.paused
+		resume := run.resume
+		m.mu.Unlock()
+		if !paused {
+			return run.ctx.Err()
+		}
+		select {
+		case <-run.ctx.Done():
+			return run.ctx.Err()
+		case <-resume:
+		}
+	}
+}
+
+func (m *AlgoManager) setTotal(run *algoRun, total int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run.order.SlicesTotal = total
+}
+
+func (m *AlgoManager) filled(run *algoRun) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return run.order.FilledSize
+}
+
+func (m *AlgoManager) recordFill(run *algoRun, resp OrderResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run.order.SlicesDone++
+	if resp.FilledSize > 0 {
+		run.notional += resp.FilledSize * resp.AvgPrice
+		run.order.FilledSize += resp.FilledSize
+		run.order.AvgPrice = run.notional / run.order.FilledSize
+	}
+	run.order.UpdatedAt = time.Now().UnixMilli()
+}
+
+func (a *Account) checkOrderRisk(coin string, size float64, reduceOnly bool) error {
+	if !reduceOnly {
+		return a.checkRisk(coin, size)
+	}
+	if a.risk != nil {
+		return a.risk.CheckReduceOrder(coin)
+	}
+	return nil
+}
+
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + fraction*(2*rand.Float64()-1)))
+}
+
+// marketOrder sends an IOC order priced 5% through the mid, the same way
+// MarketOpen does, but honouring reduceOnly and the risk checks. Cancelling
+// ctx aborts the request.
+func (a *Account) marketOrder(ctx context.Context, coin string, isBuy bool, size float64, reduceOnly bool) (OrderResponse, error) {
+	size, err := a.roundSize(coin, size)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+	if err := a.checkOrderRisk(coin, size, reduceOnly); err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+
+	price, err := a.exchange.SlippagePrice(ctx, coin, isBuy, 0.05, nil)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+	}
+
+	resp, err := a.exchange.Order(ctx, hyperliquid.CreateOrderRequest{
+		Coin:       coin,
+		IsBuy:      isBuy,
+		Size:       size,
+		Price:      price,
+		OrderType:  hyperliquid.OrderType{Limit: &hyperliquid.LimitOrderType{Tif: hyperliquid.TifIoc}},
+		ReduceOnly: reduceOnly,
+	}, nil)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+	}
+	return parseOrderResponse(resp), nil
+}
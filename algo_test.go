@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAlgoCancelAbortsSlice(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	algos := fake.account(ctx).Algos()
+	fake.orderDelay = 500 * time.Millisecond
+	fake.inFlight = make(chan struct{}, 1)
+
+	id, err := algos.Start(AlgoRequest{Type: AlgoTWAP, Coin: "BTC", IsBuy: true, Size: 0.2, DurationSeconds: 60, Slices: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-fake.inFlight:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no slice was sent")
+	}
+
+	start := time.Now()
+	if err := algos.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	algos.mu.Lock()
+	done := algos.runs[id].done
+	algos.mu.Unlock()
+	<-done
+	if elapsed := time.Since(start); elapsed > fake.orderDelay/2 {
+		t.Errorf("cancel took %v while a slice was in flight", elapsed)
+	}
+	if order, err := algos.Get(id); err != nil || order.Status != AlgoCancelled {
+		t.Errorf("algo %+v, %v, want cancelled", order, err)
+	}
+}
+
+func TestAlgoPrune(t *testing.T) {
+	algos := NewAlgoManager(nil)
+	now := time.Now()
+	for id, order := range map[string]AlgoOrder{
+		"old":     {Status: AlgoCompleted, UpdatedAt: now.Add(-2 * algoRetention).UnixMilli()},
+		"recent":  {Status: AlgoFailed, UpdatedAt: now.Add(-algoRetention / 2).UnixMilli()},
+		"running": {Status: AlgoRunning, UpdatedAt: now.Add(-2 * algoRetention).UnixMilli()},
+		"paused":  {Status: AlgoPaused, UpdatedAt: now.Add(-2 * algoRetention).UnixMilli()},
+	} {
+		order.ID = id
+		algos.runs[id] = &algoRun{order: order}
+	}
+
+	orders := algos.List()
+	if len(orders) != 3 {
+		t.Fatalf("listed %+v, want all but the old finished algo", orders)
+	}
+	if _, err := algos.Get("old"); err == nil {
+		t.Error("old finished algo was kept")
+	}
+}
@@ -2,29 +2,58 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/redis/go-redis/v9"
 	hyperliquid "github.com/sonirico/go-hyperliquid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type App struct {
-	ctx     context.Context
-	rdb     *redis.Client
-	source  *Source
-	account *Account
-	engine  *StrategyEngine
-	config  Config
+	ctx        context.Context
+	rdb        *redis.Client
+	source     *Source
+	accounts   *AccountRegistry
+	engine     *StrategyEngine
+	strategies *StrategyRegistry
+	risk       *RiskManager
+	store      *Store
+	events     *EventBus
+	config     Config
+	// mu guards config, engine and mainnetConfirmed. Reloading the accounts
+	// holds it too, so they are never rebuilt from a config being changed.
+	mu sync.RWMutex
+	// mainnetConfirmed arms order entry on mainnet for this session.
+	mainnetConfirmed bool
 }
 
-func NewApp() *App {
-	config := NewConfig()
+func NewApp(config Config) *App {
+	confirmed, _ := strconv.ParseBool(os.Getenv(confirmMainnetEnv))
+	store, err := OpenStore(config.Settings.DatabasePath)
+	if err != nil {
+		slog.Error("open store failed, strategies will not be persisted", "error", err)
+		store = nil
+	}
 	return &App{
 		source: NewSource(config),
 		rdb: redis.NewClient(&redis.Options{
-			Addr: config.RedisURL,
+			Addr: config.Settings.RedisURL,
 		}),
-		config: config,
+		accounts:   NewAccountRegistry(),
+		strategies: NewStrategyRegistry(),
+		risk:       NewRiskManager(config.Settings.Risk),
+		store:      store,
+		events:     NewEventBus(strategyEventBuffer),
+		config:     config,
+
+		mainnetConfirmed: confirmed,
 	}
 }
 
@@ -32,12 +61,34 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.source.SetContext(ctx)
 	a.source.SetRedis(a.rdb)
-	a.account = NewAccount(ctx, a.config)
-	a.engine = NewStrategyEngine(a.source)
+	a.mu.Lock()
+	a.accounts.Load(ctx, a.config, a.risk)
+	a.mu.Unlock()
+	a.events.SetEmitter(func(ev StrategyEvent) {
+		runtime.EventsEmit(a.ctx, strategyEventsTopic, ev)
+	})
+	a.mu.Lock()
+	a.engine = NewStrategyEngine(a.source, a.store, a.events)
+	network := a.config.Network
+	a.mu.Unlock()
+	a.risk.SetOnTrip(a.killSwitch)
+	slog.Info("network", "network", network.Name, "url", network.APIURL)
+	a.loadDefinitions()
+	a.resumeStrategies()
 }
 
 func (a *App) shutdown(ctx context.Context) {
-	a.engine.StopAllStrategies()
+	a.mu.RLock()
+	mode := a.config.Settings.ShutdownMode
+	a.mu.RUnlock()
+	if mode == shutdownDetach {
+		a.strategyEngine().DetachAllStrategies()
+	} else {
+		a.strategyEngine().StopAllStrategies()
+	}
+	if a.store != nil {
+		a.store.Close()
+	}
 }
 
 func (a *App) FetchCandles(symbol string, interval string, limit int) (hyperliquid.Candles, error) {
@@ -48,42 +99,274 @@ func (a *App) FetchCandlesBefore(symbol string, interval string, limit int, befo
 	return a.source.FetchCandlesBefore(symbol, interval, limit, beforeTimestamp)
 }
 
-func (a *App) StrategyRun(name, symbol string, interval string, params map[string]any) error {
-	log.Printf("Strategy Run: %s %s %s %v\n", name, symbol, interval, params)
-	strategy := NewMaxTrendPointsStrategy(params)
+// ListStrategies returns the strategies that can be backtested and run, with
+// the parameters each accepts.
+func (a *App) ListStrategies() []StrategyInfo {
+	return a.strategies.List()
+}
+
+// StrategyRun starts the registered strategy kind live under id.
+func (a *App) StrategyRun(accountID, id, kind, symbol string, interval string, params map[string]any) error {
+	slog.Info("strategy run", "account", accountID, "strategy", id, "kind", kind, "symbol", symbol, "interval", interval)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	account, err := a.accounts.Get(accountID)
+	if err != nil {
+		return err
+	}
+	if account.IsReadOnly() {
+		return fmt.Errorf("wallet is read-only, unlock a keystore to run strategies")
+	}
+	if a.risk.IsKilled() {
+		return fmt.Errorf("kill switch active, reset it before starting strategies")
+	}
+	if err := a.tradingConfirmed(); err != nil {
+		return err
+	}
+	impl, params, err := a.strategies.New(kind, params)
+	if err != nil {
+		return err
+	}
+	strategy := NewLiveStrategy(kind, impl, params)
 	strategy.Symbol = symbol
 	strategy.Interval = interval
-	strategy.account = a.account
-	return a.engine.StartStrategy(name, *strategy)
+	strategy.AccountID = account.ID()
+	strategy.account = account
+	return a.engine.StartStrategy(id, strategy)
 }
 
-func (a *App) StrategyBacktest(symbol string, interval string, limit int, params map[string]any) (*BacktestOutput, error) {
+func (a *App) StrategyBacktest(kind, symbol string, interval string, limit int, params map[string]any) (*BacktestOutput, error) {
+	strategy, _, err := a.strategies.New(kind, params)
+	if err != nil {
+		return nil, err
+	}
 	candles, err := a.source.FetchHistoricalCandles(symbol, interval, limit)
 	if err != nil {
 		return nil, err
 	}
-	strategy := NewMaxTrendPointsStrategy(params)
+	symbols, err := a.source.fetchSymbolCandles(strategy, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	feedSymbolCandles(strategy, candles, symbols)
 	return strategy.Backtest(candles)
 }
 
 func (a *App) StopLiveStrategy(name string) error {
-	return a.engine.StopStrategy(name)
+	return a.strategyEngine().StopStrategy(name)
 }
 
-func (a *App) GetRunningStrategies() []MaxTrendPointsStrategy {
-	return a.engine.GetRunningStrategies()
+// PauseLiveStrategy stops a strategy from trading new candles while keeping
+// its position open.
+func (a *App) PauseLiveStrategy(name string) error {
+	return a.strategyEngine().PauseStrategy(name)
+}
+
+func (a *App) ResumeLiveStrategy(name string) error {
+	if a.risk.IsKilled() {
+		return fmt.Errorf("kill switch active, reset it before resuming strategies")
+	}
+	if err := a.requireTradingConfirmed(); err != nil {
+		return err
+	}
+	return a.strategyEngine().ResumeStrategy(name)
+}
+
+func (a *App) GetRunningStrategies() []LiveStrategy {
+	return a.strategyEngine().GetRunningStrategies()
+}
+
+// GetStrategyEvents returns the strategy's most recent events, oldest first.
+// New events are pushed on the "strategy:event" topic as they happen.
+func (a *App) GetStrategyEvents(id string, limit int) []StrategyEvent {
+	return a.events.Events(id, limit)
+}
+
+// GetLogs returns entries from the JSON log files for the log viewer.
+func (a *App) GetLogs(filter LogFilter) ([]LogEntry, error) {
+	return ReadLogs(filter)
 }
 
 func (a *App) GetWalletAddress() string {
-	return a.account.address
+	return a.accounts.Main().GetAddress()
 }
 
+func (a *App) GetWalletStatus() WalletStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return WalletStatus{
+		Address:      a.config.Address,
+		AgentAddress: a.config.AgentAddress,
+		ReadOnly:     a.accounts.Main().IsReadOnly(),
+		Locked:       a.config.Locked(),
+		KeystorePath: a.config.Settings.KeystorePath,
+	}
+}
+
+func (a *App) GetAgentStatus() (AgentStatus, error) {
+	return a.accounts.Main().AgentStatus()
+}
+
+func (a *App) UnlockWallet(passphrase string) error {
+	a.mu.Lock()
+	if err := a.config.Unlock(passphrase); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.accounts.Load(a.ctx, a.config, a.risk)
+	address := a.config.Address
+	a.mu.Unlock()
+	slog.Info("wallet unlocked", "address", address)
+	a.resumeStrategies()
+	return nil
+}
+
+// EncryptLegacySecret moves the plaintext .secret key into an encrypted
+// keystore. The .secret file is left in place for the user to delete.
+func (a *App) EncryptLegacySecret(passphrase string) error {
+	privateKey, err := readLegacySecret()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", legacySecretPath, err)
+	}
+	a.mu.RLock()
+	path := a.config.Settings.KeystorePath
+	a.mu.RUnlock()
+	if err := writeKeystore(path, privateKey, passphrase); err != nil {
+		return err
+	}
+	slog.Info("wrote encrypted keystore, delete the legacy secret", "keystore", path, "secret", legacySecretPath)
+	return nil
+}
+
+func (a *App) GetAccounts() []AccountInfo {
+	return a.accounts.List()
+}
+
+// AddAccount registers a sub-account or vault traded with the main key.
+func (a *App) AddAccount(ac AccountConfig) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.accounts.addConfigured(a.ctx, a.config, ac, a.risk); err != nil {
+		return err
+	}
+	previous := a.config.Settings.clone()
+	a.config.Settings.Accounts = append(a.config.Settings.Accounts, ac)
+	return a.savePreferences(previous)
+}
+
+func (a *App) RemoveAccount(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.accountBusy(id); err != nil {
+		return err
+	}
+	if err := a.accounts.Remove(id); err != nil {
+		return err
+	}
+	previous := a.config.Settings.clone()
+	a.config.Settings.Accounts = slices.DeleteFunc(a.config.Settings.Accounts, func(ac AccountConfig) bool {
+		return ac.ID == id
+	})
+	return a.savePreferences(previous)
+}
+
+// GetPortfolioSummary returns the aggregated view across every registered
+// account. Accounts that fail to load are logged and left out.
 func (a *App) GetPortfolioSummary() (PortfolioSummary, error) {
-	return a.account.GetPortfolioSummary()
+	accounts := a.accounts.All()
+	summaries := make([]PortfolioSummary, 0, len(accounts))
+	var lastErr error
+	for _, account := range accounts {
+		sum, err := account.GetPortfolioSummary()
+		if err != nil {
+			slog.Warn("portfolio failed", "account", account.ID(), "error", err)
+			lastErr = err
+			continue
+		}
+		summaries = append(summaries, sum)
+	}
+	if len(summaries) == 0 && lastErr != nil {
+		return PortfolioSummary{}, lastErr
+	}
+	return AggregatePortfolio(summaries), nil
+}
+
+func (a *App) GetAccountPortfolioSummary(accountID string) (PortfolioSummary, error) {
+	account, err := a.accounts.Get(accountID)
+	if err != nil {
+		return PortfolioSummary{}, err
+	}
+	return account.GetPortfolioSummary()
 }
 
 func (a *App) GetActivePositions() ([]ActivePosition, error) {
-	return a.account.GetActivePositions()
+	sum, err := a.GetPortfolioSummary()
+	if err != nil {
+		return nil, err
+	}
+	return sum.Positions, nil
+}
+
+func (a *App) PlaceOrder(ticket OrderTicket) (OrderResponse, error) {
+	slog.Info("manual order", "account", ticket.AccountID, "coin", ticket.Coin, "kind", ticket.Kind, "size", ticket.Size, "price", ticket.Price)
+	if !ticket.ReduceOnly {
+		if err := a.requireTradingConfirmed(); err != nil {
+			return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+		}
+	}
+	account, err := a.accounts.Get(ticket.AccountID)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+	return account.PlaceOrder(ticket)
+}
+
+func (a *App) ModifyOrder(oid int64, ticket OrderTicket) (OrderResponse, error) {
+	slog.Info("manual modify", "account", ticket.AccountID, "coin", ticket.Coin, "oid", oid, "kind", ticket.Kind, "size", ticket.Size, "price", ticket.Price)
+	if err := a.requireTradingConfirmed(); err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+	account, err := a.accounts.Get(ticket.AccountID)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+	return account.ModifyOrder(oid, ticket)
+}
+
+func (a *App) GetAssetSpec(coin string) (AssetSpec, error) {
+	return a.accounts.Main().Assets().Get(coin)
+}
+
+func (a *App) CancelOrder(accountID, coin string, oid int64) error {
+	account, err := a.accounts.Get(accountID)
+	if err != nil {
+		return err
+	}
+	return account.CancelOrder(coin, oid)
+}
+
+// CancelAllOrders cancels open orders on every account and returns the total
+// number cancelled.
+func (a *App) CancelAllOrders() (int, error) {
+	total := 0
+	var errs []error
+	for _, account := range a.accounts.All() {
+		n, err := account.CancelAllOrders()
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", account.ID(), err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+func (a *App) ClosePosition(accountID, coin string, size float64) (OrderResponse, error) {
+	slog.Info("manual close", "account", accountID, "coin", coin, "size", size)
+	account, err := a.accounts.Get(accountID)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+	return account.ClosePosition(coin, size)
 }
 
 func (a *App) InvalidateCache() error {
@@ -93,3 +376,231 @@ func (a *App) InvalidateCache() error {
 func (a *App) InvalidateCacheForSymbol(symbol string) error {
 	return a.source.InvalidateCacheForSymbol(symbol)
 }
+
+func (a *App) GetRiskStatus() RiskStatus {
+	return a.risk.Status()
+}
+
+func (a *App) SetRiskLimits(limits RiskLimits) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.risk.SetLimits(limits); err != nil {
+		return err
+	}
+	previous := a.config.Settings.clone()
+	a.config.Settings.Risk = limits
+	return a.savePreferences(previous)
+}
+
+func (a *App) GetConfig() Settings {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config.Settings.clone()
+}
+
+// UpdateConfig validates and applies new settings and saves them as user
+// preferences. Redis, database and keystore paths take effect on restart.
+// Accounts added to the list are registered and accounts removed from it
+// closed, the rest keep running; removing an account, or switching network,
+// is refused while strategies or algo orders run on it. The network switch
+// comes last, once everything else has been applied.
+func (a *App) UpdateConfig(settings Settings) error {
+	settings.Network = Network(strings.ToLower(string(settings.Network)))
+	settings.Logging.Level = strings.ToLower(settings.Logging.Level)
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	for _, ac := range settings.Accounts {
+		if err := ac.Validate(); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	previous := a.config.Settings.clone()
+	network := settings.Network
+	if err := a.applySettings(previous, settings); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.mu.Unlock()
+
+	var switchErr error
+	if network != previous.Network {
+		switchErr = a.SetNetwork(string(network))
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return errors.Join(switchErr, a.savePreferences(previous))
+}
+
+// applySettings applies everything in settings but the network. It checks
+// what can fail before it changes anything. Callers hold a.mu.
+func (a *App) applySettings(previous, settings Settings) error {
+	removed := slices.DeleteFunc(slices.Clone(previous.Accounts), func(ac AccountConfig) bool {
+		return slices.Contains(settings.Accounts, ac)
+	})
+	added := slices.DeleteFunc(slices.Clone(settings.Accounts), func(ac AccountConfig) bool {
+		return slices.Contains(previous.Accounts, ac)
+	})
+	var busy []string
+	for _, ac := range removed {
+		busy = append(busy, ac.ID)
+	}
+	if settings.Network != previous.Network {
+		busy = busy[:0]
+		for _, account := range a.accounts.All() {
+			busy = append(busy, account.ID())
+		}
+	}
+	for _, id := range busy {
+		if err := a.accountBusy(id); err != nil {
+			return err
+		}
+	}
+	for _, ac := range added {
+		replaced := slices.ContainsFunc(removed, func(r AccountConfig) bool { return r.ID == ac.ID })
+		if _, err := a.accounts.Get(ac.ID); err == nil && !replaced {
+			return fmt.Errorf("account %s already registered", ac.ID)
+		}
+	}
+	if settings.Logging != previous.Logging {
+		if err := setupLogging(settings.Logging); err != nil {
+			return err
+		}
+	}
+
+	// Validate has checked the limits, so this cannot fail.
+	a.risk.SetLimits(settings.Risk)
+	for _, ac := range removed {
+		if err := a.accounts.Remove(ac.ID); err != nil {
+			slog.Warn("remove account", "account", ac.ID, "error", err)
+		}
+	}
+	settings.Network = previous.Network
+	a.config.Settings = settings
+	for _, ac := range added {
+		if err := a.accounts.addConfigured(a.ctx, a.config, ac, a.risk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountBusy returns an error when a strategy or an algo order is running
+// on account id. Callers hold a.mu.
+func (a *App) accountBusy(id string) error {
+	for _, strategy := range a.engine.GetRunningStrategies() {
+		if strategy.AccountID == id {
+			return fmt.Errorf("strategy %s is running on account %s", strategy.ID, id)
+		}
+	}
+	account, err := a.accounts.Get(id)
+	if err != nil {
+		return nil
+	}
+	for _, order := range account.Algos().List() {
+		if order.Status == AlgoRunning || order.Status == AlgoPaused {
+			return fmt.Errorf("algo order %s is still active on account %s", order.ID, id)
+		}
+	}
+	return nil
+}
+
+// savePreferences saves what changed from previous, the settings before the
+// change, as user preferences. Callers hold a.mu.
+func (a *App) savePreferences(previous Settings) error {
+	if err := SavePreferences(a.config.BaseSettings, previous, a.config.Settings); err != nil {
+		slog.Error("save preferences failed", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (a *App) TripKillSwitch(reason string) {
+	a.risk.Trip(reason)
+}
+
+func (a *App) ResetKillSwitch() {
+	a.risk.Reset()
+	runtime.EventsEmit(a.ctx, "risk:status", a.risk.Status())
+}
+
+func (a *App) StartAlgoOrder(accountID string, req AlgoRequest) (string, error) {
+	if a.risk.IsKilled() && !req.ReduceOnly {
+		return "", fmt.Errorf("kill switch active")
+	}
+	if !req.ReduceOnly {
+		if err := a.requireTradingConfirmed(); err != nil {
+			return "", err
+		}
+	}
+	account, err := a.accounts.Get(accountID)
+	if err != nil {
+		return "", err
+	}
+	return account.Algos().Start(req)
+}
+
+func (a *App) PauseAlgoOrder(id string) error {
+	algos, err := a.algoManager(id)
+	if err != nil {
+		return err
+	}
+	return algos.Pause(id)
+}
+
+func (a *App) ResumeAlgoOrder(id string) error {
+	algos, err := a.algoManager(id)
+	if err != nil {
+		return err
+	}
+	return algos.Resume(id)
+}
+
+func (a *App) CancelAlgoOrder(id string) error {
+	algos, err := a.algoManager(id)
+	if err != nil {
+		return err
+	}
+	return algos.Cancel(id)
+}
+
+func (a *App) GetAlgoOrders() []AlgoOrder {
+	var orders []AlgoOrder
+	for _, account := range a.accounts.All() {
+		orders = append(orders, account.Algos().List()...)
+	}
+	return orders
+}
+
+// algoManager finds the account running the algo with the given id.
+func (a *App) algoManager(id string) (*AlgoManager, error) {
+	for _, account := range a.accounts.All() {
+		if _, err := account.Algos().Get(id); err == nil {
+			return account.Algos(), nil
+		}
+	}
+	return nil, fmt.Errorf("algo order %s not found", id)
+}
+
+func (a *App) killSwitch(reason string) {
+	slog.Error("kill switch: halting strategies", "reason", reason)
+	a.strategyEngine().HaltAllStrategies("Kill Switch: " + reason)
+
+	for _, account := range a.accounts.All() {
+		account.Algos().CancelAll()
+
+		if n, err := account.CancelAllOrders(); err != nil {
+			slog.Error("kill switch: cancel orders failed", "account", account.ID(), "error", err)
+		} else {
+			slog.Warn("kill switch: cancelled open orders", "account", account.ID(), "orders", n)
+		}
+
+		if err := account.CloseAllPositions("Kill Switch: " + reason); err != nil {
+			slog.Error("kill switch: flatten positions failed", "account", account.ID(), "error", err)
+		}
+	}
+
+	runtime.EventsEmit(a.ctx, "risk:status", a.risk.Status())
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newTestApp returns an app started on the fake, with flip registered and
+// preferences saved to a temporary directory.
+func newTestApp(t *testing.T, fake *fakeExchange) *App {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	config := fake.config()
+	a := &App{
+		ctx:        ctx,
+		source:     fake.source(),
+		accounts:   NewAccountRegistry(),
+		strategies: NewStrategyRegistry(),
+		risk:       NewRiskManager(config.Settings.Risk),
+		events:     NewEventBus(strategyEventBuffer),
+		config:     config,
+	}
+	a.accounts.Load(ctx, config, a.risk)
+	a.engine = NewStrategyEngine(a.source, nil, a.events)
+	a.engine.poll = time.Millisecond
+	t.Cleanup(a.engine.StopAllStrategies)
+	a.strategies.Register(StrategyInfo{ID: "flip", Name: "Flip", Source: "builtin"}, func(params map[string]any) (Strategy, error) {
+		return flipStrategy{}, nil
+	})
+	return a
+}
+
+func TestUpdateConfigKeepsRunningAccounts(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	a := newTestApp(t, fake)
+	main := a.accounts.Main()
+	if err := a.StrategyRun(mainAccountID, "flip", "flip", "BTC", "1m", map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := AccountConfig{ID: "sub", Name: "Sub", Address: common.HexToAddress("0x1").Hex(), Kind: AccountSubAccount}
+	settings := a.GetConfig()
+	settings.Accounts = []AccountConfig{sub}
+	if err := a.UpdateConfig(settings); err != nil {
+		t.Fatal(err)
+	}
+	if a.accounts.Main() != main {
+		t.Error("adding an account replaced the main account")
+	}
+	if _, err := a.accounts.Get("sub"); err != nil {
+		t.Errorf("added account: %v", err)
+	}
+	if n := len(a.strategyEngine().GetRunningStrategies()); n != 1 {
+		t.Errorf("%d strategies running, want 1", n)
+	}
+
+	// A refused network switch changes nothing else.
+	settings = a.GetConfig()
+	settings.Network = NetworkTestnet
+	settings.Accounts = nil
+	settings.Risk.MaxOrdersPerMinute = 7
+	if err := a.UpdateConfig(settings); err == nil || !strings.Contains(err.Error(), "strategy flip is running") {
+		t.Fatalf("UpdateConfig error = %v, want the switch refused", err)
+	}
+	if got := a.GetConfig(); got.Network != NetworkLocal || len(got.Accounts) != 1 || a.risk.Limits().MaxOrdersPerMinute == 7 {
+		t.Errorf("settings after a refused switch = %+v", got)
+	}
+
+	settings.Network = NetworkLocal
+	if err := a.UpdateConfig(settings); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.accounts.Get("sub"); err == nil {
+		t.Error("removed account is still registered")
+	}
+	if a.accounts.Main() != main || a.risk.Limits().MaxOrdersPerMinute != 7 {
+		t.Error("removing an account replaced the main account or dropped the risk limits")
+	}
+}
+
+// TestUpdateConfigConcurrentWithStrategyRun adds and removes an account while
+// strategies start and stop on it. Run with -race.
+func TestUpdateConfigConcurrentWithStrategyRun(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	a := newTestApp(t, fake)
+	sub := AccountConfig{ID: "sub", Name: "Sub", Address: common.HexToAddress("0x1").Hex(), Kind: AccountSubAccount}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 20 {
+			settings := a.GetConfig()
+			settings.Accounts = nil
+			if i%2 == 0 {
+				settings.Accounts = []AccountConfig{sub}
+			}
+			settings.Risk.MaxOrdersPerMinute = 30 + i
+			if err := a.UpdateConfig(settings); err != nil && !strings.Contains(err.Error(), "is running on account sub") {
+				t.Errorf("UpdateConfig: %v", err)
+			}
+		}
+	}()
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			settings := a.GetConfig()
+			if _, err := a.accounts.Get("sub"); (err == nil) != (len(settings.Accounts) == 1) {
+				t.Errorf("registered account and settings disagree: %v, %+v", err, settings.Accounts)
+			}
+			return
+		default:
+		}
+		id := fmt.Sprintf("flip-%d", i)
+		if err := a.StrategyRun("sub", id, "flip", "BTC", "1m", map[string]any{}); err == nil {
+			if err := a.StopLiveStrategy(id); err != nil {
+				t.Errorf("StopLiveStrategy: %v", err)
+			}
+		}
+	}
+}
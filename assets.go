@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+const assetMetaTTL = 30 * time.Minute
+
+type AssetSpec struct {
+	Name         string
+	SzDecimals   int
+	MaxLeverage  int
+	OnlyIsolated bool
+	IsDelisted   bool
+}
+
+// AssetMetaCache keeps the perp universe from the `meta` endpoint so every
+// order path can round sizes and prices to valid increments without a round
+// trip per order.
+type AssetMetaCache struct {
+	mu        sync.RWMutex
+	ctx       context.Context
+	info      *hyperliquid.Info
+	assets    map[string]AssetSpec
+	fetchedAt time.Time
+}
+
+func NewAssetMetaCache(ctx context.Context, info *hyperliquid.Info) *AssetMetaCache {
+	return &AssetMetaCache{
+		ctx:    ctx,
+		info:   info,
+		assets: make(map[string]AssetSpec),
+	}
+}
+
+func (c *AssetMetaCache) Get(coin string) (AssetSpec, error) {
+	c.mu.RLock()
+	spec, ok := c.assets[coin]
+	stale := time.Since(c.fetchedAt) > assetMetaTTL
+	c.mu.RUnlock()
+
+	if ok && !stale {
+		return spec, nil
+	}
+	if err := c.Refresh(); err != nil {
+		if ok {
+			return spec, nil
+		}
+		return AssetSpec{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	spec, ok = c.assets[coin]
+	if !ok {
+		return AssetSpec{}, fmt.Errorf("unknown asset %s", coin)
+	}
+	return spec, nil
+}
+
+func (c *AssetMetaCache) Refresh() error {
+	meta, err := c.info.Meta(c.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch meta: %w", err)
+	}
+
+	assets := make(map[string]AssetSpec, len(meta.Universe))
+	for _, asset := range meta.Universe {
+		assets[asset.Name] = AssetSpec{
+			Name:         asset.Name,
+			SzDecimals:   asset.SzDecimals,
+			MaxLeverage:  asset.MaxLeverage,
+			OnlyIsolated: asset.OnlyIsolated,
+			IsDelisted:   asset.IsDelisted,
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.assets = assets
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+func (s AssetSpec) LotSize() float64 {
+	return math.Pow(10, -float64(s.SzDecimals))
+}
+
+// RoundSize rounds size down to the asset's lot size so an order never ends up
+// larger than requested.
+func (s AssetSpec) RoundSize(size float64) (float64, error) {
+	pow := math.Pow(10, float64(s.SzDecimals))
+	rounded := math.Floor(size*pow+1e-9) / pow
+	if rounded <= 0 {
+		return 0, fmt.Errorf("size %v for %s rounds to zero (lot size %v)", size, s.Name, s.LotSize())
+	}
+	return rounded, nil
+}
+
+// RoundPrice rounds px to at most 5 significant figures and 6-szDecimals
+// decimals. Integer prices are always valid regardless of significant figures.
+func (s AssetSpec) RoundPrice(px float64) float64 {
+	if px <= 0 {
+		return px
+	}
+	if px == math.Trunc(px) {
+		return px
+	}
+	magnitude := math.Floor(math.Log10(px)) + 1
+	sigDecimals := 5 - int(magnitude)
+	if sigDecimals < 0 {
+		return math.Round(px)
+	}
+	decimals := min(sigDecimals, 6-s.SzDecimals)
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(px*pow) / pow
+}
+
+func (s AssetSpec) ValidSize(size float64) bool {
+	return hasDecimals(size, s.SzDecimals)
+}
+
+func (s AssetSpec) ValidPrice(px float64) bool {
+	return math.Abs(px-s.RoundPrice(px)) <= px*1e-12
+}
+
+func (a *Account) roundSize(coin string, size float64) (float64, error) {
+	spec, err := a.assets.Get(coin)
+	if err != nil {
+		return 0, err
+	}
+	return spec.RoundSize(size)
+}
+
+func (a *Account) roundPrice(coin string, px float64) (float64, error) {
+	spec, err := a.assets.Get(coin)
+	if err != nil {
+		return 0, err
+	}
+	return spec.RoundPrice(px), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import "testing"
+
+func TestRoundSize(t *testing.T) {
+	tests := []struct {
+		szDecimals int
+		size, want float64
+		err        bool
+	}{
+		{0, 12.9, 12, false},
+		{0, 3, 3, false},
+		{0, 0.9, 0, true},
+		{5, 0.123456789, 0.12345, false},
+		{5, 0.00001, 0.00001, false},
+		{5, 0.000009, 0, true},
+		// Floating point noise below the lot size does not round a size down.
+		{2, 0.29, 0.29, false},
+		{2, 0.1 + 0.2, 0.3, false},
+	}
+	for _, tt := range tests {
+		spec := AssetSpec{Name: "X", SzDecimals: tt.szDecimals}
+		got, err := spec.RoundSize(tt.size)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("szDecimals %d: RoundSize(%v) = %v, %v, want %v", tt.szDecimals, tt.size, got, err, tt.want)
+		}
+		if err == nil && !spec.ValidSize(got) {
+			t.Errorf("szDecimals %d: ValidSize(%v) = false", tt.szDecimals, got)
+		}
+	}
+
+	for _, tt := range []struct {
+		szDecimals int
+		size       float64
+		want       bool
+	}{
+		{0, 12, true},
+		{0, 12.5, false},
+		{5, 0.12345, true},
+		{5, 0.123456, false},
+	} {
+		if got := (AssetSpec{SzDecimals: tt.szDecimals}).ValidSize(tt.size); got != tt.want {
+			t.Errorf("szDecimals %d: ValidSize(%v) = %v, want %v", tt.szDecimals, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestRoundPrice(t *testing.T) {
+	tests := []struct {
+		name       string
+		szDecimals int
+		px, want   float64
+	}{
+		{"five significant figures", 0, 1234.5678, 1234.6},
+		{"integer above 100k", 0, 123456, 123456},
+		{"above 100k drops the decimals", 0, 123456.7, 123457},
+		{"five figures before the point", 5, 12345.67, 12346},
+		{"decimals capped by szDecimals 5", 5, 1.23456, 1.2},
+		{"decimals capped by szDecimals 0", 0, 0.000123456, 0.000123},
+		{"decimals capped by szDecimals 2", 2, 0.000123456, 0.0001},
+		{"small price with room", 0, 0.0123456, 0.012346},
+		{"zero", 3, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := AssetSpec{SzDecimals: tt.szDecimals}
+			if got := spec.RoundPrice(tt.px); got != tt.want {
+				t.Errorf("RoundPrice(%v) = %v, want %v", tt.px, got, tt.want)
+			}
+			if !spec.ValidPrice(tt.want) {
+				t.Errorf("ValidPrice(%v) = false", tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		szDecimals int
+		px         float64
+		want       bool
+	}{
+		{0, 123456, true},
+		{0, 123456.5, false},
+		{0, 1234.5, true},
+		{0, 1234.56, false},
+		{5, 1.2, true},
+		{5, 1.23, false},
+		{0, 0.000123, true},
+		{0, 0.0001234, false},
+	} {
+		if got := (AssetSpec{SzDecimals: tt.szDecimals}).ValidPrice(tt.px); got != tt.want {
+			t.Errorf("szDecimals %d: ValidPrice(%v) = %v, want %v", tt.szDecimals, tt.px, got, tt.want)
+		}
+	}
+}
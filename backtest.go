@@ -0,0 +1,201 @@
+package main
+
+import (
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// backtestSignals trades signals over candles with config the way
+// LiveStrategy.HandleSignal does, one position at a time: an opposite signal
+// reverses, a close signal flattens and anything still open is closed at the
+// last candle. Between signals the open position is managed candle by candle
+// as the engine manages it live; trend is the strategy's trend line, which
+// the "trend" trailing stop follows.
+func backtestSignals(config StrategyConfig, candles hyperliquid.Candles, signals []Signal, trend []float64) []Position {
+	positions := []Position{}
+	var currentPosition *Position
+	managed := 0
+	anchors := config.Stops.anchors(candles, trend)
+
+	// manage plays the candles up to index against the open position.
+	manage := func(index int) {
+		if currentPosition == nil || !currentPosition.IsOpen {
+			return
+		}
+		for i := max(managed, currentPosition.EntryIndex) + 1; i <= index; i++ {
+			if manageBacktestPosition(config, candles, anchorAt(anchors, i-1), currentPosition, i) {
+				positions = append(positions, *currentPosition)
+				break
+			}
+		}
+		managed = max(managed, index)
+	}
+
+	for _, signal := range signals {
+		manage(signal.Index)
+		if currentPosition != nil && currentPosition.IsOpen && signal.Index <= currentPosition.EntryIndex {
+			continue
+		}
+
+		if signal.Type == SignalClose {
+			if currentPosition != nil && currentPosition.IsOpen {
+				fill := simulateFill(candles, signal.Index, currentPosition.Side == "short", config.Execution)
+				closeBacktestPosition(config, candles, currentPosition, fill, signal.Reason)
+				positions = append(positions, *currentPosition)
+			}
+			continue
+		}
+		if signal.Type != SignalLong && signal.Type != SignalShort {
+			continue
+		}
+
+		side := "long"
+		if signal.Type == SignalShort {
+			side = "short"
+		}
+		if currentPosition != nil && currentPosition.IsOpen && currentPosition.Side == side {
+			if config.Scaling.canAdd("pyramid", currentPosition) {
+				fill := simulateFill(candles, signal.Index, side == "long", config.Execution)
+				size := config.Scaling.addSize(config.PositionSize, currentPosition)
+				currentPosition.addEntry(fill.Price, size, size*fill.Price*config.Execution.feePercent(fill.Maker)/100)
+			}
+			continue
+		}
+
+		fill := simulateFill(candles, signal.Index, side == "long", config.Execution)
+
+		// As live, an opposite signal exits even when the trade direction
+		// filter then skips the entry.
+		if currentPosition != nil && currentPosition.IsOpen {
+			closeBacktestPosition(config, candles, currentPosition, fill, signal.Reason)
+			positions = append(positions, *currentPosition)
+		}
+		if (config.TradeDirection == "long" && side == "short") ||
+			(config.TradeDirection == "short" && side == "long") {
+			continue
+		}
+
+		currentPosition = &Position{
+			EntryIndex: fill.Index,
+			EntryPrice: fill.Price,
+			EntryTime:  candles[fill.Index].Timestamp,
+			Side:       side,
+			Size:       config.PositionSize,
+			Fees:       config.PositionSize * fill.Price * config.Execution.feePercent(fill.Maker) / 100,
+			IsOpen:     true,
+			Entries:    1,
+			BasePrice:  fill.Price,
+		}
+	}
+
+	manage(len(candles) - 1)
+	if currentPosition != nil && currentPosition.IsOpen {
+		lastIdx := len(candles) - 1
+		fill := backtestFill{Index: lastIdx, Price: parseFloat(candles[lastIdx].Close)}
+		closeBacktestPosition(config, candles, currentPosition, fill, "End of Period")
+		positions = append(positions, *currentPosition)
+	}
+
+	return positions
+}
+
+func closeBacktestPosition(config StrategyConfig, candles hyperliquid.Candles, position *Position, fill backtestFill, reason string) {
+	position.ExitIndex = fill.Index
+	position.ExitTime = candles[fill.Index].Timestamp
+	fee := position.Size * fill.Price * config.Execution.feePercent(fill.Maker) / 100
+	settlePosition(position, fill.Price, fee, reason)
+}
+
+// candlePath is the order price is assumed to trade a candle's extremes in:
+// open, low, high, close on an up candle and open, high, low, close on a
+// down candle.
+func candlePath(candle hyperliquid.Candle) []float64 {
+	open, high, low, last := parseFloat(candle.Open), parseFloat(candle.High), parseFloat(candle.Low), parseFloat(candle.Close)
+	if last < open {
+		return []float64{open, high, low, last}
+	}
+	return []float64{open, low, high, last}
+}
+
+// manageBacktestPosition plays candle i against the open position the way
+// LiveStrategy.managePosition does on every tick. At each point of
+// candlePath the stop is checked, safety orders and the combined take-profit
+// trigger, and the stop then follows the price reached; anchor is the stop
+// anchor of the previous candle. Orders fill at their price, or at the open
+// when the candle gaps through, and pay the taker fee as the market orders
+// they are live. It reports whether the position was closed.
+func manageBacktestPosition(config StrategyConfig, candles hyperliquid.Candles, anchor float64, position *Position, i int) bool {
+	if !config.Scaling.enabled() && !config.Stops.enabled() {
+		return false
+	}
+	fee := config.Execution.feePercent(false) / 100
+	for j, price := range candlePath(candles[i]) {
+		fillAt := func(level float64) float64 {
+			if j == 0 {
+				return price
+			}
+			return level
+		}
+		exit := func(level float64, reason string) bool {
+			fill := fillAt(level)
+			position.ExitIndex = i
+			position.ExitTime = candles[i].Timestamp
+			settlePosition(position, fill, position.Size*fill*fee, reason)
+			return true
+		}
+		if stopHit(position, price) {
+			return exit(position.StopPrice, position.StopReason)
+		}
+		for config.Scaling.canAdd("dca", position) {
+			level := config.Scaling.safetyPrice(position)
+			if !beyond(position.Side, price, level, true) {
+				break
+			}
+			size := config.Scaling.addSize(config.PositionSize, position)
+			fill := fillAt(level)
+			position.addEntry(fill, size, size*fill*fee)
+		}
+		if config.Scaling.enabled() {
+			if level := config.takeProfitPrice(position); beyond(position.Side, price, level, false) {
+				return exit(level, "Take Profit")
+			}
+		}
+		config.updateStop(position, price, anchor)
+	}
+	return false
+}
+
+// positionPnL is the gross PnL of position marked at currentPrice.
+func positionPnL(position *Position, currentPrice float64) float64 {
+	priceDiff := 0.0
+	if position.Side == "long" {
+		priceDiff = currentPrice - position.EntryPrice
+	} else {
+		priceDiff = position.EntryPrice - currentPrice
+	}
+	percentageChange := (priceDiff / position.EntryPrice) * 100
+	return position.Size * position.EntryPrice * (percentageChange / 100)
+}
+
+// newBacktestOutput backtests signals and attaches the strategy's chart
+// output, which may be nil.
+func newBacktestOutput(strategy Strategy, config StrategyConfig, candles hyperliquid.Candles, signals []Signal, output *StrategyOutput) *BacktestOutput {
+	var trend []float64
+	if output != nil {
+		trend = output.TrendLines
+	}
+	positions := backtestSignals(config, candles, signals, trend)
+	result := &BacktestOutput{
+		Positions:          positions,
+		PerformanceMetrics: calculatePerformance(positions),
+		Signals:            signals,
+		StrategyName:       strategy.GetName(),
+		StrategyVersion:    "1.0",
+	}
+	if output != nil {
+		result.TrendLines = output.TrendLines
+		result.TrendColors = output.TrendColors
+		result.Directions = output.Directions
+		result.Labels = output.Labels
+	}
+	return result
+}
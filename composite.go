@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+	"gopkg.in/yaml.v3"
+)
+
+// CompositeDefinition combines registered strategies into one. Each child
+// holds long, short or flat from its own signals; Mode decides what the
+// composite holds from theirs:
+//
+//	all       every child agrees
+//	any       at least one child, and none on the other side
+//	vote      more than half of the children
+//	weighted  the weighted mean of the children (long 1, short -1, flat 0)
+//	          reaches Threshold
+//
+// The composite closes its position when the combination no longer holds,
+// unless Hold is set, in which case it keeps it until the other side wins.
+type CompositeDefinition struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description"`
+	Mode        string           `yaml:"mode"`
+	Threshold   float64          `yaml:"threshold"`
+	Hold        bool             `yaml:"hold"`
+	Strategies  []CompositeChild `yaml:"strategies"`
+}
+
+// CompositeChild is a strategy of a composite. Timeframe, e.g. "4h", runs
+// it on candles aggregated from the chart's; it must be a multiple of the
+// chart interval. A child on a longer timeframe only counts a bar once it
+// has closed.
+type CompositeChild struct {
+	Strategy  string         `yaml:"strategy"`
+	Params    map[string]any `yaml:"params"`
+	Timeframe string         `yaml:"timeframe"`
+	Weight    *float64       `yaml:"weight"`
+}
+
+// compileComposite decodes a composite definition and checks that every
+// child can be created from the registry.
+func compileComposite(src string, registry *StrategyRegistry) (*CompositeDefinition, error) {
+	var def CompositeDefinition
+	dec := yaml.NewDecoder(bytes.NewReader([]byte(src)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("invalid definition: %w", err)
+	}
+	if def.Name == "" {
+		return nil, fmt.Errorf("definition has no name")
+	}
+	switch def.Mode {
+	case "":
+		def.Mode = "all"
+	case "all", "any", "vote":
+	case "weighted":
+		if def.Threshold <= 0 {
+			def.Threshold = 0.5
+		}
+	default:
+		return nil, fmt.Errorf("mode must be all, any, vote or weighted, got %q", def.Mode)
+	}
+	if len(def.Strategies) == 0 {
+		return nil, fmt.Errorf("definition has no strategies")
+	}
+	for i := range def.Strategies {
+		child := &def.Strategies[i]
+		info, ok := registry.Info(child.Strategy)
+		if !ok {
+			return nil, fmt.Errorf("strategies[%d]: unknown strategy %q", i, child.Strategy)
+		}
+		// Nesting composites could form cycles through the registry.
+		if info.Source == "composite" {
+			return nil, fmt.Errorf("strategies[%d]: %s is a composite", i, child.Strategy)
+		}
+		if child.Timeframe != "" {
+			if _, err := timeframeDuration(child.Timeframe); err != nil {
+				return nil, fmt.Errorf("strategies[%d]: %w", i, err)
+			}
+		}
+		if child.Weight != nil && *child.Weight <= 0 {
+			return nil, fmt.Errorf("strategies[%d]: weight must be positive", i)
+		}
+		// YAML integers decode as int, strategies read numbers as float64.
+		for k, v := range child.Params {
+			if n, ok := v.(int); ok {
+				child.Params[k] = float64(n)
+			}
+		}
+		strategy, _, err := registry.New(child.Strategy, child.Params)
+		if err != nil {
+			return nil, fmt.Errorf("strategies[%d]: %w", i, err)
+		}
+		// Children are evaluated on the composite's candles alone.
+		if _, ok := strategy.(MultiSymbolStrategy); ok {
+			return nil, fmt.Errorf("strategies[%d]: %s trades more than one symbol", i, child.Strategy)
+		}
+	}
+	return &def, nil
+}
+
+// timeframeDuration parses an interval such as "15m", "4h" or "1d".
+func timeframeDuration(tf string) (time.Duration, error) {
+	units := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour}
+	if len(tf) >= 2 {
+		n, err := strconv.Atoi(tf[:len(tf)-1])
+		if unit, ok := units[tf[len(tf)-1]]; ok && err == nil && n > 0 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid timeframe %q", tf)
+}
+
+type compositeChild struct {
+	name      string
+	strategy  Strategy
+	timeframe time.Duration
+	weight    float64
+}
+
+// CompositeStrategy runs a CompositeDefinition. The first child's trend
+// line is charted, colored by the composite's position.
+type CompositeStrategy struct {
+	Config   StrategyConfig
+	def      *CompositeDefinition
+	children []compositeChild
+	output   *StrategyOutput
+}
+
+// NewCompositeStrategy creates the children of def from the registry.
+func NewCompositeStrategy(def *CompositeDefinition, registry *StrategyRegistry, params map[string]any) (*CompositeStrategy, error) {
+	s := &CompositeStrategy{def: def}
+	s.Config = s.BuildConfig(params)
+	for _, c := range def.Strategies {
+		strategy, _, err := registry.New(c.Strategy, c.Params)
+		if err != nil {
+			return nil, err
+		}
+		child := compositeChild{name: strategy.GetName(), strategy: strategy, weight: 1}
+		if c.Timeframe != "" {
+			child.timeframe, _ = timeframeDuration(c.Timeframe)
+			child.name += " (" + c.Timeframe + ")"
+		}
+		if c.Weight != nil {
+			child.weight = *c.Weight
+		}
+		s.children = append(s.children, child)
+	}
+	return s, nil
+}
+
+func (s *CompositeStrategy) GetName() string {
+	return s.def.Name
+}
+
+func (s *CompositeStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	return buildStrategyConfig(params)
+}
+
+func (s *CompositeStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) {
+	return s.run(candles)
+}
+
+func (s *CompositeStrategy) GetVisualizationData(candles hyperliquid.Candles) *StrategyOutput {
+	if _, err := s.run(candles); err != nil {
+		return nil
+	}
+	return s.output
+}
+
+func (s *CompositeStrategy) Run(candles hyperliquid.Candles) (*StrategyOutput, error) {
+	if _, err := s.run(candles); err != nil {
+		return nil, err
+	}
+	return s.output, nil
+}
+
+func (s *CompositeStrategy) Backtest(candles hyperliquid.Candles) (*BacktestOutput, error) {
+	signals, err := s.GenerateSignals(candles)
+	if err != nil {
+		return nil, err
+	}
+	return newBacktestOutput(s, s.Config, candles, signals, s.output), nil
+}
+
+// WarmUp is the number of chart candles that give every child its warm-up
+// on its own timeframe; a child that does not know its warm-up gets
+// liveCandles bars. Beside the child's bars, the candles of one partial
+// group may lead and those of one unclosed group trail.
+func (s *CompositeStrategy) WarmUp(interval string) (int, error) {
+	need := 0
+	for i, child := range s.children {
+		group, childInterval := 1, interval
+		if child.timeframe != 0 {
+			base, err := timeframeDuration(interval)
+			if err != nil {
+				return 0, err
+			}
+			if child.timeframe < base || child.timeframe%base != 0 {
+				return 0, fmt.Errorf("%s: timeframe is not a multiple of the chart interval %s", child.name, interval)
+			}
+			group, childInterval = int(child.timeframe/base), s.def.Strategies[i].Timeframe
+		}
+		bars := liveCandles
+		if w, ok := child.strategy.(WarmUpStrategy); ok {
+			var err error
+			if bars, err = w.WarmUp(childInterval); err != nil {
+				return 0, fmt.Errorf("%s: %w", child.name, err)
+			}
+		}
+		need = max(need, (bars+2)*group-2)
+	}
+	return need, nil
+}
+
+// run evaluates the children, combines their states into signals and
+// builds the chart output.
+func (s *CompositeStrategy) run(candles hyperliquid.Candles) ([]Signal, error) {
+	n := len(candles)
+	states := make([][]int, len(s.children))
+	var trend []float64
+	for c, child := range s.children {
+		childCandles, barOf, err := childBars(candles, child.timeframe)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", child.name, err)
+		}
+		signals, err := child.strategy.GenerateSignals(childCandles)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", child.name, err)
+		}
+		childStates := signalStates(len(childCandles), signals)
+		states[c] = make([]int, n)
+		for i, bar := range barOf {
+			if bar >= 0 {
+				states[c][i] = childStates[bar]
+			}
+		}
+		if c == 0 {
+			if output := child.strategy.GetVisualizationData(childCandles); output != nil {
+				trend = make([]float64, n)
+				for i, bar := range barOf {
+					if bar >= 0 && bar < len(output.TrendLines) {
+						trend[i] = output.TrendLines[bar]
+					}
+				}
+			}
+		}
+	}
+
+	signals := []Signal{}
+	position := 0
+	for i := 0; i < n; i++ {
+		target := s.combine(states, i)
+		if target == position || (target == 0 && s.def.Hold) {
+			continue
+		}
+		signal := Signal{
+			Index: i,
+			Price: parseFloat(candles[i].Close),
+			Time:  candles[i].Timestamp,
+		}
+		switch target {
+		case 1:
+			signal.Type = SignalLong
+			signal.Reason = s.reason(states, i, 1)
+		case -1:
+			signal.Type = SignalShort
+			signal.Reason = s.reason(states, i, -1)
+		default:
+			signal.Type = SignalClose
+			signal.Reason = s.def.Mode + ": agreement lost"
+		}
+		signals = append(signals, signal)
+		position = target
+	}
+
+	s.output = &StrategyOutput{
+		TrendLines:  make([]float64, n),
+		TrendColors: make([]string, n),
+		Directions:  positionDirections(n, signals),
+		Labels:      []Label{},
+		Lines:       []TrendLine{},
+		FillColors:  make([]string, n),
+	}
+	for i, direction := range s.output.Directions {
+		if direction == -1 {
+			s.output.TrendColors[i] = "#1cc2d8"
+		} else {
+			s.output.TrendColors[i] = "#e49013"
+		}
+		if trend != nil && !math.IsNaN(trend[i]) {
+			s.output.TrendLines[i] = trend[i]
+		}
+	}
+	return signals, nil
+}
+
+// combine is what the composite holds on bar i: 1 long, -1 short, 0 flat.
+func (s *CompositeStrategy) combine(states [][]int, i int) int {
+	var long, short int
+	var score, total float64
+	for c, child := range s.children {
+		switch states[c][i] {
+		case 1:
+			long++
+		case -1:
+			short++
+		}
+		score += child.weight * float64(states[c][i])
+		total += child.weight
+	}
+	count := len(s.children)
+	switch s.def.Mode {
+	case "all":
+		if long == count {
+			return 1
+		}
+		if short == count {
+			return -1
+		}
+	case "any":
+		if long > 0 && short == 0 {
+			return 1
+		}
+		if short > 0 && long == 0 {
+			return -1
+		}
+	case "vote":
+		if 2*long > count {
+			return 1
+		}
+		if 2*short > count {
+			return -1
+		}
+	case "weighted":
+		if score/total >= s.def.Threshold {
+			return 1
+		}
+		if score/total <= -s.def.Threshold {
+			return -1
+		}
+	}
+	return 0
+}
+
+// reason names the children holding side on bar i.
+func (s *CompositeStrategy) reason(states [][]int, i, side int) string {
+	var names []string
+	for c, child := range s.children {
+		if states[c][i] == side {
+			names = append(names, child.name)
+		}
+	}
+	return s.def.Mode + ": " + strings.Join(names, ", ")
+}
+
+// signalStates is the position each bar holds after signals: 1 long, -1
+// short, 0 flat.
+func signalStates(n int, signals []Signal) []int {
+	states := make([]int, n)
+	state, next := 0, 0
+	for i := range states {
+		for ; next < len(signals) && signals[next].Index == i; next++ {
+			switch signals[next].Type {
+			case SignalLong:
+				state = 1
+			case SignalShort:
+				state = -1
+			case SignalClose:
+				state = 0
+			}
+		}
+		states[i] = state
+	}
+	return states
+}
+
+// childBars aggregates candles into bars of tf, or returns them as they are
+// when tf is zero or the chart interval. Only whole, closed bars are
+// included.
+// barOf maps each candle to the last bar closed by then, -1 before the
+// first.
+func childBars(candles hyperliquid.Candles, tf time.Duration) (hyperliquid.Candles, []int, error) {
+	barOf := make([]int, len(candles))
+	base := candleDuration(candles)
+	if tf == 0 || tf == base {
+		for i := range barOf {
+			barOf[i] = i
+		}
+		return candles, barOf, nil
+	}
+	if tf < base || tf%base != 0 {
+		return nil, nil, fmt.Errorf("timeframe %s is not a multiple of the chart interval %s", tf, base)
+	}
+
+	group := func(i int) int64 { return candles[i].Timestamp / tf.Milliseconds() }
+	bars := hyperliquid.Candles{}
+	var open hyperliquid.Candle
+	var high, low, volume float64
+	started := false
+	for i, candle := range candles {
+		h, l, v := parseFloat(candle.High), parseFloat(candle.Low), parseFloat(candle.Volume)
+		if i == 0 || group(i) != group(i-1) {
+			open, high, low, volume = candle, h, l, 0
+			// Candles before the first full group would make a partial bar.
+			started = started || candle.Timestamp%tf.Milliseconds() < base.Milliseconds()
+		}
+		if !started {
+			barOf[i] = -1
+			continue
+		}
+		high, low, volume = max(high, h), min(low, l), volume+v
+
+		// A bar closes with the last candle of its group; the newest
+		// candle only closes it if the next one would start a new group.
+		closed := i+1 < len(candles) && group(i+1) != group(i) ||
+			i+1 == len(candles) && (candle.Timestamp+base.Milliseconds())/tf.Milliseconds() != group(i)
+		if closed {
+			bars = append(bars, hyperliquid.Candle{
+				Timestamp: open.Timestamp,
+				Open:      open.Open,
+				High:      strconv.FormatFloat(high, 'f', -1, 64),
+				Low:       strconv.FormatFloat(low, 'f', -1, 64),
+				Close:     candle.Close,
+				Volume:    strconv.FormatFloat(volume, 'f', -1, 64),
+			})
+		}
+		barOf[i] = len(bars) - 1
+	}
+	return bars, barOf, nil
+}
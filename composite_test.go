@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"terminal/rules"
+)
+
+// compositeRegistry has the builtins, a rule strategy warming up in 21
+// bars and flip, which does not know its warm-up.
+func compositeRegistry(t *testing.T) *StrategyRegistry {
+	t.Helper()
+	registry := NewStrategyRegistry()
+	def, err := rules.Compile(`
+name: EMA Cross
+indicators:
+  - {name: ema, type: ema, length: 20}
+entry:
+  long: {crossover: [close, ema]}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.Register(StrategyInfo{ID: "ema-cross", Name: def.Name, Source: "rules"}, func(params map[string]any) (Strategy, error) {
+		return NewRuleStrategy(def, params), nil
+	})
+	registry.Register(StrategyInfo{ID: "flip", Name: "Flip", Source: "builtin"}, func(params map[string]any) (Strategy, error) {
+		return flipStrategy{}, nil
+	})
+	return registry
+}
+
+func newComposite(t *testing.T, registry *StrategyRegistry, src string) *CompositeStrategy {
+	t.Helper()
+	def, err := compileComposite(src, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewCompositeStrategy(def, registry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompositeLiveCandles(t *testing.T) {
+	registry := compositeRegistry(t)
+	tests := []struct {
+		name     string
+		children string
+		interval string
+		count    int
+		err      string
+	}{
+		{"chart timeframe", "[{strategy: max-trend}, {strategy: ema-cross}]", "1m", liveCandles, ""},
+		// 21 bars of 15 candles, plus a partial group before and an unclosed one after.
+		{"longer timeframe", "[{strategy: max-trend}, {strategy: ema-cross, timeframe: 15m}]", "1m", 23*15 - 2, ""},
+		{"unknown warm-up", "[{strategy: flip, timeframe: 5m}]", "1m", (liveCandles+2)*5 - 2, ""},
+		{"same timeframe", "[{strategy: max-trend, timeframe: 1h}]", "1h", liveCandles, ""},
+		{"never warms up", "[{strategy: ema-cross}, {strategy: max-trend, timeframe: 1h}]", "1m", 0,
+			"Test needs 12118 candles of 1m to warm up, but only the latest 5000 are available"},
+		{"not a multiple", "[{strategy: ema-cross, timeframe: 5m}]", "3m", 0,
+			"EMA Cross (5m): timeframe is not a multiple of the chart interval 3m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newComposite(t, registry, "name: Test\nstrategies: "+tt.children)
+			count, err := liveCandleCount(s, tt.interval)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil || count != tt.count {
+				t.Errorf("liveCandleCount = %d, %v, want %d", count, err, tt.count)
+			}
+		})
+	}
+}
+
+func TestEngineRejectsCompositeThatNeverWarmsUp(t *testing.T) {
+	engine, _, account := newTestEngine(t, "BTC")
+	s := newComposite(t, compositeRegistry(t), "name: Test\nstrategies: [{strategy: max-trend, timeframe: 4h}]")
+	strategy := NewLiveStrategy("composite", s, map[string]any{})
+	strategy.Symbol, strategy.Interval = "BTC", "1m"
+	strategy.AccountID, strategy.account = account.ID(), account
+
+	err := engine.StartStrategy("composite", strategy)
+	if err == nil || !strings.Contains(err.Error(), "to warm up") {
+		t.Fatalf("StartStrategy error = %v, want a warm-up error", err)
+	}
+	if len(engine.GetRunningStrategies()) != 0 {
+		t.Error("rejected strategy is listed")
+	}
+}
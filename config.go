@@ -3,41 +3,155 @@ package main
 import (
 	"crypto/ecdsa"
 	"fmt"
+	"log"
 	"os"
+	"strings"
 
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/sonirico/go-hyperliquid"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type Config struct {
-	URL        string
-	PrivateKey *ecdsa.PrivateKey
-	Address    string
-	RedisURL   string
+	Settings Settings
+	// BaseSettings are the defaults and config file alone, without
+	// preferences, environment variables or flags.
+	BaseSettings Settings
+	ConfigPath   string
+	Network      NetworkProfile
+	URL          string
+	PrivateKey   *ecdsa.PrivateKey
+	Address      string
+	AgentAddress string
+	ReadOnly     bool
 }
 
-func NewConfig() Config {
-	pk, err := os.ReadFile(".secret")
+// LoadConfig resolves the layered settings from args and the environment and
+// builds the runtime config from them.
+func LoadConfig(args []string) (Config, error) {
+	settings, base, path, err := LoadSettings(args)
 	if err != nil {
-		panic(fmt.Errorf("failed to read .secret file: %w", err))
+		return Config{}, err
 	}
-	privateKey, err := crypto.HexToECDSA(string(pk))
+	config := NewConfig(settings)
+	config.BaseSettings = base
+	config.ConfigPath = path
+	return config, nil
+}
+
+// NewConfig resolves the signing key in order of preference: an encrypted
+// keystore (unlocked with HYPERTERMINAL_PASSPHRASE or later from the UI), the
+// legacy plaintext .secret file, and finally a read-only address from
+// HYPERTERMINAL_ADDRESS. When HYPERTERMINAL_MASTER_ADDRESS is set the key is
+// treated as an API wallet trading on behalf of that account.
+func NewConfig(settings Settings) Config {
+	profile, err := networkProfile(settings.Network)
+	if err != nil {
+		log.Printf("config: %v, using testnet", err)
+		profile, _ = networkProfile(NetworkTestnet)
+	}
+	config := Config{
+		Settings: settings,
+		Network:  profile,
+		URL:      profile.APIURL,
+		ReadOnly: true,
+	}
+	if master := os.Getenv(masterAddressEnv); master != "" {
+		if common.IsHexAddress(master) {
+			config.Address = common.HexToAddress(master).Hex()
+		} else {
+			log.Printf("config: invalid %s %q", masterAddressEnv, master)
+		}
+	}
+
+	if _, err := os.Stat(config.Settings.KeystorePath); err == nil {
+		address, err := keystoreAddress(config.Settings.KeystorePath)
+		if err != nil {
+			log.Printf("config: %v", err)
+		}
+		config.setSigner(address)
+		if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+			if err := config.Unlock(passphrase); err != nil {
+				log.Printf("config: %v", err)
+			}
+		}
+		return config
+	}
+
+	privateKey, err := readLegacySecret()
+	if err == nil {
+		log.Printf("config: using plaintext %s, move it to an encrypted keystore", legacySecretPath)
+		config.setKey(privateKey)
+		return config
+	}
+	if !os.IsNotExist(err) {
+		log.Printf("config: %v", err)
+	}
+
+	if config.Address == "" {
+		config.Address = os.Getenv(addressEnv)
+	}
+	log.Printf("config: no signing key found, starting read-only for %q", config.Address)
+	return config
+}
+
+func (c *Config) Unlock(passphrase string) error {
+	privateKey, err := decryptKeystore(c.Settings.KeystorePath, passphrase)
 	if err != nil {
-		panic(fmt.Errorf("invalid private key: %w", err))
+		return err
 	}
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		panic(fmt.Errorf("failed to cast public key to ECDSA"))
+	if signer := c.signerAddress(); signer != "" && addressFromKey(privateKey) != signer {
+		return fmt.Errorf("keystore key does not match address %s", signer)
 	}
-	return Config{
-		URL:        hyperliquid.TestnetAPIURL,
-		PrivateKey: privateKey,
-		Address:    crypto.PubkeyToAddress(*publicKeyECDSA).Hex(),
-		RedisURL:   "localhost:6379",
+	c.setKey(privateKey)
+	return nil
+}
+
+// Locked reports whether an encrypted keystore is present but not unlocked.
+func (c *Config) Locked() bool {
+	if c.PrivateKey != nil {
+		return false
+	}
+	_, err := os.Stat(c.Settings.KeystorePath)
+	return err == nil
+}
+
+func (c *Config) setKey(privateKey *ecdsa.PrivateKey) {
+	c.PrivateKey = privateKey
+	c.setSigner(addressFromKey(privateKey))
+	c.ReadOnly = false
+}
+
+// setSigner records the key's address. A key that differs from an already
+// configured master address is an agent wallet for that master.
+func (c *Config) setSigner(signer string) {
+	if signer == "" {
+		return
+	}
+	if c.Address == "" || strings.EqualFold(c.Address, signer) {
+		c.Address = signer
+		c.AgentAddress = ""
+		return
+	}
+	c.AgentAddress = signer
+}
+
+func (c *Config) signerAddress() string {
+	if c.AgentAddress != "" {
+		return c.AgentAddress
+	}
+	return c.Address
+}
+
+// AgentAccount is the account an agent key trades for, or empty when the key
+// signs for its own account.
+func (c *Config) AgentAccount() string {
+	if c.AgentAddress == "" {
+		return ""
 	}
+	return c.Address
 }
 
-func (c *Config) SetSourceURL(url string) {
-	c.URL = url
+func (c *Config) SetNetwork(profile NetworkProfile) {
+	c.Settings.Network = profile.Name
+	c.Network = profile
+	c.URL = profile.APIURL
 }
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"terminal/pine"
+	"terminal/rules"
+	"terminal/script"
+)
+
+// loadDefinitions compiles the saved strategy definitions and registers
+// them. A definition that no longer compiles is logged and skipped.
+func (a *App) loadDefinitions() {
+	if a.store == nil {
+		return
+	}
+	defs, err := a.store.LoadDefinitions()
+	if err != nil {
+		log.Printf("definitions: %v", err)
+		return
+	}
+	// Composites are compiled last, once the strategies they combine are
+	// registered.
+	for _, composites := range []bool{false, true} {
+		for _, def := range defs {
+			if (def.Kind == "composite") != composites {
+				continue
+			}
+			info, factory, err := a.compileDefinition(def)
+			if err != nil {
+				log.Printf("definitions: %s: %v", def.ID, err)
+				continue
+			}
+			a.strategies.Register(info, factory)
+		}
+	}
+}
+
+// compileDefinition compiles a definition's source into its registry entry.
+func (a *App) compileDefinition(def StrategyDefinition) (StrategyInfo, StrategyFactory, error) {
+	switch def.Kind {
+	case "pine":
+		script, err := pine.Compile(def.Source)
+		if err != nil {
+			return StrategyInfo{}, nil, err
+		}
+		info := StrategyInfo{
+			ID:          def.ID,
+			Name:        script.Title,
+			Description: "Pine indicator",
+			Source:      def.Kind,
+			Parameters:  pineParameters(script.Inputs),
+		}
+		if script.IsStrategy {
+			info.Description = "Pine strategy"
+		}
+		return info, func(params map[string]any) (Strategy, error) {
+			return NewPineStrategy(script.Title, script, params), nil
+		}, nil
+	case "rules":
+		ruleDef, err := rules.Compile(def.Source)
+		if err != nil {
+			return StrategyInfo{}, nil, err
+		}
+		info := StrategyInfo{
+			ID:          def.ID,
+			Name:        ruleDef.Name,
+			Description: ruleDef.Description,
+			Source:      def.Kind,
+			Parameters:  ruleParameters(ruleDef.Parameters),
+		}
+		if info.Description == "" {
+			info.Description = "Rule-based strategy"
+		}
+		return info, func(params map[string]any) (Strategy, error) {
+			return NewRuleStrategy(ruleDef, params), nil
+		}, nil
+	case "starlark":
+		s, err := script.Compile(def.Source)
+		if err != nil {
+			return StrategyInfo{}, nil, err
+		}
+		info := StrategyInfo{
+			ID:          def.ID,
+			Name:        s.Name,
+			Description: s.Description,
+			Source:      def.Kind,
+			Parameters:  scriptParameters(s.Parameters),
+		}
+		if info.Description == "" {
+			info.Description = "Starlark strategy"
+		}
+		return info, func(params map[string]any) (Strategy, error) {
+			return NewScriptStrategy(s, params), nil
+		}, nil
+	case "composite":
+		composite, err := compileComposite(def.Source, a.strategies)
+		if err != nil {
+			return StrategyInfo{}, nil, err
+		}
+		info := StrategyInfo{
+			ID:          def.ID,
+			Name:        composite.Name,
+			Description: composite.Description,
+			Source:      def.Kind,
+			Parameters:  []StrategyParameter{},
+		}
+		if info.Description == "" {
+			info.Description = fmt.Sprintf("Composite of %d strategies (%s)", len(composite.Strategies), composite.Mode)
+		}
+		return info, func(params map[string]any) (Strategy, error) {
+			return NewCompositeStrategy(composite, a.strategies, params)
+		}, nil
+	}
+	return StrategyInfo{}, nil, fmt.Errorf("unknown definition kind %q", def.Kind)
+}
+
+// newDefinition compiles source and derives the definition's ID from the
+// name the source declares.
+func (a *App) newDefinition(kind, source string) (StrategyDefinition, StrategyInfo, StrategyFactory, error) {
+	def := StrategyDefinition{Kind: kind, Source: source}
+	info, factory, err := a.compileDefinition(def)
+	if err != nil {
+		return def, info, nil, err
+	}
+	def.ID, def.Name = definitionID(kind, info.Name), info.Name
+	info.ID = def.ID
+	return def, info, factory, nil
+}
+
+func pineParameters(inputs []pine.Input) []StrategyParameter {
+	params := make([]StrategyParameter, 0, len(inputs))
+	for _, input := range inputs {
+		param := StrategyParameter{
+			Name:    input.Name,
+			Label:   input.Title,
+			Type:    input.Type,
+			Default: input.Default,
+			Min:     input.Min,
+			Max:     input.Max,
+			Step:    input.Step,
+			Options: input.Options,
+		}
+		// Colors are edited as hex strings.
+		if param.Type == "color" {
+			param.Type = "string"
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+func ruleParameters(defs []rules.Parameter) []StrategyParameter {
+	params := make([]StrategyParameter, 0, len(defs))
+	for _, p := range defs {
+		label := p.Label
+		if label == "" {
+			label = p.Name
+		}
+		params = append(params, StrategyParameter{
+			Name:    p.Name,
+			Label:   label,
+			Type:    "number",
+			Default: p.Default,
+			Min:     p.Min,
+			Max:     p.Max,
+			Step:    p.Step,
+		})
+	}
+	return params
+}
+
+func scriptParameters(defs []script.Parameter) []StrategyParameter {
+	params := make([]StrategyParameter, 0, len(defs))
+	for _, p := range defs {
+		params = append(params, StrategyParameter(p))
+	}
+	return params
+}
+
+// definitionID is "<kind>-<slug of name>", e.g. "pine-ma-cross".
+func definitionID(kind, name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return kind + "-" + sb.String()
+}
+
+// GetDefinitions returns the saved strategy definitions.
+func (a *App) GetDefinitions() ([]StrategyDefinition, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("strategy storage is not available")
+	}
+	return a.store.LoadDefinitions()
+}
+
+// ValidateDefinition compiles source without saving it and returns the
+// strategy it would register.
+func (a *App) ValidateDefinition(kind, source string) (StrategyInfo, error) {
+	_, info, _, err := a.newDefinition(kind, source)
+	return info, err
+}
+
+// BacktestDefinition compiles source without saving it and backtests it
+// like StrategyBacktest, so a definition can be tried before it is saved.
+func (a *App) BacktestDefinition(kind, source, symbol, interval string, limit int, params map[string]any) (*BacktestOutput, error) {
+	_, _, factory, err := a.newDefinition(kind, source)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := factory(params)
+	if err != nil {
+		return nil, err
+	}
+	candles, err := a.source.FetchHistoricalCandles(symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := a.source.fetchSymbolCandles(strategy, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	feedSymbolCandles(strategy, candles, symbols)
+	return strategy.Backtest(candles)
+}
+
+// SaveDefinition compiles, saves and registers a definition. Saving a
+// definition with the name of an existing one replaces it; strategies
+// already running keep the version they were started with.
+func (a *App) SaveDefinition(kind, source string) (StrategyInfo, error) {
+	if a.store == nil {
+		return StrategyInfo{}, fmt.Errorf("strategy storage is not available")
+	}
+	def, info, factory, err := a.newDefinition(kind, source)
+	if err != nil {
+		return StrategyInfo{}, err
+	}
+	if existing, ok := a.strategies.Info(info.ID); ok && existing.Source == "builtin" {
+		return StrategyInfo{}, fmt.Errorf("%s is a built-in strategy", info.ID)
+	}
+	if err := a.store.SaveDefinition(def); err != nil {
+		return StrategyInfo{}, err
+	}
+	a.strategies.Register(info, factory)
+	return info, nil
+}
+
+// DeleteDefinition removes a definition. Definitions with running
+// strategies or used by a composite cannot be deleted.
+func (a *App) DeleteDefinition(id string) error {
+	if a.store == nil {
+		return fmt.Errorf("strategy storage is not available")
+	}
+	for _, strategy := range a.strategyEngine().GetRunningStrategies() {
+		if strategy.Kind == id {
+			return fmt.Errorf("strategy %s is running %s, stop it first", strategy.ID, id)
+		}
+	}
+	defs, err := a.store.LoadDefinitions()
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		if def.Kind != "composite" {
+			continue
+		}
+		composite, err := compileComposite(def.Source, a.strategies)
+		if err != nil {
+			continue
+		}
+		for _, child := range composite.Strategies {
+			if child.Strategy == id {
+				return fmt.Errorf("%s is part of %s, remove it there first", id, def.ID)
+			}
+		}
+	}
+	if err := a.store.DeleteDefinition(id); err != nil {
+		return err
+	}
+	a.strategies.Unregister(id)
+	return nil
+}
@@ -3,99 +3,483 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
 	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+type StrategyState string
+
+const (
+	StrategyStarting  StrategyState = "starting"
+	StrategyWarmingUp StrategyState = "warming_up"
+	StrategyRunning   StrategyState = "running"
+	StrategyPaused    StrategyState = "paused"
+	StrategyStopping  StrategyState = "stopping"
+	StrategyErrored   StrategyState = "errored"
 )
 
+// strategyRun owns a live strategy. mu guards the strategy and the flags. The
+// run goroutine trades on a copy of the strategy without holding mu and swaps
+// the copy in under it, so callers never see a half-applied signal and never
+// wait on an order.
+type strategyRun struct {
+	mu       sync.Mutex
+	strategy *LiveStrategy
+	// state is what the run loop last reached; paused and stopping override
+	// it when reported.
+	state    StrategyState
+	err      error
+	paused   bool
+	stopping bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
 type StrategyEngine struct {
-	strategies map[string]*MaxTrendPointsStrategy
+	mu         sync.RWMutex
+	strategies map[string]*strategyRun
 	source     Source
+	store      *Store
+	events     *EventBus
+	// poll is how often a run checks for a new candle; zero polls five times
+	// per interval.
+	poll time.Duration
 }
 
-func NewStrategyEngine(source *Source) *StrategyEngine {
+// NewStrategyEngine creates an engine for the source's network. store may be
+// nil, in which case strategies are not persisted.
+func NewStrategyEngine(source *Source, store *Store, events *EventBus) *StrategyEngine {
 	return &StrategyEngine{
-		strategies: make(map[string]*MaxTrendPointsStrategy),
+		strategies: make(map[string]*strategyRun),
 		source:     *source,
+		store:      store,
+		events:     events,
 	}
 }
 
-func (e *StrategyEngine) StartStrategy(id string, strategy MaxTrendPointsStrategy) error {
+// StartStrategy takes ownership of strategy and runs it under id. A strategy
+// whose State is StrategyPaused warms up and then waits for ResumeStrategy.
+func (e *StrategyEngine) StartStrategy(id string, strategy *LiveStrategy) error {
+	if _, err := liveCandleCount(strategy.strategy, strategy.Interval); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	if _, exists := e.strategies[id]; exists {
 		return fmt.Errorf("strategy %s already running", id)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	strategy.ctx = ctx
-	strategy.cancel = cancel
 	strategy.ID = id
-	strategy.IsRunning = true
-	e.strategies[id] = &strategy
-	go e.run(&strategy)
+	strategy.events = e.events
+	run := &strategyRun{
+		strategy: strategy,
+		state:    StrategyStarting,
+		paused:   strategy.State == StrategyPaused,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	e.strategies[id] = run
+
+	run.mu.Lock()
+	e.persist(run)
+	run.mu.Unlock()
+	go e.run(run)
 	return nil
 }
 
-func (e *StrategyEngine) StopStrategy(name string) error {
-	live, exists := e.strategies[name]
-	if !exists {
-		return fmt.Errorf("strategy %s not found", name)
+// StopStrategy stops the run loop, waits for it to exit and closes any open
+// position. The strategy is listed as stopping until the close completes.
+func (e *StrategyEngine) StopStrategy(id string) error {
+	run, err := e.beginStop(id)
+	if err != nil {
+		return err
 	}
+	<-run.done
 
-	live.IsRunning = false
-	live.cancel()
-
-	if live.Position != nil && live.Position.IsOpen {
-		live.ClosePosition("Strategy Stopped")
+	run.mu.Lock()
+	strategy := run.strategy.clone()
+	run.mu.Unlock()
+	if trader, ok := strategy.strategy.(OrderTrader); ok {
+		trader.CancelOrders(strategy)
+	}
+	if strategy.Position != nil && strategy.Position.IsOpen {
+		strategy.ClosePosition("Strategy Stopped")
 	}
+	run.mu.Lock()
+	run.strategy = strategy
+	run.mu.Unlock()
 
-	delete(e.strategies, name)
+	e.remove(id)
+	e.forget(id)
 	return nil
 }
 
-func (e *StrategyEngine) GetRunningStrategies() []MaxTrendPointsStrategy {
-	result := make([]MaxTrendPointsStrategy, 0, len(e.strategies))
-	for _, live := range e.strategies {
-		result = append(result, *live)
+// PauseStrategy stops acting on new candles but keeps the strategy and its
+// position. The paused flag is persisted so a restart does not resume trading.
+func (e *StrategyEngine) PauseStrategy(id string) error {
+	return e.setPaused(id, true)
+}
+
+func (e *StrategyEngine) ResumeStrategy(id string) error {
+	return e.setPaused(id, false)
+}
+
+func (e *StrategyEngine) setPaused(id string, paused bool) error {
+	run, err := e.get(id)
+	if err != nil {
+		return err
 	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.stopping {
+		return fmt.Errorf("strategy %s is stopping", id)
+	}
+	if run.paused == paused {
+		return fmt.Errorf("strategy %s is %s", id, run.currentState())
+	}
+	run.paused = paused
+	e.persist(run)
+	return nil
+}
+
+// DetachAllStrategies stops every strategy but leaves its positions open and
+// its saved state in place so it can be resumed on the next start.
+func (e *StrategyEngine) DetachAllStrategies() {
+	for _, run := range e.stopAll() {
+		<-run.done
+		run.mu.Lock()
+		e.persist(run)
+		run.mu.Unlock()
+		e.remove(run.strategy.ID)
+	}
+}
+
+// HaltAllStrategies stops every strategy without sending close orders. It is
+// used by the kill switch, which flattens the whole account itself.
+func (e *StrategyEngine) HaltAllStrategies(reason string) {
+	for _, run := range e.stopAll() {
+		<-run.done
+		run.mu.Lock()
+		if position := run.strategy.Position; position != nil && position.IsOpen {
+			run.strategy.recordClose(0, reason)
+		}
+		run.mu.Unlock()
+		e.remove(run.strategy.ID)
+		e.forget(run.strategy.ID)
+	}
+}
+
+// GetRunningStrategies returns snapshots of every strategy the engine owns,
+// including paused, errored and stopping ones, ordered by id.
+func (e *StrategyEngine) GetRunningStrategies() []LiveStrategy {
+	e.mu.RLock()
+	runs := make([]*strategyRun, 0, len(e.strategies))
+	for _, run := range e.strategies {
+		runs = append(runs, run)
+	}
+	e.mu.RUnlock()
+
+	result := make([]LiveStrategy, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, run.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
 	return result
 }
 
 func (e *StrategyEngine) StopAllStrategies() {
+	e.mu.RLock()
+	ids := make([]string, 0, len(e.strategies))
 	for id := range e.strategies {
-		e.StopStrategy(id)
+		ids = append(ids, id)
+	}
+	e.mu.RUnlock()
+
+	for _, id := range ids {
+		if err := e.StopStrategy(id); err != nil {
+			slog.Error("stop strategy failed", "strategy", id, "error", err)
+		}
 	}
 }
 
-func (e *StrategyEngine) run(strategy *MaxTrendPointsStrategy) {
-	interval := e.intervalDuration(strategy.Interval)
-	ticker := time.NewTicker(interval / 5)
-	defer ticker.Stop()
+func (e *StrategyEngine) get(id string) (*strategyRun, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	run, ok := e.strategies[id]
+	if !ok {
+		return nil, fmt.Errorf("strategy %s not found", id)
+	}
+	return run, nil
+}
+
+func (e *StrategyEngine) remove(id string) {
+	e.mu.Lock()
+	delete(e.strategies, id)
+	e.mu.Unlock()
+}
 
-	candles, err := e.source.FetchHistoricalCandles(strategy.Symbol, strategy.Interval, 200)
+func (e *StrategyEngine) beginStop(id string) (*strategyRun, error) {
+	run, err := e.get(id)
 	if err != nil {
-		return
+		return nil, err
+	}
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if run.stopping {
+		return nil, fmt.Errorf("strategy %s is already stopping", id)
+	}
+	run.stopping = true
+	run.cancel()
+	return run, nil
+}
+
+// stopAll cancels every strategy that is not already stopping and returns
+// them; the caller waits for each run loop to exit.
+func (e *StrategyEngine) stopAll() []*strategyRun {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	runs := make([]*strategyRun, 0, len(e.strategies))
+	for _, run := range e.strategies {
+		run.mu.Lock()
+		if !run.stopping {
+			run.stopping = true
+			run.cancel()
+			runs = append(runs, run)
+		}
+		run.mu.Unlock()
 	}
+	return runs
+}
 
-	if len(candles) > 0 {
-		strategy.LastCandleTime = candles[len(candles)-1].Timestamp
+func (e *StrategyEngine) run(run *strategyRun) {
+	defer close(run.done)
+	defer run.cancel()
+
+	poll := e.poll
+	if poll == 0 {
+		poll = e.intervalDuration(run.strategy.Interval) / 5
 	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
 
+	warm := false
 	for {
+		if warm {
+			e.step(run)
+		} else {
+			warm = e.warmUp(run)
+		}
 		select {
-		case <-strategy.ctx.Done():
+		case <-run.ctx.Done():
 			return
 		case <-ticker.C:
-			if err := e.processCandle(strategy); err != nil {
-				continue
-			}
 		}
 	}
 }
 
-func (e *StrategyEngine) processCandle(strategy *MaxTrendPointsStrategy) error {
-	candles, err := e.source.FetchHistoricalCandles(strategy.Symbol, strategy.Interval, 250)
+// warmUp loads history so only candles closing after the start are traded.
+// It is retried on every tick until the first fetch succeeds.
+func (e *StrategyEngine) warmUp(run *strategyRun) bool {
+	run.mu.Lock()
+	run.state = StrategyWarmingUp
+	run.mu.Unlock()
+
+	candles, err := e.source.FetchHistoricalCandles(run.strategy.Symbol, run.strategy.Interval, 200)
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
 	if err != nil {
-		return err
+		run.fail(err)
+		return false
 	}
+	if len(candles) > 0 {
+		run.strategy.LastCandleTime = candles[len(candles)-1].Timestamp
+	}
+	run.state = StrategyRunning
+	run.err = nil
+	return true
+}
 
+// liveCandles is how many candles a step fetches for a strategy that
+// does not need more to warm up. maxLiveCandles is the history Hyperliquid
+// keeps for an interval.
+const (
+	liveCandles    = 250
+	maxLiveCandles = 5000
+)
+
+// liveCandleCount is how many candles a step of s on interval fetches. It
+// fails when s can never warm up on the history available.
+func liveCandleCount(s Strategy, interval string) (int, error) {
+	w, ok := s.(WarmUpStrategy)
+	if !ok {
+		return liveCandles, nil
+	}
+	need, err := w.WarmUp(interval)
+	if err != nil {
+		return 0, err
+	}
+	if need > maxLiveCandles {
+		return 0, fmt.Errorf("%s needs %d candles of %s to warm up, but only the latest %d are available", s.GetName(), need, interval, maxLiveCandles)
+	}
+	return max(need, liveCandles), nil
+}
+
+func (e *StrategyEngine) step(run *strategyRun) {
+	run.mu.Lock()
+	skip := run.paused || run.stopping
+	run.mu.Unlock()
+	if skip {
+		return
+	}
+
+	count, err := liveCandleCount(run.strategy.strategy, run.strategy.Interval)
+	var candles hyperliquid.Candles
+	if err == nil {
+		candles, err = e.source.FetchHistoricalCandles(run.strategy.Symbol, run.strategy.Interval, count)
+	}
+	var symbols map[string]hyperliquid.Candles
+	if err == nil {
+		symbols, err = e.source.fetchSymbolCandles(run.strategy.strategy, run.strategy.Interval, count)
+	}
+
+	run.mu.Lock()
+	if run.paused || run.ctx.Err() != nil {
+		run.mu.Unlock()
+		return
+	}
+	if err != nil {
+		run.fail(err)
+		run.mu.Unlock()
+		return
+	}
+	strategy := run.strategy.clone()
+	run.mu.Unlock()
+
+	changed, err := e.advance(strategy, candles, symbols)
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.strategy = strategy
+	if changed {
+		e.persist(run)
+	}
+	if err != nil {
+		run.fail(err)
+		return
+	}
+	run.state = StrategyRunning
+	run.err = nil
+}
+
+// advance runs one tick on strategy: its resting orders, the open position
+// and the newest candle. It sends orders, so the run calls it on a copy of
+// the strategy without holding run.mu. It reports whether the strategy
+// changed and needs saving.
+func (e *StrategyEngine) advance(strategy *LiveStrategy, candles hyperliquid.Candles, symbols map[string]hyperliquid.Candles) (bool, error) {
+	feedSymbolCandles(strategy.strategy, candles, symbols)
+	changed := false
+	if trader, ok := strategy.strategy.(OrderTrader); ok {
+		traded, err := trader.Trade(strategy, candles)
+		if err != nil {
+			return traded, err
+		}
+		changed = traded
+	}
+	if strategy.managePosition(candles) {
+		changed = true
+	}
+	last := strategy.LastCandleTime
+	if err := e.processCandle(strategy, candles); err != nil {
+		return changed, err
+	}
+	return changed || strategy.LastCandleTime != last, nil
+}
+
+// fail marks the run errored. The loop keeps going and clears the error on
+// the next successful candle. Callers hold run.mu.
+func (r *strategyRun) fail(err error) {
+	if r.err == nil || r.err.Error() != err.Error() {
+		r.strategy.publish(EventError, StrategyEvent{}, "%v", err)
+	}
+	r.state = StrategyErrored
+	r.err = err
+}
+
+// currentState reports the externally visible state. Callers hold r.mu.
+func (r *strategyRun) currentState() StrategyState {
+	switch {
+	case r.stopping:
+		return StrategyStopping
+	case r.paused:
+		return StrategyPaused
+	default:
+		return r.state
+	}
+}
+
+// snapshot copies the strategy for callers outside the engine. The position
+// is copied too so the run loop can keep updating it.
+func (r *strategyRun) snapshot() LiveStrategy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := *r.strategy.clone()
+	result.strategy = nil
+	result.State = r.currentState()
+	result.Error = ""
+	if r.err != nil {
+		result.Error = r.err.Error()
+	}
+	result.Trades = append([]Position(nil), r.strategy.Trades...)
+	result.Performance = calculatePerformance(r.strategy.Trades)
+	return result
+}
+
+// persist saves the run. Callers hold run.mu.
+func (e *StrategyEngine) persist(run *strategyRun) {
+	if e.store == nil {
+		return
+	}
+	strategy := run.strategy
+	err := e.store.SaveStrategy(StrategyRecord{
+		ID:             strategy.ID,
+		Network:        e.source.network,
+		AccountID:      strategy.AccountID,
+		Kind:           strategy.Kind,
+		Name:           strategy.Name,
+		Symbol:         strategy.Symbol,
+		Interval:       strategy.Interval,
+		Params:         strategy.Config.Parameters,
+		Position:       strategy.Position,
+		Trades:         strategy.Trades,
+		Grid:           strategy.Grid,
+		LastCandleTime: strategy.LastCandleTime,
+		Paused:         run.paused,
+	})
+	if err != nil {
+		strategy.logger().Error("save strategy failed", "error", err)
+	}
+}
+
+func (e *StrategyEngine) forget(id string) {
+	if e.store == nil {
+		return
+	}
+	if err := e.store.DeleteStrategy(id); err != nil {
+		slog.Error("delete saved strategy failed", "strategy", id, "error", err)
+	}
+}
+
+func (e *StrategyEngine) processCandle(strategy *LiveStrategy, candles hyperliquid.Candles) error {
 	if len(candles) == 0 {
 		return fmt.Errorf("no candles")
 	}
@@ -105,59 +489,27 @@ func (e *StrategyEngine) processCandle(strategy *MaxTrendPointsStrategy) error {
 		return nil
 	}
 
-	fmt.Printf("[%s] 📊 New candle: O=%s H=%s L=%s C=%s @ %s\n",
-		strategy.ID,
-		latest.Open,
-		latest.High,
-		latest.Low,
-		latest.Close,
-		time.Unix(latest.Timestamp/1000, 0).Format("15:04:05"),
-	)
-
 	strategy.LastCandleTime = latest.Timestamp
+	strategy.markPosition(parseFloat(latest.Close))
 
-	signals, err := strategy.GenerateSignals(candles)
+	signal, direction, err := strategy.evaluate(candles)
 	if err != nil {
 		return err
 	}
 
-	if len(signals) == 0 {
-		if len(strategy.output.Directions) > 0 {
-			lastDir := strategy.output.Directions[len(strategy.output.Directions)-1]
-			if lastDir == -1 {
-				fmt.Printf("[%s] 📈 Trend continuing: LONG (cyan)\n", strategy.ID)
-			} else {
-				fmt.Printf("[%s] 📉 Trend continuing: SHORT (orange)\n", strategy.ID)
-			}
-		}
-		return nil
+	trend := ""
+	switch direction {
+	case -1:
+		trend = ", trend long"
+	case 1:
+		trend = ", trend short"
 	}
+	strategy.publish(EventCandle, StrategyEvent{Price: parseFloat(latest.Close)},
+		"%s O=%s H=%s L=%s C=%s%s",
+		time.UnixMilli(latest.Timestamp).Format("15:04:05"), latest.Open, latest.High, latest.Low, latest.Close, trend)
 
-	lastSignal := signals[len(signals)-1]
-	lastIdx := len(candles) - 1
-
-	if lastSignal.Index == lastIdx {
-		if lastSignal.Type == SignalLong {
-			fmt.Printf("[%s] 🟢 LONG SIGNAL DETECTED at %.2f - Trend changed to LONG (cyan line)\n",
-				strategy.ID, lastSignal.Price)
-		} else if lastSignal.Type == SignalShort {
-			fmt.Printf("[%s] 🔴 SHORT SIGNAL DETECTED at %.2f - Trend changed to SHORT (orange line)\n",
-				strategy.ID, lastSignal.Price)
-		}
-        // Close the position as the trend have changed
-        if strategy.Position != nil && strategy.Position.IsOpen {
-            strategy.ClosePosition("Trend Reversal")
-        }
-		strategy.HandleSignal(lastSignal, latest)
-	} else {
-		if len(strategy.output.Directions) > 0 {
-			lastDir := strategy.output.Directions[len(strategy.output.Directions)-1]
-			if lastDir == -1 {
-				fmt.Printf("[%s] 📈 Trend continuing: LONG (cyan)\n", strategy.ID)
-			} else {
-				fmt.Printf("[%s] 📉 Trend continuing: SHORT (orange)\n", strategy.ID)
-			}
-		}
+	if signal != nil {
+		strategy.HandleSignal(*signal, latest)
 	}
 
 	return nil
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// flipStrategy goes long on every even bar and closes on every odd one, so a
+// running strategy sends an order on each new candle.
+type flipStrategy struct{}
+
+func (flipStrategy) GetName() string { return "Flip" }
+
+func (flipStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	return buildStrategyConfig(params)
+}
+
+func (flipStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) { return nil, nil }
+
+func (flipStrategy) GetVisualizationData(candles hyperliquid.Candles) *StrategyOutput { return nil }
+
+func (flipStrategy) Backtest(candles hyperliquid.Candles) (*BacktestOutput, error) {
+	return &BacktestOutput{}, nil
+}
+
+func (flipStrategy) Run(candles hyperliquid.Candles) (*StrategyOutput, error) {
+	return &StrategyOutput{}, nil
+}
+
+func (flipStrategy) LiveSignal(candles hyperliquid.Candles) (*Signal, int, error) {
+	latest := candles[len(candles)-1]
+	signal := Signal{Index: len(candles) - 1, Type: SignalClose, Reason: "odd bar"}
+	if latest.Time/60_000%2 == 0 {
+		signal.Type, signal.Reason = SignalLong, "even bar"
+	}
+	return &signal, 0, nil
+}
+
+func newTestEngine(t *testing.T, coins ...string) (*StrategyEngine, *fakeExchange, *Account) {
+	t.Helper()
+	fake := newFakeExchange(t, coins...)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	engine := NewStrategyEngine(fake.source(), nil, NewEventBus(strategyEventBuffer))
+	engine.poll = time.Millisecond
+	return engine, fake, fake.account(ctx)
+}
+
+func newFlipStrategy(account *Account, symbol string) *LiveStrategy {
+	strategy := NewLiveStrategy("flip", flipStrategy{}, map[string]any{})
+	strategy.Symbol = symbol
+	strategy.Interval = "1m"
+	strategy.AccountID = account.ID()
+	strategy.account = account
+	return strategy
+}
+
+func TestEngineConcurrentControl(t *testing.T) {
+	coins := []string{"BTC", "ETH", "SOL", "ARB"}
+	engine, fake, account := newTestEngine(t, coins...)
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				engine.GetRunningStrategies()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, coin := range coins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 5 {
+				id := fmt.Sprintf("%s-%d", coin, i)
+				if err := engine.StartStrategy(id, newFlipStrategy(account, coin)); err != nil {
+					t.Error(err)
+					return
+				}
+				time.Sleep(50 * time.Millisecond)
+				if err := engine.PauseStrategy(id); err != nil {
+					t.Error(err)
+				}
+				engine.GetRunningStrategies()
+				if err := engine.ResumeStrategy(id); err != nil {
+					t.Error(err)
+				}
+				time.Sleep(50 * time.Millisecond)
+				if err := engine.StopStrategy(id); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	if running := engine.GetRunningStrategies(); len(running) != 0 {
+		t.Errorf("%d strategies still running", len(running))
+	}
+	if fake.orderCount() == 0 {
+		t.Error("no orders were sent")
+	}
+	for _, coin := range coins {
+		if size := fake.position(coin); size != 0 {
+			t.Errorf("%s position %v left open after stop", coin, size)
+		}
+	}
+}
+
+func TestEngineControlDoesNotWaitOnOrders(t *testing.T) {
+	engine, fake, account := newTestEngine(t, "BTC")
+	fake.orderDelay = 500 * time.Millisecond
+	fake.inFlight = make(chan struct{}, 1)
+
+	if err := engine.StartStrategy("flip", newFlipStrategy(account, "BTC")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-fake.inFlight:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order was sent")
+	}
+
+	start := time.Now()
+	if running := engine.GetRunningStrategies(); len(running) != 1 {
+		t.Fatalf("got %d strategies, want 1", len(running))
+	}
+	if err := engine.PauseStrategy("flip"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > fake.orderDelay/2 {
+		t.Errorf("snapshot and pause took %v while an order was in flight", elapsed)
+	}
+
+	engine.HaltAllStrategies("test")
+	if running := engine.GetRunningStrategies(); len(running) != 0 {
+		t.Errorf("%d strategies still running after halt", len(running))
+	}
+}
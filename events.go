@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// strategyEventsTopic is the Wails event every StrategyEvent is emitted on.
+const strategyEventsTopic = "strategy:event"
+
+// strategyEventBuffer is how many events are kept per strategy.
+const strategyEventBuffer = 500
+
+type StrategyEventType string
+
+const (
+	EventCandle         StrategyEventType = "candle"
+	EventSignal         StrategyEventType = "signal"
+	EventOrderPlaced    StrategyEventType = "order_placed"
+	EventOrderFilled    StrategyEventType = "order_filled"
+	EventOrderRejected  StrategyEventType = "order_rejected"
+	EventPositionOpened StrategyEventType = "position_opened"
+	EventPositionClosed StrategyEventType = "position_closed"
+	EventError          StrategyEventType = "error"
+)
+
+// StrategyEvent is one thing a live strategy did. Price, Size, Side and
+// OrderID are set when they apply to the event.
+type StrategyEvent struct {
+	Seq        int64
+	StrategyID string
+	Type       StrategyEventType
+	Time       int64
+	Message    string
+	Price      float64
+	Size       float64
+	Side       string
+	OrderID    string
+}
+
+// EventBus fans strategy events out to the emitter (the Wails runtime) and
+// keeps the most recent ones per strategy for GetStrategyEvents.
+type EventBus struct {
+	mu      sync.Mutex
+	size    int
+	seq     int64
+	buffers map[string]*eventRing
+	emit    func(StrategyEvent)
+}
+
+type eventRing struct {
+	events []StrategyEvent
+	next   int
+	full   bool
+}
+
+func NewEventBus(size int) *EventBus {
+	return &EventBus{
+		size:    size,
+		buffers: make(map[string]*eventRing),
+	}
+}
+
+func (b *EventBus) SetEmitter(fn func(StrategyEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.emit = fn
+}
+
+// Publish stamps, buffers and emits an event. A nil bus drops it, so
+// strategies used outside the engine (backtests) need no wiring.
+func (b *EventBus) Publish(ev StrategyEvent) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.seq++
+	ev.Seq = b.seq
+	if ev.Time == 0 {
+		ev.Time = time.Now().UnixMilli()
+	}
+	ring, ok := b.buffers[ev.StrategyID]
+	if !ok {
+		ring = &eventRing{events: make([]StrategyEvent, b.size)}
+		b.buffers[ev.StrategyID] = ring
+	}
+	ring.push(ev)
+	emit := b.emit
+	b.mu.Unlock()
+
+	if emit != nil {
+		emit(ev)
+	}
+}
+
+// Events returns up to limit of the strategy's most recent events, oldest
+// first. A limit of zero or less returns everything buffered.
+func (b *EventBus) Events(strategyID string, limit int) []StrategyEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ring, ok := b.buffers[strategyID]
+	if !ok {
+		return []StrategyEvent{}
+	}
+	events := ring.list()
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events
+}
+
+func (r *eventRing) push(ev StrategyEvent) {
+	r.events[r.next] = ev
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *eventRing) list() []StrategyEvent {
+	if !r.full {
+		return append([]StrategyEvent(nil), r.events[:r.next]...)
+	}
+	result := make([]StrategyEvent, 0, len(r.events))
+	result = append(result, r.events[r.next:]...)
+	return append(result, r.events[:r.next]...)
+}
+
+// publish logs an event for this strategy and sends it on its engine's bus.
+func (s *LiveStrategy) publish(typ StrategyEventType, ev StrategyEvent, format string, args ...any) {
+	ev.StrategyID = s.ID
+	ev.Type = typ
+	ev.Message = fmt.Sprintf(format, args...)
+
+	level := slog.LevelInfo
+	switch typ {
+	case EventCandle:
+		level = slog.LevelDebug
+	case EventOrderRejected:
+		level = slog.LevelWarn
+	case EventError:
+		level = slog.LevelError
+	}
+	attrs := []slog.Attr{slog.String("event", string(typ))}
+	if ev.Side != "" {
+		attrs = append(attrs, slog.String("side", ev.Side))
+	}
+	if ev.Price != 0 {
+		attrs = append(attrs, slog.Float64("price", ev.Price))
+	}
+	if ev.Size != 0 {
+		attrs = append(attrs, slog.Float64("size", ev.Size))
+	}
+	if ev.OrderID != "" {
+		attrs = append(attrs, slog.String("orderId", ev.OrderID))
+	}
+	s.logger().LogAttrs(context.Background(), level, ev.Message, attrs...)
+
+	s.events.Publish(ev)
+}
+
+// logger returns the default logger with the strategy's identifying fields.
+// It is built per call so it follows setupLogging reconfiguring the default.
+func (s *LiveStrategy) logger() *slog.Logger {
+	return slog.With("strategy", s.ID, "account", s.AccountID, "symbol", s.Symbol, "interval", s.Interval)
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// fakeExchange is an in-memory Hyperliquid API for tests. It serves the info
+// endpoints the terminal reads, fills every order but GTC limits in full at
+// the mid price and keeps the resulting positions. GTC limits rest until
+// fillOrder trades them. Each candle snapshot closes one more one-minute bar,
+// so every poll of a running strategy sees a new candle.
+type fakeExchange struct {
+	t      *testing.T
+	server *httptest.Server
+	coins  []string
+
+	mu        sync.Mutex
+	bar       int64
+	positions map[string]float64
+	orders    int
+	bulks     int // order actions, each with one or more orders
+	resting   map[int64]*fakeOrder
+	fills     []hyperliquid.Fill
+	// orderDelay holds every order for that long; inFlight receives a
+	// value, if set, when an order arrives.
+	orderDelay time.Duration
+	inFlight   chan struct{}
+}
+
+const fakeMid = 100.0
+
+// fakeOrder is a GTC limit order. It stays in resting once filled or
+// cancelled, with its status.
+type fakeOrder struct {
+	coin      string
+	isBuy     bool
+	price     float64
+	size      float64
+	remaining float64
+	status    hyperliquid.OrderStatusValue
+}
+
+func newFakeExchange(t *testing.T, coins ...string) *fakeExchange {
+	f := &fakeExchange{t: t, coins: coins, positions: make(map[string]float64), resting: make(map[int64]*fakeOrder)}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// source returns a candle source reading from the fake.
+func (f *fakeExchange) source() *Source {
+	return NewSource(Config{Network: NetworkProfile{Name: NetworkLocal, APIURL: f.server.URL}})
+}
+
+// account returns a trading account on the fake with a fresh key.
+func (f *fakeExchange) account(ctx context.Context) *Account {
+	return NewAccount(ctx, f.config())
+}
+
+// config returns the default settings on the fake's local network, with a
+// fresh key.
+func (f *fakeExchange) config() Config {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	settings := DefaultSettings()
+	settings.Network = NetworkLocal
+	return Config{
+		Settings:     settings,
+		BaseSettings: settings.clone(),
+		Network:      NetworkProfile{Name: NetworkLocal, APIURL: f.server.URL},
+		URL:          f.server.URL,
+		PrivateKey:   key,
+		Address:      crypto.PubkeyToAddress(key.PublicKey).Hex(),
+	}
+}
+
+func (f *fakeExchange) position(coin string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.positions[coin]
+}
+
+func (f *fakeExchange) orderCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.orders
+}
+
+func (f *fakeExchange) bulkCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.bulks
+}
+
+// restingOrders returns the IDs of the open GTC orders by price.
+func (f *fakeExchange) restingOrders() map[float64]int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make(map[float64]int64)
+	for oid, order := range f.resting {
+		if order.status == hyperliquid.OrderStatusValueOpen {
+			ids[order.price] = oid
+		}
+	}
+	return ids
+}
+
+// fillOrder trades size of a resting order at its price, filling it once
+// nothing remains.
+func (f *fakeExchange) fillOrder(oid int64, size float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order := f.resting[oid]
+	order.remaining = math.Round((order.remaining-size)*1e4) / 1e4
+	if !order.isBuy {
+		size = -size
+	}
+	f.positions[order.coin] = math.Round((f.positions[order.coin]+size)*1e4) / 1e4
+	if order.remaining <= 0 {
+		order.status = hyperliquid.OrderStatusValueFilled
+	}
+}
+
+// addFill adds a closing fill of order oid with the realized pnl and fee.
+func (f *fakeExchange) addFill(coin string, oid, tid, at int64, pnl, fee float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fills = append(f.fills, hyperliquid.Fill{
+		Coin:      coin,
+		Oid:       oid,
+		Tid:       tid,
+		Time:      at,
+		ClosedPnl: strconv.FormatFloat(pnl, 'f', -1, 64),
+		Fee:       strconv.FormatFloat(fee, 'f', -1, 64),
+	})
+}
+
+// cancelOrder cancels a resting order, as the exchange does on its own,
+// for instance for a lack of margin.
+func (f *fakeExchange) cancelOrder(oid int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resting[oid].status = hyperliquid.OrderStatusValueCanceled
+}
+
+func (f *fakeExchange) serve(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var resp any
+	switch r.URL.Path {
+	case "/info":
+		resp = f.info(req)
+	case "/exchange":
+		resp = f.exchange(req)
+	}
+	if resp == nil {
+		http.Error(w, fmt.Sprintf("unsupported request %v", req), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (f *fakeExchange) info(req map[string]any) any {
+	switch req["type"] {
+	case "meta":
+		universe := make([]hyperliquid.AssetInfo, len(f.coins))
+		for i, coin := range f.coins {
+			universe[i] = hyperliquid.AssetInfo{Name: coin, SzDecimals: 4, MaxLeverage: 50}
+		}
+		return hyperliquid.Meta{Universe: universe}
+	case "spotMeta":
+		return hyperliquid.SpotMeta{Universe: []hyperliquid.SpotAssetInfo{}, Tokens: []hyperliquid.SpotTokenInfo{}}
+	case "allMids":
+		mids := make(map[string]string, len(f.coins))
+		for _, coin := range f.coins {
+			mids[coin] = strconv.FormatFloat(fakeMid, 'f', -1, 64)
+		}
+		return mids
+	case "candleSnapshot":
+		params, _ := req["req"].(map[string]any)
+		coin, _ := params["coin"].(string)
+		return f.candles(coin, 50)
+	case "clearinghouseState":
+		return f.userState()
+	case "subAccounts":
+		return []hyperliquid.SubAccount{}
+	case "userFillsByTime":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		start, _ := req["startTime"].(float64)
+		fills := []hyperliquid.Fill{}
+		for _, fill := range f.fills {
+			if fill.Time >= int64(start) {
+				fills = append(fills, fill)
+			}
+		}
+		return fills
+	case "openOrders":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		orders := []hyperliquid.OpenOrder{}
+		for oid, order := range f.resting {
+			if order.status == hyperliquid.OrderStatusValueOpen {
+				orders = append(orders, hyperliquid.OpenOrder{Coin: order.coin, LimitPx: order.price, Oid: oid, Size: order.remaining})
+			}
+		}
+		return orders
+	case "orderStatus":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		oid, _ := req["oid"].(float64)
+		order, ok := f.resting[int64(oid)]
+		if !ok {
+			return map[string]any{"status": "unknownOid"}
+		}
+		return map[string]any{"status": "order", "order": map[string]any{
+			"status": order.status,
+			"order": hyperliquid.QueriedOrder{
+				Coin:   order.coin,
+				Oid:    int64(oid),
+				Sz:     strconv.FormatFloat(order.remaining, 'f', -1, 64),
+				OrigSz: strconv.FormatFloat(order.size, 'f', -1, 64),
+			},
+		}}
+	}
+	return nil
+}
+
+// candles closes the next bar and returns the last n bars of coin.
+func (f *fakeExchange) candles(coin string, n int) hyperliquid.Candles {
+	f.mu.Lock()
+	f.bar++
+	last := f.bar + int64(n)
+	f.mu.Unlock()
+
+	candles := make(hyperliquid.Candles, n)
+	for i := range candles {
+		bar := last - int64(n) + int64(i) + 1
+		price := strconv.FormatFloat(fakeMid+float64(bar%7), 'f', -1, 64)
+		candles[i] = hyperliquid.Candle{
+			Time:      bar * 60_000,
+			Timestamp: (bar+1)*60_000 - 1,
+			Symbol:    coin,
+			Interval:  "1m",
+			Open:      price,
+			High:      price,
+			Low:       price,
+			Close:     price,
+			Volume:    "1",
+		}
+	}
+	return candles
+}
+
+func (f *fakeExchange) userState() hyperliquid.UserState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state := hyperliquid.UserState{AssetPositions: []hyperliquid.AssetPosition{}}
+	for coin, size := range f.positions {
+		if size == 0 {
+			continue
+		}
+		entry := strconv.FormatFloat(fakeMid, 'f', -1, 64)
+		state.AssetPositions = append(state.AssetPositions, hyperliquid.AssetPosition{
+			Type: "oneWay",
+			Position: hyperliquid.Position{
+				Coin:          coin,
+				Szi:           strconv.FormatFloat(size, 'f', -1, 64),
+				EntryPx:       &entry,
+				Leverage:      hyperliquid.Leverage{Type: "cross", Value: 10},
+				PositionValue: strconv.FormatFloat(math.Abs(size)*fakeMid, 'f', -1, 64),
+			},
+		})
+	}
+	return state
+}
+
+func (f *fakeExchange) exchange(req map[string]any) any {
+	action, _ := req["action"].(map[string]any)
+	switch action["type"] {
+	case "updateLeverage":
+		return map[string]any{"status": "ok", "response": map[string]any{"type": "default", "data": map[string]any{}}}
+	case "modify":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		oid, _ := action["oid"].(float64)
+		order, _ := action["order"].(map[string]any)
+		resting, ok := f.resting[int64(oid)]
+		if !ok || resting.status != hyperliquid.OrderStatusValueOpen {
+			return map[string]any{"status": "err", "response": "order is not open"}
+		}
+		resting.price, _ = strconv.ParseFloat(order["p"].(string), 64)
+		resting.size, _ = strconv.ParseFloat(order["s"].(string), 64)
+		resting.remaining = resting.size
+		return map[string]any{"status": "ok", "response": map[string]any{"type": "order", "data": map[string]any{
+			"statuses": []hyperliquid.OrderStatus{{Resting: &hyperliquid.OrderStatusResting{Oid: int64(oid), Status: "resting"}}},
+		}}}
+	case "cancel":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		cancels, _ := action["cancels"].([]any)
+		statuses := make([]string, len(cancels))
+		for i, c := range cancels {
+			cancel, _ := c.(map[string]any)
+			oid, _ := cancel["o"].(float64)
+			if order, ok := f.resting[int64(oid)]; ok {
+				order.status = hyperliquid.OrderStatusValueCanceled
+			}
+			statuses[i] = "success"
+		}
+		return map[string]any{"status": "ok", "response": map[string]any{"type": "cancel", "data": map[string]any{"statuses": statuses}}}
+	case "order":
+	default:
+		return nil
+	}
+
+	f.mu.Lock()
+	delay, inFlight := f.orderDelay, f.inFlight
+	f.mu.Unlock()
+	if inFlight != nil {
+		select {
+		case inFlight <- struct{}{}:
+		default:
+		}
+	}
+	time.Sleep(delay)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bulks++
+	orders, _ := action["orders"].([]any)
+	statuses := make([]hyperliquid.OrderStatus, 0, len(orders))
+	for _, o := range orders {
+		order, _ := o.(map[string]any)
+		asset, _ := order["a"].(float64)
+		size, _ := strconv.ParseFloat(order["s"].(string), 64)
+		isBuy, _ := order["b"].(bool)
+		coin := f.coins[int(asset)]
+		if orderType, _ := order["t"].(map[string]any); orderType["limit"] != nil {
+			if limit, _ := orderType["limit"].(map[string]any); limit["tif"] == "Gtc" {
+				f.orders++
+				price, _ := strconv.ParseFloat(order["p"].(string), 64)
+				f.resting[int64(f.orders)] = &fakeOrder{coin: coin, isBuy: isBuy, price: price, size: size, remaining: size,
+					status: hyperliquid.OrderStatusValueOpen}
+				statuses = append(statuses, hyperliquid.OrderStatus{Resting: &hyperliquid.OrderStatusResting{Oid: int64(f.orders), Status: "resting"}})
+				continue
+			}
+		}
+		if !isBuy {
+			size = -size
+		}
+		// Sizes have four decimals; rounding keeps the book exact.
+		f.positions[coin] = math.Round((f.positions[coin]+size)*1e4) / 1e4
+		f.orders++
+		statuses = append(statuses, hyperliquid.OrderStatus{Filled: &hyperliquid.OrderStatusFilled{
+			TotalSz: order["s"].(string),
+			AvgPx:   strconv.FormatFloat(fakeMid, 'f', -1, 64),
+			Oid:     f.orders,
+		}})
+	}
+	return map[string]any{"status": "ok", "response": map[string]any{
+		"type": "order",
+		"data": hyperliquid.OrderResponse{Statuses: statuses},
+	}}
+}
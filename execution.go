@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+type ExecutionMode string
+
+const (
+	ExecutionMarket   ExecutionMode = "market"
+	ExecutionLimit    ExecutionMode = "limit"
+	ExecutionPostOnly ExecutionMode = "postOnly"
+	ExecutionTWAP     ExecutionMode = "twap"
+	ExecutionIceberg  ExecutionMode = "iceberg"
+)
+
+// ExecutionConfig describes how a strategy order is worked. Limit and
+// post-only orders are placed OffsetBps behind the touch, re-priced every
+// RepriceInterval while staying within MaxChaseBps of the first quote, and
+// whatever is left after Timeout is sent as an IOC order. The TWAP and
+// iceberg modes hand the order to the AlgoManager instead.
+type ExecutionConfig struct {
+	Mode            ExecutionMode
+	OffsetBps       float64
+	RepriceInterval time.Duration
+	MaxChaseBps     float64
+	Timeout         time.Duration
+	AlgoDuration    time.Duration
+	AlgoSlices      int
+	VisibleSize     float64
+	MakerFeePercent float64
+	TakerFeePercent float64
+}
+
+func DefaultExecutionConfig() ExecutionConfig {
+	return ExecutionConfig{
+		Mode:            ExecutionMarket,
+		OffsetBps:       0,
+		RepriceInterval: 5 * time.Second,
+		MaxChaseBps:     20,
+		Timeout:         60 * time.Second,
+		AlgoDuration:    5 * time.Minute,
+		AlgoSlices:      10,
+		MakerFeePercent: 0.015,
+		TakerFeePercent: 0.045,
+	}
+}
+
+func buildExecutionConfig(params map[string]any) ExecutionConfig {
+	exec := DefaultExecutionConfig()
+	if mode, ok := params["executionMode"].(string); ok {
+		exec.Mode = ExecutionMode(mode)
+	}
+	if offset, ok := params["limitOffsetBps"].(float64); ok {
+		exec.OffsetBps = offset
+	}
+	if seconds, ok := params["repriceSeconds"].(float64); ok && seconds > 0 {
+		exec.RepriceInterval = time.Duration(seconds * float64(time.Second))
+	}
+	if chase, ok := params["maxChaseBps"].(float64); ok {
+		exec.MaxChaseBps = chase
+	}
+	if seconds, ok := params["executionTimeoutSeconds"].(float64); ok && seconds > 0 {
+		exec.Timeout = time.Duration(seconds * float64(time.Second))
+	}
+	if seconds, ok := params["algoDurationSeconds"].(float64); ok && seconds > 0 {
+		exec.AlgoDuration = time.Duration(seconds * float64(time.Second))
+	}
+	if slices, ok := params["algoSlices"].(float64); ok {
+		exec.AlgoSlices = int(slices)
+	}
+	if visible, ok := params["visibleSize"].(float64); ok {
+		exec.VisibleSize = visible
+	}
+	if fee, ok := params["makerFeePercent"].(float64); ok {
+		exec.MakerFeePercent = fee
+	}
+	if fee, ok := params["takerFeePercent"].(float64); ok {
+		exec.TakerFeePercent = fee
+	}
+	return exec
+}
+
+func (e ExecutionConfig) Validate() error {
+	switch e.Mode {
+	case ExecutionMarket, ExecutionLimit, ExecutionPostOnly:
+	case ExecutionTWAP:
+		if e.AlgoDuration <= 0 || e.AlgoSlices < 1 {
+			return fmt.Errorf("twap execution needs a duration and at least one slice")
+		}
+	case ExecutionIceberg:
+		if e.VisibleSize <= 0 {
+			return fmt.Errorf("iceberg execution needs a visible size")
+		}
+	default:
+		return fmt.Errorf("unknown execution mode %q", e.Mode)
+	}
+	if e.Mode == ExecutionPostOnly && e.OffsetBps < 0 {
+		return fmt.Errorf("post-only orders cannot be placed through the touch")
+	}
+	if e.MaxChaseBps < 0 {
+		return fmt.Errorf("max chase must not be negative")
+	}
+	return nil
+}
+
+// limitPrice quotes OffsetBps behind the touch and clamps the quote so it
+// never chases more than MaxChaseBps away from the anchor price.
+func (e ExecutionConfig) limitPrice(touch, anchor float64, isBuy bool) float64 {
+	offset := e.OffsetBps / 10000
+	chase := e.MaxChaseBps / 10000
+	if isBuy {
+		return math.Min(touch*(1-offset), anchor*(1+chase))
+	}
+	return math.Max(touch*(1+offset), anchor*(1-chase))
+}
+
+func (e ExecutionConfig) isAlgo() bool {
+	return e.Mode == ExecutionTWAP || e.Mode == ExecutionIceberg
+}
+
+func (e ExecutionConfig) algoRequest(coin string, isBuy bool, size float64, reduceOnly bool) AlgoRequest {
+	req := AlgoRequest{
+		Coin:            coin,
+		IsBuy:           isBuy,
+		Size:            size,
+		ReduceOnly:      reduceOnly,
+		MaxChaseBps:     e.MaxChaseBps,
+		ClipTimeoutSecs: e.Timeout.Seconds(),
+	}
+	if e.Mode == ExecutionTWAP {
+		req.Type = AlgoTWAP
+		req.DurationSeconds = e.AlgoDuration.Seconds()
+		req.Slices = e.AlgoSlices
+		req.Randomize = 0.2
+	} else {
+		req.Type = AlgoIceberg
+		req.VisibleSize = min(e.VisibleSize, size)
+	}
+	return req
+}
+
+func (e ExecutionConfig) feePercent(maker bool) float64 {
+	if maker {
+		return e.MakerFeePercent
+	}
+	return e.TakerFeePercent
+}
+
+type backtestFill struct {
+	Index int
+	Price float64
+	Maker bool
+}
+
+// simulateFill models ExecutionConfig against historical candles. A resting
+// order is filled once a later candle trades through its price; it is
+// re-quoted from the candle close on the reprice schedule and crosses the
+// spread at the close of the bar where the timeout expires. TWAP fills at the
+// average close of the bars it spans.
+func simulateFill(candles hyperliquid.Candles, index int, isBuy bool, exec ExecutionConfig) backtestFill {
+	close := parseFloat(candles[index].Close)
+	if exec.Mode == ExecutionMarket || exec.Mode == "" || index >= len(candles)-1 {
+		return backtestFill{Index: index, Price: close}
+	}
+
+	barDuration := candleDuration(candles)
+	if exec.Mode == ExecutionTWAP {
+		last := min(index+max(1, int(exec.AlgoDuration/barDuration)), len(candles)-1)
+		sum := 0.0
+		for i := index + 1; i <= last; i++ {
+			sum += parseFloat(candles[i].Close)
+		}
+		return backtestFill{Index: last, Price: sum / float64(last-index)}
+	}
+
+	timeoutBars := max(1, int(math.Ceil(float64(exec.Timeout)/float64(barDuration))))
+	repriceBars := max(1, int(float64(exec.RepriceInterval)/float64(barDuration)))
+
+	anchor := close
+	price := exec.limitPrice(close, anchor, isBuy)
+	last := min(index+timeoutBars, len(candles)-1)
+
+	for i := index + 1; i <= last; i++ {
+		high := parseFloat(candles[i].High)
+		low := parseFloat(candles[i].Low)
+		if (isBuy && low < price) || (!isBuy && high > price) {
+			return backtestFill{Index: i, Price: price, Maker: true}
+		}
+		if (i-index)%repriceBars == 0 {
+			price = exec.limitPrice(parseFloat(candles[i].Close), anchor, isBuy)
+		}
+	}
+
+	return backtestFill{Index: last, Price: parseFloat(candles[last].Close)}
+}
+
+func candleDuration(candles hyperliquid.Candles) time.Duration {
+	if len(candles) < 2 {
+		return time.Minute
+	}
+	d := time.Duration(candles[1].Timestamp-candles[0].Timestamp) * time.Millisecond
+	if d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
+type chaseOrder struct {
+	oid    int64
+	price  float64
+	size   float64
+	filled float64
+}
+
+// executeLimit works a limit or post-only order according to exec and falls
+// back to an IOC order for the unfilled remainder once the timeout expires.
+// Before each re-price the resting order's fills are read so only the
+// remainder is chased. When ctx is done the resting order is cancelled and
+// the response carries what filled so far along with ctx's error.
+func (a *Account) executeLimit(ctx context.Context, coin string, isBuy bool, size float64, reduceOnly bool, exec ExecutionConfig) (OrderResponse, error) {
+	tif := hyperliquid.TifGtc
+	if exec.Mode == ExecutionPostOnly {
+		tif = hyperliquid.TifAlo
+	}
+
+	anchor, err := a.touchPrice(ctx, coin, isBuy)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+	}
+
+	order := chaseOrder{}
+	var filled, notional float64
+	deadline := time.Now().Add(exec.Timeout)
+	// syncFills adds the resting order's new fills and forgets it once it is done.
+	syncFills := func() {
+		if order.oid == 0 {
+			return
+		}
+		done, executed := a.queryOrderFill(ctx, order)
+		filled += executed - order.filled
+		notional += (executed - order.filled) * order.price
+		order.filled = executed
+		if done {
+			order = chaseOrder{}
+		}
+	}
+
+	for time.Now().Before(deadline) {
+		if _, err := a.roundSize(coin, size-filled); err != nil {
+			break
+		}
+
+		touch, err := a.touchPrice(ctx, coin, isBuy)
+		if err != nil {
+			slog.Warn("chase failed", "coin", coin, "error", err)
+		} else {
+			price, err := a.roundPrice(coin, exec.limitPrice(touch, anchor, isBuy))
+			if err == nil && price != order.price {
+				syncFills()
+			}
+			var orderSize float64
+			if err == nil {
+				orderSize, err = a.roundSize(coin, size-filled)
+			}
+			if err == nil && price != order.price {
+				req := hyperliquid.CreateOrderRequest{
+					Coin:       coin,
+					IsBuy:      isBuy,
+					Size:       orderSize,
+					Price:      price,
+					OrderType:  hyperliquid.OrderType{Limit: &hyperliquid.LimitOrderType{Tif: tif}},
+					ReduceOnly: reduceOnly,
+				}
+
+				var status hyperliquid.OrderStatus
+				if order.oid == 0 {
+					status, err = a.exchange.Order(ctx, req, nil)
+				} else {
+					status, err = a.exchange.ModifyOrder(ctx, hyperliquid.ModifyOrderRequest{Oid: order.oid, Order: req})
+				}
+
+				switch {
+				case err != nil:
+					slog.Warn("chase order failed", "coin", coin, "price", price, "size", orderSize, "error", err)
+				case status.Filled != nil:
+					filled += parseFloatSafe(status.Filled.TotalSz)
+					notional += parseFloatSafe(status.Filled.TotalSz) * parseFloatSafe(status.Filled.AvgPx)
+					order = chaseOrder{}
+				case status.Resting != nil:
+					order = chaseOrder{oid: status.Resting.Oid, price: price, size: orderSize}
+				case status.Error != nil:
+					slog.Warn("chase order rejected", "coin", coin, "price", price, "size", orderSize, "reason", *status.Error)
+				}
+			}
+		}
+
+		if _, err := a.roundSize(coin, size-filled); err != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			a.cancelChase(ctx, coin, order)
+			if order.oid != 0 {
+				_, executed := a.queryOrderFill(context.WithoutCancel(ctx), order)
+				filled += executed - order.filled
+				notional += (executed - order.filled) * order.price
+			}
+			resp := OrderResponse{Success: false, Message: "cancelled", Status: "error", FilledSize: filled}
+			if filled > 0 {
+				resp.AvgPrice = notional / filled
+			}
+			return resp, ctx.Err()
+		case <-time.After(exec.RepriceInterval):
+		}
+
+		syncFills()
+	}
+
+	if order.oid != 0 {
+		a.cancelChase(ctx, coin, order)
+		_, executed := a.queryOrderFill(ctx, order)
+		filled += executed - order.filled
+		notional += (executed - order.filled) * order.price
+	}
+
+	if remaining, err := a.roundSize(coin, size-filled); err == nil && remaining > 0 {
+		slog.Info("chase timed out, sending IOC", "coin", coin, "remaining", remaining)
+		price, err := a.exchange.SlippagePrice(ctx, coin, isBuy, 0.05, nil)
+		if err != nil {
+			return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+		}
+		status, err := a.exchange.Order(ctx, hyperliquid.CreateOrderRequest{
+			Coin:       coin,
+			IsBuy:      isBuy,
+			Size:       remaining,
+			Price:      price,
+			OrderType:  hyperliquid.OrderType{Limit: &hyperliquid.LimitOrderType{Tif: hyperliquid.TifIoc}},
+			ReduceOnly: reduceOnly,
+		}, nil)
+		if err != nil && filled == 0 {
+			return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+		}
+		if status.Filled != nil {
+			filled += parseFloatSafe(status.Filled.TotalSz)
+			notional += parseFloatSafe(status.Filled.TotalSz) * parseFloatSafe(status.Filled.AvgPx)
+		}
+	}
+
+	if filled == 0 {
+		return OrderResponse{Success: false, Message: "order not filled", Status: "error"}, fmt.Errorf("%s order not filled", coin)
+	}
+
+	return OrderResponse{
+		Success:    true,
+		Status:     "filled",
+		Message:    fmt.Sprintf("filled avgPx=%.6f size=%.8f", notional/filled, filled),
+		AvgPrice:   notional / filled,
+		FilledSize: filled,
+	}, nil
+}
+
+// cancelChase cancels the chase's resting order, if any. It outlives ctx,
+// which may be the reason the chase is ending.
+func (a *Account) cancelChase(ctx context.Context, coin string, order chaseOrder) {
+	if order.oid == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if _, err := a.exchange.Cancel(ctx, coin, order.oid); err != nil {
+		slog.Warn("chase cancel failed", "coin", coin, "oid", order.oid, "error", err)
+	}
+}
+
+func (a *Account) queryOrderFill(ctx context.Context, order chaseOrder) (bool, float64) {
+	result, err := a.info.QueryOrderByOid(ctx, a.address, order.oid)
+	if err != nil || result.Status != hyperliquid.OrderQueryStatusSuccess {
+		return false, order.filled
+	}
+	remaining := parseFloatSafe(result.Order.Order.Sz)
+	executed := order.size - remaining
+	done := result.Order.Status != hyperliquid.OrderStatusValueOpen
+	return done, executed
+}
+
+func (a *Account) touchPrice(ctx context.Context, coin string, isBuy bool) (float64, error) {
+	book, err := a.info.L2Snapshot(ctx, coin)
+	if err != nil {
+		return 0, err
+	}
+	if len(book.Levels) < 2 || len(book.Levels[0]) == 0 || len(book.Levels[1]) == 0 {
+		return 0, fmt.Errorf("empty order book for %s", coin)
+	}
+	if isBuy {
+		return book.Levels[0][0].Px, nil
+	}
+	return book.Levels[1][0].Px, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+const fillPollInterval = 15 * time.Second
+
+// watchFills feeds the realized PnL of the account's closing fills into the
+// risk manager until ctx is done. Every close counts, including exchange-side
+// stops, TP/SL triggers and liquidations that no strategy placed. Only fills
+// after the watcher starts are counted.
+func (a *Account) watchFills(ctx context.Context) {
+	since := time.Now().UnixMilli()
+	seen := make(map[int64]bool)
+	pending := make(map[int64]*fillTrade)
+	ticker := time.NewTicker(fillPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		next, err := a.pollFills(ctx, since, seen, pending)
+		if err != nil {
+			slog.Warn("fill poll failed", "account", a.id, "error", err)
+			continue
+		}
+		since = next
+	}
+}
+
+// fillTrade is the realized PnL, net of fees, of the closing fills of one
+// order so far.
+type fillTrade struct {
+	coin string
+	pnl  float64
+}
+
+// pollFills records the closing fills at or after since and returns the time
+// to poll from next. seen holds the trade ids already recorded at that time,
+// since the exchange returns them again. The fills of one order count as one
+// trade: pending carries the total of orders still open from one poll to the
+// next, and an order is recorded once it is no longer open.
+func (a *Account) pollFills(ctx context.Context, since int64, seen map[int64]bool, pending map[int64]*fillTrade) (int64, error) {
+	fills, err := a.info.UserFillsByTime(ctx, a.address, since, nil)
+	if err != nil {
+		return since, err
+	}
+
+	latest := since
+	for _, fill := range fills {
+		if seen[fill.Tid] {
+			continue
+		}
+		if fill.Time > latest {
+			latest = fill.Time
+			clear(seen)
+		}
+		if fill.Time == latest {
+			seen[fill.Tid] = true
+		}
+		closed := parseFloatSafe(fill.ClosedPnl)
+		if closed == 0 {
+			continue
+		}
+		t, ok := pending[fill.Oid]
+		if !ok {
+			t = &fillTrade{coin: fill.Coin}
+			pending[fill.Oid] = t
+		}
+		t.pnl += closed - parseFloatSafe(fill.Fee)
+	}
+	if len(pending) == 0 {
+		return latest, nil
+	}
+
+	// The fills are taken either way; orders stay pending until the open
+	// orders can be read.
+	orders, err := a.info.OpenOrders(ctx, a.address)
+	if err != nil {
+		slog.Warn("open orders failed, realized pnl deferred", "account", a.id, "error", err)
+		return latest, nil
+	}
+	open := make(map[int64]bool, len(orders))
+	for _, order := range orders {
+		open[order.Oid] = true
+	}
+	oids := slices.Sorted(maps.Keys(pending))
+	for _, oid := range oids {
+		if open[oid] {
+			continue
+		}
+		t := pending[oid]
+		delete(pending, oid)
+		if a.risk != nil {
+			slog.Info("realized pnl", "account", a.id, "coin", t.coin, "oid", oid, "pnl", t.pnl)
+			a.risk.RecordRealizedPnL(t.coin, t.pnl)
+		}
+	}
+	return latest, nil
+}
+
+// closingFill returns the size-weighted average price and the time of the
+// last of the account's fills since since that closed a position on side of
+// coin, or a zero price when there are none.
+func (a *Account) closingFill(coin, side string, since int64) (float64, int64, error) {
+	fills, err := a.info.UserFillsByTime(a.ctx, a.address, since, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch fills: %w", err)
+	}
+	// A long is closed by selling, on the ask side.
+	closeSide := "A"
+	if side == "short" {
+		closeSide = "B"
+	}
+	var size, notional float64
+	var last int64
+	for _, fill := range fills {
+		if fill.Coin != coin || fill.Side != closeSide || parseFloatSafe(fill.ClosedPnl) == 0 {
+			continue
+		}
+		sz := parseFloatSafe(fill.Size)
+		size += sz
+		notional += sz * parseFloatSafe(fill.Price)
+		last = max(last, fill.Time)
+	}
+	if size == 0 {
+		return 0, 0, nil
+	}
+	return notional / size, last, nil
+}
+
+// midPrice is the current mid price of coin.
+func (a *Account) midPrice(coin string) (float64, error) {
+	mids, err := a.info.AllMids(a.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch mid prices: %w", err)
+	}
+	mid, ok := mids[coin]
+	if !ok {
+		return 0, fmt.Errorf("no mid price for %s", coin)
+	}
+	return parseFloatSafe(mid), nil
+}
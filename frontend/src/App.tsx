@@ -2,6 +2,13 @@ import { Tabs, TabsContent, TabsList, TabsTrigger } from "@/components/ui/tabs";
 import { VisualizationTab } from "./components/tabs/VisualizationTab";
 import { ActiveStrategiesTab } from "./components/tabs/ActiveStrategiesTab";
 import { PortfolioTab } from "./components/tabs/PortfolioTab";
+import { SettingsTab } from "./components/tabs/SettingsTab";
+import { LogsTab } from "./components/tabs/LogsTab";
+import { ScriptsTab } from "./components/tabs/ScriptsTab";
+import { KillSwitch } from "./components/KillSwitch";
+import { UnlockDialog } from "./components/UnlockDialog";
+import { AgentStatusBadge } from "./components/AgentStatusBadge";
+import { NetworkSwitcher } from "./components/NetworkSwitcher";
 
 function App() {
     return (
@@ -10,12 +17,23 @@ function App() {
                 {/* Header with Tabs */}
                 <div className="border-b bg-card px-6 py-3">
                     <div className="flex items-center justify-between">
-                        <h1 className="text-xl font-bold tracking-tight">HyperTerminal</h1>
+                        <div className="flex items-center gap-3">
+                            <h1 className="text-xl font-bold tracking-tight">HyperTerminal</h1>
+                            <NetworkSwitcher />
+                        </div>
                         <TabsList>
                             <TabsTrigger value="visualization">Visualization</TabsTrigger>
                             <TabsTrigger value="active-strategies">Active Strategies</TabsTrigger>
                             <TabsTrigger value="portfolio">Portfolio</TabsTrigger>
+                            <TabsTrigger value="scripts">Scripts</TabsTrigger>
+                            <TabsTrigger value="logs">Logs</TabsTrigger>
+                            <TabsTrigger value="settings">Settings</TabsTrigger>
                         </TabsList>
+                        <div className="flex items-center gap-3">
+                            <AgentStatusBadge />
+                            <UnlockDialog />
+                            <KillSwitch />
+                        </div>
                     </div>
                 </div>
 
@@ -31,6 +49,18 @@ function App() {
                     <TabsContent value="portfolio" className="h-full m-0">
                         <PortfolioTab />
                     </TabsContent>
+
+                    <TabsContent value="scripts" className="h-full m-0">
+                        <ScriptsTab />
+                    </TabsContent>
+
+                    <TabsContent value="logs" className="h-full m-0">
+                        <LogsTab />
+                    </TabsContent>
+
+                    <TabsContent value="settings" className="h-full m-0">
+                        <SettingsTab />
+                    </TabsContent>
                 </div>
             </Tabs>
         </main>
@@ -0,0 +1,47 @@
+import { useEffect, useState } from "react";
+import { GetAgentStatus } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { Badge } from "@/components/ui/badge";
+
+export function AgentStatusBadge() {
+    const [status, setStatus] = useState<main.AgentStatus | null>(null);
+    const [error, setError] = useState('');
+
+    const refresh = async () => {
+        try {
+            setStatus(await GetAgentStatus());
+            setError('');
+        } catch (err) {
+            setError(String(err));
+        }
+    };
+
+    useEffect(() => {
+        refresh();
+        const interval = setInterval(refresh, 60000);
+        return () => clearInterval(interval);
+    }, []);
+
+    if (!status?.Enabled) {
+        return null;
+    }
+
+    const short = (addr: string) => `${addr.slice(0, 6)}…${addr.slice(-4)}`;
+    const healthy = status.Approved && !status.Expired && !status.ExpiringSoon;
+    const title = [
+        `Agent ${status.AgentAddress}${status.Name ? ` (${status.Name})` : ''}`,
+        `Trading for ${status.MasterAddress}`,
+        status.ValidUntil ? `Valid until ${new Date(status.ValidUntil).toLocaleString()}` : '',
+        status.Message || error,
+    ].filter(Boolean).join('\n');
+
+    return (
+        <Badge
+            variant={healthy ? "secondary" : "destructive"}
+            title={title}
+        >
+            Agent {short(status.AgentAddress)}
+            {!status.Approved ? ' · not approved' : status.Expired ? ' · expired' : status.ExpiringSoon ? ' · expiring' : ''}
+        </Badge>
+    );
+}
@@ -0,0 +1,81 @@
+import { useEffect, useState } from "react";
+import { GetRiskStatus, ResetKillSwitch, TripKillSwitch } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { EventsOn } from "@/../wailsjs/runtime/runtime";
+import { Badge } from "@/components/ui/badge";
+import { Button } from "@/components/ui/button";
+import { OctagonX, RotateCcw } from "lucide-react";
+
+export function KillSwitch() {
+    const [status, setStatus] = useState<main.RiskStatus | null>(null);
+    const [busy, setBusy] = useState(false);
+
+    const refresh = async () => {
+        try {
+            setStatus(await GetRiskStatus());
+        } catch (err) {
+            console.error('Risk status fetch error:', err);
+        }
+    };
+
+    useEffect(() => {
+        refresh();
+        const off = EventsOn("risk:status", (s: main.RiskStatus) => setStatus(s));
+        const interval = setInterval(refresh, 10000);
+        return () => {
+            off();
+            clearInterval(interval);
+        };
+    }, []);
+
+    const trip = async () => {
+        if (!confirm("Cancel all open orders, flatten every position and halt all strategies?")) {
+            return;
+        }
+        setBusy(true);
+        try {
+            await TripKillSwitch("manual");
+        } finally {
+            setBusy(false);
+            refresh();
+        }
+    };
+
+    const reset = async () => {
+        setBusy(true);
+        try {
+            await ResetKillSwitch();
+        } finally {
+            setBusy(false);
+            refresh();
+        }
+    };
+
+    if (status?.KillSwitch) {
+        return (
+            <div className="flex items-center gap-2">
+                <Badge variant="destructive" title={status.KillSwitchReason}>
+                    HALTED: {status.KillSwitchReason}
+                </Badge>
+                <Button variant="outline" size="sm" onClick={reset} disabled={busy}>
+                    <RotateCcw className="h-4 w-4" />
+                    Reset
+                </Button>
+            </div>
+        );
+    }
+
+    return (
+        <div className="flex items-center gap-2">
+            {status && (
+                <span className="text-xs text-muted-foreground">
+                    Daily PnL {status.DailyRealizedPnL.toFixed(2)} · Losses {status.ConsecutiveLosses}
+                </span>
+            )}
+            <Button variant="destructive" size="sm" onClick={trip} disabled={busy}>
+                <OctagonX className="h-4 w-4" />
+                Kill Switch
+            </Button>
+        </div>
+    );
+}
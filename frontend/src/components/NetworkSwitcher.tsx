@@ -0,0 +1,81 @@
+import { useEffect, useState } from "react";
+import { ConfirmMainnetTrading, GetNetworkStatus, SetNetwork } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { EventsOn } from "@/../wailsjs/runtime/runtime";
+import { Button } from "@/components/ui/button";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+
+const NETWORK_STYLES: Record<string, string> = {
+    mainnet: "border-red-500 text-red-500",
+    testnet: "border-yellow-500 text-yellow-500",
+    local: "border-sky-500 text-sky-500",
+};
+
+export function NetworkSwitcher() {
+    const [status, setStatus] = useState<main.NetworkStatus | null>(null);
+    const [busy, setBusy] = useState(false);
+
+    const refresh = async () => {
+        try {
+            setStatus(await GetNetworkStatus());
+        } catch (err) {
+            console.error('Network status fetch error:', err);
+        }
+    };
+
+    useEffect(() => {
+        refresh();
+        return EventsOn("network:status", (s: main.NetworkStatus) => setStatus(s));
+    }, []);
+
+    const confirmMainnet = async () => {
+        if (confirm("You are about to trade with real funds on MAINNET. Continue?")) {
+            await ConfirmMainnetTrading();
+        }
+    };
+
+    const switchNetwork = async (network: string) => {
+        if (network === 'mainnet' && !confirm("Switch to MAINNET? Balances and orders will be real.")) {
+            return;
+        }
+        setBusy(true);
+        try {
+            await SetNetwork(network);
+            if (network === 'mainnet') {
+                await confirmMainnet();
+            }
+        } catch (err) {
+            alert(String(err));
+        } finally {
+            setBusy(false);
+            refresh();
+        }
+    };
+
+    if (!status) {
+        return null;
+    }
+
+    return (
+        <div className="flex items-center gap-2">
+            <Select value={status.Network} onValueChange={switchNetwork} disabled={busy}>
+                <SelectTrigger
+                    className={`h-8 w-32 font-semibold uppercase ${NETWORK_STYLES[status.Network] ?? ""}`}
+                    title={status.APIURL}
+                >
+                    <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                    <SelectItem value="mainnet">Mainnet</SelectItem>
+                    <SelectItem value="testnet">Testnet</SelectItem>
+                    <SelectItem value="local">Local</SelectItem>
+                </SelectContent>
+            </Select>
+            {!status.TradingConfirmed && (
+                <Button variant="outline" size="sm" className="border-red-500 text-red-500" onClick={() => confirmMainnet().finally(refresh)}>
+                    Enable trading
+                </Button>
+            )}
+        </div>
+    );
+}
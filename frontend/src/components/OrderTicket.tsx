@@ -0,0 +1,128 @@
+import { useState } from "react";
+import { PlaceOrder } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Label } from "@/components/ui/label";
+import { Input } from "@/components/ui/input";
+import { Button } from "@/components/ui/button";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+
+interface OrderTicketProps {
+    accountId?: string;
+    onSubmitted?: () => void;
+}
+
+export function OrderTicket({ accountId = 'main', onSubmitted }: OrderTicketProps) {
+    const [coin, setCoin] = useState('BTC');
+    const [kind, setKind] = useState('limit');
+    const [tif, setTif] = useState('Gtc');
+    const [size, setSize] = useState('');
+    const [price, setPrice] = useState('');
+    const [triggerPrice, setTriggerPrice] = useState('');
+    const [leverage, setLeverage] = useState('10');
+    const [reduceOnly, setReduceOnly] = useState(false);
+    const [submitting, setSubmitting] = useState(false);
+    const [result, setResult] = useState<{ ok: boolean; message: string } | null>(null);
+
+    const submit = async (isBuy: boolean) => {
+        setSubmitting(true);
+        setResult(null);
+        try {
+            const resp = await PlaceOrder(main.OrderTicket.createFrom({
+                AccountID: accountId,
+                Coin: coin,
+                IsBuy: isBuy,
+                Kind: kind,
+                Size: parseFloat(size) || 0,
+                Price: parseFloat(price) || 0,
+                TriggerPrice: parseFloat(triggerPrice) || 0,
+                ReduceOnly: reduceOnly,
+                Tif: tif,
+                Leverage: parseInt(leverage) || 0,
+            }));
+            setResult({ ok: resp.Success, message: resp.Message || resp.Status });
+            onSubmitted?.();
+        } catch (err) {
+            setResult({ ok: false, message: String(err) });
+        } finally {
+            setSubmitting(false);
+        }
+    };
+
+    const isTriggered = kind === 'stop' || kind === 'takeProfit';
+
+    return (
+        <Card>
+            <CardHeader>
+                <CardTitle>Order Ticket</CardTitle>
+                <CardDescription>Place a manual order on {accountId}</CardDescription>
+            </CardHeader>
+            <CardContent className="space-y-4">
+                <div className="grid grid-cols-3 gap-4">
+                    <div className="space-y-2">
+                        <Label htmlFor="ticket-coin">Symbol</Label>
+                        <Input id="ticket-coin" value={coin} onChange={(e) => setCoin(e.target.value.toUpperCase())} />
+                    </div>
+                    <div className="space-y-2">
+                        <Label>Type</Label>
+                        <Select value={kind} onValueChange={setKind}>
+                            <SelectTrigger><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                <SelectItem value="market">Market</SelectItem>
+                                <SelectItem value="limit">Limit</SelectItem>
+                                <SelectItem value="stop">Stop</SelectItem>
+                                <SelectItem value="takeProfit">Take Profit</SelectItem>
+                            </SelectContent>
+                        </Select>
+                    </div>
+                    <div className="space-y-2">
+                        <Label>Time in Force</Label>
+                        <Select value={tif} onValueChange={setTif} disabled={kind !== 'limit'}>
+                            <SelectTrigger><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                <SelectItem value="Gtc">GTC</SelectItem>
+                                <SelectItem value="Ioc">IOC</SelectItem>
+                                <SelectItem value="Alo">Post Only</SelectItem>
+                            </SelectContent>
+                        </Select>
+                    </div>
+                </div>
+                <div className="grid grid-cols-4 gap-4">
+                    <div className="space-y-2">
+                        <Label htmlFor="ticket-size">Size</Label>
+                        <Input id="ticket-size" type="number" value={size} onChange={(e) => setSize(e.target.value)} />
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="ticket-price">{isTriggered ? 'Limit Price (optional)' : 'Price'}</Label>
+                        <Input id="ticket-price" type="number" value={price} disabled={kind === 'market'} onChange={(e) => setPrice(e.target.value)} />
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="ticket-trigger">Trigger Price</Label>
+                        <Input id="ticket-trigger" type="number" value={triggerPrice} disabled={!isTriggered} onChange={(e) => setTriggerPrice(e.target.value)} />
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="ticket-leverage">Leverage</Label>
+                        <Input id="ticket-leverage" type="number" value={leverage} onChange={(e) => setLeverage(e.target.value)} />
+                    </div>
+                </div>
+                <div className="flex items-center justify-between">
+                    <label className="flex items-center gap-2 text-sm">
+                        <input type="checkbox" checked={reduceOnly} onChange={(e) => setReduceOnly(e.target.checked)} />
+                        Reduce only
+                    </label>
+                    <div className="flex gap-2">
+                        <Button className="bg-green-600 hover:bg-green-700" disabled={submitting} onClick={() => submit(true)}>
+                            Buy / Long
+                        </Button>
+                        <Button variant="destructive" disabled={submitting} onClick={() => submit(false)}>
+                            Sell / Short
+                        </Button>
+                    </div>
+                </div>
+                {result && (
+                    <p className={`text-sm ${result.ok ? "text-green-500" : "text-red-500"}`}>{result.message}</p>
+                )}
+            </CardContent>
+        </Card>
+    );
+}
@@ -0,0 +1,87 @@
+import { useEffect, useState } from "react";
+import { DiscardSavedStrategy, GetSavedStrategies, ResumeSavedStrategies } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Badge } from "@/components/ui/badge";
+import { Button } from "@/components/ui/button";
+import { Play, X } from "lucide-react";
+
+interface SavedStrategiesProps {
+    runningIds: string[];
+}
+
+// SavedStrategies lists persisted strategies that are not running, e.g. after
+// a detached shutdown or on an account that is still locked.
+export function SavedStrategies({ runningIds }: SavedStrategiesProps) {
+    const [records, setRecords] = useState<main.StrategyRecord[]>([]);
+    const [busy, setBusy] = useState(false);
+
+    const refresh = async () => {
+        try {
+            setRecords((await GetSavedStrategies()) || []);
+        } catch (err) {
+            console.error('Saved strategies fetch error:', err);
+        }
+    };
+
+    useEffect(() => {
+        refresh();
+    }, [runningIds.join(",")]);
+
+    const stopped = records.filter((r) => !runningIds.includes(r.ID));
+    if (stopped.length === 0) {
+        return null;
+    }
+
+    const run = async (action: () => Promise<unknown>) => {
+        setBusy(true);
+        try {
+            await action();
+        } catch (err) {
+            alert(String(err));
+        } finally {
+            setBusy(false);
+            refresh();
+        }
+    };
+
+    return (
+        <Card>
+            <CardHeader className="flex flex-row items-center justify-between">
+                <div>
+                    <CardTitle>Saved Strategies</CardTitle>
+                    <CardDescription>Persisted strategies that are not running</CardDescription>
+                </div>
+                <Button size="sm" disabled={busy} onClick={() => run(ResumeSavedStrategies)}>
+                    <Play className="h-4 w-4" />
+                    Resume All
+                </Button>
+            </CardHeader>
+            <CardContent className="space-y-2">
+                {stopped.map((rec) => (
+                    <div key={rec.ID} className="flex items-center justify-between text-sm">
+                        <div className="flex items-center gap-2">
+                            <span className="font-medium">{rec.ID}</span>
+                            <span className="text-muted-foreground">
+                                {rec.Symbol}/USD · {rec.Interval} · {rec.AccountID} · {rec.Network}
+                            </span>
+                            {rec.Position?.IsOpen && (
+                                <Badge variant={rec.Position.Side === "long" ? "default" : "destructive"}>
+                                    {rec.Position.Side.toUpperCase()} {rec.Position.Size}
+                                </Badge>
+                            )}
+                        </div>
+                        <Button
+                            variant="ghost"
+                            size="sm"
+                            disabled={busy}
+                            onClick={() => confirm(`Discard ${rec.ID}? Any open position stays on the exchange.`) && run(() => DiscardSavedStrategy(rec.ID))}
+                        >
+                            <X className="h-4 w-4" />
+                        </Button>
+                    </div>
+                ))}
+            </CardContent>
+        </Card>
+    );
+}
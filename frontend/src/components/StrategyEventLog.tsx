@@ -0,0 +1,57 @@
+import { useEffect, useState } from "react";
+import { GetStrategyEvents } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { EventsOn } from "@/../wailsjs/runtime/runtime";
+import { Badge } from "@/components/ui/badge";
+
+const MAX_EVENTS = 100;
+
+const typeVariant: Record<string, "default" | "secondary" | "destructive" | "outline"> = {
+    candle: "outline",
+    signal: "secondary",
+    order_placed: "secondary",
+    order_filled: "default",
+    order_rejected: "destructive",
+    position_opened: "default",
+    position_closed: "default",
+    error: "destructive",
+};
+
+interface StrategyEventLogProps {
+    strategyId: string;
+}
+
+export function StrategyEventLog({ strategyId }: StrategyEventLogProps) {
+    const [events, setEvents] = useState<main.StrategyEvent[]>([]);
+
+    useEffect(() => {
+        GetStrategyEvents(strategyId, MAX_EVENTS)
+            .then((evs) => setEvents(evs || []))
+            .catch((err) => console.error('Strategy events fetch error:', err));
+
+        return EventsOn("strategy:event", (ev: main.StrategyEvent) => {
+            if (ev.StrategyID !== strategyId) {
+                return;
+            }
+            setEvents((prev) => [...prev.filter((p) => p.Seq !== ev.Seq), ev].slice(-MAX_EVENTS));
+        });
+    }, [strategyId]);
+
+    if (events.length === 0) {
+        return <p className="text-xs text-muted-foreground">No events yet</p>;
+    }
+
+    return (
+        <div className="max-h-48 overflow-y-auto space-y-1 font-mono text-xs">
+            {[...events].reverse().map((ev) => (
+                <div key={ev.Seq} className="flex items-center gap-2">
+                    <span className="text-muted-foreground">{new Date(ev.Time).toLocaleTimeString()}</span>
+                    <Badge variant={typeVariant[ev.Type] || "outline"} className="text-[10px]">
+                        {ev.Type.replace('_', ' ')}
+                    </Badge>
+                    <span className="truncate">{ev.Message}</span>
+                </div>
+            ))}
+        </div>
+    );
+}
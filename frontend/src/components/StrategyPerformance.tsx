@@ -0,0 +1,88 @@
+import { useState } from "react";
+import { main } from "@/../wailsjs/go/models";
+import { Button } from "@/components/ui/button";
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from "@/components/ui/table";
+
+interface StrategyPerformanceProps {
+    strategy: main.LiveStrategy;
+}
+
+const pnlClass = (value: number) => (value >= 0 ? "text-green-400" : "text-red-400");
+
+// StrategyPerformance shows live metrics and the closed-trade journal. The
+// metrics come from the same calculation as backtests.
+export function StrategyPerformance({ strategy }: StrategyPerformanceProps) {
+    const [showJournal, setShowJournal] = useState(false);
+    const perf = strategy.Performance;
+    const trades = strategy.Trades || [];
+
+    return (
+        <div className="space-y-3">
+            <div className="grid grid-cols-6 gap-4 text-sm">
+                <div>
+                    <p className="text-muted-foreground">Realized PnL</p>
+                    <p className={`font-medium ${pnlClass(perf.TotalPnL)}`}>${perf.TotalPnL.toFixed(2)}</p>
+                </div>
+                <div>
+                    <p className="text-muted-foreground">Unrealized PnL</p>
+                    <p className={`font-medium ${pnlClass(strategy.UnrealizedPnL)}`}>${strategy.UnrealizedPnL.toFixed(2)}</p>
+                </div>
+                <div>
+                    <p className="text-muted-foreground">Win Rate</p>
+                    <p className="font-medium">{perf.WinRate.toFixed(1)}%</p>
+                </div>
+                <div>
+                    <p className="text-muted-foreground">Trades</p>
+                    <p className="font-medium">{perf.TotalTrades}</p>
+                </div>
+                <div>
+                    <p className="text-muted-foreground">Fees Paid</p>
+                    <p className="font-medium">${perf.FeesPaid.toFixed(2)}</p>
+                </div>
+                <div>
+                    <p className="text-muted-foreground">Max Drawdown</p>
+                    <p className="font-medium text-red-400">${perf.MaxDrawdown.toFixed(2)}</p>
+                </div>
+            </div>
+
+            {trades.length > 0 && (
+                <Button variant="ghost" size="sm" onClick={() => setShowJournal(!showJournal)}>
+                    {showJournal ? 'Hide' : 'Show'} trade journal ({trades.length})
+                </Button>
+            )}
+
+            {showJournal && (
+                <Table>
+                    <TableHeader>
+                        <TableRow>
+                            <TableHead>Side</TableHead>
+                            <TableHead>Entry</TableHead>
+                            <TableHead>Exit</TableHead>
+                            <TableHead>Size</TableHead>
+                            <TableHead>Fees</TableHead>
+                            <TableHead>PnL</TableHead>
+                            <TableHead>Reason</TableHead>
+                            <TableHead>Closed</TableHead>
+                        </TableRow>
+                    </TableHeader>
+                    <TableBody>
+                        {[...trades].reverse().map((trade, i) => (
+                            <TableRow key={i}>
+                                <TableCell className="capitalize">{trade.Side}</TableCell>
+                                <TableCell>${trade.EntryPrice.toFixed(2)}</TableCell>
+                                <TableCell>${trade.ExitPrice.toFixed(2)}</TableCell>
+                                <TableCell>{trade.Size}</TableCell>
+                                <TableCell>${trade.Fees.toFixed(2)}</TableCell>
+                                <TableCell className={pnlClass(trade.PnL)}>
+                                    ${trade.PnL.toFixed(2)} ({trade.PnLPercentage.toFixed(2)}%)
+                                </TableCell>
+                                <TableCell>{trade.ExitReason}</TableCell>
+                                <TableCell>{new Date(trade.ExitTime).toLocaleString()}</TableCell>
+                            </TableRow>
+                        ))}
+                    </TableBody>
+                </Table>
+            )}
+        </div>
+    );
+}
@@ -7,6 +7,8 @@ import {
   LineData,
   IChartApi,
   ISeriesApi,
+  IPriceLine,
+  LineStyle,
   createSeriesMarkers,
 } from "lightweight-charts";
 import { useEffect, useRef, useState, useMemo, useCallback } from "react";
@@ -31,6 +33,7 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
   const chartInstanceRef = useRef<IChartApi | null>(null);
   const candleSeriesRef = useRef<ISeriesApi<"Candlestick"> | null>(null);
   const trendLineSeriesRef = useRef<ISeriesApi<"Line">[]>([]);
+  const gridLinesRef = useRef<IPriceLine[]>([]);
   const [clickedPrice, setClickedPrice] = useState<number | null>(null);
   const prevCandlesLength = useRef(0);
   const prevStrategyHash = useRef("");
@@ -338,6 +341,27 @@ export const TradingChart = ({ intervalSeconds }: TradingChartProps) => {
     prevStrategyHash.current = strategyHash;
   }, [strategyOutput, strategyHash, candles]);
 
+  // Grid strategies draw their levels as price lines.
+  useEffect(() => {
+    const candleSeries = candleSeriesRef.current;
+    if (!candleSeries) return;
+
+    gridLinesRef.current.forEach((line) => candleSeries.removePriceLine(line));
+    gridLinesRef.current = [];
+
+    const levels = strategyOutput?.Grid?.Levels ?? [];
+    gridLinesRef.current = levels.map((price, i) =>
+      candleSeries.createPriceLine({
+        price,
+        color: "#8b8b8b",
+        lineWidth: 1,
+        lineStyle: LineStyle.Dotted,
+        axisLabelVisible: i === 0 || i === levels.length - 1,
+        title: i === 0 ? "Grid low" : i === levels.length - 1 ? "Grid high" : "",
+      })
+    );
+  }, [strategyOutput]);
+
   const handleChartClick = (e: React.MouseEvent) => {
     if (!chartInstanceRef.current || !candleSeriesRef.current) return;
     const rect = chartRef.current?.getBoundingClientRect();
@@ -0,0 +1,89 @@
+import { useEffect, useState } from "react";
+import { GetWalletStatus, UnlockWallet } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { Dialog, DialogContent, DialogDescription, DialogFooter, DialogHeader, DialogTitle } from "@/components/ui/dialog";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Button } from "@/components/ui/button";
+import { Badge } from "@/components/ui/badge";
+
+export function UnlockDialog() {
+    const [status, setStatus] = useState<main.WalletStatus | null>(null);
+    const [open, setOpen] = useState(false);
+    const [passphrase, setPassphrase] = useState('');
+    const [error, setError] = useState('');
+    const [busy, setBusy] = useState(false);
+
+    const refresh = async () => {
+        try {
+            const s = await GetWalletStatus();
+            setStatus(s);
+            return s;
+        } catch (err) {
+            console.error('Wallet status fetch error:', err);
+            return null;
+        }
+    };
+
+    useEffect(() => {
+        refresh().then((s) => setOpen(!!s?.Locked));
+    }, []);
+
+    const unlock = async () => {
+        setBusy(true);
+        setError('');
+        try {
+            await UnlockWallet(passphrase);
+            setPassphrase('');
+            setOpen(false);
+            refresh();
+        } catch (err) {
+            setError(String(err));
+        } finally {
+            setBusy(false);
+        }
+    };
+
+    return (
+        <>
+            {status?.ReadOnly && (
+                <Badge
+                    variant="outline"
+                    className={status.Locked ? "cursor-pointer" : ""}
+                    onClick={() => status.Locked && setOpen(true)}
+                >
+                    Read-only
+                </Badge>
+            )}
+            <Dialog open={open} onOpenChange={setOpen}>
+                <DialogContent>
+                    <DialogHeader>
+                        <DialogTitle>Unlock Wallet</DialogTitle>
+                        <DialogDescription>
+                            Enter the passphrase for {status?.KeystorePath} ({status?.Address || 'unknown address'}).
+                        </DialogDescription>
+                    </DialogHeader>
+                    <div className="space-y-2">
+                        <Label htmlFor="unlock-passphrase">Passphrase</Label>
+                        <Input
+                            id="unlock-passphrase"
+                            type="password"
+                            value={passphrase}
+                            onChange={(e) => setPassphrase(e.target.value)}
+                            onKeyDown={(e) => e.key === 'Enter' && unlock()}
+                        />
+                        {error && <p className="text-sm text-red-500">{error}</p>}
+                    </div>
+                    <DialogFooter>
+                        <Button variant="outline" onClick={() => setOpen(false)}>
+                            Continue read-only
+                        </Button>
+                        <Button disabled={busy || !passphrase} onClick={unlock}>
+                            Unlock
+                        </Button>
+                    </DialogFooter>
+                </DialogContent>
+            </Dialog>
+        </>
+    );
+}
@@ -6,6 +6,9 @@ import { Separator } from "@/components/ui/separator";
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
 import { X, TrendingUp, TrendingDown } from "lucide-react";
 import { TradingStrategyManager } from "@/lib/TradingStrategyManager";
+import { SavedStrategies } from "@/components/SavedStrategies";
+import { StrategyEventLog } from "@/components/StrategyEventLog";
+import { StrategyPerformance } from "@/components/StrategyPerformance";
 
 interface ActiveStrategy {
     id: string;
@@ -124,9 +127,19 @@ export function ActiveStrategiesTab() {
         // TODO: Implement position closing
     };
 
-    const handlePauseStrategy = async (id: string) => {
-        console.log('Pause strategy:', id);
-        // TODO: Implement pause
+    const handlePauseStrategy = async (id: string, paused: boolean) => {
+        try {
+            if (paused) {
+                await strategyManager.resumeLiveStrategy(id);
+            } else {
+                await strategyManager.pauseLiveStrategy(id);
+            }
+            const running = await strategyManager.getRunningStrategies();
+            setStrategies(running || []);
+        } catch (error) {
+            console.error('Failed to pause strategy:', error);
+            alert(`Failed to ${paused ? 'resume' : 'pause'} strategy: ${error}`);
+        }
     };
 
     const handleStopStrategy = async (id: string) => {
@@ -144,8 +157,12 @@ export function ActiveStrategiesTab() {
 
     const filteredStrategies = strategies.filter(s => {
         if (filterStatus === 'all') return true;
-        const status = s.IsRunning ? 'running' : 'paused';
-        return status === filterStatus;
+        return s.State === filterStatus;
+    }).sort((a, b) => {
+        if (sortBy === 'symbol') return a.Symbol.localeCompare(b.Symbol);
+        if (sortBy === 'status') return a.State.localeCompare(b.State);
+        const pnl = (s: any) => (s.Performance?.TotalPnL || 0) + (s.UnrealizedPnL || 0);
+        return pnl(b) - pnl(a);
     });
 
     return (
@@ -166,6 +183,8 @@ export function ActiveStrategiesTab() {
                             <SelectItem value="all">All</SelectItem>
                             <SelectItem value="running">Running</SelectItem>
                             <SelectItem value="paused">Paused</SelectItem>
+                            <SelectItem value="warming_up">Warming Up</SelectItem>
+                            <SelectItem value="errored">Errored</SelectItem>
                         </SelectContent>
                     </Select>
                     <Select value={sortBy} onValueChange={setSortBy}>
@@ -188,12 +207,15 @@ export function ActiveStrategiesTab() {
                             <div className="flex items-center justify-between">
                                 <div className="flex flex-col gap-2">
                                     <div className="flex items-center gap-3">
-                                        <CardTitle className="text-lg">{strategy.Strategy?.name || strategy.ID}</CardTitle>
-                                        <Badge variant={strategy.IsRunning ? 'default' : 'secondary'}>
-                                            {strategy.IsRunning ? 'running' : 'stopped'}
+                                        <CardTitle className="text-lg">{strategy.Name || strategy.ID}</CardTitle>
+                                        <Badge
+                                            variant={strategy.State === 'running' ? 'default' : strategy.State === 'errored' ? 'destructive' : 'secondary'}
+                                            title={strategy.Error || undefined}
+                                        >
+                                            {strategy.State.replace('_', ' ')}
                                         </Badge>
                                         <span className="text-sm text-muted-foreground">
-                                            {strategy.Symbol}/USD · {strategy.Interval}
+                                            {strategy.Symbol}/USD · {strategy.Interval} · {strategy.AccountID}
                                         </span>
                                     </div>
                                     {strategy.Config?.Parameters && (
@@ -219,11 +241,20 @@ export function ActiveStrategiesTab() {
                                     )}
                                 </div>
                                 <div className="flex gap-2">
-                                    {strategy.IsRunning && !strategy.Position?.IsOpen && (
+                                    {strategy.State !== 'stopping' && (
                                         <>
-                                            <Button variant="destructive" size="sm" onClick={() => handleStopStrategy(strategy.ID)}>
-                                                Stop
+                                            <Button
+                                                variant="outline"
+                                                size="sm"
+                                                onClick={() => handlePauseStrategy(strategy.ID, strategy.State === 'paused')}
+                                            >
+                                                {strategy.State === 'paused' ? 'Resume' : 'Pause'}
                                             </Button>
+                                            {!strategy.Position?.IsOpen && (
+                                                <Button variant="destructive" size="sm" onClick={() => handleStopStrategy(strategy.ID)}>
+                                                    Stop
+                                                </Button>
+                                            )}
                                         </>
                                     )}
                                 </div>
@@ -252,19 +283,33 @@ export function ActiveStrategiesTab() {
                                         <div className="grid grid-cols-4 gap-4 text-sm">
                                             <div>
                                                 <p className="text-muted-foreground">Entry Price</p>
-                                                <p className="font-medium">${strategy.Position.EntryPrice?.toFixed(2) || 'N/A'}</p>
+                                                <p className="font-medium">
+                                                    ${strategy.Position.EntryPrice?.toFixed(2) || 'N/A'}
+                                                    {strategy.Position.Entries > 1 && (
+                                                        <span className="text-xs text-muted-foreground"> avg of {strategy.Position.Entries}</span>
+                                                    )}
+                                                </p>
                                             </div>
                                             <div>
                                                 <p className="text-muted-foreground">Size</p>
                                                 <p className="font-medium">{strategy.Position.Size} {strategy.Symbol}</p>
+                                                {strategy.Position.Legs?.slice(1).map((leg) => (
+                                                    <p key={leg.Symbol} className="text-xs text-muted-foreground">
+                                                        {leg.Side} {leg.Size.toFixed(4)} {leg.Symbol} @ ${leg.EntryPrice.toFixed(2)}
+                                                    </p>
+                                                ))}
                                             </div>
                                             <div>
                                                 <p className="text-muted-foreground">TP Target</p>
                                                 <p className="font-medium text-green-400">${(strategy.Position.EntryPrice * (1 + strategy.Config.TakeProfitPercent / 100)).toFixed(2)}</p>
                                             </div>
                                             <div>
-                                                <p className="text-muted-foreground">SL Target</p>
-                                                <p className="font-medium text-red-400">${(strategy.Position.EntryPrice * (1 - strategy.Config.StopLossPercent / 100)).toFixed(2)}</p>
+                                                <p className="text-muted-foreground">{strategy.Position.StopPrice > 0 ? strategy.Position.StopReason : 'SL Target'}</p>
+                                                <p className="font-medium text-red-400">
+                                                    ${(strategy.Position.StopPrice > 0
+                                                        ? strategy.Position.StopPrice
+                                                        : strategy.Position.EntryPrice * (1 - strategy.Config.StopLossPercent / 100)).toFixed(2)}
+                                                </p>
                                             </div>
                                         </div>
                                     </div>
@@ -272,6 +317,36 @@ export function ActiveStrategiesTab() {
                             ) : (
                                 <p className="text-sm text-muted-foreground text-center py-4">No active positions</p>
                             )}
+                            {strategy.Grid && (
+                                <div className="grid grid-cols-4 gap-4 text-sm mt-3">
+                                    <div>
+                                        <p className="text-muted-foreground">Grid Range</p>
+                                        <p className="font-medium">
+                                            ${strategy.Grid.Levels[0]?.toFixed(2)} - ${strategy.Grid.Levels[strategy.Grid.Levels.length - 1]?.toFixed(2)}
+                                        </p>
+                                    </div>
+                                    <div>
+                                        <p className="text-muted-foreground">Resting Orders</p>
+                                        <p className="font-medium">
+                                            {strategy.Grid.Stopped ? `Stopped: ${strategy.Grid.Stopped}` : strategy.Grid.Orders.filter((o) => o.OrderID).length}
+                                        </p>
+                                    </div>
+                                    <div>
+                                        <p className="text-muted-foreground">Round Trips</p>
+                                        <p className="font-medium">{strategy.Grid.RoundTrips}</p>
+                                    </div>
+                                    <div>
+                                        <p className="text-muted-foreground">Grid Profit</p>
+                                        <p className={`font-medium ${strategy.Grid.GridProfit >= 0 ? 'text-green-400' : 'text-red-400'}`}>
+                                            ${strategy.Grid.GridProfit.toFixed(2)}
+                                        </p>
+                                    </div>
+                                </div>
+                            )}
+                            <Separator className="my-3" />
+                            <StrategyPerformance strategy={strategy} />
+                            <Separator className="my-3" />
+                            <StrategyEventLog strategyId={strategy.ID} />
                         </CardContent>
                     </Card>
                 ))}
@@ -284,6 +359,8 @@ export function ActiveStrategiesTab() {
                     </CardContent>
                 </Card>
             )}
+
+            <SavedStrategies runningIds={strategies.map((s) => s.ID)} />
         </div>
     );
 }
@@ -0,0 +1,106 @@
+import { useEffect, useState } from "react";
+import { GetLogs } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { Card, CardContent } from "@/components/ui/card";
+import { Badge } from "@/components/ui/badge";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+import { RefreshCw } from "lucide-react";
+
+const levelVariant: Record<string, "default" | "secondary" | "destructive" | "outline"> = {
+    DEBUG: "outline",
+    INFO: "secondary",
+    WARN: "default",
+    ERROR: "destructive",
+};
+
+export function LogsTab() {
+    const [entries, setEntries] = useState<main.LogEntry[]>([]);
+    const [level, setLevel] = useState("info");
+    const [strategy, setStrategy] = useState("");
+    const [search, setSearch] = useState("");
+    const [error, setError] = useState<string | null>(null);
+
+    const load = async () => {
+        try {
+            const result = await GetLogs(main.LogFilter.createFrom({
+                Level: level,
+                Strategy: strategy,
+                Search: search,
+                Limit: 500,
+            }));
+            setEntries(result || []);
+            setError(null);
+        } catch (err) {
+            setError(String(err));
+        }
+    };
+
+    useEffect(() => {
+        load();
+    }, [level]);
+
+    return (
+        <div className="p-6 space-y-4">
+            <div className="flex items-center justify-between">
+                <div>
+                    <h2 className="text-2xl font-bold tracking-tight">Logs</h2>
+                    <p className="text-muted-foreground">Newest entries from the application log files</p>
+                </div>
+                <div className="flex gap-3">
+                    <Select value={level} onValueChange={setLevel}>
+                        <SelectTrigger className="w-[120px]"><SelectValue /></SelectTrigger>
+                        <SelectContent>
+                            <SelectItem value="debug">Debug</SelectItem>
+                            <SelectItem value="info">Info</SelectItem>
+                            <SelectItem value="warn">Warn</SelectItem>
+                            <SelectItem value="error">Error</SelectItem>
+                        </SelectContent>
+                    </Select>
+                    <Input
+                        className="w-[160px]"
+                        placeholder="Strategy id"
+                        value={strategy}
+                        onChange={(e) => setStrategy(e.target.value)}
+                        onKeyDown={(e) => e.key === 'Enter' && load()}
+                    />
+                    <Input
+                        className="w-[200px]"
+                        placeholder="Search"
+                        value={search}
+                        onChange={(e) => setSearch(e.target.value)}
+                        onKeyDown={(e) => e.key === 'Enter' && load()}
+                    />
+                    <Button variant="outline" size="sm" onClick={load}>
+                        <RefreshCw className="h-4 w-4" />
+                    </Button>
+                </div>
+            </div>
+
+            {error && <p className="text-sm text-red-500">{error}</p>}
+
+            <Card>
+                <CardContent className="p-0 max-h-[70vh] overflow-y-auto font-mono text-xs">
+                    {[...entries].reverse().map((entry, i) => (
+                        <div key={i} className="flex items-start gap-2 px-4 py-1 border-b border-border/50">
+                            <span className="text-muted-foreground whitespace-nowrap">
+                                {new Date(entry.Time).toLocaleString()}
+                            </span>
+                            <Badge variant={levelVariant[entry.Level] || "outline"} className="text-[10px]">
+                                {entry.Level}
+                            </Badge>
+                            <span className="flex-1">{entry.Message}</span>
+                            <span className="text-muted-foreground truncate max-w-[40%]">
+                                {Object.entries(entry.Fields || {}).map(([k, v]) => `${k}=${v}`).join(' ')}
+                            </span>
+                        </div>
+                    ))}
+                    {entries.length === 0 && !error && (
+                        <p className="text-muted-foreground text-center py-12">No log entries</p>
+                    )}
+                </CardContent>
+            </Card>
+        </div>
+    );
+}
@@ -16,11 +16,17 @@ import {
     TableRow,
 } from "@/components/ui/table";
 import { Button } from "@/components/ui/button";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
 import { usePortfolio } from "@/hooks/usePortfolio";
-import { Loader2, RefreshCw, AlertCircle } from "lucide-react";
+import { OrderTicket } from "@/components/OrderTicket";
+import { CancelAllOrders, CancelOrder, ClosePosition } from "@/../wailsjs/go/main/App";
+import { Loader2, RefreshCw, AlertCircle, X } from "lucide-react";
+import { useState } from "react";
 
 export function PortfolioTab() {
-    const { portfolio, address, loading, error, refresh } = usePortfolio();
+    const [accountId, setAccountId] = useState('all');
+    const { accounts, portfolio, address, loading, error, refresh } = usePortfolio(accountId);
+    const isAggregate = accountId === 'all';
     if (loading && !portfolio) {
         return (
             <div className="flex items-center justify-center h-full">
@@ -56,6 +62,16 @@ export function PortfolioTab() {
         0
     );
 
+    const runAndRefresh = async (action: () => Promise<unknown>) => {
+        try {
+            await action();
+        } catch (err) {
+            alert(String(err));
+        } finally {
+            refresh();
+        }
+    };
+
     return (
         <div className="p-6 space-y-6">
             <div className="flex justify-between items-center">
@@ -67,19 +83,34 @@ export function PortfolioTab() {
                             : "Account overview"}
                     </p>
                 </div>
-                <Button
-                    onClick={refresh}
-                    variant="outline"
-                    size="sm"
-                    disabled={loading}
-                >
-                    {loading ? (
-                        <Loader2 className="h-4 w-4 animate-spin" />
-                    ) : (
-                        <RefreshCw className="h-4 w-4" />
+                <div className="flex items-center gap-2">
+                    {accounts.length > 1 && (
+                        <Select value={accountId} onValueChange={setAccountId}>
+                            <SelectTrigger className="w-48"><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                <SelectItem value="all">All accounts</SelectItem>
+                                {accounts.map((account) => (
+                                    <SelectItem key={account.ID} value={account.ID}>
+                                        {account.Name}
+                                    </SelectItem>
+                                ))}
+                            </SelectContent>
+                        </Select>
                     )}
-                    <span className="ml-2">Refresh</span>
-                </Button>
+                    <Button
+                        onClick={refresh}
+                        variant="outline"
+                        size="sm"
+                        disabled={loading}
+                    >
+                        {loading ? (
+                            <Loader2 className="h-4 w-4 animate-spin" />
+                        ) : (
+                            <RefreshCw className="h-4 w-4" />
+                        )}
+                        <span className="ml-2">Refresh</span>
+                    </Button>
+                </div>
             </div>
 
             {/* Account Summary */}
@@ -186,6 +217,7 @@ export function PortfolioTab() {
                         <Table>
                             <TableHeader>
                                 <TableRow>
+                                    {isAggregate && <TableHead>Account</TableHead>}
                                     <TableHead>Symbol</TableHead>
                                     <TableHead>Side</TableHead>
                                     <TableHead className="text-right">Entry</TableHead>
@@ -194,6 +226,7 @@ export function PortfolioTab() {
                                     <TableHead className="text-right">Value</TableHead>
                                     <TableHead className="text-right">PnL</TableHead>
                                     <TableHead className="text-right">ROE</TableHead>
+                                    <TableHead />
                                 </TableRow>
                             </TableHeader>
                             <TableBody>
@@ -201,6 +234,7 @@ export function PortfolioTab() {
                                     const pnl = parseFloat(position.UnrealizedPnl);
                                     return (
                                         <TableRow key={idx}>
+                                            {isAggregate && <TableCell>{position.AccountID}</TableCell>}
                                             <TableCell className="font-medium">
                                                 {position.Coin}/USD
                                             </TableCell>
@@ -239,6 +273,15 @@ export function PortfolioTab() {
                                             >
                                                 {position.ReturnOnEquity}%
                                             </TableCell>
+                                            <TableCell className="text-right">
+                                                <Button
+                                                    variant="outline"
+                                                    size="sm"
+                                                    onClick={() => runAndRefresh(() => ClosePosition(position.AccountID, position.Coin, 0))}
+                                                >
+                                                    Close
+                                                </Button>
+                                            </TableCell>
                                         </TableRow>
                                     );
                                 })}
@@ -247,6 +290,72 @@ export function PortfolioTab() {
                     )}
                 </CardContent>
             </Card>
+
+            {/* Open Orders */}
+            <Card>
+                <CardHeader className="flex flex-row items-center justify-between">
+                    <div>
+                        <CardTitle>Open Orders</CardTitle>
+                        <CardDescription>Resting orders on the book</CardDescription>
+                    </div>
+                    <Button
+                        variant="outline"
+                        size="sm"
+                        disabled={portfolio.OpenOrders.length === 0}
+                        onClick={() => runAndRefresh(CancelAllOrders)}
+                    >
+                        Cancel All
+                    </Button>
+                </CardHeader>
+                <CardContent>
+                    {portfolio.OpenOrders.length === 0 ? (
+                        <div className="text-center py-8 text-muted-foreground">
+                            No open orders
+                        </div>
+                    ) : (
+                        <Table>
+                            <TableHeader>
+                                <TableRow>
+                                    {isAggregate && <TableHead>Account</TableHead>}
+                                    <TableHead>Symbol</TableHead>
+                                    <TableHead>Side</TableHead>
+                                    <TableHead className="text-right">Price</TableHead>
+                                    <TableHead className="text-right">Size</TableHead>
+                                    <TableHead className="text-right">Order ID</TableHead>
+                                    <TableHead />
+                                </TableRow>
+                            </TableHeader>
+                            <TableBody>
+                                {portfolio.OpenOrders.map((order) => (
+                                    <TableRow key={order.oid}>
+                                        {isAggregate && <TableCell>{portfolio.OrderAccounts[order.oid]}</TableCell>}
+                                        <TableCell className="font-medium">{order.coin}/USD</TableCell>
+                                        <TableCell>
+                                            <Badge variant={order.side === "B" ? "default" : "destructive"}>
+                                                {order.side === "B" ? "BUY" : "SELL"}
+                                            </Badge>
+                                        </TableCell>
+                                        <TableCell className="text-right">${order.limitPx}</TableCell>
+                                        <TableCell className="text-right">{order.sz}</TableCell>
+                                        <TableCell className="text-right">{order.oid}</TableCell>
+                                        <TableCell className="text-right">
+                                            <Button
+                                                variant="ghost"
+                                                size="sm"
+                                                onClick={() => runAndRefresh(() => CancelOrder(portfolio.OrderAccounts[order.oid], order.coin, order.oid))}
+                                            >
+                                                <X className="h-4 w-4" />
+                                            </Button>
+                                        </TableCell>
+                                    </TableRow>
+                                ))}
+                            </TableBody>
+                        </Table>
+                    )}
+                </CardContent>
+            </Card>
+
+            <OrderTicket accountId={isAggregate ? 'main' : accountId} onSubmitted={refresh} />
         </div>
     );
 }
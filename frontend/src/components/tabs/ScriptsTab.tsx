@@ -0,0 +1,293 @@
+import { useEffect, useState } from "react";
+import { BacktestDefinition, DeleteDefinition, GetDefinitions, SaveDefinition, ValidateDefinition } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Badge } from "@/components/ui/badge";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+import { Plus, Trash2 } from "lucide-react";
+
+const KINDS = [
+    { value: "pine", label: "Pine Script" },
+    { value: "rules", label: "Rules (YAML/JSON)" },
+    { value: "starlark", label: "Starlark" },
+    { value: "composite", label: "Composite" },
+];
+
+const TEMPLATES: Record<string, string> = {
+    pine: `//@version=5
+strategy("My Strategy", overlay = true)
+
+fastLength = input.int(9, "Fast Length", minval = 1)
+slowLength = input.int(21, "Slow Length", minval = 1)
+
+fast = ta.ema(close, fastLength)
+slow = ta.ema(close, slowLength)
+
+if ta.crossover(fast, slow)
+    strategy.entry("Long", strategy.long)
+if ta.crossunder(fast, slow)
+    strategy.entry("Short", strategy.short)
+
+plot(fast, "Fast", color = color.teal)
+`,
+    rules: `name: EMA Cross
+description: EMA crossover with an RSI filter
+parameters:
+  - {name: fastLength, label: Fast Length, default: 9, min: 2, max: 50}
+  - {name: slowLength, label: Slow Length, default: 21, min: 2, max: 200}
+indicators:
+  - {name: fast, type: ema, length: fastLength}
+  - {name: slow, type: ema, length: slowLength}
+  - {name: rsi, type: rsi, length: 14}
+entry:
+  long:
+    all:
+      - crossover: [fast, slow]
+      - below: [rsi, 70]
+  short:
+    all:
+      - crossunder: [fast, slow]
+      - above: [rsi, 30]
+exit:
+  long:
+    above: [rsi, 80]
+  short:
+    below: [rsi, 20]
+sizing: {positionSize: 0.01}
+risk: {takeProfitPercent: 3, stopLossPercent: 1.5, tradeDirection: both}
+plot: [fast]
+`,
+    starlark: `name = "RSI Reversal"
+description = "Trades RSI leaving the oversold and overbought zones"
+parameters = [
+    {"name": "length", "label": "RSI Length", "default": 14, "min": 2, "max": 100},
+    {"name": "oversold", "label": "Oversold", "default": 30},
+    {"name": "overbought", "label": "Overbought", "default": 70},
+]
+
+def generate(bars, params):
+    rsi = ta.rsi(bars.close, params["length"])
+    up = ta.crossover(rsi, params["oversold"])
+    down = ta.crossunder(rsi, params["overbought"])
+    for i in range(len(bars.close)):
+        if up[i]:
+            strategy.long(i, "RSI Oversold")
+        elif down[i]:
+            strategy.short(i, "RSI Overbought")
+    plot(ta.ema(bars.close, 50), "EMA 50")
+`,
+    composite: `name: Max Trend Confirmed
+description: Max Trend Points on the chart interval, confirmed on 4h
+# all, any, vote or weighted
+mode: all
+# weighted only: the weighted mean needed to hold a side
+# threshold: 0.5
+# keep positions until the other side wins instead of closing on disagreement
+# hold: true
+strategies:
+  - strategy: max-trend
+    params: {factor: 2.5}
+  - strategy: max-trend
+    timeframe: 4h
+    weight: 1
+`,
+};
+
+export function ScriptsTab() {
+    const [definitions, setDefinitions] = useState<main.StrategyDefinition[]>([]);
+    const [selectedId, setSelectedId] = useState<string | null>(null);
+    const [kind, setKind] = useState("pine");
+    const [source, setSource] = useState(TEMPLATES.pine);
+    const [info, setInfo] = useState<main.StrategyInfo | null>(null);
+    const [error, setError] = useState<string | null>(null);
+    const [symbol, setSymbol] = useState("BTC");
+    const [timeframe, setTimeframe] = useState("1h");
+    const [backtest, setBacktest] = useState<main.BacktestOutput | null>(null);
+    const [testing, setTesting] = useState(false);
+
+    const load = async () => {
+        try {
+            setDefinitions((await GetDefinitions()) || []);
+        } catch (err) {
+            setError(String(err));
+        }
+    };
+
+    useEffect(() => {
+        load();
+    }, []);
+
+    const select = (def: main.StrategyDefinition) => {
+        setSelectedId(def.ID);
+        setKind(def.Kind);
+        setSource(def.Source);
+        setInfo(null);
+        setBacktest(null);
+        setError(null);
+    };
+
+    const startNew = () => {
+        setSelectedId(null);
+        setSource(TEMPLATES[kind] || "");
+        setInfo(null);
+        setBacktest(null);
+        setError(null);
+    };
+
+    const changeKind = (value: string) => {
+        setKind(value);
+        setSource(TEMPLATES[value] || "");
+        setInfo(null);
+        setBacktest(null);
+        setError(null);
+    };
+
+    const validate = async () => {
+        try {
+            setInfo(await ValidateDefinition(kind, source));
+            setError(null);
+        } catch (err) {
+            setInfo(null);
+            setError(String(err));
+        }
+    };
+
+    const runBacktest = async () => {
+        setTesting(true);
+        try {
+            setBacktest(await BacktestDefinition(kind, source, symbol, timeframe, 1000, {}));
+            setError(null);
+        } catch (err) {
+            setBacktest(null);
+            setError(String(err));
+        } finally {
+            setTesting(false);
+        }
+    };
+
+    const save = async () => {
+        try {
+            const saved = await SaveDefinition(kind, source);
+            setInfo(saved);
+            setSelectedId(saved.ID);
+            setError(null);
+            await load();
+        } catch (err) {
+            setError(String(err));
+        }
+    };
+
+    const remove = async () => {
+        if (!selectedId || !confirm(`Delete ${selectedId}?`)) return;
+        try {
+            await DeleteDefinition(selectedId);
+            startNew();
+            await load();
+        } catch (err) {
+            setError(String(err));
+        }
+    };
+
+    return (
+        <div className="p-6 h-full flex gap-4">
+            <Card className="w-72 flex-shrink-0">
+                <CardHeader className="flex flex-row items-center justify-between space-y-0">
+                    <div>
+                        <CardTitle className="text-lg">Scripts</CardTitle>
+                        <CardDescription>User-defined strategies</CardDescription>
+                    </div>
+                    <Button variant="outline" size="sm" onClick={startNew}>
+                        <Plus className="h-4 w-4" />
+                    </Button>
+                </CardHeader>
+                <CardContent className="space-y-1">
+                    {definitions.length === 0 && (
+                        <p className="text-sm text-muted-foreground">No saved scripts</p>
+                    )}
+                    {definitions.map((def) => (
+                        <button
+                            key={def.ID}
+                            onClick={() => select(def)}
+                            className={`w-full text-left px-3 py-2 rounded text-sm hover:bg-muted ${selectedId === def.ID ? "bg-muted" : ""}`}
+                        >
+                            <div className="font-medium truncate">{def.Name}</div>
+                            <div className="text-xs text-muted-foreground">{def.Kind}</div>
+                        </button>
+                    ))}
+                </CardContent>
+            </Card>
+
+            <Card className="flex-1 flex flex-col min-w-0">
+                <CardHeader className="flex flex-row items-center justify-between space-y-0">
+                    <div>
+                        <CardTitle className="text-lg">{selectedId || "New script"}</CardTitle>
+                        <CardDescription>
+                            Saved scripts appear in the strategy list of the Visualization tab
+                        </CardDescription>
+                    </div>
+                    <div className="flex gap-2">
+                        <Select value={kind} onValueChange={changeKind} disabled={selectedId !== null}>
+                            <SelectTrigger className="w-[140px]"><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                {KINDS.map((k) => (
+                                    <SelectItem key={k.value} value={k.value}>{k.label}</SelectItem>
+                                ))}
+                            </SelectContent>
+                        </Select>
+                        <Input
+                            value={symbol}
+                            onChange={(e) => setSymbol(e.target.value.toUpperCase())}
+                            className="w-[90px]"
+                        />
+                        <Input
+                            value={timeframe}
+                            onChange={(e) => setTimeframe(e.target.value)}
+                            className="w-[70px]"
+                        />
+                        <Button variant="outline" onClick={runBacktest} disabled={testing}>
+                            {testing ? "Testing..." : "Backtest"}
+                        </Button>
+                        <Button variant="outline" onClick={validate}>Validate</Button>
+                        <Button onClick={save}>Save</Button>
+                        {selectedId && (
+                            <Button variant="destructive" onClick={remove}>
+                                <Trash2 className="h-4 w-4" />
+                            </Button>
+                        )}
+                    </div>
+                </CardHeader>
+                <CardContent className="flex-1 flex flex-col gap-3 min-h-0">
+                    <textarea
+                        value={source}
+                        onChange={(e) => setSource(e.target.value)}
+                        spellCheck={false}
+                        className="flex-1 min-h-[400px] w-full rounded border bg-background p-3 font-mono text-sm"
+                    />
+                    {error && <p className="text-sm text-destructive whitespace-pre-wrap">{error}</p>}
+                    {info && (
+                        <div className="flex flex-wrap items-center gap-2 text-sm">
+                            <span className="font-medium">{info.Name}</span>
+                            <Badge variant="secondary">{info.Description}</Badge>
+                            {(info.Parameters || []).map((p) => (
+                                <Badge key={p.Name} variant="outline">
+                                    {p.Label}: {String(p.Default ?? "")}
+                                </Badge>
+                            ))}
+                        </div>
+                    )}
+                    {backtest && (
+                        <div className="flex flex-wrap items-center gap-4 text-sm">
+                            <span>Trades: {backtest.TotalTrades}</span>
+                            <span>Win rate: {backtest.WinRate.toFixed(1)}%</span>
+                            <span className={backtest.TotalPnL >= 0 ? "text-green-500" : "text-red-500"}>
+                                PnL: {backtest.TotalPnL.toFixed(2)} ({backtest.TotalPnLPercent.toFixed(2)}%)
+                            </span>
+                        </div>
+                    )}
+                </CardContent>
+            </Card>
+        </div>
+    );
+}
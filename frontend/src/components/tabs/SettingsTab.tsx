@@ -0,0 +1,240 @@
+import { useEffect, useState } from "react";
+import { GetConfig, UpdateConfig } from "@/../wailsjs/go/main/App";
+import { main } from "@/../wailsjs/go/models";
+import { Card, CardContent, CardDescription, CardHeader, CardTitle } from "@/components/ui/card";
+import { Label } from "@/components/ui/label";
+import { Input } from "@/components/ui/input";
+import { Button } from "@/components/ui/button";
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+import { TIMEFRAMES } from "@/config/trading";
+
+export function SettingsTab() {
+    const [settings, setSettings] = useState<main.Settings | null>(null);
+    const [saving, setSaving] = useState(false);
+    const [result, setResult] = useState<{ ok: boolean; message: string } | null>(null);
+
+    const load = async () => {
+        try {
+            setSettings(await GetConfig());
+        } catch (err) {
+            console.error('Config fetch error:', err);
+        }
+    };
+
+    useEffect(() => {
+        load();
+    }, []);
+
+    if (!settings) {
+        return null;
+    }
+
+    const update = (patch: Partial<main.Settings>) => {
+        setSettings(main.Settings.createFrom({ ...settings, ...patch }));
+    };
+
+    const updateRisk = (key: keyof main.RiskLimits, value: string) => {
+        update({ Risk: main.RiskLimits.createFrom({ ...settings.Risk, [key]: parseFloat(value) || 0 }) });
+    };
+
+    const save = async () => {
+        if (settings.Network === 'mainnet' && !confirm("Save settings with MAINNET as the network?")) {
+            return;
+        }
+        setSaving(true);
+        setResult(null);
+        try {
+            await UpdateConfig(settings);
+            setResult({ ok: true, message: 'Settings saved. Redis, database and keystore changes apply after restart.' });
+            load();
+        } catch (err) {
+            setResult({ ok: false, message: String(err) });
+        } finally {
+            setSaving(false);
+        }
+    };
+
+    return (
+        <div className="p-6 space-y-6 max-w-3xl">
+            <div>
+                <h2 className="text-2xl font-bold tracking-tight">Settings</h2>
+                <p className="text-muted-foreground">
+                    Fields you change are saved as user preferences on top of the config file. Environment variables and flags still win at startup.
+                </p>
+            </div>
+
+            <Card>
+                <CardHeader>
+                    <CardTitle>Connection</CardTitle>
+                    <CardDescription>Network and storage</CardDescription>
+                </CardHeader>
+                <CardContent className="grid grid-cols-2 gap-4">
+                    <div className="space-y-2">
+                        <Label>Network</Label>
+                        <Select value={settings.Network} onValueChange={(v) => update({ Network: v })}>
+                            <SelectTrigger><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                <SelectItem value="mainnet">Mainnet</SelectItem>
+                                <SelectItem value="testnet">Testnet</SelectItem>
+                                <SelectItem value="local">Local</SelectItem>
+                            </SelectContent>
+                        </Select>
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="settings-redis">Redis</Label>
+                        <Input id="settings-redis" value={settings.RedisURL} onChange={(e) => update({ RedisURL: e.target.value })} />
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="settings-db">Database Path</Label>
+                        <Input id="settings-db" value={settings.DatabasePath} onChange={(e) => update({ DatabasePath: e.target.value })} />
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="settings-keystore">Keystore Path</Label>
+                        <Input id="settings-keystore" value={settings.KeystorePath} onChange={(e) => update({ KeystorePath: e.target.value })} />
+                    </div>
+                </CardContent>
+            </Card>
+
+            <Card>
+                <CardHeader>
+                    <CardTitle>Defaults</CardTitle>
+                    <CardDescription>Initial symbol and interval</CardDescription>
+                </CardHeader>
+                <CardContent className="grid grid-cols-2 gap-4">
+                    <div className="space-y-2">
+                        <Label htmlFor="settings-symbol">Symbol</Label>
+                        <Input
+                            id="settings-symbol"
+                            value={settings.Defaults.Symbol}
+                            onChange={(e) => update({ Defaults: { ...settings.Defaults, Symbol: e.target.value.toUpperCase() } })}
+                        />
+                    </div>
+                    <div className="space-y-2">
+                        <Label>Interval</Label>
+                        <Select
+                            value={settings.Defaults.Interval}
+                            onValueChange={(v) => update({ Defaults: { ...settings.Defaults, Interval: v } })}
+                        >
+                            <SelectTrigger><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                {TIMEFRAMES.map((tf) => (
+                                    <SelectItem key={tf.value} value={tf.value}>{tf.label}</SelectItem>
+                                ))}
+                            </SelectContent>
+                        </Select>
+                    </div>
+                </CardContent>
+            </Card>
+
+            <Card>
+                <CardHeader>
+                    <CardTitle>Risk Limits</CardTitle>
+                    <CardDescription>Zero disables a limit</CardDescription>
+                </CardHeader>
+                <CardContent className="grid grid-cols-3 gap-4">
+                    {([
+                        ['MaxSymbolNotional', 'Max Symbol Notional'],
+                        ['MaxTotalNotional', 'Max Total Notional'],
+                        ['MaxDailyLoss', 'Max Daily Loss'],
+                        ['MaxConsecutiveLosses', 'Max Consecutive Losses'],
+                        ['MaxOrdersPerMinute', 'Max Orders / Minute'],
+                    ] as [keyof main.RiskLimits, string][]).map(([key, label]) => (
+                        <div key={key} className="space-y-2">
+                            <Label htmlFor={`settings-${key}`}>{label}</Label>
+                            <Input
+                                id={`settings-${key}`}
+                                type="number"
+                                value={settings.Risk[key] as number}
+                                onChange={(e) => updateRisk(key, e.target.value)}
+                            />
+                        </div>
+                    ))}
+                </CardContent>
+            </Card>
+
+            <Card>
+                <CardHeader>
+                    <CardTitle>Logging</CardTitle>
+                </CardHeader>
+                <CardContent className="grid grid-cols-2 gap-4">
+                    <div className="space-y-2">
+                        <Label>Level</Label>
+                        <Select
+                            value={settings.Logging.Level}
+                            onValueChange={(v) => update({ Logging: { ...settings.Logging, Level: v } })}
+                        >
+                            <SelectTrigger><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                <SelectItem value="debug">Debug</SelectItem>
+                                <SelectItem value="info">Info</SelectItem>
+                                <SelectItem value="warn">Warn</SelectItem>
+                                <SelectItem value="error">Error</SelectItem>
+                            </SelectContent>
+                        </Select>
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="settings-logfile">Log File</Label>
+                        <Input
+                            id="settings-logfile"
+                            placeholder="App data directory"
+                            value={settings.Logging.File}
+                            onChange={(e) => update({ Logging: { ...settings.Logging, File: e.target.value } })}
+                        />
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="settings-logsize">Max File Size (MB)</Label>
+                        <Input
+                            id="settings-logsize"
+                            type="number"
+                            value={settings.Logging.MaxSizeMB}
+                            onChange={(e) => update({ Logging: { ...settings.Logging, MaxSizeMB: parseInt(e.target.value) || 0 } })}
+                        />
+                    </div>
+                    <div className="space-y-2">
+                        <Label htmlFor="settings-logfiles">Files Kept</Label>
+                        <Input
+                            id="settings-logfiles"
+                            type="number"
+                            value={settings.Logging.MaxFiles}
+                            onChange={(e) => update({ Logging: { ...settings.Logging, MaxFiles: parseInt(e.target.value) || 0 } })}
+                        />
+                    </div>
+                </CardContent>
+            </Card>
+
+            <Card>
+                <CardHeader>
+                    <CardTitle>Strategies</CardTitle>
+                    <CardDescription>What happens to live strategies when the app quits</CardDescription>
+                </CardHeader>
+                <CardContent className="grid grid-cols-2 gap-4">
+                    <div className="space-y-2">
+                        <Label>Shutdown Mode</Label>
+                        <Select value={settings.ShutdownMode} onValueChange={(v) => update({ ShutdownMode: v })}>
+                            <SelectTrigger><SelectValue /></SelectTrigger>
+                            <SelectContent>
+                                <SelectItem value="flatten">Close positions</SelectItem>
+                                <SelectItem value="detach">Keep positions open</SelectItem>
+                            </SelectContent>
+                        </Select>
+                    </div>
+                    <label className="flex items-center gap-2 text-sm pt-6">
+                        <input
+                            type="checkbox"
+                            checked={settings.ResumeStrategies}
+                            onChange={(e) => update({ ResumeStrategies: e.target.checked })}
+                        />
+                        Resume saved strategies on start
+                    </label>
+                </CardContent>
+            </Card>
+
+            <div className="flex items-center gap-4">
+                <Button onClick={save} disabled={saving}>Save Settings</Button>
+                {result && (
+                    <p className={`text-sm ${result.ok ? "text-green-500" : "text-red-500"}`}>{result.message}</p>
+                )}
+            </div>
+        </div>
+    );
+}
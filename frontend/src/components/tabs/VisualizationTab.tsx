@@ -1,4 +1,4 @@
-import { useEffect } from "react";
+import { useEffect, useState } from "react";
 import { TradingChart } from "@/components/TradingChart";
 import { Label } from "@/components/ui/label";
 import {
@@ -27,17 +27,21 @@ import {
     TableHeader,
     TableRow,
 } from "@/components/ui/table";
-import { STRATEGIES, StrategyParameter } from "@/types/strategy";
+import { STRATEGIES, Strategy, StrategyParameter, fromStrategyInfo } from "@/types/strategy";
 import { main } from "@/../wailsjs/go/models";
 import { TradingStrategyManager } from "@/lib/TradingStrategyManager";
 import { useChartStore } from "@/store/chartStore";
 import { useVisualizationStore } from "@/store/visualizationStore";
 import { TIMEFRAMES, SYMBOLS } from "@/config/trading";
+import { GetAccounts, ListStrategies } from "@/../wailsjs/go/main/App";
 
 const strategyManager = TradingStrategyManager.getInstance();
 
 export function VisualizationTab() {
     const { chartData, updateStrategyOutput } = useChartStore();
+    const [accounts, setAccounts] = useState<main.AccountInfo[]>([]);
+    const [accountId, setAccountId] = useState("main");
+    const [strategies, setStrategies] = useState<Strategy[]>(STRATEGIES);
     const {
         symbol,
         timeframe,
@@ -46,6 +50,8 @@ export function VisualizationTab() {
         takeProfitPercent,
         stopLossPercent,
         tradeDirection,
+        scaling,
+        stops,
         strategyApplied,
         cachedStrategyOutput,
         cacheKey,
@@ -57,6 +63,8 @@ export function VisualizationTab() {
         setTakeProfitPercent,
         setStopLossPercent,
         setTradeDirection,
+        setScaling,
+        setStops,
         setStrategyApplied,
         setCachedStrategyOutput,
         setShowEntryPrices,
@@ -75,9 +83,18 @@ export function VisualizationTab() {
             tp: takeProfitPercent,
             sl: stopLossPercent,
             dir: tradeDirection,
+            scaling,
+            stops,
         });
     };
 
+    useEffect(() => {
+        GetAccounts().then(setAccounts).catch((err) => console.error("Accounts fetch error:", err));
+        ListStrategies()
+            .then((infos) => setStrategies(infos.map(fromStrategyInfo)))
+            .catch((err) => console.error("Strategies fetch error:", err));
+    }, []);
+
     // Restore cached strategy output when tab mounts
     useEffect(() => {
         strategyManager.loadData(symbol, timeframe, LIMIT, INITIAL_VIEWPORT);
@@ -92,7 +109,7 @@ export function VisualizationTab() {
         TIMEFRAMES.find((tf) => tf.value === timeframe) || TIMEFRAMES[3];
 
     const handleStrategyChange = (strategyId: string) => {
-        const strategy = STRATEGIES.find((s) => s.id === strategyId);
+        const strategy = strategies.find((s) => s.id === strategyId);
         if (strategy) {
             setSelectedStrategy(strategy);
         }
@@ -105,6 +122,8 @@ export function VisualizationTab() {
                 takeProfitPercent,
                 stopLossPercent,
                 tradeDirection,
+                ...scaling,
+                ...stops,
             };
 
             await strategyManager.applyStrategy(
@@ -140,10 +159,14 @@ export function VisualizationTab() {
                 takeProfitPercent,
                 stopLossPercent,
                 tradeDirection,
+                ...scaling,
+                ...stops,
             };
 
             await strategyManager.startLiveStrategy(
+                accountId,
                 strategyId,
+                selectedStrategy.id,
                 symbol,
                 timeframe,
                 params
@@ -346,7 +369,7 @@ export function VisualizationTab() {
                                         <SelectValue />
                                     </SelectTrigger>
                                     <SelectContent>
-                                        {STRATEGIES.map((strategy) => (
+                                        {strategies.map((strategy) => (
                                             <SelectItem
                                                 key={strategy.id}
                                                 value={strategy.id}
@@ -448,8 +471,245 @@ export function VisualizationTab() {
                                         percentage
                                     </p>
                                 </div>
+
+                                <div className="space-y-2">
+                                    <Label htmlFor="scaleMode">
+                                        Position Scaling
+                                    </Label>
+                                    <Select
+                                        value={scaling.scaleMode}
+                                        onValueChange={(value) =>
+                                            setScaling({
+                                                scaleMode: value as
+                                                    | "off"
+                                                    | "dca"
+                                                    | "pyramid",
+                                            })
+                                        }
+                                    >
+                                        <SelectTrigger id="scaleMode">
+                                            <SelectValue />
+                                        </SelectTrigger>
+                                        <SelectContent>
+                                            <SelectItem value="off">
+                                                Off
+                                            </SelectItem>
+                                            <SelectItem value="dca">
+                                                DCA (safety orders)
+                                            </SelectItem>
+                                            <SelectItem value="pyramid">
+                                                Pyramid (repeated signals)
+                                            </SelectItem>
+                                        </SelectContent>
+                                    </Select>
+                                    <p className="text-xs text-muted-foreground">
+                                        Add to open positions; take profit is
+                                        then measured from the average entry
+                                    </p>
+                                </div>
+
+                                <div className="space-y-2">
+                                    <Label htmlFor="trailingStop">
+                                        Trailing Stop
+                                    </Label>
+                                    <Select
+                                        value={stops.trailingStop}
+                                        onValueChange={(value) =>
+                                            setStops({
+                                                trailingStop: value as
+                                                    | "off"
+                                                    | "percent"
+                                                    | "atr"
+                                                    | "trend",
+                                            })
+                                        }
+                                    >
+                                        <SelectTrigger id="trailingStop">
+                                            <SelectValue />
+                                        </SelectTrigger>
+                                        <SelectContent>
+                                            <SelectItem value="off">
+                                                Off
+                                            </SelectItem>
+                                            <SelectItem value="percent">
+                                                Percent
+                                            </SelectItem>
+                                            <SelectItem value="atr">
+                                                ATR Multiple
+                                            </SelectItem>
+                                            <SelectItem value="trend">
+                                                Trend Line
+                                            </SelectItem>
+                                        </SelectContent>
+                                    </Select>
+                                    <p className="text-xs text-muted-foreground">
+                                        With a trailing or break-even stop, the
+                                        stop loss starts at the entry and follows
+                                        price
+                                    </p>
+                                </div>
+
+                                <div className="grid grid-cols-3 gap-2">
+                                    {stops.trailingStop === "percent" && (
+                                        <div className="space-y-2">
+                                            <Label htmlFor="trailPercent">
+                                                Trail (%)
+                                            </Label>
+                                            <Input
+                                                id="trailPercent"
+                                                type="number"
+                                                step="0.1"
+                                                min="0.1"
+                                                max="50"
+                                                value={stops.trailPercent}
+                                                onChange={(e) =>
+                                                    setStops({
+                                                        trailPercent: parseFloat(e.target.value),
+                                                    })
+                                                }
+                                            />
+                                        </div>
+                                    )}
+                                    {stops.trailingStop === "atr" && (
+                                        <>
+                                            <div className="space-y-2">
+                                                <Label htmlFor="atrLength">
+                                                    ATR Length
+                                                </Label>
+                                                <Input
+                                                    id="atrLength"
+                                                    type="number"
+                                                    step="1"
+                                                    min="1"
+                                                    max="200"
+                                                    value={stops.atrLength}
+                                                    onChange={(e) =>
+                                                        setStops({
+                                                            atrLength: parseFloat(e.target.value),
+                                                        })
+                                                    }
+                                                />
+                                            </div>
+                                            <div className="space-y-2">
+                                                <Label htmlFor="atrMultiplier">
+                                                    ATR Multiple
+                                                </Label>
+                                                <Input
+                                                    id="atrMultiplier"
+                                                    type="number"
+                                                    step="0.1"
+                                                    min="0.1"
+                                                    max="20"
+                                                    value={stops.atrMultiplier}
+                                                    onChange={(e) =>
+                                                        setStops({
+                                                            atrMultiplier: parseFloat(e.target.value),
+                                                        })
+                                                    }
+                                                />
+                                            </div>
+                                        </>
+                                    )}
+                                    <div className="space-y-2">
+                                        <Label htmlFor="breakEven">
+                                            Break Even (%)
+                                        </Label>
+                                        <Input
+                                            id="breakEven"
+                                            type="number"
+                                            step="0.1"
+                                            min="0"
+                                            max="100"
+                                            value={stops.breakEvenPercent}
+                                            onChange={(e) =>
+                                                setStops({
+                                                    breakEvenPercent: parseFloat(e.target.value),
+                                                })
+                                            }
+                                        />
+                                    </div>
+                                </div>
+
+                                {scaling.scaleMode !== "off" && (
+                                    <div className="grid grid-cols-3 gap-2">
+                                        <div className="space-y-2">
+                                            <Label htmlFor="scaleOrders">
+                                                Max Adds
+                                            </Label>
+                                            <Input
+                                                id="scaleOrders"
+                                                type="number"
+                                                step="1"
+                                                min="1"
+                                                max="20"
+                                                value={scaling.scaleOrders}
+                                                onChange={(e) =>
+                                                    setScaling({
+                                                        scaleOrders: parseFloat(e.target.value),
+                                                    })
+                                                }
+                                            />
+                                        </div>
+                                        {scaling.scaleMode === "dca" && (
+                                            <div className="space-y-2">
+                                                <Label htmlFor="scaleStep">
+                                                    Step (%)
+                                                </Label>
+                                                <Input
+                                                    id="scaleStep"
+                                                    type="number"
+                                                    step="0.1"
+                                                    min="0.1"
+                                                    max="50"
+                                                    value={scaling.scaleStepPercent}
+                                                    onChange={(e) =>
+                                                        setScaling({
+                                                            scaleStepPercent: parseFloat(e.target.value),
+                                                        })
+                                                    }
+                                                />
+                                            </div>
+                                        )}
+                                        <div className="space-y-2">
+                                            <Label htmlFor="scaleMultiplier">
+                                                Size Multiplier
+                                            </Label>
+                                            <Input
+                                                id="scaleMultiplier"
+                                                type="number"
+                                                step="0.1"
+                                                min="0.1"
+                                                max="5"
+                                                value={scaling.scaleSizeMultiplier}
+                                                onChange={(e) =>
+                                                    setScaling({
+                                                        scaleSizeMultiplier: parseFloat(e.target.value),
+                                                    })
+                                                }
+                                            />
+                                        </div>
+                                    </div>
+                                )}
                             </div>
 
+                            {accounts.length > 1 && (
+                                <div className="space-y-2">
+                                    <Label htmlFor="account">Account</Label>
+                                    <Select value={accountId} onValueChange={setAccountId}>
+                                        <SelectTrigger id="account">
+                                            <SelectValue />
+                                        </SelectTrigger>
+                                        <SelectContent>
+                                            {accounts.map((account) => (
+                                                <SelectItem key={account.ID} value={account.ID}>
+                                                    {account.Name}
+                                                </SelectItem>
+                                            ))}
+                                        </SelectContent>
+                                    </Select>
+                                </div>
+                            )}
+
                             <div className="flex gap-2">
                                 <Button
                                     className="flex-1"
@@ -517,6 +777,26 @@ export function VisualizationTab() {
                                         <span className="text-muted-foreground">Stop Loss:</span>
                                         <span className="font-medium">{stopLossPercent}%</span>
                                     </div>
+                                    {stops.trailingStop !== "off" && (
+                                        <div className="flex justify-between">
+                                            <span className="text-muted-foreground">Trailing Stop:</span>
+                                            <span className="font-medium capitalize">{stops.trailingStop}</span>
+                                        </div>
+                                    )}
+                                    {stops.breakEvenPercent > 0 && (
+                                        <div className="flex justify-between">
+                                            <span className="text-muted-foreground">Break Even:</span>
+                                            <span className="font-medium">{stops.breakEvenPercent}%</span>
+                                        </div>
+                                    )}
+                                    {scaling.scaleMode !== "off" && (
+                                        <div className="flex justify-between">
+                                            <span className="text-muted-foreground">Scaling:</span>
+                                            <span className="font-medium uppercase">
+                                                {scaling.scaleMode} x{scaling.scaleOrders}
+                                            </span>
+                                        </div>
+                                    )}
                                 </div>
                             </div>
 
@@ -752,12 +1032,27 @@ export function VisualizationTab() {
                                                                 {position.EntryPrice.toFixed(
                                                                     2
                                                                 )}
+                                                                {position.Entries > 1 && (
+                                                                    <span className="text-xs text-muted-foreground">
+                                                                        {" "}avg of {position.Entries}
+                                                                    </span>
+                                                                )}
+                                                                {position.Legs?.slice(1).map((leg) => (
+                                                                    <div key={leg.Symbol} className="text-xs text-muted-foreground">
+                                                                        {leg.Side} {leg.Size.toFixed(4)} {leg.Symbol} @ ${leg.EntryPrice.toFixed(2)}
+                                                                    </div>
+                                                                ))}
                                                             </TableCell>
                                                             <TableCell className="font-mono">
                                                                 $
                                                                 {position.ExitPrice.toFixed(
                                                                     2
                                                                 )}
+                                                                {position.Legs?.slice(1).map((leg) => (
+                                                                    <div key={leg.Symbol} className="text-xs text-muted-foreground">
+                                                                        {leg.Symbol} @ ${leg.ExitPrice.toFixed(2)}
+                                                                    </div>
+                                                                ))}
                                                             </TableCell>
                                                             <TableCell className="font-mono">
                                                                 {position.Size.toFixed(
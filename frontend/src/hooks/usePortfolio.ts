@@ -1,8 +1,9 @@
 import { useState, useEffect } from 'react';
-import { GetPortfolioSummary, GetWalletAddress } from '@/../wailsjs/go/main/App';
+import { GetAccountPortfolioSummary, GetAccounts, GetPortfolioSummary, GetWalletAddress } from '@/../wailsjs/go/main/App';
 import { hyperliquid, main } from "@/../wailsjs/go/models";
 
-export function usePortfolio() {
+export function usePortfolio(accountId: string = 'all') {
+    const [accounts, setAccounts] = useState<main.AccountInfo[]>([]);
     const [portfolio, setPortfolio] = useState<main.PortfolioSummary | null>(null);
     const [isConnected, setIsConnected] = useState(false);
     const [address, setAddress] = useState<string>('');
@@ -22,7 +23,11 @@ export function usePortfolio() {
             const addr = await GetWalletAddress();
             setAddress(addr);
 
-            const data = await GetPortfolioSummary();
+            setAccounts(await GetAccounts());
+
+            const data = accountId === 'all'
+                ? await GetPortfolioSummary()
+                : await GetAccountPortfolioSummary(accountId);
             setPortfolio(data);
         } catch (err) {
             setError(err instanceof Error ? err.message : 'Failed to fetch portfolio');
@@ -37,9 +42,10 @@ export function usePortfolio() {
         // Refresh every 10 seconds
         const interval = setInterval(fetchPortfolio, 10000);
         return () => clearInterval(interval);
-    }, []);
+    }, [accountId]);
 
     return {
+        accounts,
         portfolio,
         isConnected,
         address,
@@ -1,4 +1,4 @@
-import { FetchCandles, StrategyBacktest, StrategyRun, StopLiveStrategy, GetRunningStrategies } from '@/../wailsjs/go/main/App';
+import { FetchCandles, StrategyBacktest, StrategyRun, StopLiveStrategy, PauseLiveStrategy, ResumeLiveStrategy, GetRunningStrategies } from '@/../wailsjs/go/main/App';
 import { main } from '@/../wailsjs/go/models';
 import { useChartStore } from '@/store/chartStore';
 
@@ -106,6 +106,7 @@ export class TradingStrategyManager {
             setLoading(true);
 
             const fullStrategyOutput = await StrategyBacktest(
+                strategyId,
                 symbol,
                 interval,
                 limit,
@@ -152,18 +153,28 @@ export class TradingStrategyManager {
     }
 
     async startLiveStrategy(
+        accountId: string,
         name: string,
+        kind: string,
         symbol: string,
         interval: string,
         params: Record<string, any>
     ): Promise<void> {
-        return StrategyRun(name, symbol, interval, params);
+        return StrategyRun(accountId, name, kind, symbol, interval, params);
     }
 
     async stopLiveStrategy(id: string): Promise<void> {
         return StopLiveStrategy(id);
     }
 
+    async pauseLiveStrategy(id: string): Promise<void> {
+        return PauseLiveStrategy(id);
+    }
+
+    async resumeLiveStrategy(id: string): Promise<void> {
+        return ResumeLiveStrategy(id);
+    }
+
     async getRunningStrategies(): Promise<any[]> {
         return GetRunningStrategies();
     }
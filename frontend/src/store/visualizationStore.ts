@@ -2,6 +2,23 @@ import { create } from 'zustand';
 import { Strategy, STRATEGIES } from '@/types/strategy';
 import { main } from '../../wailsjs/go/models';
 
+// ScalingSettings are the scale-in parameters shared by every strategy.
+export interface ScalingSettings {
+    scaleMode: 'off' | 'dca' | 'pyramid';
+    scaleOrders: number;
+    scaleStepPercent: number;
+    scaleSizeMultiplier: number;
+}
+
+// StopSettings manage the protective stop of every strategy's positions.
+export interface StopSettings {
+    trailingStop: 'off' | 'percent' | 'atr' | 'trend';
+    trailPercent: number;
+    atrLength: number;
+    atrMultiplier: number;
+    breakEvenPercent: number;
+}
+
 interface VisualizationState {
     symbol: string;
     timeframe: string;
@@ -10,6 +27,8 @@ interface VisualizationState {
     takeProfitPercent: number;
     stopLossPercent: number;
     tradeDirection: 'both' | 'long' | 'short';
+    scaling: ScalingSettings;
+    stops: StopSettings;
     strategyApplied: boolean;
     cachedStrategyOutput: main.BacktestOutput | null;
     cacheKey: string;
@@ -22,6 +41,8 @@ interface VisualizationState {
     setTakeProfitPercent: (percent: number) => void;
     setStopLossPercent: (percent: number) => void;
     setTradeDirection: (direction: 'both' | 'long' | 'short') => void;
+    setScaling: (scaling: Partial<ScalingSettings>) => void;
+    setStops: (stops: Partial<StopSettings>) => void;
     setStrategyApplied: (applied: boolean) => void;
     setCachedStrategyOutput: (output: main.BacktestOutput | null, key: string) => void;
     clearCache: () => void;
@@ -42,6 +63,19 @@ export const useVisualizationStore = create<VisualizationState>((set) => ({
     takeProfitPercent: 2.0,
     stopLossPercent: 2.0,
     tradeDirection: 'long',
+    scaling: {
+        scaleMode: 'off',
+        scaleOrders: 3,
+        scaleStepPercent: 2,
+        scaleSizeMultiplier: 1,
+    },
+    stops: {
+        trailingStop: 'off',
+        trailPercent: 1.5,
+        atrLength: 14,
+        atrMultiplier: 3,
+        breakEvenPercent: 0,
+    },
     strategyApplied: false,
     cachedStrategyOutput: null,
     cacheKey: '',
@@ -66,6 +100,8 @@ export const useVisualizationStore = create<VisualizationState>((set) => ({
     setTakeProfitPercent: (percent) => set({ takeProfitPercent: percent, cacheKey: '' }),
     setStopLossPercent: (percent) => set({ stopLossPercent: percent, cacheKey: '' }),
     setTradeDirection: (direction) => set({ tradeDirection: direction, cacheKey: '' }),
+    setScaling: (scaling) => set((state) => ({ scaling: { ...state.scaling, ...scaling }, cacheKey: '' })),
+    setStops: (stops) => set((state) => ({ stops: { ...state.stops, ...stops }, cacheKey: '' })),
     setStrategyApplied: (applied) => set({ strategyApplied: applied }),
     setCachedStrategyOutput: (output, key) => set({
         cachedStrategyOutput: output,
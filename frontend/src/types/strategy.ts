@@ -1,3 +1,5 @@
+import { main } from '../../wailsjs/go/models';
+
 export interface StrategyParameter {
     name: string;
     label: string;
@@ -34,53 +36,51 @@ export const STRATEGIES: Strategy[] = [
                 max: 10
             },
         ]
-    },
-    {
-        id: 'rsi-strategy',
-        name: 'RSI Strategy',
-        description: 'Mean reversion strategy using RSI indicator',
-        parameters: [
-            {
-                name: 'period',
-                label: 'RSI Period',
-                type: 'input',
-                inputType: 'number',
-                defaultValue: 14,
-                step: 1,
-                min: 2,
-                max: 50
-            },
-            {
-                name: 'overbought',
-                label: 'Overbought Level',
-                type: 'input',
-                inputType: 'number',
-                defaultValue: 70,
-                step: 1,
-                min: 50,
-                max: 100
-            },
-            {
-                name: 'oversold',
-                label: 'Oversold Level',
-                type: 'input',
-                inputType: 'number',
-                defaultValue: 30,
-                step: 1,
-                min: 0,
-                max: 50
-            },
-            {
-                name: 'mode',
-                label: 'Trading Mode',
-                type: 'select',
-                defaultValue: 'both',
-                options: [
-                    { value: 'both', label: 'Long & Short' },
-                    { value: 'long', label: 'Long Only' },
-                    { value: 'short', label: 'Short Only' }
-                ]
-            }
-        ]
     }
 ];
+
+// fromStrategyInfo converts a registered strategy into the form the
+// configuration panel renders.
+export function fromStrategyInfo(info: main.StrategyInfo): Strategy {
+    return {
+        id: info.ID,
+        name: info.Name,
+        description: info.Description,
+        parameters: (info.Parameters || []).map((p): StrategyParameter => {
+            const base = {
+                name: p.Name,
+                label: p.Label || p.Name,
+                defaultValue: p.Default ?? '',
+            };
+            switch (p.Type) {
+                case 'number':
+                    return {
+                        ...base,
+                        type: 'input',
+                        inputType: 'number',
+                        step: p.Step || undefined,
+                        min: p.Min || undefined,
+                        max: p.Max || undefined,
+                    };
+                case 'bool':
+                    return {
+                        ...base,
+                        type: 'select',
+                        defaultValue: String(p.Default ?? false),
+                        options: [
+                            { value: 'true', label: 'On' },
+                            { value: 'false', label: 'Off' },
+                        ],
+                    };
+                case 'select':
+                    return {
+                        ...base,
+                        type: 'select',
+                        options: (p.Options || []).map((o) => ({ value: o, label: o })),
+                    };
+            }
+            const isColor = typeof p.Default === 'string' && /^#[0-9a-f]{6}([0-9a-f]{2})?$/i.test(p.Default);
+            return { ...base, type: 'input', inputType: isColor ? 'color' : 'text' };
+        }),
+    };
+}
@@ -1,26 +1,108 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
-import {hyperliquid} from '../models';
 import {main} from '../models';
+import {hyperliquid} from '../models';
+
+export function AddAccount(arg1:main.AccountConfig):Promise<void>;
+
+export function BacktestDefinition(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:Record<string, any>):Promise<main.BacktestOutput>;
+
+export function CancelAlgoOrder(arg1:string):Promise<void>;
+
+export function CancelAllOrders():Promise<number>;
+
+export function CancelOrder(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function ClosePosition(arg1:string,arg2:string,arg3:number):Promise<main.OrderResponse>;
+
+export function ConfirmMainnetTrading():Promise<void>;
+
+export function DeleteDefinition(arg1:string):Promise<void>;
+
+export function DiscardSavedStrategy(arg1:string):Promise<void>;
+
+export function EncryptLegacySecret(arg1:string):Promise<void>;
 
 export function FetchCandles(arg1:string,arg2:string,arg3:number):Promise<hyperliquid.Candles>;
 
 export function FetchCandlesBefore(arg1:string,arg2:string,arg3:number,arg4:number):Promise<hyperliquid.Candles>;
 
+export function GetAccountPortfolioSummary(arg1:string):Promise<main.PortfolioSummary>;
+
+export function GetAccounts():Promise<Array<main.AccountInfo>>;
+
 export function GetActivePositions():Promise<Array<main.ActivePosition>>;
 
+export function GetAgentStatus():Promise<main.AgentStatus>;
+
+export function GetAlgoOrders():Promise<Array<main.AlgoOrder>>;
+
+export function GetAssetSpec(arg1:string):Promise<main.AssetSpec>;
+
+export function GetConfig():Promise<main.Settings>;
+
+export function GetDefinitions():Promise<Array<main.StrategyDefinition>>;
+
+export function GetLogs(arg1:main.LogFilter):Promise<Array<main.LogEntry>>;
+
+export function GetNetworkStatus():Promise<main.NetworkStatus>;
+
 export function GetPortfolioSummary():Promise<main.PortfolioSummary>;
 
-export function GetRunningStrategies():Promise<Array<main.MaxTrendPointsStrategy>>;
+export function GetRiskStatus():Promise<main.RiskStatus>;
+
+export function GetRunningStrategies():Promise<Array<main.LiveStrategy>>;
+
+export function GetSavedStrategies():Promise<Array<main.StrategyRecord>>;
+
+export function GetStrategyEvents(arg1:string,arg2:number):Promise<Array<main.StrategyEvent>>;
 
 export function GetWalletAddress():Promise<string>;
 
+export function GetWalletStatus():Promise<main.WalletStatus>;
+
 export function InvalidateCache():Promise<void>;
 
 export function InvalidateCacheForSymbol(arg1:string):Promise<void>;
 
+export function ListStrategies():Promise<Array<main.StrategyInfo>>;
+
+export function ModifyOrder(arg1:number,arg2:main.OrderTicket):Promise<main.OrderResponse>;
+
+export function PauseAlgoOrder(arg1:string):Promise<void>;
+
+export function PauseLiveStrategy(arg1:string):Promise<void>;
+
+export function PlaceOrder(arg1:main.OrderTicket):Promise<main.OrderResponse>;
+
+export function RemoveAccount(arg1:string):Promise<void>;
+
+export function ResetKillSwitch():Promise<void>;
+
+export function ResumeAlgoOrder(arg1:string):Promise<void>;
+
+export function ResumeLiveStrategy(arg1:string):Promise<void>;
+
+export function ResumeSavedStrategies():Promise<void>;
+
+export function SaveDefinition(arg1:string,arg2:string):Promise<main.StrategyInfo>;
+
+export function SetNetwork(arg1:string):Promise<void>;
+
+export function SetRiskLimits(arg1:main.RiskLimits):Promise<void>;
+
+export function StartAlgoOrder(arg1:string,arg2:main.AlgoRequest):Promise<string>;
+
 export function StopLiveStrategy(arg1:string):Promise<void>;
 
-export function StrategyBacktest(arg1:string,arg2:string,arg3:number,arg4:Record<string, any>):Promise<main.BacktestOutput>;
+export function StrategyBacktest(arg1:string,arg2:string,arg3:string,arg4:number,arg5:Record<string, any>):Promise<main.BacktestOutput>;
+
+export function StrategyRun(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:Record<string, any>):Promise<void>;
+
+export function TripKillSwitch(arg1:string):Promise<void>;
+
+export function UnlockWallet(arg1:string):Promise<void>;
+
+export function UpdateConfig(arg1:main.Settings):Promise<void>;
 
-export function StrategyRun(arg1:string,arg2:string,arg3:string,arg4:Record<string, any>):Promise<void>;
+export function ValidateDefinition(arg1:string,arg2:string):Promise<main.StrategyInfo>;
@@ -2,6 +2,46 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAccount(arg1) {
+  return window['go']['main']['App']['AddAccount'](arg1);
+}
+
+export function BacktestDefinition(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['BacktestDefinition'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function CancelAlgoOrder(arg1) {
+  return window['go']['main']['App']['CancelAlgoOrder'](arg1);
+}
+
+export function CancelAllOrders() {
+  return window['go']['main']['App']['CancelAllOrders']();
+}
+
+export function CancelOrder(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CancelOrder'](arg1, arg2, arg3);
+}
+
+export function ClosePosition(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ClosePosition'](arg1, arg2, arg3);
+}
+
+export function ConfirmMainnetTrading() {
+  return window['go']['main']['App']['ConfirmMainnetTrading']();
+}
+
+export function DeleteDefinition(arg1) {
+  return window['go']['main']['App']['DeleteDefinition'](arg1);
+}
+
+export function DiscardSavedStrategy(arg1) {
+  return window['go']['main']['App']['DiscardSavedStrategy'](arg1);
+}
+
+export function EncryptLegacySecret(arg1) {
+  return window['go']['main']['App']['EncryptLegacySecret'](arg1);
+}
+
 export function FetchCandles(arg1, arg2, arg3) {
   return window['go']['main']['App']['FetchCandles'](arg1, arg2, arg3);
 }
@@ -10,22 +50,74 @@ export function FetchCandlesBefore(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['FetchCandlesBefore'](arg1, arg2, arg3, arg4);
 }
 
+export function GetAccountPortfolioSummary(arg1) {
+  return window['go']['main']['App']['GetAccountPortfolioSummary'](arg1);
+}
+
+export function GetAccounts() {
+  return window['go']['main']['App']['GetAccounts']();
+}
+
 export function GetActivePositions() {
   return window['go']['main']['App']['GetActivePositions']();
 }
 
+export function GetAgentStatus() {
+  return window['go']['main']['App']['GetAgentStatus']();
+}
+
+export function GetAlgoOrders() {
+  return window['go']['main']['App']['GetAlgoOrders']();
+}
+
+export function GetAssetSpec(arg1) {
+  return window['go']['main']['App']['GetAssetSpec'](arg1);
+}
+
+export function GetConfig() {
+  return window['go']['main']['App']['GetConfig']();
+}
+
+export function GetDefinitions() {
+  return window['go']['main']['App']['GetDefinitions']();
+}
+
+export function GetLogs(arg1) {
+  return window['go']['main']['App']['GetLogs'](arg1);
+}
+
+export function GetNetworkStatus() {
+  return window['go']['main']['App']['GetNetworkStatus']();
+}
+
 export function GetPortfolioSummary() {
   return window['go']['main']['App']['GetPortfolioSummary']();
 }
 
+export function GetRiskStatus() {
+  return window['go']['main']['App']['GetRiskStatus']();
+}
+
 export function GetRunningStrategies() {
   return window['go']['main']['App']['GetRunningStrategies']();
 }
 
+export function GetSavedStrategies() {
+  return window['go']['main']['App']['GetSavedStrategies']();
+}
+
+export function GetStrategyEvents(arg1, arg2) {
+  return window['go']['main']['App']['GetStrategyEvents'](arg1, arg2);
+}
+
 export function GetWalletAddress() {
   return window['go']['main']['App']['GetWalletAddress']();
 }
 
+export function GetWalletStatus() {
+  return window['go']['main']['App']['GetWalletStatus']();
+}
+
 export function InvalidateCache() {
   return window['go']['main']['App']['InvalidateCache']();
 }
@@ -34,14 +126,86 @@ export function InvalidateCacheForSymbol(arg1) {
   return window['go']['main']['App']['InvalidateCacheForSymbol'](arg1);
 }
 
+export function ListStrategies() {
+  return window['go']['main']['App']['ListStrategies']();
+}
+
+export function ModifyOrder(arg1, arg2) {
+  return window['go']['main']['App']['ModifyOrder'](arg1, arg2);
+}
+
+export function PauseAlgoOrder(arg1) {
+  return window['go']['main']['App']['PauseAlgoOrder'](arg1);
+}
+
+export function PauseLiveStrategy(arg1) {
+  return window['go']['main']['App']['PauseLiveStrategy'](arg1);
+}
+
+export function PlaceOrder(arg1) {
+  return window['go']['main']['App']['PlaceOrder'](arg1);
+}
+
+export function RemoveAccount(arg1) {
+  return window['go']['main']['App']['RemoveAccount'](arg1);
+}
+
+export function ResetKillSwitch() {
+  return window['go']['main']['App']['ResetKillSwitch']();
+}
+
+export function ResumeAlgoOrder(arg1) {
+  return window['go']['main']['App']['ResumeAlgoOrder'](arg1);
+}
+
+export function ResumeLiveStrategy(arg1) {
+  return window['go']['main']['App']['ResumeLiveStrategy'](arg1);
+}
+
+export function ResumeSavedStrategies() {
+  return window['go']['main']['App']['ResumeSavedStrategies']();
+}
+
+export function SaveDefinition(arg1, arg2) {
+  return window['go']['main']['App']['SaveDefinition'](arg1, arg2);
+}
+
+export function SetNetwork(arg1) {
+  return window['go']['main']['App']['SetNetwork'](arg1);
+}
+
+export function SetRiskLimits(arg1) {
+  return window['go']['main']['App']['SetRiskLimits'](arg1);
+}
+
+export function StartAlgoOrder(arg1, arg2) {
+  return window['go']['main']['App']['StartAlgoOrder'](arg1, arg2);
+}
+
 export function StopLiveStrategy(arg1) {
   return window['go']['main']['App']['StopLiveStrategy'](arg1);
 }
 
-export function StrategyBacktest(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['StrategyBacktest'](arg1, arg2, arg3, arg4);
+export function StrategyBacktest(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['StrategyBacktest'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function StrategyRun(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['StrategyRun'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function TripKillSwitch(arg1) {
+  return window['go']['main']['App']['TripKillSwitch'](arg1);
+}
+
+export function UnlockWallet(arg1) {
+  return window['go']['main']['App']['UnlockWallet'](arg1);
+}
+
+export function UpdateConfig(arg1) {
+  return window['go']['main']['App']['UpdateConfig'](arg1);
 }
 
-export function StrategyRun(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['StrategyRun'](arg1, arg2, arg3, arg4);
+export function ValidateDefinition(arg1, arg2) {
+  return window['go']['main']['App']['ValidateDefinition'](arg1, arg2);
 }
@@ -77,7 +77,46 @@ export namespace main {
 	        this.AccountLeverage = source["AccountLeverage"];
 	    }
 	}
+	export class AccountConfig {
+	    ID: string;
+	    Name: string;
+	    Address: string;
+	    Kind: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AccountConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Name = source["Name"];
+	        this.Address = source["Address"];
+	        this.Kind = source["Kind"];
+	    }
+	}
+	export class AccountInfo {
+	    ID: string;
+	    Name: string;
+	    Address: string;
+	    Kind: string;
+	    ReadOnly: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AccountInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Name = source["Name"];
+	        this.Address = source["Address"];
+	        this.Kind = source["Kind"];
+	        this.ReadOnly = source["ReadOnly"];
+	    }
+	}
 	export class ActivePosition {
+	    AccountID: string;
 	    Coin: string;
 	    Side: string;
 	    Size: string;
@@ -96,6 +135,7 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.AccountID = source["AccountID"];
 	        this.Coin = source["Coin"];
 	        this.Side = source["Side"];
 	        this.Size = source["Size"];
@@ -109,6 +149,238 @@ export namespace main {
 	        this.ReturnOnEquity = source["ReturnOnEquity"];
 	    }
 	}
+	export class AgentStatus {
+	    Enabled: boolean;
+	    MasterAddress: string;
+	    AgentAddress: string;
+	    Name: string;
+	    Approved: boolean;
+	    // Go type: time
+	    ValidUntil: any;
+	    Expired: boolean;
+	    ExpiringSoon: boolean;
+	    Message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AgentStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Enabled = source["Enabled"];
+	        this.MasterAddress = source["MasterAddress"];
+	        this.AgentAddress = source["AgentAddress"];
+	        this.Name = source["Name"];
+	        this.Approved = source["Approved"];
+	        this.ValidUntil = this.convertValues(source["ValidUntil"], null);
+	        this.Expired = source["Expired"];
+	        this.ExpiringSoon = source["ExpiringSoon"];
+	        this.Message = source["Message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AlgoRequest {
+	    Type: string;
+	    Coin: string;
+	    IsBuy: boolean;
+	    Size: number;
+	    ReduceOnly: boolean;
+	    DurationSeconds: number;
+	    Slices: number;
+	    Randomize: number;
+	    VisibleSize: number;
+	    PostOnly: boolean;
+	    MaxChaseBps: number;
+	    ClipTimeoutSecs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AlgoRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Type = source["Type"];
+	        this.Coin = source["Coin"];
+	        this.IsBuy = source["IsBuy"];
+	        this.Size = source["Size"];
+	        this.ReduceOnly = source["ReduceOnly"];
+	        this.DurationSeconds = source["DurationSeconds"];
+	        this.Slices = source["Slices"];
+	        this.Randomize = source["Randomize"];
+	        this.VisibleSize = source["VisibleSize"];
+	        this.PostOnly = source["PostOnly"];
+	        this.MaxChaseBps = source["MaxChaseBps"];
+	        this.ClipTimeoutSecs = source["ClipTimeoutSecs"];
+	    }
+	}
+	export class AlgoOrder {
+	    ID: string;
+	    Request: AlgoRequest;
+	    Status: string;
+	    FilledSize: number;
+	    AvgPrice: number;
+	    SlicesDone: number;
+	    SlicesTotal: number;
+	    StartedAt: number;
+	    UpdatedAt: number;
+	    Error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AlgoOrder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Request = this.convertValues(source["Request"], AlgoRequest);
+	        this.Status = source["Status"];
+	        this.FilledSize = source["FilledSize"];
+	        this.AvgPrice = source["AvgPrice"];
+	        this.SlicesDone = source["SlicesDone"];
+	        this.SlicesTotal = source["SlicesTotal"];
+	        this.StartedAt = source["StartedAt"];
+	        this.UpdatedAt = source["UpdatedAt"];
+	        this.Error = source["Error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class AssetSpec {
+	    Name: string;
+	    SzDecimals: number;
+	    MaxLeverage: number;
+	    OnlyIsolated: boolean;
+	    IsDelisted: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AssetSpec(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.SzDecimals = source["SzDecimals"];
+	        this.MaxLeverage = source["MaxLeverage"];
+	        this.OnlyIsolated = source["OnlyIsolated"];
+	        this.IsDelisted = source["IsDelisted"];
+	    }
+	}
+	export class GridOrder {
+	    Level: number;
+	    Price: number;
+	    Side: string;
+	    OrderID: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GridOrder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Level = source["Level"];
+	        this.Price = source["Price"];
+	        this.Side = source["Side"];
+	        this.OrderID = source["OrderID"];
+	    }
+	}
+	export class GridState {
+	    Levels: number[];
+	    Size: number;
+	    Orders: GridOrder[];
+	    Lots: Position[];
+	    GridProfit: number;
+	    RoundTrips: number;
+	    Stopped: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GridState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Levels = source["Levels"];
+	        this.Size = source["Size"];
+	        this.Orders = this.convertValues(source["Orders"], GridOrder);
+	        this.Lots = this.convertValues(source["Lots"], Position);
+	        this.GridProfit = source["GridProfit"];
+	        this.RoundTrips = source["RoundTrips"];
+	        this.Stopped = source["Stopped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PositionLeg {
+	    Symbol: string;
+	    Side: string;
+	    Size: number;
+	    EntryPrice: number;
+	    ExitPrice: number;
+	    MarkPrice: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PositionLeg(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Symbol = source["Symbol"];
+	        this.Side = source["Side"];
+	        this.Size = source["Size"];
+	        this.EntryPrice = source["EntryPrice"];
+	        this.ExitPrice = source["ExitPrice"];
+	        this.MarkPrice = source["MarkPrice"];
+	    }
+	}
 	export class Position {
 	    EntryIndex: number;
 	    EntryPrice: number;
@@ -120,10 +392,17 @@ export namespace main {
 	    Size: number;
 	    PnL: number;
 	    PnLPercentage: number;
+	    Fees: number;
 	    IsOpen: boolean;
 	    ExitReason: string;
 	    MaxDrawdown: number;
 	    MaxProfit: number;
+	    Entries: number;
+	    BasePrice: number;
+	    StopPrice: number;
+	    StopReason: string;
+	    BestPrice: number;
+	    Legs: PositionLeg[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Position(source);
@@ -141,11 +420,36 @@ export namespace main {
 	        this.Size = source["Size"];
 	        this.PnL = source["PnL"];
 	        this.PnLPercentage = source["PnLPercentage"];
+	        this.Fees = source["Fees"];
 	        this.IsOpen = source["IsOpen"];
 	        this.ExitReason = source["ExitReason"];
 	        this.MaxDrawdown = source["MaxDrawdown"];
 	        this.MaxProfit = source["MaxProfit"];
+	        this.Entries = source["Entries"];
+	        this.BasePrice = source["BasePrice"];
+	        this.StopPrice = source["StopPrice"];
+	        this.StopReason = source["StopReason"];
+	        this.BestPrice = source["BestPrice"];
+	        this.Legs = this.convertValues(source["Legs"], PositionLeg);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class Signal {
 	    Index: number;
@@ -196,6 +500,7 @@ export namespace main {
 	    Positions: Position[];
 	    StrategyName: string;
 	    StrategyVersion: string;
+	    Grid?: GridState;
 	    TotalPnL: number;
 	    TotalPnLPercent: number;
 	    WinRate: number;
@@ -211,6 +516,7 @@ export namespace main {
 	    LongestWinStreak: number;
 	    LongestLossStreak: number;
 	    AverageHoldTime: number;
+	    FeesPaid: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new BacktestOutput(source);
@@ -226,6 +532,7 @@ export namespace main {
 	        this.Positions = this.convertValues(source["Positions"], Position);
 	        this.StrategyName = source["StrategyName"];
 	        this.StrategyVersion = source["StrategyVersion"];
+	        this.Grid = this.convertValues(source["Grid"], GridState);
 	        this.TotalPnL = source["TotalPnL"];
 	        this.TotalPnLPercent = source["TotalPnLPercent"];
 	        this.WinRate = source["WinRate"];
@@ -241,6 +548,7 @@ export namespace main {
 	        this.LongestWinStreak = source["LongestWinStreak"];
 	        this.LongestLossStreak = source["LongestLossStreak"];
 	        this.AverageHoldTime = source["AverageHoldTime"];
+	        this.FeesPaid = source["FeesPaid"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -261,13 +569,86 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class ExecutionConfig {
+	    Mode: string;
+	    OffsetBps: number;
+	    RepriceInterval: number;
+	    MaxChaseBps: number;
+	    Timeout: number;
+	    AlgoDuration: number;
+	    AlgoSlices: number;
+	    VisibleSize: number;
+	    MakerFeePercent: number;
+	    TakerFeePercent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExecutionConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Mode = source["Mode"];
+	        this.OffsetBps = source["OffsetBps"];
+	        this.RepriceInterval = source["RepriceInterval"];
+	        this.MaxChaseBps = source["MaxChaseBps"];
+	        this.Timeout = source["Timeout"];
+	        this.AlgoDuration = source["AlgoDuration"];
+	        this.AlgoSlices = source["AlgoSlices"];
+	        this.VisibleSize = source["VisibleSize"];
+	        this.MakerFeePercent = source["MakerFeePercent"];
+	        this.TakerFeePercent = source["TakerFeePercent"];
+	    }
+	}
+	
+	
+	
+	export class StopConfig {
+	    Trailing: string;
+	    TrailPercent: number;
+	    ATRLength: number;
+	    ATRMultiplier: number;
+	    BreakEvenPercent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StopConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Trailing = source["Trailing"];
+	        this.TrailPercent = source["TrailPercent"];
+	        this.ATRLength = source["ATRLength"];
+	        this.ATRMultiplier = source["ATRMultiplier"];
+	        this.BreakEvenPercent = source["BreakEvenPercent"];
+	    }
+	}
+	export class ScalingConfig {
+	    Mode: string;
+	    Orders: number;
+	    StepPercent: number;
+	    SizeMultiplier: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScalingConfig(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Mode = source["Mode"];
+	        this.Orders = source["Orders"];
+	        this.StepPercent = source["StepPercent"];
+	        this.SizeMultiplier = source["SizeMultiplier"];
+	    }
+	}
 	export class StrategyConfig {
 	    PositionSize: number;
 	    TradeDirection: string;
 	    TakeProfitPercent: number;
 	    StopLossPercent: number;
 	    Interval: number;
+	    Execution: ExecutionConfig;
+	    Scaling: ScalingConfig;
+	    Stops: StopConfig;
 	    Parameters: Record<string, any>;
 	
 	    static createFrom(source: any = {}) {
@@ -281,32 +662,111 @@ export namespace main {
 	        this.TakeProfitPercent = source["TakeProfitPercent"];
 	        this.StopLossPercent = source["StopLossPercent"];
 	        this.Interval = source["Interval"];
+	        this.Execution = this.convertValues(source["Execution"], ExecutionConfig);
+	        this.Scaling = this.convertValues(source["Scaling"], ScalingConfig);
+	        this.Stops = this.convertValues(source["Stops"], StopConfig);
 	        this.Parameters = source["Parameters"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PerformanceMetrics {
+	    TotalPnL: number;
+	    TotalPnLPercent: number;
+	    WinRate: number;
+	    TotalTrades: number;
+	    WinningTrades: number;
+	    LosingTrades: number;
+	    AverageWin: number;
+	    AverageLoss: number;
+	    ProfitFactor: number;
+	    MaxDrawdown: number;
+	    MaxDrawdownPercent: number;
+	    SharpeRatio: number;
+	    LongestWinStreak: number;
+	    LongestLossStreak: number;
+	    AverageHoldTime: number;
+	    FeesPaid: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PerformanceMetrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.TotalPnL = source["TotalPnL"];
+	        this.TotalPnLPercent = source["TotalPnLPercent"];
+	        this.WinRate = source["WinRate"];
+	        this.TotalTrades = source["TotalTrades"];
+	        this.WinningTrades = source["WinningTrades"];
+	        this.LosingTrades = source["LosingTrades"];
+	        this.AverageWin = source["AverageWin"];
+	        this.AverageLoss = source["AverageLoss"];
+	        this.ProfitFactor = source["ProfitFactor"];
+	        this.MaxDrawdown = source["MaxDrawdown"];
+	        this.MaxDrawdownPercent = source["MaxDrawdownPercent"];
+	        this.SharpeRatio = source["SharpeRatio"];
+	        this.LongestWinStreak = source["LongestWinStreak"];
+	        this.LongestLossStreak = source["LongestLossStreak"];
+	        this.AverageHoldTime = source["AverageHoldTime"];
+	        this.FeesPaid = source["FeesPaid"];
+	    }
 	}
-	export class MaxTrendPointsStrategy {
+	export class LiveStrategy {
 	    ID: string;
+	    Kind: string;
+	    Name: string;
+	    AccountID: string;
 	    Symbol: string;
 	    Interval: string;
 	    LastCandleTime: number;
-	    IsRunning: boolean;
+	    State: string;
+	    Error: string;
 	    Position?: Position;
-	    Factor: number;
+	    Trades: Position[];
+	    Grid?: GridState;
+	    Performance: PerformanceMetrics;
+	    MarkPrice: number;
+	    UnrealizedPnL: number;
 	    Config: StrategyConfig;
 	
 	    static createFrom(source: any = {}) {
-	        return new MaxTrendPointsStrategy(source);
+	        return new LiveStrategy(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.ID = source["ID"];
+	        this.Kind = source["Kind"];
+	        this.Name = source["Name"];
+	        this.AccountID = source["AccountID"];
 	        this.Symbol = source["Symbol"];
 	        this.Interval = source["Interval"];
 	        this.LastCandleTime = source["LastCandleTime"];
-	        this.IsRunning = source["IsRunning"];
+	        this.State = source["State"];
+	        this.Error = source["Error"];
 	        this.Position = this.convertValues(source["Position"], Position);
-	        this.Factor = source["Factor"];
+	        this.Trades = this.convertValues(source["Trades"], Position);
+	        this.Grid = this.convertValues(source["Grid"], GridState);
+	        this.Performance = this.convertValues(source["Performance"], PerformanceMetrics);
+	        this.MarkPrice = source["MarkPrice"];
+	        this.UnrealizedPnL = source["UnrealizedPnL"];
 	        this.Config = this.convertValues(source["Config"], StrategyConfig);
 	    }
 	
@@ -328,12 +788,139 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class LogEntry {
+	    Time: string;
+	    Level: string;
+	    Message: string;
+	    Fields: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Time = source["Time"];
+	        this.Level = source["Level"];
+	        this.Message = source["Message"];
+	        this.Fields = source["Fields"];
+	    }
+	}
+	export class LogFilter {
+	    Level: string;
+	    Strategy: string;
+	    Search: string;
+	    Limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Level = source["Level"];
+	        this.Strategy = source["Strategy"];
+	        this.Search = source["Search"];
+	        this.Limit = source["Limit"];
+	    }
+	}
+	export class LoggingConfig {
+	    Level: string;
+	    File: string;
+	    MaxSizeMB: number;
+	    MaxFiles: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoggingConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Level = source["Level"];
+	        this.File = source["File"];
+	        this.MaxSizeMB = source["MaxSizeMB"];
+	        this.MaxFiles = source["MaxFiles"];
+	    }
+	}
+	export class NetworkStatus {
+	    Network: string;
+	    APIURL: string;
+	    IsMainnet: boolean;
+	    TradingConfirmed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Network = source["Network"];
+	        this.APIURL = source["APIURL"];
+	        this.IsMainnet = source["IsMainnet"];
+	        this.TradingConfirmed = source["TradingConfirmed"];
+	    }
+	}
+	export class OrderResponse {
+	    Success: boolean;
+	    OrderID: string;
+	    Message: string;
+	    Status: string;
+	    AvgPrice: number;
+	    FilledSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrderResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Success = source["Success"];
+	        this.OrderID = source["OrderID"];
+	        this.Message = source["Message"];
+	        this.Status = source["Status"];
+	        this.AvgPrice = source["AvgPrice"];
+	        this.FilledSize = source["FilledSize"];
+	    }
+	}
+	export class OrderTicket {
+	    AccountID: string;
+	    Coin: string;
+	    IsBuy: boolean;
+	    Kind: string;
+	    Size: number;
+	    Price: number;
+	    TriggerPrice: number;
+	    ReduceOnly: boolean;
+	    Tif: string;
+	    Leverage: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OrderTicket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.AccountID = source["AccountID"];
+	        this.Coin = source["Coin"];
+	        this.IsBuy = source["IsBuy"];
+	        this.Kind = source["Kind"];
+	        this.Size = source["Size"];
+	        this.Price = source["Price"];
+	        this.TriggerPrice = source["TriggerPrice"];
+	        this.ReduceOnly = source["ReduceOnly"];
+	        this.Tif = source["Tif"];
+	        this.Leverage = source["Leverage"];
+	    }
+	}
+	
 	export class PortfolioSummary {
+	    AccountID: string;
 	    Balance: AccountBalance;
 	    Positions: ActivePosition[];
 	    TotalPositions: number;
 	    TotalPnL: number;
 	    OpenOrders: hyperliquid.OpenOrder[];
+	    OrderAccounts: Record<number, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new PortfolioSummary(source);
@@ -341,11 +928,332 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.AccountID = source["AccountID"];
 	        this.Balance = this.convertValues(source["Balance"], AccountBalance);
 	        this.Positions = this.convertValues(source["Positions"], ActivePosition);
 	        this.TotalPositions = source["TotalPositions"];
 	        this.TotalPnL = source["TotalPnL"];
 	        this.OpenOrders = this.convertValues(source["OpenOrders"], hyperliquid.OpenOrder);
+	        this.OrderAccounts = source["OrderAccounts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class RiskEvent {
+	    Time: number;
+	    Coin: string;
+	    Reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RiskEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Time = source["Time"];
+	        this.Coin = source["Coin"];
+	        this.Reason = source["Reason"];
+	    }
+	}
+	export class RiskLimits {
+	    MaxSymbolNotional: number;
+	    MaxTotalNotional: number;
+	    MaxDailyLoss: number;
+	    MaxConsecutiveLosses: number;
+	    MaxOrdersPerMinute: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RiskLimits(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.MaxSymbolNotional = source["MaxSymbolNotional"];
+	        this.MaxTotalNotional = source["MaxTotalNotional"];
+	        this.MaxDailyLoss = source["MaxDailyLoss"];
+	        this.MaxConsecutiveLosses = source["MaxConsecutiveLosses"];
+	        this.MaxOrdersPerMinute = source["MaxOrdersPerMinute"];
+	    }
+	}
+	export class RiskStatus {
+	    Limits: RiskLimits;
+	    KillSwitch: boolean;
+	    KillSwitchReason: string;
+	    KillSwitchTime: number;
+	    DailyRealizedPnL: number;
+	    ConsecutiveLosses: number;
+	    OrdersLastMinute: number;
+	    Rejections: RiskEvent[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RiskStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Limits = this.convertValues(source["Limits"], RiskLimits);
+	        this.KillSwitch = source["KillSwitch"];
+	        this.KillSwitchReason = source["KillSwitchReason"];
+	        this.KillSwitchTime = source["KillSwitchTime"];
+	        this.DailyRealizedPnL = source["DailyRealizedPnL"];
+	        this.ConsecutiveLosses = source["ConsecutiveLosses"];
+	        this.OrdersLastMinute = source["OrdersLastMinute"];
+	        this.Rejections = this.convertValues(source["Rejections"], RiskEvent);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TradingDefaults {
+	    Symbol: string;
+	    Interval: string;
+	    Symbols: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TradingDefaults(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Symbol = source["Symbol"];
+	        this.Interval = source["Interval"];
+	        this.Symbols = source["Symbols"];
+	    }
+	}
+	export class Settings {
+	    Network: string;
+	    RedisURL: string;
+	    DatabasePath: string;
+	    KeystorePath: string;
+	    Defaults: TradingDefaults;
+	    Risk: RiskLimits;
+	    Logging: LoggingConfig;
+	    Accounts: AccountConfig[];
+	    ShutdownMode: string;
+	    ResumeStrategies: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Network = source["Network"];
+	        this.RedisURL = source["RedisURL"];
+	        this.DatabasePath = source["DatabasePath"];
+	        this.KeystorePath = source["KeystorePath"];
+	        this.Defaults = this.convertValues(source["Defaults"], TradingDefaults);
+	        this.Risk = this.convertValues(source["Risk"], RiskLimits);
+	        this.Logging = this.convertValues(source["Logging"], LoggingConfig);
+	        this.Accounts = this.convertValues(source["Accounts"], AccountConfig);
+	        this.ShutdownMode = source["ShutdownMode"];
+	        this.ResumeStrategies = source["ResumeStrategies"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class StrategyDefinition {
+	    ID: string;
+	    Kind: string;
+	    Name: string;
+	    Source: string;
+	    UpdatedAt: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StrategyDefinition(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Kind = source["Kind"];
+	        this.Name = source["Name"];
+	        this.Source = source["Source"];
+	        this.UpdatedAt = source["UpdatedAt"];
+	    }
+	}
+	export class StrategyEvent {
+	    Seq: number;
+	    StrategyID: string;
+	    Type: string;
+	    Time: number;
+	    Message: string;
+	    Price: number;
+	    Size: number;
+	    Side: string;
+	    OrderID: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StrategyEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Seq = source["Seq"];
+	        this.StrategyID = source["StrategyID"];
+	        this.Type = source["Type"];
+	        this.Time = source["Time"];
+	        this.Message = source["Message"];
+	        this.Price = source["Price"];
+	        this.Size = source["Size"];
+	        this.Side = source["Side"];
+	        this.OrderID = source["OrderID"];
+	    }
+	}
+	export class StrategyParameter {
+	    Name: string;
+	    Label: string;
+	    Type: string;
+	    Default: any;
+	    Min: number;
+	    Max: number;
+	    Step: number;
+	    Options: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StrategyParameter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.Label = source["Label"];
+	        this.Type = source["Type"];
+	        this.Default = source["Default"];
+	        this.Min = source["Min"];
+	        this.Max = source["Max"];
+	        this.Step = source["Step"];
+	        this.Options = source["Options"];
+	    }
+	}
+	export class StrategyInfo {
+	    ID: string;
+	    Name: string;
+	    Description: string;
+	    Source: string;
+	    Parameters: StrategyParameter[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StrategyInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Name = source["Name"];
+	        this.Description = source["Description"];
+	        this.Source = source["Source"];
+	        this.Parameters = this.convertValues(source["Parameters"], StrategyParameter);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class StrategyRecord {
+	    ID: string;
+	    Kind: string;
+	    Network: string;
+	    AccountID: string;
+	    Name: string;
+	    Symbol: string;
+	    Interval: string;
+	    Params: Record<string, any>;
+	    Position?: Position;
+	    Trades: Position[];
+	    Grid?: GridState;
+	    LastCandleTime: number;
+	    Paused: boolean;
+	    UpdatedAt: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StrategyRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.Kind = source["Kind"];
+	        this.Network = source["Network"];
+	        this.AccountID = source["AccountID"];
+	        this.Name = source["Name"];
+	        this.Symbol = source["Symbol"];
+	        this.Interval = source["Interval"];
+	        this.Params = source["Params"];
+	        this.Position = this.convertValues(source["Position"], Position);
+	        this.Trades = this.convertValues(source["Trades"], Position);
+	        this.Grid = this.convertValues(source["Grid"], GridState);
+	        this.LastCandleTime = source["LastCandleTime"];
+	        this.Paused = source["Paused"];
+	        this.UpdatedAt = source["UpdatedAt"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -367,7 +1275,26 @@ export namespace main {
 		}
 	}
 	
+	export class WalletStatus {
+	    Address: string;
+	    AgentAddress: string;
+	    ReadOnly: boolean;
+	    Locked: boolean;
+	    KeystorePath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WalletStatus(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Address = source["Address"];
+	        this.AgentAddress = source["AgentAddress"];
+	        this.ReadOnly = source["ReadOnly"];
+	        this.Locked = source["Locked"];
+	        this.KeystorePath = source["KeystorePath"];
+	    }
+	}
 
 }
 
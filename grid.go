@@ -0,0 +1,672 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// GridConfig is the ladder of a grid strategy. Lower and Upper of zero place
+// the grid RangePercent around the price it starts at.
+type GridConfig struct {
+	Lower        float64
+	Upper        float64
+	RangePercent float64
+	Levels       int
+	// Spacing is "arithmetic" (equal steps) or "geometric" (equal ratios).
+	Spacing string
+	// Exit is what happens when price leaves the range: "flatten" closes the
+	// inventory, "stop" only cancels the orders.
+	Exit string
+}
+
+func buildGridConfig(params map[string]any) (GridConfig, error) {
+	config := GridConfig{RangePercent: 10, Levels: 10, Spacing: "arithmetic", Exit: "flatten"}
+	if v, ok := params["lowerPrice"].(float64); ok {
+		config.Lower = v
+	}
+	if v, ok := params["upperPrice"].(float64); ok {
+		config.Upper = v
+	}
+	if v, ok := params["rangePercent"].(float64); ok {
+		config.RangePercent = v
+	}
+	if v, ok := params["gridLevels"].(float64); ok {
+		config.Levels = int(v)
+	}
+	if v, ok := params["gridSpacing"].(string); ok {
+		config.Spacing = v
+	}
+	if v, ok := params["rangeExit"].(string); ok {
+		config.Exit = v
+	}
+
+	switch {
+	case config.Levels < 3:
+		return config, fmt.Errorf("a grid needs at least 3 levels")
+	case config.Spacing != "arithmetic" && config.Spacing != "geometric":
+		return config, fmt.Errorf("unknown grid spacing %q", config.Spacing)
+	case config.Exit != "flatten" && config.Exit != "stop":
+		return config, fmt.Errorf("unknown range exit %q", config.Exit)
+	case config.Lower < 0 || config.Upper < 0:
+		return config, fmt.Errorf("grid bounds must be positive")
+	case (config.Lower == 0) != (config.Upper == 0):
+		return config, fmt.Errorf("set both grid bounds, or neither to use the range percent")
+	case config.Lower > 0 && config.Lower >= config.Upper:
+		return config, fmt.Errorf("lower price must be below upper price")
+	case config.Lower == 0 && (config.RangePercent <= 0 || config.RangePercent >= 100):
+		return config, fmt.Errorf("range percent must be between 0 and 100")
+	}
+	return config, nil
+}
+
+// levels returns the grid's prices, ascending, for a grid started at start.
+func (c GridConfig) levels(start float64) ([]float64, error) {
+	lower, upper := c.Lower, c.Upper
+	if lower == 0 {
+		lower = start * (1 - c.RangePercent/100)
+		upper = start * (1 + c.RangePercent/100)
+	}
+	if start < lower || start > upper {
+		return nil, fmt.Errorf("price %.2f is outside the grid %.2f-%.2f", start, lower, upper)
+	}
+	levels := make([]float64, c.Levels)
+	steps := float64(c.Levels - 1)
+	for i := range levels {
+		if c.Spacing == "geometric" {
+			levels[i] = lower * math.Pow(upper/lower, float64(i)/steps)
+		} else {
+			levels[i] = lower + (upper-lower)*float64(i)/steps
+		}
+	}
+	return levels, nil
+}
+
+// GridOrder is a resting order on one level. OrderID is zero until the order
+// is on the exchange; backtests never set it. Filled is the part of the
+// level's size already traded; an order placed again after a partial fill
+// is for the rest.
+type GridOrder struct {
+	Level   int
+	Price   float64
+	Side    string // "buy" or "sell"
+	OrderID int64
+	Filled  float64
+}
+
+// GridState is a running grid: its levels, resting orders and inventory.
+// Every level but one holds an order, buys below the empty level and sells
+// above it; a fill moves the empty level to the filled one and answers the
+// fill on the level next to it. Lots are the open fills, newest last, and are
+// closed last in, first out by opposite fills.
+type GridState struct {
+	Levels     []float64
+	Size       float64
+	Orders     []GridOrder
+	Lots       []Position
+	GridProfit float64
+	RoundTrips int
+	// Stopped is why the grid stopped trading, empty while it runs.
+	Stopped string
+}
+
+func newGridState(levels []float64, size, start float64) *GridState {
+	empty := 0
+	for i, level := range levels {
+		if math.Abs(level-start) < math.Abs(levels[empty]-start) {
+			empty = i
+		}
+	}
+	g := &GridState{Levels: levels, Size: size, Orders: []GridOrder{}, Lots: []Position{}}
+	for i, level := range levels {
+		switch {
+		case i < empty:
+			g.Orders = append(g.Orders, GridOrder{Level: i, Price: level, Side: "buy"})
+		case i > empty:
+			g.Orders = append(g.Orders, GridOrder{Level: i, Price: level, Side: "sell"})
+		}
+	}
+	return g
+}
+
+func (g *GridState) clone() *GridState {
+	c := *g
+	c.Levels = slices.Clone(g.Levels)
+	c.Orders = slices.Clone(g.Orders)
+	c.Lots = slices.Clone(g.Lots)
+	return &c
+}
+
+func (g *GridState) lower() float64 { return g.Levels[0] }
+func (g *GridState) upper() float64 { return g.Levels[len(g.Levels)-1] }
+
+// fill executes size of the order on level at price. The fill closes the
+// newest lots while they are on the other side, splitting one it only
+// partly closes, and opens a lot with what is left. Once the order has
+// filled in full, the answering order replaces it one level up for a buy or
+// one level down for a sell. It returns the lots the fill closed.
+func (g *GridState) fill(level int, size float64, index int, at int64, price, feePercent float64) []Position {
+	i := slices.IndexFunc(g.Orders, func(o GridOrder) bool { return o.Level == level })
+	if i < 0 {
+		return nil
+	}
+	order := &g.Orders[i]
+	// Sizes are rounded to the lot size; what is left below this is dust.
+	dust := g.Size * 1e-9
+	size = min(size, g.Size-order.Filled)
+	if size <= dust {
+		return nil
+	}
+	order.Filled += size
+
+	side := "long"
+	if order.Side == "sell" {
+		side = "short"
+	}
+	if g.Size-order.Filled <= dust {
+		answer := GridOrder{Level: level + 1, Side: "sell"}
+		if order.Side == "sell" {
+			answer = GridOrder{Level: level - 1, Side: "buy"}
+		}
+		answer.Price = g.Levels[answer.Level]
+		g.Orders = append(slices.Delete(g.Orders, i, i+1), answer)
+	}
+
+	var closed []Position
+	for n := len(g.Lots); size > dust && n > 0 && g.Lots[n-1].Side != side; n = len(g.Lots) {
+		lot := &g.Lots[n-1]
+		part := *lot
+		part.Size = min(size, lot.Size)
+		part.Fees = lot.Fees * part.Size / lot.Size
+		lot.Size -= part.Size
+		lot.Fees -= part.Fees
+		if lot.Size <= dust {
+			g.Lots = g.Lots[:n-1]
+			g.RoundTrips++
+		}
+		part.ExitIndex = index
+		part.ExitTime = at
+		settlePosition(&part, price, part.Size*price*feePercent/100, "Grid")
+		g.GridProfit += part.PnL
+		closed = append(closed, part)
+		size -= part.Size
+	}
+	if size > dust {
+		g.Lots = append(g.Lots, Position{
+			EntryIndex: index,
+			EntryPrice: price,
+			EntryTime:  at,
+			Side:       side,
+			Size:       size,
+			Fees:       size * price * feePercent / 100,
+			IsOpen:     true,
+		})
+	}
+	return closed
+}
+
+// position is the grid's inventory as one position, nil when it holds none.
+// Lots are always on one side, since an opposite fill closes a lot first.
+func (g *GridState) position() *Position {
+	if len(g.Lots) == 0 {
+		return nil
+	}
+	position := g.Lots[0]
+	position.Size, position.Fees = 0, 0
+	notional := 0.0
+	for _, lot := range g.Lots {
+		position.Size += lot.Size
+		position.Fees += lot.Fees
+		notional += lot.Size * lot.EntryPrice
+	}
+	position.EntryPrice = notional / position.Size
+	return &position
+}
+
+// next returns the resting order that price moving from one point to the
+// next reaches first: the highest buy it falls through or the lowest sell it
+// rises through. As in simulateFill, price has to trade through a level.
+func (g *GridState) next(from, to float64) (GridOrder, bool) {
+	var best GridOrder
+	found := false
+	for _, order := range g.Orders {
+		switch {
+		case to < from && order.Side == "buy" && order.Price > to && order.Price <= from:
+			if !found || order.Price > best.Price {
+				best, found = order, true
+			}
+		case to > from && order.Side == "sell" && order.Price < to && order.Price >= from:
+			if !found || order.Price < best.Price {
+				best, found = order, true
+			}
+		}
+	}
+	return best, found
+}
+
+// GridStrategy keeps a ladder of resting limit orders between two prices.
+// Each level trades PositionSize. It never acts on candle signals; live, the
+// engine drives it through OrderTrader.
+type GridStrategy struct {
+	Config StrategyConfig
+	grid   GridConfig
+	state  *GridState
+	output *StrategyOutput
+}
+
+func NewGridStrategy(params map[string]any) (*GridStrategy, error) {
+	grid, err := buildGridConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	strategy := &GridStrategy{grid: grid}
+	strategy.Config = strategy.BuildConfig(params)
+	return strategy, nil
+}
+
+func (s *GridStrategy) GetName() string {
+	return "Grid"
+}
+
+// BuildConfig turns scaling off; each grid level already sizes its own
+// orders.
+func (s *GridStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	config := buildStrategyConfig(params)
+	config.Scaling.Mode = "off"
+	return config
+}
+
+func (s *GridStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) {
+	signals, _, err := s.simulate(candles)
+	return signals, err
+}
+
+func (s *GridStrategy) GetVisualizationData(candles hyperliquid.Candles) *StrategyOutput {
+	if _, _, err := s.simulate(candles); err != nil {
+		return nil
+	}
+	return s.output
+}
+
+func (s *GridStrategy) Run(candles hyperliquid.Candles) (*StrategyOutput, error) {
+	if _, _, err := s.simulate(candles); err != nil {
+		return nil, err
+	}
+	return s.output, nil
+}
+
+// Backtest trades the grid from the first candle's close, filling resting
+// orders at their level from each candle's High and Low.
+func (s *GridStrategy) Backtest(candles hyperliquid.Candles) (*BacktestOutput, error) {
+	signals, positions, err := s.simulate(candles)
+	if err != nil {
+		return nil, err
+	}
+	return &BacktestOutput{
+		TrendLines:         s.output.TrendLines,
+		TrendColors:        s.output.TrendColors,
+		Directions:         s.output.Directions,
+		Labels:             s.output.Labels,
+		Signals:            signals,
+		Positions:          positions,
+		Grid:               s.state,
+		StrategyName:       s.GetName(),
+		StrategyVersion:    "1.0",
+		PerformanceMetrics: calculatePerformance(positions),
+	}, nil
+}
+
+// LiveSignal never signals: the grid trades through its resting orders.
+func (s *GridStrategy) LiveSignal(candles hyperliquid.Candles) (*Signal, int, error) {
+	return nil, 0, nil
+}
+
+// simulate runs the grid over candles. Resting orders fill as candlePath
+// crosses them, at their level with the maker fee. Leaving the range stops the grid at the bound crossed.
+func (s *GridStrategy) simulate(candles hyperliquid.Candles) ([]Signal, []Position, error) {
+	n := len(candles)
+	s.output = &StrategyOutput{
+		TrendLines:  make([]float64, n),
+		TrendColors: make([]string, n),
+		Directions:  make([]int, n),
+		Labels:      []Label{},
+		Lines:       []TrendLine{},
+		FillColors:  make([]string, n),
+	}
+	signals, positions := []Signal{}, []Position{}
+	if n == 0 {
+		s.state = nil
+		return signals, positions, nil
+	}
+
+	start := parseFloat(candles[0].Close)
+	levels, err := s.grid.levels(start)
+	if err != nil {
+		return nil, nil, err
+	}
+	g := newGridState(levels, s.Config.PositionSize, start)
+	s.state = g
+	exec := s.Config.Execution
+
+	for i := 1; i < n && g.Stopped == ""; i++ {
+		candle := candles[i]
+		// Gaps between candles fill at the open, like any other move.
+		from := parseFloat(candles[i-1].Close)
+		for _, to := range candlePath(candle) {
+			for {
+				order, ok := g.next(from, to)
+				if !ok {
+					break
+				}
+				signal := Signal{Index: i, Type: SignalLong, Price: order.Price, Time: candle.Timestamp,
+					Reason: fmt.Sprintf("Grid %s %.2f", order.Side, order.Price)}
+				if order.Side == "sell" {
+					signal.Type = SignalShort
+				}
+				signals = append(signals, signal)
+				positions = append(positions, g.fill(order.Level, g.Size, i, candle.Timestamp, order.Price, exec.feePercent(true))...)
+			}
+			if to > g.upper() || to < g.lower() {
+				bound := g.lower()
+				if to > g.upper() {
+					bound = g.upper()
+				}
+				signals = append(signals, Signal{Index: i, Type: SignalClose, Price: bound, Time: candle.Timestamp, Reason: "Range Exit"})
+				g.Orders = []GridOrder{}
+				g.Stopped = "Range Exit"
+				if s.grid.Exit == "flatten" {
+					if position := s.closeLots(g, i, candle.Timestamp, bound, exec.feePercent(false), "Range Exit"); position != nil {
+						positions = append(positions, *position)
+					}
+				}
+				break
+			}
+			from = to
+		}
+	}
+
+	last := candles[n-1]
+	if position := s.closeLots(g, n-1, last.Timestamp, parseFloat(last.Close), exec.feePercent(false), "End of Period"); position != nil {
+		positions = append(positions, *position)
+	}
+	return signals, positions, nil
+}
+
+// closeLots closes the grid's inventory as one position, as a live market
+// close would.
+func (s *GridStrategy) closeLots(g *GridState, index int, at int64, price, feePercent float64, reason string) *Position {
+	position := g.position()
+	if position == nil {
+		return nil
+	}
+	g.Lots = []Position{}
+	position.ExitIndex = index
+	position.ExitTime = at
+	settlePosition(position, price, position.Size*price*feePercent/100, reason)
+	return position
+}
+
+// Trade places the grid on the first call, then on every engine tick picks
+// up fills, answers them and re-places orders the exchange dropped. Once the
+// price leaves the range the orders are cancelled and, with the flatten
+// exit, the inventory is closed; the grid then stays stopped. Trade reports
+// whether the grid changed so the engine can save it.
+func (s *GridStrategy) Trade(live *LiveStrategy, candles hyperliquid.Candles) (bool, error) {
+	if len(candles) == 0 {
+		return false, nil
+	}
+	price := parseFloat(candles[len(candles)-1].Close)
+	changed := false
+	if live.Grid == nil {
+		g, err := s.newLiveGrid(live, price)
+		if err != nil {
+			return false, err
+		}
+		live.Grid = g
+		changed = true
+		live.publish(EventSignal, StrategyEvent{Price: price}, "grid of %d levels from %.2f to %.2f, %.4f %s per level",
+			len(g.Levels), g.lower(), g.upper(), g.Size, live.Symbol)
+	}
+	g := live.Grid
+	if g.Stopped != "" {
+		return changed, nil
+	}
+
+	filled, err := s.checkFills(live)
+	if err != nil {
+		return changed || filled, err
+	}
+	if price < g.lower() || price > g.upper() {
+		s.exitRange(live, price)
+		return true, nil
+	}
+	placed, err := s.placeOrders(live)
+	return changed || filled || placed, err
+}
+
+// CancelOrders cancels the grid's resting orders.
+func (s *GridStrategy) CancelOrders(live *LiveStrategy) {
+	if live.Grid == nil {
+		return
+	}
+	for i := range live.Grid.Orders {
+		order := &live.Grid.Orders[i]
+		if order.OrderID == 0 {
+			continue
+		}
+		if err := live.account.CancelOrder(live.Symbol, order.OrderID); err != nil {
+			live.publish(EventError, StrategyEvent{Price: order.Price, OrderID: strconv.FormatInt(order.OrderID, 10)}, "%v", err)
+			continue
+		}
+		order.OrderID = 0
+	}
+}
+
+// newLiveGrid lays the grid out at the exchange's tick and lot sizes.
+func (s *GridStrategy) newLiveGrid(live *LiveStrategy, price float64) (*GridState, error) {
+	levels, err := s.grid.levels(price)
+	if err != nil {
+		return nil, err
+	}
+	for i, level := range levels {
+		if levels[i], err = live.account.roundPrice(live.Symbol, level); err != nil {
+			return nil, err
+		}
+		if i > 0 && levels[i] <= levels[i-1] {
+			return nil, fmt.Errorf("grid levels are closer than the %s tick size", live.Symbol)
+		}
+	}
+	size, err := live.account.roundSize(live.Symbol, s.Config.PositionSize)
+	if err != nil {
+		return nil, err
+	}
+	return newGridState(levels, size, price), nil
+}
+
+// gridFill is size more of a grid order traded.
+type gridFill struct {
+	order GridOrder
+	size  float64
+}
+
+// checkFills applies what the grid's orders traded since the last check:
+// partial fills of open orders, and the rest of orders that are no longer
+// open. Fills are applied buys from the top down and sells from the bottom
+// up, as price would have reached them. Orders cancelled by the exchange are
+// placed again for their unfilled size.
+func (s *GridStrategy) checkFills(live *LiveStrategy) (bool, error) {
+	g := live.Grid
+	open, err := live.account.openOrderSizes(live.Symbol)
+	if err != nil {
+		return false, err
+	}
+	var fills []gridFill
+	changed := false
+	for i := range g.Orders {
+		order := &g.Orders[i]
+		if order.OrderID == 0 {
+			continue
+		}
+		// The unfilled size of an order is the level's size less everything
+		// traded on the level, whichever order traded it.
+		remaining, isOpen := open[order.OrderID]
+		if !isOpen {
+			status, left, err := live.account.orderStatus(order.OrderID)
+			if err != nil {
+				return changed, err
+			}
+			switch status {
+			case hyperliquid.OrderStatusValueFilled:
+				remaining = 0
+			case hyperliquid.OrderStatusValueOpen:
+				isOpen, remaining = true, left
+			default:
+				remaining = left
+				live.publish(EventOrderRejected, StrategyEvent{Price: order.Price, Size: left, OrderID: strconv.FormatInt(order.OrderID, 10)},
+					"grid %s @ %.2f %s, placing it again", order.Side, order.Price, status)
+				order.OrderID = 0
+				changed = true
+			}
+		}
+		if traded := g.Size - remaining - order.Filled; traded > g.Size*1e-9 {
+			fills = append(fills, gridFill{*order, traded})
+		}
+	}
+	slices.SortFunc(fills, func(a, b gridFill) int {
+		if a.order.Side != b.order.Side {
+			if a.order.Side == "buy" {
+				return -1
+			}
+			return 1
+		}
+		if a.order.Side == "buy" {
+			return b.order.Level - a.order.Level
+		}
+		return a.order.Level - b.order.Level
+	})
+	for _, f := range fills {
+		s.applyFill(live, f.order, f.size, f.order.Price, true)
+	}
+	return changed || len(fills) > 0, nil
+}
+
+// placeOrders places every order not on the exchange in one bulk order, so
+// the batch passes the risk checks and the order rate limit as one action.
+func (s *GridStrategy) placeOrders(live *LiveStrategy) (bool, error) {
+	g := live.Grid
+	var pending []GridOrder
+	var tickets []OrderTicket
+	for _, order := range g.Orders {
+		if order.OrderID != 0 {
+			continue
+		}
+		size, err := live.account.roundSize(live.Symbol, g.Size-order.Filled)
+		if err != nil {
+			return false, err
+		}
+		pending = append(pending, order)
+		tickets = append(tickets, OrderTicket{
+			Coin:  live.Symbol,
+			IsBuy: order.Side == "buy",
+			Kind:  OrderLimit,
+			Size:  size,
+			Price: order.Price,
+			Tif:   "gtc",
+		})
+	}
+	if len(tickets) == 0 {
+		return false, nil
+	}
+	responses, err := live.account.PlaceOrders(tickets)
+	if err != nil {
+		return false, fmt.Errorf("grid orders: %w", err)
+	}
+
+	changed := false
+	var firstErr error
+	for k, resp := range responses {
+		order, size := pending[k], tickets[k].Size
+		if !resp.Success {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("grid %s @ %.2f: %s", order.Side, order.Price, resp.Message)
+			}
+			continue
+		}
+		changed = true
+		if resp.Status == "filled" {
+			price := order.Price
+			if resp.AvgPrice > 0 {
+				price = resp.AvgPrice
+			}
+			s.applyFill(live, order, size, price, false)
+			continue
+		}
+		i := slices.IndexFunc(g.Orders, func(o GridOrder) bool { return o.Level == order.Level })
+		if i < 0 {
+			continue
+		}
+		g.Orders[i].OrderID, _ = strconv.ParseInt(resp.OrderID, 10, 64)
+		live.publish(EventOrderPlaced, StrategyEvent{Price: order.Price, Size: size, Side: order.Side, OrderID: resp.OrderID},
+			"grid %s %.4f %s @ %.2f", order.Side, size, live.Symbol, order.Price)
+	}
+	return changed, firstErr
+}
+
+// applyFill records size of a grid order traded at price. Lots it closes
+// are round trips, or parts of them, and go to the trade journal.
+func (s *GridStrategy) applyFill(live *LiveStrategy, order GridOrder, size, price float64, maker bool) {
+	g := live.Grid
+	event := StrategyEvent{Price: price, Size: size, Side: order.Side}
+	if order.OrderID != 0 {
+		event.OrderID = strconv.FormatInt(order.OrderID, 10)
+	}
+	lots := g.fill(order.Level, size, 0, time.Now().UnixMilli(), price, s.Config.Execution.feePercent(maker))
+	if filled := order.Filled + size; filled < g.Size*(1-1e-9) {
+		live.publish(EventOrderFilled, event, "grid %s partially filled %.4f of %.4f @ %.2f", order.Side, filled, g.Size, price)
+	} else {
+		live.publish(EventOrderFilled, event, "grid %s filled %.4f @ %.2f", order.Side, size, price)
+	}
+	for _, lot := range lots {
+		live.Trades = append(live.Trades, lot)
+		event.OrderID, event.Size = "", lot.Size
+		live.publish(EventPositionClosed, event, "grid round trip %.2f to %.2f, pnl %.2f, grid profit %.2f",
+			lot.EntryPrice, lot.ExitPrice, lot.PnL, g.GridProfit)
+	}
+	s.syncPosition(live)
+}
+
+// syncPosition mirrors the grid's inventory in the strategy's position so
+// marking, closing and the UI treat it like any other position.
+func (s *GridStrategy) syncPosition(live *LiveStrategy) {
+	position := live.Grid.position()
+	current := live.Position
+	switch {
+	case position == nil:
+		if current != nil && current.IsOpen {
+			live.Position = nil
+			live.UnrealizedPnL = 0
+		}
+	case current != nil && current.IsOpen && current.Side == position.Side:
+		current.Size, current.EntryPrice, current.Fees = position.Size, position.EntryPrice, position.Fees
+	default:
+		live.Position = position
+	}
+}
+
+func (s *GridStrategy) exitRange(live *LiveStrategy, price float64) {
+	g := live.Grid
+	live.publish(EventSignal, StrategyEvent{Price: price}, "price %.2f left the grid %.2f-%.2f", price, g.lower(), g.upper())
+	s.CancelOrders(live)
+	g.Orders = []GridOrder{}
+	g.Stopped = "Range Exit"
+	if s.grid.Exit == "flatten" && live.Position != nil && live.Position.IsOpen {
+		live.ClosePosition("Range Exit")
+		if !live.Position.IsOpen {
+			g.Lots = []Position{}
+		}
+	}
+}
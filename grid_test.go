@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// newLiveGrid starts a grid of levels around the fake's mid on BTC, with
+// every level trading 0.2.
+func newLiveGrid(t *testing.T, fake *fakeExchange, levels int, risk *RiskManager) (*GridStrategy, *LiveStrategy) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	account := fake.account(ctx)
+	account.SetRiskManager(risk)
+	params := map[string]any{"gridLevels": float64(levels), "rangePercent": 10.0, "positionSize": 0.2}
+	s, err := NewGridStrategy(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := NewLiveStrategy("grid", s, params)
+	live.Symbol, live.Interval = "BTC", "1m"
+	live.AccountID, live.account = account.ID(), account
+	return s, live
+}
+
+// tradeGrid runs one engine tick of the grid at the fake's mid.
+func tradeGrid(t *testing.T, s *GridStrategy, live *LiveStrategy) {
+	t.Helper()
+	mid := strconv.FormatFloat(fakeMid, 'f', -1, 64)
+	if _, err := s.Trade(live, hyperliquid.Candles{{Close: mid}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gridOrder(t *testing.T, g *GridState, level int) GridOrder {
+	t.Helper()
+	i := slices.IndexFunc(g.Orders, func(o GridOrder) bool { return o.Level == level })
+	if i < 0 {
+		t.Fatalf("no order on level %d in %+v", level, g.Orders)
+	}
+	return g.Orders[i]
+}
+
+func TestGridPlacesLevelsInOneAction(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	s, live := newLiveGrid(t, fake, 41, NewRiskManager(RiskLimits{MaxOrdersPerMinute: 5}))
+
+	tradeGrid(t, s, live)
+	if n := fake.bulkCount(); n != 1 {
+		t.Errorf("grid placed in %d order actions, want 1", n)
+	}
+	if n := len(fake.restingOrders()); n != 40 {
+		t.Errorf("%d resting orders, want 40", n)
+	}
+	for _, order := range live.Grid.Orders {
+		if order.OrderID == 0 {
+			t.Errorf("order on level %d was not placed", order.Level)
+		}
+	}
+}
+
+func TestGridPartialFills(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	s, live := newLiveGrid(t, fake, 11, nil)
+	tradeGrid(t, s, live)
+	g := live.Grid
+	// The grid runs from 90 to 110 in steps of 2, empty at 100 on level 5.
+	buy := gridOrder(t, g, 4)
+
+	fake.fillOrder(buy.OrderID, 0.08)
+	tradeGrid(t, s, live)
+	if got := gridOrder(t, g, 4); got.Side != "buy" || math.Abs(got.Filled-0.08) > 1e-9 || got.OrderID != buy.OrderID {
+		t.Errorf("partly filled order = %+v, want the buy resting with 0.08 filled", got)
+	}
+	if slices.ContainsFunc(g.Orders, func(o GridOrder) bool { return o.Level == 5 }) {
+		t.Error("the grid answered a partial fill")
+	}
+	if live.Position == nil || live.Position.Side != "long" || math.Abs(live.Position.Size-0.08) > 1e-9 {
+		t.Errorf("position = %+v, want long 0.08", live.Position)
+	}
+
+	// The exchange cancels the rest, which is placed again for 0.12.
+	fake.cancelOrder(buy.OrderID)
+	tradeGrid(t, s, live)
+	again := gridOrder(t, g, 4)
+	if again.OrderID == 0 || again.OrderID == buy.OrderID || math.Abs(again.Filled-0.08) > 1e-9 {
+		t.Fatalf("re-placed order = %+v", again)
+	}
+	if resting := fake.restingOrders()[98]; resting != again.OrderID {
+		t.Errorf("resting order at 98 is %d, want %d", resting, again.OrderID)
+	}
+
+	fake.fillOrder(again.OrderID, 0.12)
+	tradeGrid(t, s, live)
+	if slices.ContainsFunc(g.Orders, func(o GridOrder) bool { return o.Level == 4 }) {
+		t.Error("the filled buy is still on the grid")
+	}
+	if sell := gridOrder(t, g, 5); sell.Side != "sell" || sell.OrderID == 0 {
+		t.Errorf("answer = %+v, want a placed sell on level 5", sell)
+	}
+	if live.Position == nil || math.Abs(live.Position.Size-0.2) > 1e-9 {
+		t.Errorf("position = %+v, want long 0.2", live.Position)
+	}
+}
+
+func TestGridStatePartialFill(t *testing.T) {
+	g := newGridState([]float64{90, 95, 100, 105, 110}, 1, 100)
+	g.fill(1, 1, 0, 0, 95, 0)
+	g.fill(0, 0.5, 1, 0, 90, 0)
+	if len(g.Lots) != 2 {
+		t.Fatalf("lots = %+v", g.Lots)
+	}
+
+	// The sell answering the buy at 95 partly fills on level 2, closing part
+	// of the newest lot, the half bought at 90.
+	closed := g.fill(2, 0.3, 2, 0, 100, 0)
+	if len(closed) != 1 || closed[0].EntryPrice != 90 || math.Abs(closed[0].Size-0.3) > 1e-9 || math.Abs(closed[0].PnL-3) > 1e-9 {
+		t.Errorf("closed = %+v, want 0.3 bought at 90 with a pnl of 3", closed)
+	}
+	if g.RoundTrips != 0 || math.Abs(g.Lots[1].Size-0.2) > 1e-9 {
+		t.Errorf("round trips %d, lots %+v", g.RoundTrips, g.Lots)
+	}
+
+	// The rest of the sell closes the half lot and part of the one at 95.
+	closed = g.fill(2, 0.7, 3, 0, 100, 0)
+	if len(closed) != 2 || closed[1].EntryPrice != 95 || math.Abs(closed[1].Size-0.5) > 1e-9 {
+		t.Errorf("closed = %+v", closed)
+	}
+	if g.RoundTrips != 1 || len(g.Lots) != 1 || math.Abs(g.Lots[0].Size-0.5) > 1e-9 {
+		t.Errorf("round trips %d, lots %+v", g.RoundTrips, g.Lots)
+	}
+	if math.Abs(g.GridProfit-7.5) > 1e-9 {
+		t.Errorf("grid profit %.4f, want 7.5", g.GridProfit)
+	}
+	if buy := slices.IndexFunc(g.Orders, func(o GridOrder) bool { return o.Level == 1 && o.Side == "buy" }); buy < 0 {
+		t.Errorf("orders %+v, want the sell answered by a buy on level 1", g.Orders)
+	}
+}
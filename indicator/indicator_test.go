@@ -0,0 +1,85 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+)
+
+// The fixture is 33 hourly bars whose closes are Wilder's classic RSI example
+// series; the bars cross UTC midnight before bar 5 and bar 29. The expected
+// values in the tests come from a direct port of TradingView's reference
+// implementations of the ta.* functions, rounded to eight decimals.
+var (
+	closes = []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83,
+		45.1, 45.42, 45.84, 46.08, 45.89, 46.03,
+		45.61, 46.28, 46.28, 46, 46.03, 46.41,
+		46.22, 45.64, 46.21, 46.25, 45.71, 46.45,
+		45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+		43.42, 42.66, 43.13,
+	}
+	highs = []float64{
+		44.55, 44.44, 44.27, 44.01, 44.51, 45.1,
+		45.19, 45.75, 46.09, 46.22, 46.27, 46.25,
+		45.92, 46.45, 46.57, 46.11, 46.39, 46.65,
+		46.41, 46.06, 46.36, 46.53, 46.05, 46.58,
+		46.04, 45.74, 44.23, 44.5, 44.38, 44.8,
+		43.79, 42.96, 43.23,
+	}
+	lows = []float64{
+		44.16, 43.97, 43.85, 43.39, 43.98, 44.69,
+		44.83, 45.23, 45.43, 45.85, 45.73, 45.7,
+		45.36, 45.99, 46.17, 45.62, 45.83, 46.15,
+		45.88, 45.51, 45.9, 46.08, 45.43, 46.09,
+		45.57, 45.2, 43.63, 43.94, 43.9, 44.38,
+		43.3, 42.39, 42.78,
+	}
+	volumes = []float64{
+		1200, 900, 1500, 1100, 1300, 1700,
+		800, 1400, 1600, 1000, 1250, 950,
+		1800, 1150, 1050, 1350, 1450, 1550,
+		1650, 850, 1750, 1225, 975, 1325,
+		1425, 1525, 1625, 1725, 1825, 1925,
+		2025, 2125, 2225,
+	}
+	times = hourly(1699989200000, len(closes))
+)
+
+// tooLong is a length no indicator can fill from the fixture.
+const tooLong = 40
+
+var nan = math.NaN()
+
+func hourly(start int64, n int) []int64 {
+	out := make([]int64, n)
+	for i := range out {
+		out[i] = start + int64(i)*3_600_000
+	}
+	return out
+}
+
+// assertSeries compares got with want to 1e-6; a NaN only matches a NaN.
+func assertSeries(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s has %d values, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.IsNaN(got[i]) != math.IsNaN(want[i]) || math.Abs(got[i]-want[i]) > 1e-6 {
+			t.Errorf("%s[%d] = %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+// assertAllNaN checks a series computed with a length longer than its input.
+func assertAllNaN(t *testing.T, name string, got []float64, n int) {
+	t.Helper()
+	if len(got) != n {
+		t.Fatalf("%s has %d values, want %d", name, len(got), n)
+	}
+	for i, v := range got {
+		if !math.IsNaN(v) {
+			t.Errorf("%s[%d] = %v, want NaN", name, i, v)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package indicator
+
+import "math"
+
+// SMA is the simple moving average.
+func SMA(src []float64, length int) []float64 {
+	return replay(src, NewSMAStream(length).Update)
+}
+
+// EMA is the exponential moving average with alpha 2/(length+1).
+func EMA(src []float64, length int) []float64 {
+	return replay(src, NewEMAStream(length).Update)
+}
+
+// RMA is Wilder's moving average (alpha 1/length), used by RSI, ATR and ADX.
+func RMA(src []float64, length int) []float64 {
+	return replay(src, NewRMAStream(length).Update)
+}
+
+// WMA is the linearly weighted moving average; the newest bar has weight
+// length.
+func WMA(src []float64, length int) []float64 {
+	return replay(src, NewWMAStream(length).Update)
+}
+
+// HMA is the Hull moving average: WMA(2*WMA(src, length/2) - WMA(src,
+// length), floor(sqrt(length))).
+func HMA(src []float64, length int) []float64 {
+	return replay(src, NewHMAStream(length).Update)
+}
+
+// Stdev is the population standard deviation over length bars, as Pine's
+// ta.stdev with biased=true.
+func Stdev(src []float64, length int) []float64 {
+	out := nanSeries(len(src))
+	if length < 1 {
+		return out
+	}
+	mean := SMA(src, length)
+	for i := length - 1; i < len(src); i++ {
+		if math.IsNaN(mean[i]) {
+			continue
+		}
+		sum := 0.0
+		for _, v := range src[i-length+1 : i+1] {
+			sum += (v - mean[i]) * (v - mean[i])
+		}
+		out[i] = math.Sqrt(sum / float64(length))
+	}
+	return out
+}
+
+// SMAStream is the streaming SMA.
+type SMAStream struct {
+	sums  windowSums
+	value float64
+}
+
+func NewSMAStream(length int) *SMAStream {
+	return &SMAStream{sums: newWindowSums(length), value: math.NaN()}
+}
+
+func (s *SMAStream) Update(v float64) float64 {
+	s.value = math.NaN()
+	if s.sums.update(v) {
+		s.value = s.sums.sum / float64(s.sums.length)
+	}
+	return s.value
+}
+
+func (s *SMAStream) Value() float64 { return s.value }
+
+// Clone returns an independent copy, so a caller can evaluate an unfinished
+// bar without committing it.
+func (s *SMAStream) Clone() *SMAStream {
+	return &SMAStream{sums: s.sums.clone(), value: s.value}
+}
+
+// SmoothedStream is the streaming EMA or RMA. Like Pine it is seeded with the
+// SMA whenever the previous value is missing.
+type SmoothedStream struct {
+	alpha float64
+	seed  *SMAStream
+	value float64
+}
+
+func NewEMAStream(length int) *SmoothedStream {
+	return &SmoothedStream{alpha: 2 / float64(length+1), seed: NewSMAStream(length), value: math.NaN()}
+}
+
+func NewRMAStream(length int) *SmoothedStream {
+	return &SmoothedStream{alpha: 1 / float64(length), seed: NewSMAStream(length), value: math.NaN()}
+}
+
+func (s *SmoothedStream) Update(v float64) float64 {
+	seed := s.seed.Update(v)
+	switch {
+	case math.IsNaN(s.value):
+		s.value = seed
+	case math.IsNaN(v):
+		s.value = math.NaN()
+	default:
+		s.value = s.alpha*v + (1-s.alpha)*s.value
+	}
+	return s.value
+}
+
+func (s *SmoothedStream) Value() float64 { return s.value }
+
+func (s *SmoothedStream) Clone() *SmoothedStream {
+	return &SmoothedStream{alpha: s.alpha, seed: s.seed.Clone(), value: s.value}
+}
+
+// WMAStream is the streaming WMA. Each bar every weight drops by one, so the
+// weighted sum loses the plain sum of the window and gains length times the
+// new value.
+type WMAStream struct {
+	sums  windowSums
+	value float64
+}
+
+func NewWMAStream(length int) *WMAStream {
+	return &WMAStream{sums: newWindowSums(length), value: math.NaN()}
+}
+
+func (s *WMAStream) Update(v float64) float64 {
+	s.value = math.NaN()
+	if s.sums.update(v) {
+		n := float64(s.sums.length)
+		s.value = s.sums.weighted / (n * (n + 1) / 2)
+	}
+	return s.value
+}
+
+func (s *WMAStream) Value() float64 { return s.value }
+
+func (s *WMAStream) Clone() *WMAStream {
+	return &WMAStream{sums: s.sums.clone(), value: s.value}
+}
+
+// HMAStream is the streaming HMA.
+type HMAStream struct {
+	half, full, smooth *WMAStream
+	value              float64
+}
+
+func NewHMAStream(length int) *HMAStream {
+	if length < 2 {
+		length = 0
+	}
+	return &HMAStream{
+		half:   NewWMAStream(length / 2),
+		full:   NewWMAStream(length),
+		smooth: NewWMAStream(int(math.Sqrt(float64(length)))),
+		value:  math.NaN(),
+	}
+}
+
+func (s *HMAStream) Update(v float64) float64 {
+	s.value = s.smooth.Update(2*s.half.Update(v) - s.full.Update(v))
+	return s.value
+}
+
+func (s *HMAStream) Value() float64 { return s.value }
+
+func (s *HMAStream) Clone() *HMAStream {
+	return &HMAStream{half: s.half.Clone(), full: s.full.Clone(), smooth: s.smooth.Clone(), value: s.value}
+}
+
+// StdevStream is the streaming Stdev. It keeps a running sum of squares, so
+// it can differ from Stdev in the last few digits.
+type StdevStream struct {
+	sums  windowSums
+	value float64
+}
+
+func NewStdevStream(length int) *StdevStream {
+	return &StdevStream{sums: newWindowSums(length), value: math.NaN()}
+}
+
+func (s *StdevStream) Update(v float64) float64 {
+	s.value = math.NaN()
+	if s.sums.update(v) {
+		s.value = math.Sqrt(s.sums.variance())
+	}
+	return s.value
+}
+
+func (s *StdevStream) Value() float64 { return s.value }
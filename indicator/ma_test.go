@@ -0,0 +1,79 @@
+package indicator
+
+import "testing"
+
+var movingAverages = []struct {
+	name   string
+	fn     func([]float64, int) []float64
+	length int
+	want   []float64
+}{
+	{"SMA", SMA, 5, []float64{
+		nan, nan, nan, nan, 44.104, 44.202,
+		44.404, 44.658, 45.104, 45.454, 45.666, 45.852,
+		45.89, 45.978, 46.018, 46.04, 46.04, 46.2,
+		46.188, 46.06, 46.102, 46.146, 46.006, 46.052,
+		46.08, 45.908, 45.464, 45.158, 44.712, 44.47,
+		44.084, 43.81, 43.6,
+	}},
+	{"EMA", EMA, 5, []float64{
+		nan, nan, nan, nan, 44.104, 44.346,
+		44.59733333, 44.87155556, 45.19437037, 45.48958025, 45.6230535, 45.75870233,
+		45.70913489, 45.89942326, 46.02628217, 46.01752145, 46.02168097, 46.15112064,
+		46.17408043, 45.99605362, 46.06736908, 46.12824605, 45.9888307, 46.1425538,
+		46.02170253, 45.79780169, 45.20853446, 44.86568964, 44.65045976, 44.62363984,
+		44.22242656, 43.70161771, 43.51107847,
+	}},
+	{"RMA", RMA, 5, []float64{
+		nan, nan, nan, nan, 44.104, 44.2492,
+		44.41936, 44.619488, 44.8635904, 45.10687232, 45.26349786, 45.41679828,
+		45.45543863, 45.6203509, 45.75228072, 45.80182458, 45.84745966, 45.95996773,
+		46.01197418, 45.93757935, 45.99206348, 46.04365078, 45.97692063, 46.0715365,
+		46.0132292, 45.88058336, 45.51046669, 45.24437335, 45.03949868, 44.94559894,
+		44.64047916, 44.24438332, 44.02150666,
+	}},
+	{"WMA", WMA, 5, []float64{
+		nan, nan, nan, nan, 44.07066667, 44.31266667,
+		44.612, 44.95066667, 45.34466667, 45.67, 45.81533333, 45.93666667,
+		45.856, 45.986, 46.08666667, 46.08066667, 46.07733333, 46.20066667,
+		46.20733333, 46.02466667, 46.07466667, 46.124, 45.97866667, 46.12666667,
+		46.036, 45.79266667, 45.16666667, 44.73866667, 44.426, 44.37866667,
+		44.02866667, 43.554, 43.32733333,
+	}},
+	{"HMA", HMA, 9, []float64{
+		nan, nan, nan, nan, nan, nan,
+		nan, nan, nan, nan, 46.29074074, 46.33951852,
+		46.15662963, 46.10355556, 46.16092593, 46.19459259, 46.17955556, 46.22233333,
+		46.2612963, 46.11762963, 46.046, 46.05103704, 45.98259259, 46.08688889,
+		46.04255556, 45.81192593, 45.11540741, 44.36692593, 43.85462963, 43.79396296,
+		43.68366667, 43.26651852, 42.88111111,
+	}},
+}
+
+func TestMovingAverages(t *testing.T) {
+	for _, tt := range movingAverages {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSeries(t, tt.name, tt.fn(closes, tt.length), tt.want)
+		})
+	}
+}
+
+func TestMovingAveragesEdgeCases(t *testing.T) {
+	for _, tt := range movingAverages {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(nil, tt.length); len(got) != 0 {
+				t.Errorf("empty input gave %v", got)
+			}
+			assertAllNaN(t, tt.name, tt.fn(closes, tooLong), len(closes))
+		})
+	}
+}
+
+func TestMovingAverageNaNInput(t *testing.T) {
+	src := append([]float64(nil), closes[:10]...)
+	src[6] = nan
+	got := SMA(src, 3)
+	want := []float64{nan, nan, (src[0] + src[1] + src[2]) / 3, (src[1] + src[2] + src[3]) / 3,
+		(src[2] + src[3] + src[4]) / 3, (src[3] + src[4] + src[5]) / 3, nan, nan, nan, (src[7] + src[8] + src[9]) / 3}
+	assertSeries(t, "SMA", got, want)
+}
@@ -0,0 +1,103 @@
+package indicator
+
+import "math"
+
+// RSI is the relative strength index using Wilder's smoothing.
+func RSI(src []float64, length int) []float64 {
+	return replay(src, NewRSIStream(length).Update)
+}
+
+// MACD returns the MACD line (fast EMA minus slow EMA), its signal EMA and
+// the histogram.
+func MACD(src []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	macd = combine(EMA(src, fast), EMA(src, slow), func(f, s float64) float64 { return f - s })
+	signalLine = EMA(macd, signal)
+	histogram = combine(macd, signalLine, func(m, s float64) float64 { return m - s })
+	return macd, signalLine, histogram
+}
+
+// Stochastic returns %K (the raw stochastic smoothed over smoothK bars) and
+// %D (%K smoothed over smoothD bars).
+func Stochastic(close, high, low []float64, length, smoothK, smoothD int) (k, d []float64) {
+	stream := NewStochasticStream(length, smoothK, smoothD)
+	k = make([]float64, len(close))
+	d = make([]float64, len(close))
+	for i := range close {
+		k[i], d[i] = stream.Update(close[i], high[i], low[i])
+	}
+	return k, d
+}
+
+// RSIStream is the streaming RSI.
+type RSIStream struct {
+	prev     float64
+	up, down *SmoothedStream
+	value    float64
+}
+
+func NewRSIStream(length int) *RSIStream {
+	return &RSIStream{prev: math.NaN(), up: NewRMAStream(length), down: NewRMAStream(length), value: math.NaN()}
+}
+
+func (s *RSIStream) Update(v float64) float64 {
+	change := v - s.prev
+	s.prev = v
+	// math.Max and math.Min keep a NaN change NaN.
+	up := s.up.Update(math.Max(change, 0))
+	down := s.down.Update(-math.Min(change, 0))
+	switch {
+	case math.IsNaN(up) || math.IsNaN(down):
+		s.value = math.NaN()
+	case down == 0:
+		s.value = 100
+	case up == 0:
+		s.value = 0
+	default:
+		s.value = 100 - 100/(1+up/down)
+	}
+	return s.value
+}
+
+func (s *RSIStream) Value() float64 { return s.value }
+
+// MACDStream is the streaming MACD.
+type MACDStream struct {
+	fast, slow, signal *SmoothedStream
+}
+
+func NewMACDStream(fast, slow, signal int) *MACDStream {
+	return &MACDStream{fast: NewEMAStream(fast), slow: NewEMAStream(slow), signal: NewEMAStream(signal)}
+}
+
+func (s *MACDStream) Update(v float64) (macd, signal, histogram float64) {
+	macd = s.fast.Update(v) - s.slow.Update(v)
+	signal = s.signal.Update(macd)
+	return macd, signal, macd - signal
+}
+
+// StochasticStream is the streaming Stochastic.
+type StochasticStream struct {
+	highest, lowest *ExtremeStream
+	k, d            *SMAStream
+}
+
+func NewStochasticStream(length, smoothK, smoothD int) *StochasticStream {
+	return &StochasticStream{
+		highest: NewHighestStream(length),
+		lowest:  NewLowestStream(length),
+		k:       NewSMAStream(smoothK),
+		d:       NewSMAStream(smoothD),
+	}
+}
+
+func (s *StochasticStream) Update(close, high, low float64) (k, d float64) {
+	highest := s.highest.Update(high)
+	lowest := s.lowest.Update(low)
+	// A flat window divides by zero, which is NaN as in Pine.
+	raw := math.NaN()
+	if rng := highest - lowest; rng != 0 {
+		raw = 100 * (close - lowest) / rng
+	}
+	k = s.k.Update(raw)
+	return k, s.d.Update(k)
+}
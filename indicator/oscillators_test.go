@@ -0,0 +1,93 @@
+package indicator
+
+import "testing"
+
+func TestRSI(t *testing.T) {
+	assertSeries(t, "RSI", RSI(closes, 14), []float64{
+		nan, nan, nan, nan, nan, nan,
+		nan, nan, nan, nan, nan, nan,
+		nan, nan, 70.46413502, 66.24961855, 66.48094183, 69.34685316,
+		66.29471266, 57.91502067, 62.88071831, 63.20878872, 56.01158479, 62.33992931,
+		54.67097138, 50.3868152, 40.01942379, 41.4926354, 41.90242968, 45.49949724,
+		37.32277831, 33.09048257, 37.78877198,
+	})
+}
+
+func TestMACD(t *testing.T) {
+	macd, signal, histogram := MACD(closes, 3, 6, 4)
+	assertSeries(t, "MACD", macd, []float64{
+		nan, nan, nan, nan, nan, 0.24791667,
+		0.31145833, 0.35822917, 0.41375744, 0.42590933, 0.32869082, 0.27701409,
+		0.12898467, 0.20126207, 0.1983237, 0.10894233, 0.06788579, 0.12495334,
+		0.08676985, -0.06354852, 0.01398756, 0.04825223, -0.06211785, 0.06590967,
+		-0.04135331, -0.16589676, -0.46953417, -0.47875693, -0.4050855, -0.24590492,
+		-0.40035401, -0.56117811, -0.4377327,
+	})
+	assertSeries(t, "signal", signal, []float64{
+		nan, nan, nan, nan, nan, nan,
+		nan, nan, 0.3328404, 0.37006797, 0.35351711, 0.3229159,
+		0.24534341, 0.22771087, 0.21595601, 0.17315053, 0.13104464, 0.12860812,
+		0.11187281, 0.04170428, 0.03061759, 0.03767145, -0.00224427, 0.0250173,
+		-0.00153094, -0.06727727, -0.22818003, -0.32841079, -0.35908068, -0.31381037,
+		-0.34842783, -0.43352794, -0.43520985,
+	})
+	assertSeries(t, "histogram", histogram, []float64{
+		nan, nan, nan, nan, nan, nan,
+		nan, nan, 0.08091704, 0.05584136, -0.02482629, -0.04590181,
+		-0.11635874, -0.0264488, -0.0176323, -0.06420821, -0.06315885, -0.00365478,
+		-0.02510296, -0.1052528, -0.01663003, 0.01058078, -0.05987358, 0.04089237,
+		-0.03982237, -0.09861949, -0.24135414, -0.15034614, -0.04600483, 0.06790545,
+		-0.05192618, -0.12765017, -0.00252286,
+	})
+}
+
+func TestStochastic(t *testing.T) {
+	k, d := Stochastic(closes, highs, lows, 5, 3, 3)
+	assertSeries(t, "%K", k, []float64{
+		nan, nan, nan, nan, nan, nan,
+		86.74833636, 88.40915849, 89.72286931, 88.33942704, 84.20414769, 80.46128708,
+		59.33557184, 62.93309137, 62.63641835, 71.10976319, 61.43250689, 61.65449731,
+		63.44111905, 48.78498836, 43.68648158, 45.90643275, 50.59011164, 59.68749278,
+		48.19499341, 43.33333333, 18.28788995, 14.35765168, 18.89490588, 29.22505288,
+		25.67703028, 21.25102751, 16.6362379,
+	})
+	assertSeries(t, "%D", d, []float64{
+		nan, nan, nan, nan, nan, nan,
+		nan, nan, 88.29345472, 88.82381828, 87.42214801, 84.33495394,
+		74.6670022, 67.5766501, 61.63502719, 65.55975764, 65.05956281, 64.7322558,
+		62.17604108, 57.96020157, 51.97086299, 46.12596756, 46.72767532, 52.06134572,
+		52.82419928, 50.40527317, 36.60540557, 25.32629166, 17.18014917, 20.82587015,
+		24.59899634, 25.38437022, 21.18809856,
+	})
+}
+
+func TestOscillatorsEdgeCases(t *testing.T) {
+	if got := RSI(nil, 14); len(got) != 0 {
+		t.Errorf("RSI of empty input gave %v", got)
+	}
+	macd, signal, histogram := MACD(nil, 12, 26, 9)
+	if len(macd)+len(signal)+len(histogram) != 0 {
+		t.Errorf("MACD of empty input gave %v %v %v", macd, signal, histogram)
+	}
+	k, d := Stochastic(nil, nil, nil, 14, 3, 3)
+	if len(k)+len(d) != 0 {
+		t.Errorf("Stochastic of empty input gave %v %v", k, d)
+	}
+
+	n := len(closes)
+	assertAllNaN(t, "RSI", RSI(closes, tooLong), n)
+	macd, signal, histogram = MACD(closes, 12, tooLong, 9)
+	assertAllNaN(t, "MACD", macd, n)
+	assertAllNaN(t, "signal", signal, n)
+	assertAllNaN(t, "histogram", histogram, n)
+	k, d = Stochastic(closes, highs, lows, tooLong, 3, 3)
+	assertAllNaN(t, "%K", k, n)
+	assertAllNaN(t, "%D", d, n)
+}
+
+func TestRSIFlat(t *testing.T) {
+	rising := []float64{1, 2, 3, 4, 5}
+	assertSeries(t, "RSI", RSI(rising, 2), []float64{nan, nan, 100, 100, 100})
+	falling := []float64{5, 4, 3, 2, 1}
+	assertSeries(t, "RSI", RSI(falling, 2), []float64{nan, nan, 0, 0, 0})
+}
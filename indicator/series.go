@@ -0,0 +1,125 @@
+// Package indicator implements technical indicators over price series.
+//
+// Every function takes series oldest first and returns series of the same
+// length. Bars without enough history are NaN, matching Pine Script's na:
+// window functions need length values first, recursive averages (EMA, RMA)
+// are seeded with the SMA of their first full window, and a NaN input yields
+// a NaN output for the bars whose window contains it.
+//
+// Each indicator also has a stream type whose Update consumes one bar in
+// amortised O(1) and returns the value for that bar. The series functions
+// replay their stream, so live strategies that keep a stream warm between
+// candles get exactly what a backtest over the same history computes.
+package indicator
+
+import "math"
+
+// Na reports whether v is the missing value.
+func Na(v float64) bool {
+	return math.IsNaN(v)
+}
+
+// Nz replaces a missing value with replacement, like Pine's nz.
+func Nz(v, replacement float64) float64 {
+	if math.IsNaN(v) {
+		return replacement
+	}
+	return v
+}
+
+// FixNaN replaces missing values with the last value that was present.
+func FixNaN(src []float64) []float64 {
+	out := make([]float64, len(src))
+	last := math.NaN()
+	for i, v := range src {
+		if !math.IsNaN(v) {
+			last = v
+		}
+		out[i] = last
+	}
+	return out
+}
+
+// Change returns src[i] - src[i-length].
+func Change(src []float64, length int) []float64 {
+	out := nanSeries(len(src))
+	for i := length; i < len(src); i++ {
+		out[i] = src[i] - src[i-length]
+	}
+	return out
+}
+
+// Highest returns the highest value over the last length bars, ignoring
+// missing values.
+func Highest(src []float64, length int) []float64 {
+	return replay(src, NewHighestStream(length).Update)
+}
+
+// Lowest returns the lowest value over the last length bars, ignoring
+// missing values.
+func Lowest(src []float64, length int) []float64 {
+	return replay(src, NewLowestStream(length).Update)
+}
+
+// ExtremeStream is the streaming Highest or Lowest.
+type ExtremeStream struct {
+	extremeStream
+	value float64
+}
+
+func NewHighestStream(length int) *ExtremeStream {
+	return &ExtremeStream{extremeStream{length: length, better: func(a, b float64) bool { return a > b }}, math.NaN()}
+}
+
+func NewLowestStream(length int) *ExtremeStream {
+	return &ExtremeStream{extremeStream{length: length, better: func(a, b float64) bool { return a < b }}, math.NaN()}
+}
+
+func (s *ExtremeStream) Update(v float64) float64 {
+	s.value = s.update(v)
+	return s.value
+}
+
+func (s *ExtremeStream) Value() float64 { return s.value }
+
+// ArgMax returns the index and value of the first maximum in values, or
+// (0, 0) for an empty slice.
+func ArgMax(values []float64) (int, float64) {
+	return argExtreme(values, func(a, b float64) bool { return a > b })
+}
+
+// ArgMin returns the index and value of the first minimum in values, or
+// (0, 0) for an empty slice.
+func ArgMin(values []float64) (int, float64) {
+	return argExtreme(values, func(a, b float64) bool { return a < b })
+}
+
+func argExtreme(values []float64, better func(a, b float64) bool) (int, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	idx, best := 0, values[0]
+	for i, v := range values {
+		if better(v, best) {
+			idx, best = i, v
+		}
+	}
+	return idx, best
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+// combine applies fn element-wise; a NaN on either side gives NaN.
+func combine(a, b []float64, fn func(x, y float64) float64) []float64 {
+	out := make([]float64, len(a))
+	for i := range a {
+		out[i] = fn(a[i], b[i])
+	}
+	return out
+}
@@ -0,0 +1,245 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// bars is a generated OHLCV history.
+type bars struct {
+	high, low, close, volume []float64
+	times                    []int64
+}
+
+// randomBars is a seeded random walk of n one-minute bars around 30000, so
+// windowed sums see large prices with small spreads. gaps sets every gaps-th
+// close to NaN; zero leaves none.
+func randomBars(n, gaps int) bars {
+	r := rand.New(rand.NewSource(1))
+	b := bars{
+		high:   make([]float64, n),
+		low:    make([]float64, n),
+		close:  make([]float64, n),
+		volume: make([]float64, n),
+		times:  make([]int64, n),
+	}
+	price := 30000.0
+	for i := range n {
+		price += r.NormFloat64() * 20
+		b.close[i] = price
+		b.high[i] = price + r.Float64()*30
+		b.low[i] = price - r.Float64()*30
+		b.volume[i] = 1 + r.Float64()*100
+		b.times[i] = 1_700_000_000_000 + int64(i)*60_000
+		if gaps > 0 && i%gaps == gaps-1 {
+			b.close[i] = math.NaN()
+		}
+	}
+	return b
+}
+
+// indicators computes every indicator both ways: series runs the batch
+// function and stream feeds the stream one bar at a time. Each returns the
+// indicator's output series in the same order.
+var indicators = []struct {
+	name   string
+	series func(b bars) [][]float64
+	stream func(b bars) [][]float64
+}{
+	{"SMA", func(b bars) [][]float64 { return [][]float64{SMA(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewSMAStream(20).Update) }},
+	{"EMA", func(b bars) [][]float64 { return [][]float64{EMA(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewEMAStream(20).Update) }},
+	{"RMA", func(b bars) [][]float64 { return [][]float64{RMA(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewRMAStream(20).Update) }},
+	{"WMA", func(b bars) [][]float64 { return [][]float64{WMA(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewWMAStream(20).Update) }},
+	{"HMA", func(b bars) [][]float64 { return [][]float64{HMA(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewHMAStream(20).Update) }},
+	{"Stdev", func(b bars) [][]float64 { return [][]float64{Stdev(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewStdevStream(20).Update) }},
+	{"Highest", func(b bars) [][]float64 { return [][]float64{Highest(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewHighestStream(20).Update) }},
+	{"Lowest", func(b bars) [][]float64 { return [][]float64{Lowest(b.close, 20)} },
+		func(b bars) [][]float64 { return streamed(b, NewLowestStream(20).Update) }},
+	{"RSI", func(b bars) [][]float64 { return [][]float64{RSI(b.close, 14)} },
+		func(b bars) [][]float64 { return streamed(b, NewRSIStream(14).Update) }},
+	{"MACD", func(b bars) [][]float64 {
+		macd, signal, histogram := MACD(b.close, 12, 26, 9)
+		return [][]float64{macd, signal, histogram}
+	}, func(b bars) [][]float64 {
+		s := NewMACDStream(12, 26, 9)
+		out := outputs(len(b.close), 3)
+		for i, v := range b.close {
+			out[0][i], out[1][i], out[2][i] = s.Update(v)
+		}
+		return out
+	}},
+	{"Stochastic", func(b bars) [][]float64 {
+		k, d := Stochastic(b.close, b.high, b.low, 14, 3, 3)
+		return [][]float64{k, d}
+	}, func(b bars) [][]float64 {
+		s := NewStochasticStream(14, 3, 3)
+		out := outputs(len(b.close), 2)
+		for i := range b.close {
+			out[0][i], out[1][i] = s.Update(b.close[i], b.high[i], b.low[i])
+		}
+		return out
+	}},
+	{"TrueRange", func(b bars) [][]float64 { return [][]float64{TrueRange(b.high, b.low, b.close, true)} },
+		func(b bars) [][]float64 {
+			s := NewTrueRangeStream(true)
+			out := outputs(len(b.close), 1)
+			for i := range b.close {
+				out[0][i] = s.Update(b.high[i], b.low[i], b.close[i])
+			}
+			return out
+		}},
+	{"ATR", func(b bars) [][]float64 { return [][]float64{ATR(b.high, b.low, b.close, 14)} },
+		func(b bars) [][]float64 {
+			s := NewATRStream(14)
+			out := outputs(len(b.close), 1)
+			for i := range b.close {
+				out[0][i] = s.Update(b.high[i], b.low[i], b.close[i])
+			}
+			return out
+		}},
+	{"Bollinger", func(b bars) [][]float64 {
+		middle, upper, lower := Bollinger(b.close, 20, 2)
+		return [][]float64{middle, upper, lower}
+	}, func(b bars) [][]float64 {
+		s := NewBollingerStream(20, 2)
+		out := outputs(len(b.close), 3)
+		for i, v := range b.close {
+			out[0][i], out[1][i], out[2][i] = s.Update(v)
+		}
+		return out
+	}},
+	{"Keltner", func(b bars) [][]float64 {
+		middle, upper, lower := Keltner(b.close, b.high, b.low, 20, 2)
+		return [][]float64{middle, upper, lower}
+	}, func(b bars) [][]float64 {
+		s := NewKeltnerStream(20, 2)
+		out := outputs(len(b.close), 3)
+		for i := range b.close {
+			out[0][i], out[1][i], out[2][i] = s.Update(b.close[i], b.high[i], b.low[i])
+		}
+		return out
+	}},
+	{"Donchian", func(b bars) [][]float64 {
+		upper, lower, middle := Donchian(b.high, b.low, 20)
+		return [][]float64{upper, lower, middle}
+	}, func(b bars) [][]float64 {
+		s := NewDonchianStream(20)
+		out := outputs(len(b.close), 3)
+		for i := range b.close {
+			out[0][i], out[1][i], out[2][i] = s.Update(b.high[i], b.low[i])
+		}
+		return out
+	}},
+	{"Supertrend", func(b bars) [][]float64 {
+		line, direction := Supertrend(b.high, b.low, b.close, 3, 10)
+		return [][]float64{line, directions(direction)}
+	}, func(b bars) [][]float64 {
+		s := NewSupertrendStream(3, 10)
+		out := outputs(len(b.close), 2)
+		for i := range b.close {
+			line, direction := s.Update(b.high[i], b.low[i], b.close[i])
+			out[0][i], out[1][i] = line, float64(direction)
+		}
+		return out
+	}},
+	{"ADX", func(b bars) [][]float64 {
+		plusDI, minusDI, adx := ADX(b.high, b.low, b.close, 14, 14)
+		return [][]float64{plusDI, minusDI, adx}
+	}, func(b bars) [][]float64 {
+		s := NewADXStream(14, 14)
+		out := outputs(len(b.close), 3)
+		for i := range b.close {
+			out[0][i], out[1][i], out[2][i] = s.Update(b.high[i], b.low[i], b.close[i])
+		}
+		return out
+	}},
+	{"VWAP", func(b bars) [][]float64 { return [][]float64{VWAP(b.close, b.volume, b.times)} },
+		func(b bars) [][]float64 {
+			s := NewVWAPStream()
+			out := outputs(len(b.close), 1)
+			for i := range b.close {
+				out[0][i] = s.Update(b.close[i], b.volume[i], b.times[i])
+			}
+			return out
+		}},
+	{"OBV", func(b bars) [][]float64 { return [][]float64{OBV(b.close, b.volume)} },
+		func(b bars) [][]float64 {
+			s := NewOBVStream()
+			out := outputs(len(b.close), 1)
+			for i := range b.close {
+				out[0][i] = s.Update(b.close[i], b.volume[i])
+			}
+			return out
+		}},
+}
+
+func streamed(b bars, update func(float64) float64) [][]float64 {
+	out := outputs(len(b.close), 1)
+	for i, v := range b.close {
+		out[0][i] = update(v)
+	}
+	return out
+}
+
+func outputs(n, count int) [][]float64 {
+	out := make([][]float64, count)
+	for i := range out {
+		out[i] = make([]float64, n)
+	}
+	return out
+}
+
+func directions(d []int) []float64 {
+	out := make([]float64, len(d))
+	for i, v := range d {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// TestStreamsMatchSeries checks that feeding a stream bar by bar gives the
+// batch series, with and without missing closes. StdevStream keeps running
+// sums, so values agree to a relative 1e-9 rather than exactly.
+func TestStreamsMatchSeries(t *testing.T) {
+	for _, gaps := range []int{0, 97} {
+		b := randomBars(5000, gaps)
+		for _, ind := range indicators {
+			t.Run(fmt.Sprintf("%s/gaps=%d", ind.name, gaps), func(t *testing.T) {
+				series, stream := ind.series(b), ind.stream(b)
+				for k := range series {
+					for i := range series[k] {
+						want, got := series[k][i], stream[k][i]
+						if math.IsNaN(want) != math.IsNaN(got) || math.Abs(got-want) > 1e-9*math.Max(1, math.Abs(want)) {
+							t.Fatalf("output %d bar %d: stream %v, series %v", k, i, got, want)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkIndicators(b *testing.B) {
+	data := randomBars(100_000, 0)
+	for _, ind := range indicators {
+		b.Run(ind.name+"/series", func(b *testing.B) {
+			for b.Loop() {
+				ind.series(data)
+			}
+		})
+		b.Run(ind.name+"/stream", func(b *testing.B) {
+			for b.Loop() {
+				ind.stream(data)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package indicator
+
+import "math"
+
+// Supertrend follows Pine's ta.supertrend: bands factor ATRs around hl2 that
+// only ratchet toward price. direction is -1 in an uptrend (the line is the
+// lower band) and 1 in a downtrend. Until the ATR is available the line is
+// NaN, where Pine plots the zero it seeds the bands with, and direction is 1.
+func Supertrend(high, low, close []float64, factor float64, atrPeriod int) (line []float64, direction []int) {
+	stream := NewSupertrendStream(factor, atrPeriod)
+	line = make([]float64, len(close))
+	direction = make([]int, len(close))
+	for i := range close {
+		line[i], direction[i] = stream.Update(high[i], low[i], close[i])
+	}
+	return line, direction
+}
+
+// ADX returns the +DI, -DI and average directional index as Pine's ta.dmi.
+func ADX(high, low, close []float64, diLength, adxSmoothing int) (plusDI, minusDI, adx []float64) {
+	stream := NewADXStream(diLength, adxSmoothing)
+	plusDI = make([]float64, len(close))
+	minusDI = make([]float64, len(close))
+	adx = make([]float64, len(close))
+	for i := range close {
+		plusDI[i], minusDI[i], adx[i] = stream.Update(high[i], low[i], close[i])
+	}
+	return plusDI, minusDI, adx
+}
+
+// SupertrendStream is the streaming Supertrend.
+type SupertrendStream struct {
+	factor       float64
+	atr          *ATRStream
+	bars         int
+	prevATR      float64
+	prevClose    float64
+	upper, lower float64
+	line         float64
+	direction    int
+}
+
+func NewSupertrendStream(factor float64, atrPeriod int) *SupertrendStream {
+	return &SupertrendStream{factor: factor, atr: NewATRStream(atrPeriod)}
+}
+
+func (s *SupertrendStream) Update(high, low, close float64) (line float64, direction int) {
+	atr := s.atr.Update(high, low, close)
+	hl2 := (high + low) / 2
+	upper := hl2 + s.factor*atr
+	lower := hl2 - s.factor*atr
+
+	if s.bars == 0 {
+		s.direction = 1
+	} else {
+		prevLower, prevUpper := Nz(s.lower, 0), Nz(s.upper, 0)
+		if !(lower > prevLower || s.prevClose < prevLower) {
+			lower = prevLower
+		}
+		if !(upper < prevUpper || s.prevClose > prevUpper) {
+			upper = prevUpper
+		}
+
+		switch {
+		case math.IsNaN(s.prevATR):
+			s.direction = 1
+		case s.line == prevUpper:
+			s.direction = 1
+			if close > upper {
+				s.direction = -1
+			}
+		default:
+			s.direction = -1
+			if close < lower {
+				s.direction = 1
+			}
+		}
+	}
+	s.line = upper
+	if s.direction == -1 {
+		s.line = lower
+	}
+
+	s.bars++
+	s.upper, s.lower = upper, lower
+	s.prevATR, s.prevClose = atr, close
+	if math.IsNaN(atr) {
+		return math.NaN(), s.direction
+	}
+	return s.line, s.direction
+}
+
+// ADXStream is the streaming ADX.
+type ADXStream struct {
+	prevHigh, prevLow float64
+	tr                *TrueRangeStream
+	trur              *SmoothedStream
+	plusDM, minusDM   *SmoothedStream
+	plusDI, minusDI   float64
+	adx               *SmoothedStream
+}
+
+func NewADXStream(diLength, adxSmoothing int) *ADXStream {
+	return &ADXStream{
+		prevHigh: math.NaN(),
+		prevLow:  math.NaN(),
+		tr:       NewTrueRangeStream(false),
+		trur:     NewRMAStream(diLength),
+		plusDM:   NewRMAStream(diLength),
+		minusDM:  NewRMAStream(diLength),
+		plusDI:   math.NaN(),
+		minusDI:  math.NaN(),
+		adx:      NewRMAStream(adxSmoothing),
+	}
+}
+
+func (s *ADXStream) Update(high, low, close float64) (plusDI, minusDI, adx float64) {
+	up, down := high-s.prevHigh, -(low - s.prevLow)
+	s.prevHigh, s.prevLow = high, low
+	plusDM, minusDM := math.NaN(), math.NaN()
+	if !math.IsNaN(up) {
+		plusDM, minusDM = 0, 0
+		if up > down && up > 0 {
+			plusDM = up
+		}
+		if down > up && down > 0 {
+			minusDM = down
+		}
+	}
+
+	// Like Pine's fixnan, a DI that cannot be computed keeps its last value.
+	trur := s.trur.Update(s.tr.Update(high, low, close))
+	if di := 100 * s.plusDM.Update(plusDM) / trur; !math.IsNaN(di) {
+		s.plusDI = di
+	}
+	if di := 100 * s.minusDM.Update(minusDM) / trur; !math.IsNaN(di) {
+		s.minusDI = di
+	}
+
+	sum := s.plusDI + s.minusDI
+	if sum == 0 {
+		sum = 1
+	}
+	adx = 100 * s.adx.Update(math.Abs(s.plusDI-s.minusDI)/sum)
+	return s.plusDI, s.minusDI, adx
+}
@@ -0,0 +1,81 @@
+package indicator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSupertrend(t *testing.T) {
+	line, direction := Supertrend(highs, lows, closes, 3, 5)
+	assertSeries(t, "line", line, []float64{
+		nan, nan, nan, nan, 46.009, 46.009,
+		46.009, 46.009, 46.009, 44.35794528, 44.35794528, 44.35794528,
+		44.35794528, 44.35794528, 44.61831231, 44.61831231, 44.61831231, 44.61831231,
+		44.61831231, 44.61831231, 44.61831231, 44.61831231, 44.61831231, 44.61831231,
+		44.61831231, 44.61831231, 46.63191515, 46.63191515, 46.4260257, 46.4260257,
+		46.04845645, 45.29576516, 45.29576516,
+	})
+	want := []int{
+		1, 1, 1, 1, 1, 1,
+		1, 1, 1, -1, -1, -1,
+		-1, -1, -1, -1, -1, -1,
+		-1, -1, -1, -1, -1, -1,
+		-1, -1, 1, 1, 1, 1,
+		1, 1, 1,
+	}
+	if !slices.Equal(direction, want) {
+		t.Errorf("direction = %v, want %v", direction, want)
+	}
+}
+
+func TestADX(t *testing.T) {
+	plusDI, minusDI, adx := ADX(highs, lows, closes, 5, 5)
+	assertSeries(t, "+DI", plusDI, []float64{
+		nan, nan, nan, nan, nan, 32.8313253,
+		31.89655172, 43.41125767, 44.98628311, 43.56259944, 35.28235026, 28.40777614,
+		21.90782975, 32.77874291, 32.40145962, 25.31293743, 29.96229729, 32.43776258,
+		26.5682257, 20.39001208, 25.23390975, 27.1280797, 20.18529237, 30.50755658,
+		23.10483296, 19.25543525, 11.90377816, 16.78701863, 14.67279099, 23.90124638,
+		16.62750882, 12.70712976, 17.56758517,
+	})
+	assertSeries(t, "-DI", minusDI, []float64{
+		nan, nan, nan, nan, nan, 23.19277108,
+		20.42440318, 16.08985242, 12.63492552, 10.96561008, 13.1052334, 11.61453845,
+		20.56821886, 15.13948881, 13.08392761, 28.45250907, 23.09399575, 18.31929484,
+		24.22254929, 30.70815969, 23.71706141, 20.1356984, 35.26928069, 26.33186677,
+		34.2809182, 39.19781756, 59.0822555, 51.13654279, 45.7457268, 38.43441623,
+		52.61745078, 61.04223865, 52.48245717,
+	})
+	assertSeries(t, "ADX", adx, []float64{
+		nan, nan, nan, nan, nan, nan,
+		nan, nan, nan, 40.19463749, 41.32215939, 41.44966479,
+		33.79049243, 34.39462475, 36.00965058, 29.97559755, 26.56954001, 26.81878656,
+		22.37869163, 21.94151173, 18.17295126, 17.49723632, 19.43791328, 17.01962414,
+		17.51077257, 20.83197907, 29.9579099, 34.08049845, 37.55029684, 34.70311238,
+		38.15745357, 43.63390125, 44.87567229,
+	})
+}
+
+func TestTrendEdgeCases(t *testing.T) {
+	line, direction := Supertrend(nil, nil, nil, 3, 10)
+	if len(line)+len(direction) != 0 {
+		t.Errorf("Supertrend of empty input gave %v %v", line, direction)
+	}
+	plusDI, minusDI, adx := ADX(nil, nil, nil, 14, 14)
+	if len(plusDI)+len(minusDI)+len(adx) != 0 {
+		t.Errorf("ADX of empty input gave %v %v %v", plusDI, minusDI, adx)
+	}
+
+	n := len(closes)
+	line, direction = Supertrend(highs, lows, closes, 3, tooLong)
+	assertAllNaN(t, "line", line, n)
+	for i, d := range direction {
+		if d != 1 {
+			t.Errorf("direction[%d] = %d before the ATR is available, want 1", i, d)
+		}
+	}
+	plusDI, minusDI, adx = ADX(highs, lows, closes, tooLong, 14)
+	assertAllNaN(t, "+DI", plusDI, n)
+	assertAllNaN(t, "-DI", minusDI, n)
+	assertAllNaN(t, "ADX", adx, n)
+}
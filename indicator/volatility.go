@@ -0,0 +1,136 @@
+package indicator
+
+import "math"
+
+// TrueRange is max(high-low, |high-prevClose|, |low-prevClose|). On the first
+// bar there is no previous close; handleNa returns high-low there instead of
+// NaN, as Pine's ta.tr(true).
+func TrueRange(high, low, close []float64, handleNa bool) []float64 {
+	stream := NewTrueRangeStream(handleNa)
+	out := make([]float64, len(high))
+	for i := range high {
+		out[i] = stream.Update(high[i], low[i], close[i])
+	}
+	return out
+}
+
+// ATR is the average true range using Wilder's smoothing.
+func ATR(high, low, close []float64, length int) []float64 {
+	return RMA(TrueRange(high, low, close, true), length)
+}
+
+// Bollinger returns the SMA basis and the bands mult standard deviations
+// above and below it.
+func Bollinger(src []float64, length int, mult float64) (middle, upper, lower []float64) {
+	middle = SMA(src, length)
+	dev := Stdev(src, length)
+	upper = combine(middle, dev, func(m, d float64) float64 { return m + mult*d })
+	lower = combine(middle, dev, func(m, d float64) float64 { return m - mult*d })
+	return middle, upper, lower
+}
+
+// Keltner returns the EMA basis of close and the bands mult times the EMA of
+// the true range above and below it.
+func Keltner(close, high, low []float64, length int, mult float64) (middle, upper, lower []float64) {
+	middle = EMA(close, length)
+	rng := EMA(TrueRange(high, low, close, true), length)
+	upper = combine(middle, rng, func(m, r float64) float64 { return m + mult*r })
+	lower = combine(middle, rng, func(m, r float64) float64 { return m - mult*r })
+	return middle, upper, lower
+}
+
+// Donchian returns the highest high, the lowest low and their midpoint over
+// length bars.
+func Donchian(high, low []float64, length int) (upper, lower, middle []float64) {
+	upper = Highest(high, length)
+	lower = Lowest(low, length)
+	middle = combine(upper, lower, func(u, l float64) float64 { return (u + l) / 2 })
+	return upper, lower, middle
+}
+
+// TrueRangeStream is the streaming TrueRange.
+type TrueRangeStream struct {
+	handleNa  bool
+	prevClose float64
+}
+
+func NewTrueRangeStream(handleNa bool) *TrueRangeStream {
+	return &TrueRangeStream{handleNa: handleNa, prevClose: math.NaN()}
+}
+
+func (s *TrueRangeStream) Update(high, low, close float64) float64 {
+	prev := s.prevClose
+	s.prevClose = close
+	if math.IsNaN(prev) {
+		if s.handleNa {
+			return high - low
+		}
+		return math.NaN()
+	}
+	return math.Max(high-low, math.Max(math.Abs(high-prev), math.Abs(low-prev)))
+}
+
+// ATRStream is the streaming ATR.
+type ATRStream struct {
+	tr  *TrueRangeStream
+	rma *SmoothedStream
+}
+
+func NewATRStream(length int) *ATRStream {
+	return &ATRStream{tr: NewTrueRangeStream(true), rma: NewRMAStream(length)}
+}
+
+func (s *ATRStream) Update(high, low, close float64) float64 {
+	return s.rma.Update(s.tr.Update(high, low, close))
+}
+
+func (s *ATRStream) Value() float64 { return s.rma.Value() }
+
+// BollingerStream is the streaming Bollinger.
+type BollingerStream struct {
+	mult  float64
+	basis *SMAStream
+	dev   *StdevStream
+}
+
+func NewBollingerStream(length int, mult float64) *BollingerStream {
+	return &BollingerStream{mult: mult, basis: NewSMAStream(length), dev: NewStdevStream(length)}
+}
+
+func (s *BollingerStream) Update(v float64) (middle, upper, lower float64) {
+	middle = s.basis.Update(v)
+	dev := s.dev.Update(v)
+	return middle, middle + s.mult*dev, middle - s.mult*dev
+}
+
+// KeltnerStream is the streaming Keltner.
+type KeltnerStream struct {
+	mult       float64
+	tr         *TrueRangeStream
+	basis, rng *SmoothedStream
+}
+
+func NewKeltnerStream(length int, mult float64) *KeltnerStream {
+	return &KeltnerStream{mult: mult, tr: NewTrueRangeStream(true), basis: NewEMAStream(length), rng: NewEMAStream(length)}
+}
+
+func (s *KeltnerStream) Update(close, high, low float64) (middle, upper, lower float64) {
+	middle = s.basis.Update(close)
+	rng := s.rng.Update(s.tr.Update(high, low, close))
+	return middle, middle + s.mult*rng, middle - s.mult*rng
+}
+
+// DonchianStream is the streaming Donchian.
+type DonchianStream struct {
+	highest, lowest *ExtremeStream
+}
+
+func NewDonchianStream(length int) *DonchianStream {
+	return &DonchianStream{highest: NewHighestStream(length), lowest: NewLowestStream(length)}
+}
+
+func (s *DonchianStream) Update(high, low float64) (upper, lower, middle float64) {
+	upper = s.highest.Update(high)
+	lower = s.lowest.Update(low)
+	return upper, lower, (upper + lower) / 2
+}
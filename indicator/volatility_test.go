@@ -0,0 +1,126 @@
+package indicator
+
+import "testing"
+
+func TestATR(t *testing.T) {
+	assertSeries(t, "ATR", ATR(highs, lows, closes, 5), []float64{
+		nan, nan, nan, nan, 0.588, 0.6244,
+		0.57152, 0.587216, 0.6037728, 0.55901824, 0.55521459, 0.55417167,
+		0.57733734, 0.62986987, 0.5838959, 0.59911672, 0.59129337, 0.5970347,
+		0.58362776, 0.60890221, 0.63112177, 0.59489741, 0.63991793, 0.68593434,
+		0.72474748, 0.69579798, 0.90063838, 0.83251071, 0.76200857, 0.72560685,
+		0.83448548, 0.87358839, 0.81287071,
+	})
+}
+
+func TestBollinger(t *testing.T) {
+	middle, upper, lower := Bollinger(closes, 5, 2)
+	assertSeries(t, "middle", middle, []float64{
+		nan, nan, nan, nan, 44.104, 44.202,
+		44.404, 44.658, 45.104, 45.454, 45.666, 45.852,
+		45.89, 45.978, 46.018, 46.04, 46.04, 46.2,
+		46.188, 46.06, 46.102, 46.146, 46.006, 46.052,
+		46.08, 45.908, 45.464, 45.158, 44.712, 44.47,
+		44.084, 43.81, 43.6,
+	})
+	assertSeries(t, "upper", upper, []float64{
+		nan, nan, nan, nan, 44.63550353, 44.99015227,
+		45.44949319, 45.92653616, 46.12995127, 46.37344331, 46.37746047, 46.31837324,
+		46.22057526, 46.42295393, 46.52418574, 46.53136544, 46.53136544, 46.51723808,
+		46.49664867, 46.57303021, 46.62283011, 46.67220908, 46.54896961, 46.69029774,
+		46.6524334, 46.69676105, 47.06447993, 47.01743647, 46.13688736, 45.41851463,
+		44.83644668, 45.18322977, 45.00348139,
+	})
+	assertSeries(t, "lower", lower, []float64{
+		nan, nan, nan, nan, 43.57249647, 43.41384773,
+		43.35850681, 43.38946384, 44.07804873, 44.53455669, 44.95453953, 45.38562676,
+		45.55942474, 45.53304607, 45.51181426, 45.54863456, 45.54863456, 45.88276192,
+		45.87935133, 45.54696979, 45.58116989, 45.61979092, 45.46303039, 45.41370226,
+		45.5075666, 45.11923895, 43.86352007, 43.29856353, 43.28711264, 43.52148537,
+		43.33155332, 42.43677023, 42.19651861,
+	})
+}
+
+func TestKeltner(t *testing.T) {
+	middle, upper, lower := Keltner(closes, highs, lows, 5, 1.5)
+	assertSeries(t, "middle", middle, []float64{
+		nan, nan, nan, nan, 44.104, 44.346,
+		44.59733333, 44.87155556, 45.19437037, 45.48958025, 45.6230535, 45.75870233,
+		45.70913489, 45.89942326, 46.02628217, 46.01752145, 46.02168097, 46.15112064,
+		46.17408043, 45.99605362, 46.06736908, 46.12824605, 45.9888307, 46.1425538,
+		46.02170253, 45.79780169, 45.20853446, 44.86568964, 44.65045976, 44.62363984,
+		44.22242656, 43.70161771, 43.51107847,
+	})
+	assertSeries(t, "upper", upper, []float64{
+		nan, nan, nan, nan, 44.986, 45.319,
+		45.426, 45.749, 46.11433333, 46.29288889, 46.42859259, 46.5707284,
+		46.5854856, 46.90365706, 46.89577138, 46.92718092, 46.90812061, 47.05208041,
+		47.03972027, 46.92814685, 47.04876457, 47.00750971, 46.98500647, 47.24167098,
+		47.19444732, 46.86963155, 46.7830877, 46.1953918, 45.77692787, 45.66461858,
+		45.55141238, 45.10260826, 44.73007217,
+	})
+	assertSeries(t, "lower", lower, []float64{
+		nan, nan, nan, nan, 43.222, 43.373,
+		43.76866667, 43.99411111, 44.27440741, 44.6862716, 44.8175144, 44.94667627,
+		44.83278418, 44.89518945, 45.15679297, 45.10786198, 45.13524132, 45.25016088,
+		45.30844059, 45.06396039, 45.08597359, 45.2489824, 44.99265493, 45.04343662,
+		44.84895775, 44.72597183, 43.63398122, 43.53598748, 43.52399165, 43.5826611,
+		42.89344073, 42.30062716, 42.29208477,
+	})
+}
+
+func TestDonchian(t *testing.T) {
+	upper, lower, middle := Donchian(highs, lows, 5)
+	assertSeries(t, "upper", upper, []float64{
+		nan, nan, nan, nan, 44.55, 45.1,
+		45.19, 45.75, 46.09, 46.22, 46.27, 46.27,
+		46.27, 46.45, 46.57, 46.57, 46.57, 46.65,
+		46.65, 46.65, 46.65, 46.65, 46.53, 46.58,
+		46.58, 46.58, 46.58, 46.58, 46.04, 45.74,
+		44.8, 44.8, 44.8,
+	})
+	assertSeries(t, "lower", lower, []float64{
+		nan, nan, nan, nan, 43.39, 43.39,
+		43.39, 43.39, 43.98, 44.69, 44.83, 45.23,
+		45.36, 45.36, 45.36, 45.36, 45.36, 45.62,
+		45.62, 45.51, 45.51, 45.51, 45.43, 45.43,
+		45.43, 45.2, 43.63, 43.63, 43.63, 43.63,
+		43.3, 42.39, 42.39,
+	})
+	assertSeries(t, "middle", middle, []float64{
+		nan, nan, nan, nan, 43.97, 44.245,
+		44.29, 44.57, 45.035, 45.455, 45.55, 45.75,
+		45.815, 45.905, 45.965, 45.965, 45.965, 46.135,
+		46.135, 46.08, 46.08, 46.08, 45.98, 46.005,
+		46.005, 45.89, 45.105, 45.105, 44.835, 44.685,
+		44.05, 43.595, 43.595,
+	})
+}
+
+func TestVolatilityEdgeCases(t *testing.T) {
+	if got := ATR(nil, nil, nil, 14); len(got) != 0 {
+		t.Errorf("ATR of empty input gave %v", got)
+	}
+	for name, bands := range map[string]func() ([]float64, []float64, []float64){
+		"Bollinger": func() ([]float64, []float64, []float64) { return Bollinger(nil, 20, 2) },
+		"Keltner":   func() ([]float64, []float64, []float64) { return Keltner(nil, nil, nil, 20, 2) },
+		"Donchian":  func() ([]float64, []float64, []float64) { return Donchian(nil, nil, 20) },
+	} {
+		if a, b, c := bands(); len(a)+len(b)+len(c) != 0 {
+			t.Errorf("%s of empty input gave %v %v %v", name, a, b, c)
+		}
+	}
+
+	n := len(closes)
+	assertAllNaN(t, "ATR", ATR(highs, lows, closes, tooLong), n)
+	for name, bands := range map[string]func() ([]float64, []float64, []float64){
+		"Bollinger": func() ([]float64, []float64, []float64) { return Bollinger(closes, tooLong, 2) },
+		"Keltner":   func() ([]float64, []float64, []float64) { return Keltner(closes, highs, lows, tooLong, 2) },
+		"Donchian":  func() ([]float64, []float64, []float64) { return Donchian(highs, lows, tooLong) },
+	} {
+		a, b, c := bands()
+		assertAllNaN(t, name, a, n)
+		assertAllNaN(t, name, b, n)
+		assertAllNaN(t, name, c, n)
+	}
+}
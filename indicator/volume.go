@@ -0,0 +1,77 @@
+package indicator
+
+import "math"
+
+const dayMillis = 24 * 60 * 60 * 1000
+
+// VWAP is the volume-weighted average of src, anchored to each UTC day.
+// times are bar open times in Unix milliseconds.
+func VWAP(src, volume []float64, times []int64) []float64 {
+	stream := NewVWAPStream()
+	out := make([]float64, len(src))
+	for i := range src {
+		out[i] = stream.Update(src[i], volume[i], times[i])
+	}
+	return out
+}
+
+// OBV is on-balance volume: the running sum of volume signed by the
+// direction of each bar's change. The first bar contributes nothing.
+func OBV(close, volume []float64) []float64 {
+	stream := NewOBVStream()
+	out := make([]float64, len(close))
+	for i := range close {
+		out[i] = stream.Update(close[i], volume[i])
+	}
+	return out
+}
+
+// VWAPStream is the streaming VWAP.
+type VWAPStream struct {
+	day         int64
+	started     bool
+	sumPV, sumV float64
+}
+
+func NewVWAPStream() *VWAPStream {
+	return &VWAPStream{}
+}
+
+func (s *VWAPStream) Update(src, volume float64, time int64) float64 {
+	if !s.started || time/dayMillis != s.day {
+		s.started, s.day = true, time/dayMillis
+		s.sumPV, s.sumV = 0, 0
+	}
+	if !math.IsNaN(src) && !math.IsNaN(volume) {
+		s.sumPV += src * volume
+		s.sumV += volume
+	}
+	if s.sumV == 0 {
+		return math.NaN()
+	}
+	return s.sumPV / s.sumV
+}
+
+// OBVStream is the streaming OBV.
+type OBVStream struct {
+	started   bool
+	prevClose float64
+	total     float64
+}
+
+func NewOBVStream() *OBVStream {
+	return &OBVStream{}
+}
+
+func (s *OBVStream) Update(close, volume float64) float64 {
+	if s.started {
+		switch {
+		case close > s.prevClose:
+			s.total += volume
+		case close < s.prevClose:
+			s.total -= volume
+		}
+	}
+	s.started, s.prevClose = true, close
+	return s.total
+}
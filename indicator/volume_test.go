@@ -0,0 +1,40 @@
+package indicator
+
+import "testing"
+
+func TestVWAP(t *testing.T) {
+	hlc3 := make([]float64, len(closes))
+	for i := range closes {
+		hlc3[i] = (highs[i] + lows[i] + closes[i]) / 3
+	}
+	assertSeries(t, "VWAP", VWAP(hlc3, volumes, times), []float64{
+		44.35, 44.27142857, 44.19583333, 44.07276596, 44.11622222, 44.87333333,
+		44.92666667, 45.12051282, 45.31430303, 45.42748718, 45.51391398, 45.56626437,
+		45.57719048, 45.64261803, 45.70027559, 45.72042705, 45.75437634, 45.81337243,
+		45.84483957, 45.8401364, 45.86614241, 45.88901221, 45.88241489, 45.90861699,
+		45.90253968, 45.87659466, 45.77084467, 45.68415529, 45.60010622, 44.58333333,
+		44.02966245, 43.55406036, 43.41804217,
+	})
+}
+
+func TestOBV(t *testing.T) {
+	assertSeries(t, "OBV", OBV(closes, volumes), []float64{
+		0, -900, 600, -500, 800, 2500,
+		3300, 4700, 6300, 7300, 6050, 7000,
+		5200, 6350, 6350, 5000, 6450, 8000,
+		6350, 5500, 7250, 8475, 7500, 8825,
+		7400, 5875, 4250, 5975, 7800, 9725,
+		7700, 5575, 7800,
+	})
+}
+
+func TestVolumeEdgeCases(t *testing.T) {
+	if got := VWAP(nil, nil, nil); len(got) != 0 {
+		t.Errorf("VWAP of empty input gave %v", got)
+	}
+	if got := OBV(nil, nil); len(got) != 0 {
+		t.Errorf("OBV of empty input gave %v", got)
+	}
+	// A day without volume yet has no VWAP.
+	assertSeries(t, "VWAP", VWAP([]float64{10, 11, 12}, []float64{0, 0, 2}, times[:3]), []float64{nan, nan, 12})
+}
@@ -0,0 +1,150 @@
+package indicator
+
+import "math"
+
+// replay feeds src through a stream's Update, giving the series form of a
+// streaming indicator.
+func replay(src []float64, update func(float64) float64) []float64 {
+	out := make([]float64, len(src))
+	for i, v := range src {
+		out[i] = update(v)
+	}
+	return out
+}
+
+// window is a fixed-size ring of the most recent values.
+type window struct {
+	values []float64
+	next   int
+	count  int
+}
+
+func newWindow(length int) window {
+	return window{values: make([]float64, max(length, 1))}
+}
+
+// push adds v and returns the value it evicted, if the window was full.
+func (w *window) push(v float64) (old float64, evicted bool) {
+	if w.count == len(w.values) {
+		old, evicted = w.values[w.next], true
+	} else {
+		w.count++
+	}
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	return old, evicted
+}
+
+func (w *window) full() bool {
+	return w.count == len(w.values)
+}
+
+// at returns the i-th value, oldest first.
+func (w *window) at(i int) float64 {
+	start := 0
+	if w.full() {
+		start = w.next
+	}
+	return w.values[(start+i)%len(w.values)]
+}
+
+func (w *window) clone() window {
+	c := *w
+	c.values = append([]float64(nil), w.values...)
+	return c
+}
+
+// windowSums keeps the plain, linearly weighted and squared sums of the last
+// length values. They are updated in O(1) and recomputed whenever the ring
+// completes a lap, so rounding error cannot accumulate. The squares are taken
+// around shift, the oldest value at the last recompute, which keeps the
+// variance accurate when prices are large and the spread is small.
+type windowSums struct {
+	length   int
+	win      window
+	missing  int
+	synced   bool
+	sum      float64
+	weighted float64
+	shift    float64
+	squares  float64
+}
+
+func newWindowSums(length int) windowSums {
+	return windowSums{length: length, win: newWindow(length)}
+}
+
+// update adds v and reports whether the sums cover a full window with no
+// missing values.
+func (w *windowSums) update(v float64) bool {
+	old, evicted := w.win.push(v)
+	if math.IsNaN(v) {
+		w.missing++
+	}
+	if evicted && math.IsNaN(old) {
+		w.missing--
+	}
+	switch {
+	case w.length < 1 || !w.win.full() || w.missing > 0:
+		w.synced = false
+		return false
+	case !w.synced || w.win.next == 0:
+		w.sum, w.weighted, w.squares = 0, 0, 0
+		w.shift = w.win.at(0)
+		for i := 0; i < w.length; i++ {
+			x := w.win.at(i)
+			w.sum += x
+			w.weighted += x * float64(i+1)
+			w.squares += (x - w.shift) * (x - w.shift)
+		}
+	default:
+		w.weighted += float64(w.length)*v - w.sum
+		w.sum += v - old
+		w.squares += (v-w.shift)*(v-w.shift) - (old-w.shift)*(old-w.shift)
+	}
+	w.synced = true
+	return true
+}
+
+// variance is the population variance of the window.
+func (w *windowSums) variance() float64 {
+	n := float64(w.length)
+	mean := w.sum/n - w.shift
+	return math.Max(w.squares/n-mean*mean, 0)
+}
+
+func (w *windowSums) clone() windowSums {
+	c := *w
+	c.win = w.win.clone()
+	return c
+}
+
+// extremeStream tracks the best value over the last length bars with a
+// monotonic deque, ignoring missing values.
+type extremeStream struct {
+	length int
+	better func(a, b float64) bool
+	bar    int
+	idx    []int
+	vals   []float64
+}
+
+func (s *extremeStream) update(v float64) float64 {
+	if !math.IsNaN(v) {
+		for len(s.vals) > 0 && !s.better(s.vals[len(s.vals)-1], v) {
+			s.idx = s.idx[:len(s.idx)-1]
+			s.vals = s.vals[:len(s.vals)-1]
+		}
+		s.idx = append(s.idx, s.bar)
+		s.vals = append(s.vals, v)
+	}
+	for len(s.idx) > 0 && s.idx[0] <= s.bar-s.length {
+		s.idx = s.idx[1:]
+		s.vals = s.vals[1:]
+	}
+	s.bar++
+	if s.length < 1 || s.bar < s.length || len(s.vals) == 0 {
+		return math.NaN()
+	}
+	return s.vals[0]
+}
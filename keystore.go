@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+const (
+	keystoreEnv         = "HYPERTERMINAL_KEYSTORE"
+	passphraseEnv       = "HYPERTERMINAL_PASSPHRASE"
+	addressEnv          = "HYPERTERMINAL_ADDRESS"
+	masterAddressEnv    = "HYPERTERMINAL_MASTER_ADDRESS"
+	defaultKeystorePath = "keystore.json"
+	legacySecretPath    = ".secret"
+)
+
+type WalletStatus struct {
+	Address      string
+	AgentAddress string
+	ReadOnly     bool
+	Locked       bool
+	KeystorePath string
+}
+
+// keystoreAddress reads the address stored next to the encrypted key so the
+// app can show the wallet before it is unlocked.
+func keystoreAddress(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keystore: %w", err)
+	}
+	var header struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return "", fmt.Errorf("invalid keystore file: %w", err)
+	}
+	if !common.IsHexAddress(header.Address) {
+		return "", fmt.Errorf("keystore has no valid address")
+	}
+	return common.HexToAddress(header.Address).Hex(), nil
+}
+
+func decryptKeystore(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock keystore: %w", err)
+	}
+	return key.PrivateKey, nil
+}
+
+// writeKeystore encrypts privateKey with scrypt using the standard go-ethereum
+// parameters and writes it with owner-only permissions.
+func writeKeystore(path string, privateKey *ecdsa.PrivateKey, passphrase string) error {
+	if len(passphrase) < 8 {
+		return fmt.Errorf("passphrase must be at least 8 characters")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("keystore %s already exists", path)
+	}
+	key := &keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+	data, err := keystore.EncryptKey(key, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func readLegacySecret() (*ecdsa.PrivateKey, error) {
+	pk, err := os.ReadFile(legacySecretPath)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(pk)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return privateKey, nil
+}
+
+func addressFromKey(privateKey *ecdsa.PrivateKey) string {
+	return crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+}
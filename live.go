@@ -0,0 +1,326 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// LiveStrategy is a Strategy trading one symbol on one account under the
+// engine. Kind is the registry ID it was created from; the exported fields
+// are what the UI and the store see.
+type LiveStrategy struct {
+	ID             string
+	Kind           string
+	Name           string
+	AccountID      string
+	Symbol         string
+	Interval       string
+	LastCandleTime int64
+	State          StrategyState
+	Error          string
+	Position       *Position
+	// Trades is the journal of closed live trades, oldest first.
+	Trades []Position
+	// Grid is the state of a grid strategy, nil for other strategies.
+	Grid          *GridState
+	Performance   PerformanceMetrics
+	MarkPrice     float64
+	UnrealizedPnL float64
+	Config        StrategyConfig
+	strategy      Strategy
+	account       *Account
+	events        *EventBus
+	// anchor caches stopAnchor for the candle closing at anchorTime.
+	anchor     float64
+	anchorTime int64
+}
+
+func NewLiveStrategy(kind string, strategy Strategy, params map[string]any) *LiveStrategy {
+	return &LiveStrategy{
+		Kind:     kind,
+		Name:     strategy.GetName(),
+		Config:   strategy.BuildConfig(params),
+		strategy: strategy,
+	}
+}
+
+// clone copies the strategy with its own position and grid, so the copy can
+// trade while the original is read. Closed trades are shared; appending to
+// the copy's journal never writes to the original's.
+func (s *LiveStrategy) clone() *LiveStrategy {
+	c := *s
+	if s.Position != nil {
+		position := *s.Position
+		position.Legs = slices.Clone(position.Legs)
+		c.Position = &position
+	}
+	if s.Grid != nil {
+		c.Grid = s.Grid.clone()
+	}
+	c.Trades = slices.Clip(s.Trades)
+	return &c
+}
+
+// evaluate returns the signal for the newest candle, if any, and the current
+// direction. Strategies without incremental state regenerate their signals
+// over the window and act when the last one lands on the newest candle.
+func (s *LiveStrategy) evaluate(candles hyperliquid.Candles) (*Signal, int, error) {
+	if live, ok := s.strategy.(LiveSignaler); ok {
+		return live.LiveSignal(candles)
+	}
+	signals, err := s.strategy.GenerateSignals(candles)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(signals) == 0 || signals[len(signals)-1].Index != len(candles)-1 {
+		return nil, 0, nil
+	}
+	return &signals[len(signals)-1], 0, nil
+}
+
+func (s *LiveStrategy) HandleSignal(signal Signal, candle hyperliquid.Candle) {
+	price := parseFloat(candle.Close)
+
+	if signal.Type == SignalClose {
+		if s.Position != nil && s.Position.IsOpen {
+			s.publish(EventSignal, StrategyEvent{Price: price, Side: s.Position.Side}, "close signal at %.2f: %s", price, signal.Reason)
+			s.ClosePosition(signal.Reason)
+		}
+		return
+	}
+	if signal.Type != SignalLong && signal.Type != SignalShort {
+		s.publish(EventError, StrategyEvent{Price: price}, "invalid signal type %d", signal.Type)
+		return
+	}
+
+	side := "long"
+	isBuy := true
+	if signal.Type == SignalShort {
+		side = "short"
+		isBuy = false
+	}
+
+	// An opposite signal always exits, even when the trade direction filter
+	// then ignores the entry.
+	if s.Position != nil && s.Position.IsOpen && s.Position.Side != side {
+		s.ClosePosition(signal.Reason)
+	}
+
+	event := StrategyEvent{Price: price, Side: side}
+	if s.Config.TradeDirection == "long" && side == "short" {
+		s.publish(EventSignal, event, "short signal ignored, trade direction is long only")
+		return
+	}
+	if s.Config.TradeDirection == "short" && side == "long" {
+		s.publish(EventSignal, event, "long signal ignored, trade direction is short only")
+		return
+	}
+	s.publish(EventSignal, event, "%s signal at %.2f: %s", side, price, signal.Reason)
+
+	if s.Position != nil && s.Position.IsOpen {
+		if s.Config.Scaling.canAdd("pyramid", s.Position) {
+			s.addToPosition(price, signal.Reason)
+			return
+		}
+		s.publish(EventSignal, event, "already %s, signal ignored", side)
+		return
+	}
+
+	event.Size = s.Config.PositionSize
+	s.publish(EventOrderPlaced, event, "open %s %.4f %s, leverage 10x", side, s.Config.PositionSize, s.Symbol)
+	resp, err := s.account.OpenPositionWith(s.Symbol, isBuy, s.Config.PositionSize, 10, s.Config.Execution)
+	event.OrderID = resp.OrderID
+	if err != nil {
+		s.publish(EventOrderRejected, event, "open %s failed: %v", side, err)
+		return
+	}
+
+	if resp.Success {
+		size := s.Config.PositionSize
+		if resp.FilledSize > 0 {
+			size = resp.FilledSize
+		}
+		if resp.AvgPrice > 0 {
+			price = resp.AvgPrice
+		}
+		s.Position = &Position{
+			EntryPrice: price,
+			EntryTime:  time.Now().UnixMilli(),
+			Side:       side,
+			Size:       size,
+			Fees:       s.liveFee(size, price),
+			IsOpen:     true,
+			Entries:    1,
+			BasePrice:  price,
+		}
+		event.Price, event.Size = price, size
+		s.publish(EventOrderFilled, event, "filled %s %.4f @ %.2f", side, size, price)
+		event.OrderID = ""
+		s.publish(EventPositionOpened, event, "%s %.4f %s @ %.2f", side, size, s.Symbol, price)
+	} else {
+		s.publish(EventOrderRejected, event, "open %s rejected: %s", side, resp.Message)
+	}
+}
+
+func (s *LiveStrategy) ClosePosition(reason string) {
+	if s.Position == nil || !s.Position.IsOpen {
+		return
+	}
+	if len(s.Position.Legs) > 0 {
+		s.closeLegs(reason)
+		return
+	}
+
+	event := StrategyEvent{Side: s.Position.Side, Size: s.Position.Size}
+	s.publish(EventOrderPlaced, event, "close %s %.4f %s: %s", s.Position.Side, s.Position.Size, s.Symbol, reason)
+	resp, err := s.account.ClosePositionWith(s.Symbol, s.Position.Size, s.Config.Execution)
+	event.OrderID = resp.OrderID
+	if err != nil {
+		s.publish(EventOrderRejected, event, "close failed: %v", err)
+		return
+	}
+
+	s.recordClose(resp.AvgPrice, reason)
+	event.Price = s.Position.ExitPrice
+	s.publish(EventOrderFilled, event, "close filled: %s", resp.Message)
+	event.OrderID = ""
+	s.publish(EventPositionClosed, event, "%s %s closed: %s, pnl %.2f", s.Position.Side, s.Symbol, reason, s.Position.PnL)
+}
+
+// closeLegs closes each leg of a multi-symbol position still open. A leg that
+// fails to close leaves the position open so the next close retries the legs
+// left.
+func (s *LiveStrategy) closeLegs(reason string) {
+	position := s.Position
+	for i := range position.Legs {
+		leg := &position.Legs[i]
+		if leg.ExitPrice > 0 {
+			continue
+		}
+		event := StrategyEvent{Side: leg.Side, Size: leg.Size}
+		s.publish(EventOrderPlaced, event, "close %s %.4f %s: %s", leg.Side, leg.Size, leg.Symbol, reason)
+		resp, err := s.account.ClosePositionWith(leg.Symbol, leg.Size, s.Config.Execution)
+		event.OrderID = resp.OrderID
+		if err != nil {
+			s.publish(EventOrderRejected, event, "close %s failed: %v", leg.Symbol, err)
+			return
+		}
+		leg.ExitPrice = resp.AvgPrice
+		if leg.ExitPrice <= 0 {
+			leg.ExitPrice = leg.MarkPrice
+		}
+		if leg.ExitPrice <= 0 {
+			leg.ExitPrice = leg.EntryPrice
+		}
+		event.Price = leg.ExitPrice
+		s.publish(EventOrderFilled, event, "close %s filled: %s", leg.Symbol, resp.Message)
+	}
+
+	exitFee := 0.0
+	for _, leg := range position.Legs {
+		exitFee += s.liveFee(leg.Size, leg.ExitPrice)
+	}
+	position.ExitTime = time.Now().UnixMilli()
+	settleLegs(position, exitFee, reason)
+	s.Trades = append(s.Trades, *position)
+	s.UnrealizedPnL = 0
+	s.publish(EventPositionClosed, StrategyEvent{Price: position.ExitPrice, Side: position.Side, Size: position.Size},
+		"%s %s spread closed: %s, pnl %.2f", position.Side, s.Symbol, reason, position.PnL)
+}
+
+// managePosition manages the open position at the current price, the close
+// of the newest candle: it exits at the stop, adds DCA safety orders as price
+// reaches them, takes the combined profit of a scaled position and moves the
+// stop after the price. The engine calls it on every tick; it reports whether
+// the position changed.
+func (s *LiveStrategy) managePosition(candles hyperliquid.Candles) bool {
+	position := s.Position
+	if position == nil || !position.IsOpen || len(candles) == 0 ||
+		(!s.Config.Scaling.enabled() && !s.Config.Stops.enabled()) {
+		return false
+	}
+	price := parseFloat(candles[len(candles)-1].Close)
+	event := StrategyEvent{Price: price, Side: position.Side}
+
+	if stopHit(position, price) {
+		s.publish(EventSignal, event, "%s %.2f reached at %.2f", strings.ToLower(position.StopReason), position.StopPrice, price)
+		s.ClosePosition(position.StopReason)
+		return true
+	}
+	if s.Config.Scaling.canAdd("dca", position) {
+		level := s.Config.Scaling.safetyPrice(position)
+		if beyond(position.Side, price, level, true) {
+			s.addToPosition(price, fmt.Sprintf("safety order %d at %.2f", position.adds()+1, level))
+			return true
+		}
+	}
+	if s.Config.Scaling.enabled() {
+		if level := s.Config.takeProfitPrice(position); beyond(position.Side, price, level, false) {
+			s.publish(EventSignal, event, "take profit %.2f reached at %.2f", level, price)
+			s.ClosePosition("Take Profit")
+			return true
+		}
+	}
+
+	stop, best := position.StopPrice, position.BestPrice
+	s.Config.updateStop(position, price, s.stopAnchor(candles))
+	if position.StopPrice != stop {
+		s.publish(EventSignal, event, "%s moved to %.2f", strings.ToLower(position.StopReason), position.StopPrice)
+	}
+	return position.StopPrice != stop || position.BestPrice != best
+}
+
+// stopAnchor is the stop anchor of the last closed candle, computed once per
+// candle. The newest candle is still forming.
+func (s *LiveStrategy) stopAnchor(candles hyperliquid.Candles) float64 {
+	if !s.Config.Stops.trailing() || s.Config.Stops.Trailing == "percent" || len(candles) < 2 {
+		return 0
+	}
+	latest := candles[len(candles)-1].Timestamp
+	if latest == s.anchorTime {
+		return s.anchor
+	}
+	var trend []float64
+	if s.Config.Stops.Trailing == "trend" {
+		output := s.strategy.GetVisualizationData(candles)
+		if output == nil {
+			return 0
+		}
+		trend = output.TrendLines
+	}
+	s.anchor = anchorAt(s.Config.Stops.anchors(candles, trend), len(candles)-2)
+	s.anchorTime = latest
+	return s.anchor
+}
+
+// addToPosition scales into the open position with the next add's size.
+func (s *LiveStrategy) addToPosition(price float64, reason string) {
+	position := s.Position
+	size := s.Config.Scaling.addSize(s.Config.PositionSize, position)
+	event := StrategyEvent{Price: price, Side: position.Side, Size: size}
+	s.publish(EventOrderPlaced, event, "add %s %.4f %s: %s", position.Side, size, s.Symbol, reason)
+	resp, err := s.account.OpenPositionWith(s.Symbol, position.Side == "long", size, 10, s.Config.Execution)
+	event.OrderID = resp.OrderID
+	if err != nil {
+		s.publish(EventOrderRejected, event, "add %s failed: %v", position.Side, err)
+		return
+	}
+	if !resp.Success {
+		s.publish(EventOrderRejected, event, "add %s rejected: %s", position.Side, resp.Message)
+		return
+	}
+	if resp.FilledSize > 0 {
+		size = resp.FilledSize
+	}
+	if resp.AvgPrice > 0 {
+		price = resp.AvgPrice
+	}
+	position.addEntry(price, size, s.liveFee(size, price))
+	event.Price, event.Size = price, size
+	s.publish(EventOrderFilled, event, "added %s %.4f @ %.2f, average entry %.2f over %d entries",
+		position.Side, size, price, position.EntryPrice, position.Entries)
+}
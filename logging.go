@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultLogMaxSizeMB = 10
+	defaultLogMaxFiles  = 5
+	defaultLogLimit     = 500
+)
+
+// LogEntry is one parsed JSON log line. Attributes other than time, level and
+// msg are kept in Fields.
+type LogEntry struct {
+	Time    string
+	Level   string
+	Message string
+	Fields  map[string]any
+}
+
+// LogFilter selects log entries for the viewer. Level is the minimum level,
+// Strategy matches the "strategy" field and Search is a case-insensitive
+// substring of the whole line. Limit caps the newest entries returned.
+type LogFilter struct {
+	Level    string
+	Strategy string
+	Search   string
+	Limit    int
+}
+
+var (
+	logLevel  = new(slog.LevelVar)
+	logOutput *rotatingFile
+	logMu     sync.Mutex
+)
+
+// setupLogging installs a JSON slog handler writing to stderr and a rotating
+// file, and routes the standard logger through it. It may be called again to
+// apply new settings.
+func setupLogging(cfg LoggingConfig) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return fmt.Errorf("invalid log level %q", cfg.Level)
+	}
+	path, err := logFilePath(cfg)
+	if err != nil {
+		return err
+	}
+	out, err := openRotatingFile(path, cfg.MaxSizeMB, cfg.MaxFiles)
+	if err != nil {
+		return err
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+	logLevel.Set(level)
+	handler := slog.NewJSONHandler(io.MultiWriter(os.Stderr, out), &slog.HandlerOptions{Level: logLevel})
+	slog.SetDefault(slog.New(handler))
+	if logOutput != nil {
+		logOutput.Close()
+	}
+	logOutput = out
+	return nil
+}
+
+// logFilePath returns the configured log file, or hyperterminal.log in the
+// app data directory.
+func logFilePath(cfg LoggingConfig) (string, error) {
+	if cfg.File != "" {
+		return cfg.File, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no app data directory, set logging.file: %w", err)
+	}
+	return filepath.Join(dir, "hyperterminal", "logs", "hyperterminal.log"), nil
+}
+
+// ReadLogs returns matching entries from the current log file and its
+// rotated backups, oldest first.
+func ReadLogs(filter LogFilter) ([]LogEntry, error) {
+	logMu.Lock()
+	out := logOutput
+	logMu.Unlock()
+	if out == nil {
+		return nil, fmt.Errorf("file logging is not enabled")
+	}
+
+	minLevel := slog.LevelDebug
+	if filter.Level != "" {
+		if err := minLevel.UnmarshalText([]byte(filter.Level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", filter.Level)
+		}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultLogLimit
+	}
+	search := strings.ToLower(filter.Search)
+
+	// Newest file first; stop once enough entries are collected.
+	var batches [][]LogEntry
+	total := 0
+	for _, path := range out.files() {
+		entries, err := readLogFile(path, minLevel, filter.Strategy, search)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		batches = append(batches, entries)
+		if total += len(entries); total >= limit {
+			break
+		}
+	}
+
+	result := make([]LogEntry, 0, min(total, limit))
+	for i := len(batches) - 1; i >= 0; i-- {
+		result = append(result, batches[i]...)
+	}
+	if len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result, nil
+}
+
+func readLogFile(path string, minLevel slog.Level, strategy, search string) ([]LogEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if search != "" && !strings.Contains(strings.ToLower(string(line)), search) {
+			continue
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(line, &fields); err != nil {
+			continue
+		}
+		entry := LogEntry{Fields: fields}
+		entry.Time, _ = fields["time"].(string)
+		entry.Level, _ = fields["level"].(string)
+		entry.Message, _ = fields["msg"].(string)
+		delete(fields, "time")
+		delete(fields, "level")
+		delete(fields, "msg")
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(entry.Level)); err == nil && level < minLevel {
+			continue
+		}
+		if strategy != "" && fields["strategy"] != strategy {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// rotatingFile is an io.Writer that renames the file to path.1 (shifting older
+// backups up to path.N) once it grows past maxSize.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func openRotatingFile(path string, maxSizeMB, maxFiles int) (*rotatingFile, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultLogMaxSizeMB
+	}
+	if maxFiles <= 0 {
+		maxFiles = defaultLogMaxFiles
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &rotatingFile{
+		path:     path,
+		maxSize:  int64(maxSizeMB) << 20,
+		maxFiles: maxFiles,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// files lists the log file and its backups, newest first.
+func (r *rotatingFile) files() []string {
+	paths := []string{r.path}
+	for i := 1; i < r.maxFiles; i++ {
+		paths = append(paths, fmt.Sprintf("%s.%d", r.path, i))
+	}
+	return paths
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	paths := r.files()
+	os.Remove(paths[len(paths)-1])
+	for i := len(paths) - 2; i >= 0; i-- {
+		if err := os.Rename(paths[i], paths[i+1]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	return r.open()
+}
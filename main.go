@@ -2,19 +2,35 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+
+	"terminal/script"
 )
 
 //go:embed all:frontend/dist
 var assets embed.FS
 
 func main() {
-	app := NewApp()
+	if script.ServeWorker() {
+		return
+	}
+	config, err := LoadConfig(os.Args[1:])
+	if err != nil {
+		println("Error:", err.Error())
+		os.Exit(1)
+	}
+	if err := setupLogging(config.Settings.Logging); err != nil {
+		println("Error:", err.Error())
+		os.Exit(1)
+	}
+
+	app := NewApp(config)
 
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "HyperTerminal",
 		Width:  1024,
 		Height: 1024,
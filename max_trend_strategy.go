@@ -1,27 +1,20 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"math"
-	"time"
+	"slices"
 
 	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/indicator"
 )
 
 type MaxTrendPointsStrategy struct {
-	ID             string
-	Symbol         string
-	Interval       string
-	LastCandleTime int64
-	IsRunning      bool
-	Position       *Position
-	ctx            context.Context
-	cancel         context.CancelFunc
-	Factor         float64
-	Config         StrategyConfig
-	output         *StrategyOutput
-	account        *Account
+	Factor    float64
+	Config    StrategyConfig
+	output    *StrategyOutput
+	trend     *trendState
+	trendTime int64
 }
 
 func NewMaxTrendPointsStrategy(params map[string]any) *MaxTrendPointsStrategy {
@@ -37,29 +30,15 @@ func (s *MaxTrendPointsStrategy) GetName() string {
 	return "Max Trend Points"
 }
 
-func (s *MaxTrendPointsStrategy) BuildConfig(params map[string]any) StrategyConfig {
-	config := StrategyConfig{
-		PositionSize:      0.005,
-		TradeDirection:    "both",
-		TakeProfitPercent: 5.0,
-		StopLossPercent:   2.0,
-		Parameters:        params,
-	}
+// maxTrendWarmUp is the history LiveSignal needs to rebuild the trend.
+const maxTrendWarmUp = 200
 
-	if size, ok := params["positionSize"].(float64); ok {
-		config.PositionSize = size
-	}
-	if direction, ok := params["tradeDirection"].(string); ok {
-		config.TradeDirection = direction
-	}
-	if tp, ok := params["takeProfitPercent"].(float64); ok {
-		config.TakeProfitPercent = tp
-	}
-	if sl, ok := params["stopLossPercent"].(float64); ok {
-		config.StopLossPercent = sl
-	}
+func (s *MaxTrendPointsStrategy) WarmUp(interval string) (int, error) {
+	return maxTrendWarmUp, nil
+}
 
-	return config
+func (s *MaxTrendPointsStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	return buildStrategyConfig(params)
 }
 
 func (s *MaxTrendPointsStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) {
@@ -113,168 +92,7 @@ func (s *MaxTrendPointsStrategy) Backtest(candles hyperliquid.Candles) (*Backtes
 	if err != nil {
 		return nil, err
 	}
-	if err := s.calculateTrends(candles); err != nil {
-		return nil, err
-	}
-	positions := s.calculateBacktestPositions(candles, signals)
-	output := s.calculateBacktestOutput(positions)
-
-	output.TrendLines = s.output.TrendLines
-	output.TrendColors = s.output.TrendColors
-	output.Directions = s.output.Directions
-	output.Labels = s.output.Labels
-	output.Signals = signals
-	output.StrategyName = s.GetName()
-	output.StrategyVersion = "1.0"
-
-	return &output, nil
-}
-
-func (s *MaxTrendPointsStrategy) HandleSignal(signal Signal, candle hyperliquid.Candle) {
-	price := parseFloat(candle.Close)
-
-	fmt.Printf("[%s] 📊 Signal Received: Type=%d at %.2f - %s\n", s.ID, signal.Type, price, signal.Reason)
-
-	if signal.Type != SignalLong && signal.Type != SignalShort {
-		fmt.Printf("[%s] ⚠️  Invalid signal type: %d\n", s.ID, signal.Type)
-		return
-	}
-
-	side := "long"
-	isBuy := true
-	if signal.Type == SignalShort {
-		side = "short"
-		isBuy = false
-	}
-
-	if s.Config.TradeDirection == "long" && side == "short" {
-		fmt.Printf("[%s] ⚠️  Signal filtered: SHORT signal ignored (trade direction: long only)\n", s.ID)
-		return
-	}
-	if s.Config.TradeDirection == "short" && side == "long" {
-		fmt.Printf("[%s] ⚠️  Signal filtered: LONG signal ignored (trade direction: short only)\n", s.ID)
-		return
-	}
-
-	if s.Position != nil && s.Position.IsOpen {
-		if s.Position.Side == side {
-			fmt.Printf("[%s] ℹ️  Already in %s position, ignoring signal\n", s.ID, side)
-			return
-		}
-		fmt.Printf("[%s] 🔄 Closing existing %s position before opening new %s position\n", s.ID, s.Position.Side, side)
-		s.ClosePosition("Trend Reversal")
-	}
-
-	fmt.Printf("[%s] 🚀 Opening %s position: size=%.4f, leverage=10x\n", s.ID, side, s.Config.PositionSize)
-	resp, err := s.account.OpenPosition(s.Symbol, isBuy, s.Config.PositionSize, 10)
-	if err != nil {
-		fmt.Printf("[%s] ❌ Failed to open position: %v\n", s.ID, err)
-		return
-	}
-
-	if resp.Success {
-		s.Position = &Position{
-			EntryPrice: price,
-			EntryTime:  time.Now().UnixMilli(),
-			Side:       side,
-			Size:       s.Config.PositionSize,
-			IsOpen:     true,
-		}
-		fmt.Printf("[%s] ✅ Position opened successfully: %s %.4f @ %.2f\n", s.ID, side, s.Config.PositionSize, price)
-	} else {
-		fmt.Printf("[%s] ❌ Position open failed: %s\n", s.ID, resp.Message)
-	}
-}
-
-func (s *MaxTrendPointsStrategy) ClosePosition(reason string) {
-	if s.Position == nil || !s.Position.IsOpen {
-		return
-	}
-
-	fmt.Printf("[%s] Closing position: %s", s.ID, reason)
-	resp, err := s.account.ClosePosition(s.Symbol, s.Position.Size)
-	if err != nil {
-		fmt.Printf("[%s] Failed to close position: %v", s.ID, err)
-		return
-	}
-
-	s.Position.IsOpen = false
-	s.Position.ExitReason = reason
-	s.Position.ExitTime = time.Now().UnixMilli()
-	fmt.Printf("[%s] Position closed: %s", s.ID, resp.Message)
-}
-
-func (s *MaxTrendPointsStrategy) calculateBacktestPositions(candles hyperliquid.Candles, signals []Signal) []Position {
-	positions := []Position{}
-	var currentPosition *Position
-
-	for _, signal := range signals {
-		if signal.Type != SignalLong && signal.Type != SignalShort {
-			continue
-		}
-
-		side := "long"
-		if signal.Type == SignalShort {
-			side = "short"
-		}
-
-		if (s.Config.TradeDirection == "long" && side == "short") ||
-			(s.Config.TradeDirection == "short" && side == "long") {
-			continue
-		}
-
-		if currentPosition != nil && currentPosition.IsOpen {
-			s.closeBacktestPosition(candles, currentPosition, signal.Index, signal.Price, "Trend Reversal")
-			positions = append(positions, *currentPosition)
-		}
-
-		currentPosition = &Position{
-			EntryIndex: signal.Index,
-			EntryPrice: signal.Price,
-			EntryTime:  signal.Time,
-			Side:       side,
-			Size:       s.Config.PositionSize,
-			IsOpen:     true,
-		}
-	}
-
-	if currentPosition != nil && currentPosition.IsOpen {
-		lastCandle := candles[len(candles)-1]
-		lastPrice := parseFloat(lastCandle.Close)
-		s.closeBacktestPosition(candles, currentPosition, len(candles)-1, lastPrice, "End of Period")
-		positions = append(positions, *currentPosition)
-	}
-
-	return positions
-}
-
-func (s *MaxTrendPointsStrategy) closeBacktestPosition(candles hyperliquid.Candles, position *Position, exitIndex int, exitPrice float64, reason string) {
-	position.ExitIndex = exitIndex
-	position.ExitPrice = exitPrice
-	position.ExitTime = candles[exitIndex].Timestamp
-	position.IsOpen = false
-	position.ExitReason = reason
-
-	priceDiff := 0.0
-	if position.Side == "long" {
-		priceDiff = exitPrice - position.EntryPrice
-	} else {
-		priceDiff = position.EntryPrice - exitPrice
-	}
-
-	position.PnLPercentage = (priceDiff / position.EntryPrice) * 100
-	position.PnL = position.Size * position.EntryPrice * (position.PnLPercentage / 100)
-}
-
-func (s *MaxTrendPointsStrategy) calculateBacktestPnL(position *Position, currentPrice float64) float64 {
-	priceDiff := 0.0
-	if position.Side == "long" {
-		priceDiff = currentPrice - position.EntryPrice
-	} else {
-		priceDiff = position.EntryPrice - currentPrice
-	}
-	percentageChange := (priceDiff / position.EntryPrice) * 100
-	return position.Size * position.EntryPrice * (percentageChange / 100)
+	return newBacktestOutput(s, s.Config, candles, signals, s.output), nil
 }
 
 func (s *MaxTrendPointsStrategy) calculateTrends(candles hyperliquid.Candles) error {
@@ -290,57 +108,10 @@ func (s *MaxTrendPointsStrategy) calculateTrends(candles hyperliquid.Candles) er
 	if n < 200 {
 		return fmt.Errorf("insufficient candles")
 	}
-	hl2 := make([]float64, n)
-	highLowDiff := make([]float64, n)
-	for i := range candles {
-		high := parseFloat(candles[i].High)
-		low := parseFloat(candles[i].Low)
-		hl2[i] = (high + low) / 2
-		highLowDiff[i] = high - low
-	}
-	dist := s.hma(highLowDiff, 200)
-	upperBand := make([]float64, n)
-	lowerBand := make([]float64, n)
-	for i := range candles {
-		upperBand[i] = hl2[i] + s.Factor*dist[i]
-		lowerBand[i] = hl2[i] - s.Factor*dist[i]
-	}
-	trendLine := make([]float64, n)
+	trend := newTrendState(s.Factor)
 	direction := make([]int, n)
-	for i := range candles {
-		if i == 0 {
-			direction[i] = 1
-			trendLine[i] = upperBand[i]
-		} else {
-			close := parseFloat(candles[i-1].Close)
-			if lowerBand[i] <= lowerBand[i-1] && close >= lowerBand[i-1] {
-				lowerBand[i] = lowerBand[i-1]
-			}
-			if upperBand[i] >= upperBand[i-1] && close <= upperBand[i-1] {
-				upperBand[i] = upperBand[i-1]
-			}
-			if dist[i-1] == 0 {
-				direction[i] = 1
-			} else if trendLine[i-1] == upperBand[i-1] {
-				if parseFloat(candles[i].Close) > upperBand[i] {
-					direction[i] = -1
-				} else {
-					direction[i] = 1
-				}
-			} else {
-				if parseFloat(candles[i].Close) < lowerBand[i] {
-					direction[i] = 1
-				} else {
-					direction[i] = -1
-				}
-			}
-			if direction[i] == -1 {
-				trendLine[i] = lowerBand[i]
-			} else {
-				trendLine[i] = upperBand[i]
-			}
-		}
-		s.output.TrendLines[i] = trendLine[i]
+	for i, candle := range candles {
+		s.output.TrendLines[i], direction[i] = trend.update(candle)
 		s.output.Directions[i] = direction[i]
 		if direction[i] == 1 {
 			s.output.TrendColors[i] = "#e49013"
@@ -388,14 +159,14 @@ func (s *MaxTrendPointsStrategy) calculateTrends(candles hyperliquid.Candles) er
 			if direction[i] == -1 {
 				highest = append(highest, parseFloat(candles[i].High))
 				if currentLineUp != nil && len(highest) > 0 {
-					maxIdx, maxVal := s.findMax(highest)
+					maxIdx, maxVal := indicator.ArgMax(highest)
 					currentLineUp.EndIndex = start + maxIdx + 1
 					currentLineUp.EndPrice = maxVal
 				}
 			} else {
 				lowest = append(lowest, parseFloat(candles[i].Low))
 				if currentLineDn != nil && len(lowest) > 0 {
-					minIdx, minVal := s.findMin(lowest)
+					minIdx, minVal := indicator.ArgMin(lowest)
 					currentLineDn.EndIndex = start + minIdx + 1
 					currentLineDn.EndPrice = minVal
 				}
@@ -436,144 +207,123 @@ func (s *MaxTrendPointsStrategy) calculateTrends(candles hyperliquid.Candles) er
 	return nil
 }
 
-func (s *MaxTrendPointsStrategy) calculateBacktestOutput(positions []Position) BacktestOutput {
-	result := BacktestOutput{
-		Positions: positions,
-	}
-
-	if len(positions) == 0 {
-		return result
-	}
+// trendState is the Max Trend Points trend advanced one candle at a time.
+// calculateTrends replays it over a series; live strategies keep one warm
+// between candles so each new candle costs O(1).
+type trendState struct {
+	factor       float64
+	dist         *indicator.HMAStream
+	bars         int
+	prevDist     float64
+	prevClose    float64
+	upper, lower float64
+	line         float64
+	direction    int
+}
 
-	var totalWin, totalLoss float64
-	var winStreak, lossStreak, currentWinStreak, currentLossStreak int
-	var totalHoldTime time.Duration
-	var totalCapitalInvested float64
+func newTrendState(factor float64) *trendState {
+	return &trendState{factor: factor, dist: indicator.NewHMAStream(200)}
+}
 
-	for _, pos := range positions {
-		if pos.IsOpen {
-			continue
+func (t *trendState) update(candle hyperliquid.Candle) (line float64, direction int) {
+	high := parseFloat(candle.High)
+	low := parseFloat(candle.Low)
+	close := parseFloat(candle.Close)
+	hl2 := (high + low) / 2
+	// dist is na for the HMA warm-up; the bands collapse onto hl2 there and
+	// the direction stays down, as in the Pine original.
+	dist := t.dist.Update(high - low)
+	upper := hl2 + t.factor*indicator.Nz(dist, 0)
+	lower := hl2 - t.factor*indicator.Nz(dist, 0)
+
+	if t.bars == 0 {
+		t.direction = 1
+	} else {
+		if lower <= t.lower && t.prevClose >= t.lower {
+			lower = t.lower
 		}
-
-		result.TotalTrades++
-		result.TotalPnL += pos.PnL
-
-		capitalInvested := pos.Size * pos.EntryPrice
-		totalCapitalInvested += capitalInvested
-
-		if pos.PnL > 0 {
-			result.WinningTrades++
-			totalWin += pos.PnL
-			currentWinStreak++
-			currentLossStreak = 0
-			if currentWinStreak > winStreak {
-				winStreak = currentWinStreak
+		if upper >= t.upper && t.prevClose <= t.upper {
+			upper = t.upper
+		}
+		if indicator.Na(t.prevDist) {
+			t.direction = 1
+		} else if t.line == t.upper {
+			if close > upper {
+				t.direction = -1
+			} else {
+				t.direction = 1
 			}
 		} else {
-			result.LosingTrades++
-			totalLoss += -pos.PnL
-			currentLossStreak++
-			currentWinStreak = 0
-			if currentLossStreak > lossStreak {
-				lossStreak = currentLossStreak
+			if close < lower {
+				t.direction = 1
+			} else {
+				t.direction = -1
 			}
 		}
-
-		if pos.MaxDrawdown < result.MaxDrawdown {
-			result.MaxDrawdown = pos.MaxDrawdown
-		}
-
-		holdTime := time.Duration(pos.ExitTime-pos.EntryTime) * time.Millisecond
-		totalHoldTime += holdTime
-	}
-
-	if result.TotalTrades > 0 {
-		result.WinRate = (float64(result.WinningTrades) / float64(result.TotalTrades)) * 100
-		result.AverageHoldTime = totalHoldTime / time.Duration(result.TotalTrades)
-
-		avgCapitalInvested := totalCapitalInvested / float64(result.TotalTrades)
-		if avgCapitalInvested > 0 {
-			result.TotalPnLPercent = (result.TotalPnL / avgCapitalInvested) * 100
-		}
-	}
-
-	if result.WinningTrades > 0 {
-		result.AverageWin = totalWin / float64(result.WinningTrades)
 	}
-	if result.LosingTrades > 0 {
-		result.AverageLoss = totalLoss / float64(result.LosingTrades)
-	}
-	if totalLoss > 0 {
-		result.ProfitFactor = totalWin / totalLoss
+	if t.direction == -1 {
+		t.line = lower
+	} else {
+		t.line = upper
 	}
 
-	result.LongestWinStreak = winStreak
-	result.LongestLossStreak = lossStreak
-
-	return result
+	t.bars++
+	t.upper, t.lower = upper, lower
+	t.prevDist, t.prevClose = dist, close
+	return t.line, t.direction
 }
 
-func (s *MaxTrendPointsStrategy) hma(values []float64, period int) []float64 {
-	if len(values) < period {
-		return make([]float64, len(values))
-	}
-	halfPeriod := period / 2
-	sqrtPeriod := int(math.Sqrt(float64(period)))
-	wma1 := s.wma(values, halfPeriod)
-	wma2 := s.wma(values, period)
-	diff := make([]float64, len(values))
-	for i := range diff {
-		if i >= period-1 {
-			diff[i] = 2*wma1[i] - wma2[i]
-		}
-	}
-	return s.wma(diff, sqrtPeriod)
+func (t *trendState) clone() *trendState {
+	c := *t
+	c.dist = t.dist.Clone()
+	return &c
 }
-func (s *MaxTrendPointsStrategy) wma(values []float64, period int) []float64 {
-	result := make([]float64, len(values))
-	if len(values) < period {
-		return result
-	}
-	for i := period - 1; i < len(values); i++ {
-		sum := 0.0
-		weightSum := 0.0
-		for j := 0; j < period; j++ {
-			weight := float64(period - j)
-			sum += values[i-j] * weight
-			weightSum += weight
+
+// LiveSignal folds the candles that closed since the last call into the live
+// trend state and evaluates the newest, still forming, candle on a copy of
+// it. The signal is the one GenerateSignals would give for the last candle,
+// without recomputing the history. It also returns the current direction.
+func (s *MaxTrendPointsStrategy) LiveSignal(candles hyperliquid.Candles) (*Signal, int, error) {
+	closed := candles[:len(candles)-1]
+	latest := candles[len(candles)-1]
+
+	// Rebuild from history on the first call, or when a gap (a long pause)
+	// means the last folded candle is no longer in the window.
+	if s.trend != nil && !slices.ContainsFunc(closed, func(c hyperliquid.Candle) bool { return c.Timestamp == s.trendTime }) {
+		s.trend = nil
+	}
+	if s.trend == nil {
+		if len(candles) < maxTrendWarmUp {
+			return nil, 0, fmt.Errorf("insufficient candles")
 		}
-		result[i] = sum / weightSum
-	}
-	return result
-}
-func (s *MaxTrendPointsStrategy) findMax(arr []float64) (int, float64) {
-	if len(arr) == 0 {
-		return 0, 0
+		s.trend = newTrendState(s.Factor)
+		s.trendTime = 0
 	}
-	maxIdx := 0
-	maxVal := arr[0]
-	for i, v := range arr {
-		if v > maxVal {
-			maxVal = v
-			maxIdx = i
+	for _, candle := range closed {
+		if candle.Timestamp > s.trendTime {
+			s.trend.update(candle)
+			s.trendTime = candle.Timestamp
 		}
 	}
-	return maxIdx, maxVal
-}
-func (s *MaxTrendPointsStrategy) findMin(arr []float64) (int, float64) {
-	if len(arr) == 0 {
-		return 0, 0
+
+	prevDirection := s.trend.direction
+	_, direction := s.trend.clone().update(latest)
+	if s.trend.bars == 0 || direction == prevDirection {
+		return nil, direction, nil
 	}
-	minIdx := 0
-	minVal := arr[0]
-	for i, v := range arr {
-		if v < minVal {
-			minVal = v
-			minIdx = i
-		}
+	signalType := SignalShort
+	if prevDirection == 1 && direction == -1 {
+		signalType = SignalLong
 	}
-	return minIdx, minVal
+	return &Signal{
+		Index:  len(candles) - 1,
+		Type:   signalType,
+		Price:  parseFloat(latest.Close),
+		Time:   latest.Timestamp,
+		Reason: "Trend Reversal",
+	}, direction, nil
 }
+
 func (s *MaxTrendPointsStrategy) formatPercent(percentage float64) string {
 	sign := ""
 	if percentage > 0 {
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	networkEnv        = "HYPERTERMINAL_NETWORK"
+	localURLEnv       = "HYPERTERMINAL_LOCAL_URL"
+	confirmMainnetEnv = "HYPERTERMINAL_CONFIRM_MAINNET"
+)
+
+type Network string
+
+const (
+	NetworkMainnet Network = "mainnet"
+	NetworkTestnet Network = "testnet"
+	NetworkLocal   Network = "local"
+)
+
+// NetworkProfile is the API endpoint every component talks to. Candles,
+// account state and order signing must all use the same profile.
+type NetworkProfile struct {
+	Name   Network
+	APIURL string
+}
+
+type NetworkStatus struct {
+	Network          Network
+	APIURL           string
+	IsMainnet        bool
+	TradingConfirmed bool
+}
+
+func (p NetworkProfile) IsMainnet() bool {
+	return p.Name == NetworkMainnet
+}
+
+func networkProfile(name Network) (NetworkProfile, error) {
+	switch Network(strings.ToLower(string(name))) {
+	case NetworkMainnet:
+		return NetworkProfile{Name: NetworkMainnet, APIURL: hyperliquid.MainnetAPIURL}, nil
+	case NetworkTestnet, "":
+		return NetworkProfile{Name: NetworkTestnet, APIURL: hyperliquid.TestnetAPIURL}, nil
+	case NetworkLocal:
+		url := os.Getenv(localURLEnv)
+		if url == "" {
+			url = hyperliquid.LocalAPIURL
+		}
+		return NetworkProfile{Name: NetworkLocal, APIURL: url}, nil
+	default:
+		return NetworkProfile{}, fmt.Errorf("unknown network %q, expected mainnet, testnet or local", name)
+	}
+}
+
+// requireTradingConfirmed guards every order entry point. Testnet and the
+// local mock trade freely; mainnet needs an explicit confirmation first.
+func (a *App) requireTradingConfirmed() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.tradingConfirmed()
+}
+
+// tradingConfirmed is requireTradingConfirmed for callers holding a.mu.
+func (a *App) tradingConfirmed() error {
+	if a.config.Network.IsMainnet() && !a.mainnetConfirmed {
+		return fmt.Errorf("mainnet trading is not confirmed, confirm it before placing orders")
+	}
+	return nil
+}
+
+// strategyEngine returns the engine of the current network.
+func (a *App) strategyEngine() *StrategyEngine {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.engine
+}
+
+func (a *App) GetNetworkStatus() NetworkStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return NetworkStatus{
+		Network:          a.config.Network.Name,
+		APIURL:           a.config.Network.APIURL,
+		IsMainnet:        a.config.Network.IsMainnet(),
+		TradingConfirmed: !a.config.Network.IsMainnet() || a.mainnetConfirmed,
+	}
+}
+
+// SetNetwork switches every component to another network. It is refused
+// while strategies or algos are running so nothing keeps trading against the
+// old endpoint.
+func (a *App) SetNetwork(name string) error {
+	profile, err := networkProfile(Network(name))
+	if err != nil {
+		return err
+	}
+	switched, err := a.switchNetwork(profile)
+	if switched {
+		a.networkChanged()
+	}
+	return err
+}
+
+// switchNetwork points the source, accounts and engine at profile and reports
+// whether it changed anything. It holds a.mu throughout so no strategy starts
+// on the old engine meanwhile.
+func (a *App) switchNetwork(profile NetworkProfile) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if profile == a.config.Network {
+		return false, nil
+	}
+	if n := len(a.engine.GetRunningStrategies()); n > 0 {
+		return false, fmt.Errorf("stop %d running strategies before switching network", n)
+	}
+	for _, order := range a.GetAlgoOrders() {
+		if order.Status == AlgoRunning || order.Status == AlgoPaused {
+			return false, fmt.Errorf("algo order %s is still active", order.ID)
+		}
+	}
+
+	a.config.SetNetwork(profile)
+	a.mainnetConfirmed = false
+	a.source.SetNetwork(profile)
+	a.accounts.Load(a.ctx, a.config, a.risk)
+	a.engine = NewStrategyEngine(a.source, a.store, a.events)
+	return true, nil
+}
+
+func (a *App) ConfirmMainnetTrading() {
+	a.mu.Lock()
+	a.mainnetConfirmed = true
+	a.mu.Unlock()
+	a.networkChanged()
+}
+
+// networkChanged publishes the network status and resumes the strategies
+// that can now trade.
+func (a *App) networkChanged() {
+	a.emitNetworkStatus()
+	a.resumeStrategies()
+}
+
+func (a *App) emitNetworkStatus() {
+	runtime.EventsEmit(a.ctx, "network:status", a.GetNetworkStatus())
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+const minOrderNotional = 10.0
+
+type OrderKind string
+
+const (
+	OrderMarket     OrderKind = "market"
+	OrderLimit      OrderKind = "limit"
+	OrderStop       OrderKind = "stop"
+	OrderTakeProfit OrderKind = "takeProfit"
+)
+
+// OrderTicket is a manually entered order. Price is the limit price for limit
+// orders and the optional limit price of a triggered stop or take-profit; a
+// zero Price makes a triggered order execute as market.
+type OrderTicket struct {
+	AccountID    string
+	Coin         string
+	IsBuy        bool
+	Kind         OrderKind
+	Size         float64
+	Price        float64
+	TriggerPrice float64
+	ReduceOnly   bool
+	Tif          string
+	Leverage     int
+}
+
+func (a *Account) PlaceOrder(ticket OrderTicket) (OrderResponse, error) {
+	req, err := a.buildOrderRequest(ticket)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+
+	if err := a.checkOrderRisk(ticket.Coin, ticket.Size, ticket.ReduceOnly); err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+
+	if ticket.Leverage > 0 && !ticket.ReduceOnly {
+		if _, err := a.exchange.UpdateLeverage(a.ctx, ticket.Leverage, ticket.Coin, false); err != nil {
+			return OrderResponse{
+				Success: false,
+				Message: fmt.Sprintf("failed to set leverage: %v", err),
+				Status:  "error",
+			}, err
+		}
+	}
+
+	resp, err := a.exchange.Order(a.ctx, req, nil)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+	}
+	return parseOrderResponse(resp), nil
+}
+
+// PlaceOrders sends tickets on one coin as a single bulk order. The risk
+// checks treat the batch as one action: it takes one slot of the order rate
+// limit, and its exposure is the larger of its buy and sell sides. Leverage
+// is left as it is. The responses follow the tickets; an order the exchange
+// rejects has Success false and the exchange's message.
+func (a *Account) PlaceOrders(tickets []OrderTicket) ([]OrderResponse, error) {
+	if len(tickets) == 0 {
+		return nil, nil
+	}
+	coin := tickets[0].Coin
+	reqs := make([]hyperliquid.CreateOrderRequest, len(tickets))
+	var buys, sells float64
+	reduceOnly := true
+	for i, ticket := range tickets {
+		if ticket.Coin != coin {
+			return nil, fmt.Errorf("bulk orders must all be on %s, got %s", coin, ticket.Coin)
+		}
+		req, err := a.buildOrderRequest(ticket)
+		if err != nil {
+			return nil, err
+		}
+		reqs[i] = req
+		if ticket.IsBuy {
+			buys += ticket.Size
+		} else {
+			sells += ticket.Size
+		}
+		reduceOnly = reduceOnly && ticket.ReduceOnly
+	}
+	if err := a.checkOrderRisk(coin, max(buys, sells), reduceOnly); err != nil {
+		return nil, err
+	}
+
+	// BulkOrders also fails when single orders were rejected; those are
+	// reported in their responses.
+	resp, err := a.exchange.BulkOrders(a.ctx, reqs, nil)
+	if resp == nil {
+		if err == nil {
+			err = fmt.Errorf("empty bulk order response")
+		}
+		return nil, fmt.Errorf("failed to place orders: %w", err)
+	}
+	if !resp.Ok {
+		if err == nil {
+			err = fmt.Errorf("%s", resp.Err)
+		}
+		return nil, fmt.Errorf("failed to place orders: %w", err)
+	}
+	out := make([]OrderResponse, len(tickets))
+	for i := range out {
+		out[i] = OrderResponse{Success: false, Message: "no status returned", Status: "error"}
+		if i < len(resp.Data.Statuses) {
+			out[i] = parseOrderResponse(resp.Data.Statuses[i])
+		}
+	}
+	return out, nil
+}
+
+func (a *Account) ModifyOrder(oid int64, ticket OrderTicket) (OrderResponse, error) {
+	if ticket.Kind == OrderMarket {
+		err := fmt.Errorf("market orders cannot be modified")
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+
+	req, err := a.buildOrderRequest(ticket)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+
+	// The risk checks see only what the modify adds to the order. While the
+	// kill switch is tripped, only reduce-only modifies go through.
+	sizes, err := a.openOrderSizes(ticket.Coin)
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+	}
+	oldSize, ok := sizes[oid]
+	if !ok {
+		err := fmt.Errorf("order %d is not open on %s", oid, ticket.Coin)
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+	if err := a.checkOrderRisk(ticket.Coin, req.Size-oldSize, ticket.ReduceOnly); err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "rejected"}, err
+	}
+
+	resp, err := a.exchange.ModifyOrder(a.ctx, hyperliquid.ModifyOrderRequest{Oid: oid, Order: req})
+	if err != nil {
+		return OrderResponse{Success: false, Message: err.Error(), Status: "error"}, err
+	}
+	return parseOrderResponse(resp), nil
+}
+
+func (a *Account) CancelOrder(coin string, oid int64) error {
+	if err := a.requireSigner(); err != nil {
+		return err
+	}
+	if _, err := a.exchange.Cancel(a.ctx, coin, oid); err != nil {
+		return fmt.Errorf("failed to cancel order %d: %w", oid, err)
+	}
+	return nil
+}
+
+// openOrderSizes returns the remaining size of the account's open orders
+// on coin by order ID.
+func (a *Account) openOrderSizes(coin string) (map[int64]float64, error) {
+	orders, err := a.info.OpenOrders(a.ctx, a.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	sizes := make(map[int64]float64)
+	for _, order := range orders {
+		if order.Coin == coin {
+			sizes[order.Oid] = order.Size
+		}
+	}
+	return sizes, nil
+}
+
+// orderStatus returns the status of an order, such as filled or canceled,
+// and its size left unfilled.
+func (a *Account) orderStatus(oid int64) (hyperliquid.OrderStatusValue, float64, error) {
+	result, err := a.info.QueryOrderByOid(a.ctx, a.address, oid)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to query order %d: %w", oid, err)
+	}
+	if result.Status != hyperliquid.OrderQueryStatusSuccess {
+		return "", 0, fmt.Errorf("order %d not found", oid)
+	}
+	return result.Order.Status, parseFloatSafe(result.Order.Order.Sz), nil
+}
+
+func (a *Account) buildOrderRequest(ticket OrderTicket) (hyperliquid.CreateOrderRequest, error) {
+	if err := a.requireSigner(); err != nil {
+		return hyperliquid.CreateOrderRequest{}, err
+	}
+	if err := a.validateTicket(ticket); err != nil {
+		return hyperliquid.CreateOrderRequest{}, err
+	}
+
+	req := hyperliquid.CreateOrderRequest{
+		Coin:       ticket.Coin,
+		IsBuy:      ticket.IsBuy,
+		Size:       ticket.Size,
+		Price:      ticket.Price,
+		ReduceOnly: ticket.ReduceOnly,
+	}
+
+	switch ticket.Kind {
+	case OrderMarket:
+		price, err := a.exchange.SlippagePrice(a.ctx, ticket.Coin, ticket.IsBuy, 0.05, nil)
+		if err != nil {
+			return req, fmt.Errorf("failed to get slippage price: %w", err)
+		}
+		req.Price = price
+		req.OrderType = hyperliquid.OrderType{Limit: &hyperliquid.LimitOrderType{Tif: hyperliquid.TifIoc}}
+	case OrderLimit:
+		tif, err := parseTif(ticket.Tif)
+		if err != nil {
+			return req, err
+		}
+		req.OrderType = hyperliquid.OrderType{Limit: &hyperliquid.LimitOrderType{Tif: tif}}
+	case OrderStop, OrderTakeProfit:
+		tpsl := hyperliquid.StopLoss
+		if ticket.Kind == OrderTakeProfit {
+			tpsl = hyperliquid.TakeProfit
+		}
+		isMarket := ticket.Price == 0
+		if isMarket {
+			price, err := a.exchange.SlippagePrice(a.ctx, ticket.Coin, ticket.IsBuy, 0.05, &ticket.TriggerPrice)
+			if err != nil {
+				return req, fmt.Errorf("failed to get slippage price: %w", err)
+			}
+			req.Price = price
+		}
+		req.OrderType = hyperliquid.OrderType{Trigger: &hyperliquid.TriggerOrderType{
+			TriggerPx: ticket.TriggerPrice,
+			IsMarket:  isMarket,
+			Tpsl:      tpsl,
+		}}
+	}
+
+	return req, nil
+}
+
+func (a *Account) validateTicket(ticket OrderTicket) error {
+	switch ticket.Kind {
+	case OrderMarket, OrderLimit, OrderStop, OrderTakeProfit:
+	default:
+		return fmt.Errorf("unknown order type %q", ticket.Kind)
+	}
+	if ticket.Size <= 0 {
+		return fmt.Errorf("size must be positive")
+	}
+	if ticket.Kind == OrderLimit && ticket.Price <= 0 {
+		return fmt.Errorf("limit orders need a price")
+	}
+	if (ticket.Kind == OrderStop || ticket.Kind == OrderTakeProfit) && ticket.TriggerPrice <= 0 {
+		return fmt.Errorf("%s orders need a trigger price", ticket.Kind)
+	}
+
+	spec, err := a.assets.Get(ticket.Coin)
+	if err != nil {
+		return err
+	}
+	if !spec.ValidSize(ticket.Size) {
+		return fmt.Errorf("size %v is not a multiple of the %s lot size %v", ticket.Size, ticket.Coin, spec.LotSize())
+	}
+	for _, px := range []float64{ticket.Price, ticket.TriggerPrice} {
+		if px > 0 && !spec.ValidPrice(px) {
+			return fmt.Errorf("price %v is not a valid %s tick, nearest valid price is %v", px, ticket.Coin, spec.RoundPrice(px))
+		}
+	}
+
+	refPrice := ticket.Price
+	if refPrice == 0 {
+		refPrice = ticket.TriggerPrice
+	}
+	if refPrice == 0 {
+		mids, err := a.info.AllMids(a.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch mid prices: %w", err)
+		}
+		refPrice = parseFloatSafe(mids[ticket.Coin])
+	}
+	if !ticket.ReduceOnly && ticket.Size*refPrice < minOrderNotional {
+		return fmt.Errorf("order value %.2f is below the minimum of %.2f", ticket.Size*refPrice, minOrderNotional)
+	}
+	return nil
+}
+
+func parseTif(tif string) (hyperliquid.Tif, error) {
+	switch strings.ToLower(tif) {
+	case "", "gtc":
+		return hyperliquid.TifGtc, nil
+	case "ioc":
+		return hyperliquid.TifIoc, nil
+	case "alo":
+		return hyperliquid.TifAlo, nil
+	default:
+		return "", fmt.Errorf("unknown time in force %q", tif)
+	}
+}
+
+func hasDecimals(x float64, decimals int) bool {
+	pow := math.Pow(10, float64(decimals))
+	return math.Abs(math.Round(x*pow)-x*pow) < 1e-6
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestModifyOrderRisk(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	account := fake.account(ctx)
+	risk := NewRiskManager(RiskLimits{MaxSymbolNotional: 50})
+	account.SetRiskManager(risk)
+
+	ticket := OrderTicket{Coin: "BTC", IsBuy: true, Kind: OrderLimit, Size: 0.2, Price: 90, Tif: "gtc"}
+	resp, err := account.PlaceOrder(ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oid := fake.restingOrders()[90]
+	if !resp.Success || oid == 0 {
+		t.Fatalf("PlaceOrder = %+v", resp)
+	}
+
+	modify := func(size float64, reduceOnly bool) error {
+		t.Helper()
+		ticket.Size, ticket.ReduceOnly = size, reduceOnly
+		_, err := account.ModifyOrder(oid, ticket)
+		return err
+	}
+	// Growing the order by 0.3 adds 30 of notional, within the limit; by
+	// 0.6 more it would reach 110.
+	if err := modify(0.5, false); err != nil {
+		t.Errorf("modify within the limit: %v", err)
+	}
+	if err := modify(1.1, false); err == nil || !strings.Contains(err.Error(), "would exceed limit 50.00") {
+		t.Errorf("modify past the limit: error = %v", err)
+	}
+
+	risk.Trip("test")
+	if err := modify(0.3, false); err == nil || !strings.Contains(err.Error(), "kill switch active") {
+		t.Errorf("modify with the kill switch tripped: error = %v", err)
+	}
+	if err := modify(0.3, true); err != nil {
+		t.Errorf("reduce-only modify with the kill switch tripped: %v", err)
+	}
+
+	if _, err := account.ModifyOrder(oid+1, ticket); err == nil {
+		t.Error("modified an order that is not open")
+	}
+}
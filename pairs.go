@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// PairsConfig is a pairs trade of the strategy's symbol against Symbol.
+// Entries and exits are z-scores of the spread between the two.
+type PairsConfig struct {
+	Symbol   string
+	Lookback int
+	EntryZ   float64
+	ExitZ    float64
+	// StopZ closes a position whose spread keeps diverging; 0 disables it.
+	StopZ float64
+}
+
+func buildPairsConfig(params map[string]any) (PairsConfig, error) {
+	config := PairsConfig{Symbol: "ETH", Lookback: 100, EntryZ: 2, ExitZ: 0.5, StopZ: 4}
+	if symbol, ok := params["pairSymbol"].(string); ok {
+		config.Symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	}
+	if lookback, ok := params["lookback"].(float64); ok {
+		config.Lookback = int(lookback)
+	}
+	if z, ok := params["entryZ"].(float64); ok {
+		config.EntryZ = z
+	}
+	if z, ok := params["exitZ"].(float64); ok {
+		config.ExitZ = z
+	}
+	if z, ok := params["stopZ"].(float64); ok {
+		config.StopZ = z
+	}
+
+	switch {
+	case config.Symbol == "":
+		return config, fmt.Errorf("pair symbol is required")
+	case config.Lookback < 10:
+		return config, fmt.Errorf("lookback must be at least 10 candles")
+	case config.EntryZ <= 0 || config.ExitZ < 0 || config.ExitZ >= config.EntryZ:
+		return config, fmt.Errorf("exit z-score must be below the entry z-score")
+	case config.StopZ != 0 && config.StopZ <= config.EntryZ:
+		return config, fmt.Errorf("stop z-score must be above the entry z-score")
+	}
+	return config, nil
+}
+
+// pairSeries is the spread of a pair per candle, NaN until the lookback is
+// filled. The log price of the strategy's symbol is regressed on the pair
+// symbol's over the lookback: Hedge is the slope, the notional of the pair
+// leg per unit of notional of the strategy's leg, Fair the strategy's price
+// the regression implies and Z the z-score of the residual.
+type pairSeries struct {
+	Hedge []float64
+	Fair  []float64
+	Z     []float64
+}
+
+// PairsStrategy trades the spread between its symbol and a second one: when
+// the spread's z-score stretches past EntryZ it sells the rich leg and buys
+// the cheap one, sized by the hedge ratio, and closes both once the z-score
+// comes back within ExitZ. PositionSize is the size of the strategy's own
+// leg. Live, the engine drives it through OrderTrader.
+type PairsStrategy struct {
+	Config StrategyConfig
+	pair   PairsConfig
+	hedge  hyperliquid.Candles
+	series *pairSeries
+	output *StrategyOutput
+}
+
+func NewPairsStrategy(params map[string]any) (*PairsStrategy, error) {
+	pair, err := buildPairsConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	strategy := &PairsStrategy{pair: pair}
+	strategy.Config = strategy.BuildConfig(params)
+	return strategy, nil
+}
+
+func (s *PairsStrategy) GetName() string {
+	return "Pairs"
+}
+
+// BuildConfig turns scaling and stops off; they manage a single leg and the
+// pair exits on its spread.
+func (s *PairsStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	config := buildStrategyConfig(params)
+	config.Scaling.Mode = "off"
+	config.Stops = StopConfig{Trailing: "off"}
+	return config
+}
+
+func (s *PairsStrategy) Symbols() []string {
+	return []string{s.pair.Symbol}
+}
+
+func (s *PairsStrategy) SetSymbolCandles(symbol string, candles hyperliquid.Candles) {
+	if symbol == s.pair.Symbol {
+		s.hedge = candles
+	}
+}
+
+func (s *PairsStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) {
+	return s.run(candles)
+}
+
+func (s *PairsStrategy) GetVisualizationData(candles hyperliquid.Candles) *StrategyOutput {
+	if _, err := s.run(candles); err != nil {
+		return nil
+	}
+	return s.output
+}
+
+func (s *PairsStrategy) Run(candles hyperliquid.Candles) (*StrategyOutput, error) {
+	if _, err := s.run(candles); err != nil {
+		return nil, err
+	}
+	return s.output, nil
+}
+
+// LiveSignal never signals: the pair trades both legs through Trade.
+func (s *PairsStrategy) LiveSignal(candles hyperliquid.Candles) (*Signal, int, error) {
+	return nil, 0, nil
+}
+
+// Backtest trades both legs at candle closes with the taker fee. Each
+// position is long the spread (long the strategy's symbol, short the pair)
+// or short it, and carries both legs.
+func (s *PairsStrategy) Backtest(candles hyperliquid.Candles) (*BacktestOutput, error) {
+	signals, err := s.run(candles)
+	if err != nil {
+		return nil, err
+	}
+	fee := s.Config.Execution.feePercent(false) / 100
+	positions := []Position{}
+	var current *Position
+	for _, signal := range signals {
+		i := signal.Index
+		if current != nil {
+			s.closeLegs(current, candles, i, fee, signal.Reason)
+			positions = append(positions, *current)
+			current = nil
+		}
+		if signal.Type == SignalLong || signal.Type == SignalShort {
+			current = s.openLegs(signal, i, candles[i].Timestamp, fee)
+		}
+	}
+	if current != nil {
+		last := len(candles) - 1
+		s.closeLegs(current, candles, last, fee, "End of Period")
+		positions = append(positions, *current)
+	}
+
+	return &BacktestOutput{
+		TrendLines:         s.output.TrendLines,
+		TrendColors:        s.output.TrendColors,
+		Directions:         s.output.Directions,
+		Labels:             s.output.Labels,
+		Signals:            signals,
+		Positions:          positions,
+		StrategyName:       s.GetName(),
+		StrategyVersion:    "1.0",
+		PerformanceMetrics: calculatePerformance(positions),
+	}, nil
+}
+
+func (s *PairsStrategy) openLegs(signal Signal, index int, time int64, fee float64) *Position {
+	price, hedgePrice := signal.Price, parseFloat(s.hedge[index].Close)
+	position := &Position{
+		EntryIndex: index,
+		EntryPrice: price,
+		EntryTime:  time,
+		Side:       "long",
+		Size:       s.Config.PositionSize,
+		IsOpen:     true,
+		Entries:    1,
+		BasePrice:  price,
+		Legs:       s.legs(signal.Type == SignalLong, s.Config.PositionSize, price, hedgePrice, s.series.Hedge[index]),
+	}
+	if signal.Type == SignalShort {
+		position.Side = "short"
+	}
+	for _, leg := range position.Legs {
+		position.Fees += leg.Size * leg.EntryPrice * fee
+	}
+	return position
+}
+
+func (s *PairsStrategy) closeLegs(position *Position, candles hyperliquid.Candles, index int, fee float64, reason string) {
+	position.Legs[0].ExitPrice = parseFloat(candles[index].Close)
+	position.Legs[1].ExitPrice = parseFloat(s.hedge[index].Close)
+	exitFee := 0.0
+	for _, leg := range position.Legs {
+		exitFee += leg.Size * leg.ExitPrice * fee
+	}
+	position.ExitIndex = index
+	position.ExitTime = candles[index].Timestamp
+	settleLegs(position, exitFee, reason)
+}
+
+// legs are the two legs of a position long the spread when long, sized so
+// the pair leg's notional is hedge times the strategy leg's. A negative
+// hedge ratio puts both legs on the same side.
+func (s *PairsStrategy) legs(long bool, size, price, hedgePrice, hedge float64) []PositionLeg {
+	own, other := "long", "short"
+	if !long {
+		own, other = "short", "long"
+	}
+	if hedge < 0 {
+		other = own
+	}
+	return []PositionLeg{
+		{Side: own, Size: size, EntryPrice: price},
+		{Symbol: s.pair.Symbol, Side: other, Size: math.Abs(hedge) * size * price / hedgePrice, EntryPrice: hedgePrice},
+	}
+}
+
+// run computes the spread over candles, builds the chart output and returns
+// the signals: long or short the spread on an entry, close on a reversion
+// or stop. After a stop the z-score has to come back inside the entry band
+// before the pair trades again.
+func (s *PairsStrategy) run(candles hyperliquid.Candles) ([]Signal, error) {
+	n := len(candles)
+	if len(s.hedge) != n {
+		return nil, fmt.Errorf("no %s candles aligned to the strategy's candles", s.pair.Symbol)
+	}
+	series, err := s.spread(candles)
+	if err != nil {
+		return nil, err
+	}
+	s.series = series
+
+	signals := []Signal{}
+	state, cooling := 0, false
+	for i := range candles {
+		z := series.Z[i]
+		if math.IsNaN(z) {
+			continue
+		}
+		signal := Signal{Index: i, Price: parseFloat(candles[i].Close), Time: candles[i].Timestamp}
+		switch {
+		case state == 0 && cooling:
+			cooling = math.Abs(z) >= s.pair.EntryZ
+			continue
+		case state == 0 && z >= s.pair.EntryZ:
+			state, signal.Type = -1, SignalShort
+			signal.Reason = fmt.Sprintf("Spread z %.2f", z)
+		case state == 0 && z <= -s.pair.EntryZ:
+			state, signal.Type = 1, SignalLong
+			signal.Reason = fmt.Sprintf("Spread z %.2f", z)
+		case state != 0 && s.pair.StopZ > 0 && z*float64(-state) >= s.pair.StopZ:
+			state, cooling, signal.Type = 0, true, SignalClose
+			signal.Reason = "Spread Stop"
+		case state != 0 && z*float64(-state) <= s.pair.ExitZ:
+			state, signal.Type = 0, SignalClose
+			signal.Reason = "Spread Reverted"
+		default:
+			continue
+		}
+		signals = append(signals, signal)
+	}
+
+	s.output = &StrategyOutput{
+		TrendLines:  make([]float64, n),
+		TrendColors: make([]string, n),
+		Directions:  positionDirections(n, signals),
+		Labels:      []Label{},
+		Lines:       []TrendLine{},
+		FillColors:  make([]string, n),
+	}
+	for i, direction := range s.output.Directions {
+		if direction == -1 {
+			s.output.TrendColors[i] = "#1cc2d8"
+		} else {
+			s.output.TrendColors[i] = "#e49013"
+		}
+		// The chart skips zero values; JSON has no NaN.
+		if !math.IsNaN(series.Fair[i]) {
+			s.output.TrendLines[i] = series.Fair[i]
+		}
+	}
+	return signals, nil
+}
+
+func (s *PairsStrategy) spread(candles hyperliquid.Candles) (*pairSeries, error) {
+	n := len(candles)
+	x, y := make([]float64, n), make([]float64, n)
+	for i := range candles {
+		own, other := parseFloat(candles[i].Close), parseFloat(s.hedge[i].Close)
+		x[i], y[i] = math.NaN(), math.NaN()
+		if own > 0 && other > 0 {
+			x[i], y[i] = math.Log(other), math.Log(own)
+		}
+	}
+
+	series := &pairSeries{Hedge: make([]float64, n), Fair: make([]float64, n), Z: make([]float64, n)}
+	window := s.pair.Lookback
+	for i := range series.Z {
+		series.Hedge[i], series.Fair[i], series.Z[i] = math.NaN(), math.NaN(), math.NaN()
+		if i < window-1 {
+			continue
+		}
+		var sx, sy, sxx, sxy float64
+		valid := true
+		for j := i - window + 1; j <= i; j++ {
+			if math.IsNaN(x[j]) {
+				valid = false
+				break
+			}
+			sx += x[j]
+			sy += y[j]
+			sxx += x[j] * x[j]
+			sxy += x[j] * y[j]
+		}
+		w := float64(window)
+		variance := sxx - sx*sx/w
+		if !valid || variance <= 0 {
+			continue
+		}
+		beta := (sxy - sx*sy/w) / variance
+		alpha := (sy - beta*sx) / w
+		var ss float64
+		for j := i - window + 1; j <= i; j++ {
+			r := y[j] - alpha - beta*x[j]
+			ss += r * r
+		}
+		std := math.Sqrt(ss / w)
+		if std == 0 {
+			continue
+		}
+		series.Hedge[i] = beta
+		series.Fair[i] = math.Exp(alpha + beta*x[i])
+		series.Z[i] = (y[i] - alpha - beta*x[i]) / std
+	}
+	return series, nil
+}
+
+// Trade evaluates the spread once per candle, when the newest candle is not
+// yet processed, and opens or closes both legs at market. It keeps the
+// position's leg marks current on every tick.
+func (s *PairsStrategy) Trade(live *LiveStrategy, candles hyperliquid.Candles) (bool, error) {
+	if len(candles) == 0 {
+		return false, nil
+	}
+	latest := len(candles) - 1
+	if position := live.Position; position != nil && position.IsOpen && len(position.Legs) == 2 && len(s.hedge) == len(candles) {
+		position.Legs[1].MarkPrice = parseFloat(s.hedge[latest].Close)
+	}
+	if candles[latest].Timestamp <= live.LastCandleTime {
+		return false, nil
+	}
+
+	signals, err := s.run(candles)
+	if err != nil {
+		return false, err
+	}
+	if len(signals) == 0 || signals[len(signals)-1].Index != latest {
+		return false, nil
+	}
+	signal := signals[len(signals)-1]
+	event := StrategyEvent{Price: signal.Price}
+
+	if live.Position != nil && live.Position.IsOpen {
+		if signal.Type != SignalClose {
+			return false, nil
+		}
+		live.publish(EventSignal, event, "close pair at z %.2f: %s", s.series.Z[latest], signal.Reason)
+		live.ClosePosition(signal.Reason)
+		return true, nil
+	}
+	if signal.Type == SignalClose {
+		return false, nil
+	}
+	live.publish(EventSignal, event, "%s: hedge ratio %.3f", signal.Reason, s.series.Hedge[latest])
+	return s.openLive(live, signal, parseFloat(s.hedge[latest].Close), s.series.Hedge[latest]), nil
+}
+
+// CancelOrders has nothing to cancel: the pair trades at market.
+func (s *PairsStrategy) CancelOrders(live *LiveStrategy) {}
+
+// openLive opens the strategy's leg, then the pair leg; if the pair leg
+// fails the first is closed again so the strategy never holds one leg.
+func (s *PairsStrategy) openLive(live *LiveStrategy, signal Signal, hedgePrice, hedge float64) bool {
+	legs := s.legs(signal.Type == SignalLong, s.Config.PositionSize, signal.Price, hedgePrice, hedge)
+	legs[0].Symbol = live.Symbol
+
+	position := &Position{
+		EntryTime: time.Now().UnixMilli(),
+		Side:      "long",
+		IsOpen:    true,
+		Entries:   1,
+	}
+	if signal.Type == SignalShort {
+		position.Side = "short"
+	}
+	for i := range legs {
+		leg := &legs[i]
+		event := StrategyEvent{Price: leg.EntryPrice, Size: leg.Size, Side: leg.Side}
+		live.publish(EventOrderPlaced, event, "open %s %.4f %s", leg.Side, leg.Size, leg.Symbol)
+		resp, err := live.account.OpenPositionWith(leg.Symbol, leg.Side == "long", leg.Size, 10, s.Config.Execution)
+		event.OrderID = resp.OrderID
+		if err == nil && !resp.Success {
+			err = fmt.Errorf("%s", resp.Message)
+		}
+		if err != nil {
+			live.publish(EventOrderRejected, event, "open %s %s failed: %v", leg.Side, leg.Symbol, err)
+			if i == 0 {
+				return false
+			}
+			first := legs[0]
+			live.publish(EventOrderPlaced, StrategyEvent{Side: first.Side, Size: first.Size},
+				"close %s %.4f %s: pair leg failed", first.Side, first.Size, first.Symbol)
+			if _, err := live.account.ClosePositionWith(first.Symbol, first.Size, s.Config.Execution); err != nil {
+				// Keep the lone leg so stopping the strategy closes it.
+				live.publish(EventError, StrategyEvent{}, "close %s failed, position is unhedged: %v", first.Symbol, err)
+				position.Legs = legs[:1]
+				position.Size, position.EntryPrice, position.BasePrice = first.Size, first.EntryPrice, first.EntryPrice
+				live.Position = position
+				return true
+			}
+			return false
+		}
+		if resp.FilledSize > 0 {
+			leg.Size = resp.FilledSize
+		}
+		if resp.AvgPrice > 0 {
+			leg.EntryPrice = resp.AvgPrice
+		}
+		leg.MarkPrice = leg.EntryPrice
+		position.Fees += live.liveFee(leg.Size, leg.EntryPrice)
+		event.Price, event.Size = leg.EntryPrice, leg.Size
+		live.publish(EventOrderFilled, event, "filled %s %.4f %s @ %.2f", leg.Side, leg.Size, leg.Symbol, leg.EntryPrice)
+	}
+	position.Legs = legs
+	position.Size, position.EntryPrice, position.BasePrice = legs[0].Size, legs[0].EntryPrice, legs[0].EntryPrice
+	live.Position = position
+	live.publish(EventPositionOpened, StrategyEvent{Price: position.EntryPrice, Size: position.Size, Side: position.Side},
+		"%s %s/%s spread: %s %.4f %s, %s %.4f %s", position.Side, live.Symbol, s.pair.Symbol,
+		legs[0].Side, legs[0].Size, legs[0].Symbol, legs[1].Side, legs[1].Size, legs[1].Symbol)
+	return true
+}
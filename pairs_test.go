@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// pairCandles returns the closes of a strategy symbol whose log price is 1.5
+// times its pair's plus a residual. The residual alternates between +0.001
+// and -0.001 while the pair's price moves in steps of two bars, so over an
+// even lookback the regression recovers the hedge ratio exactly and quiet
+// bars have z-scores of ±1. spikes replaces the residual at some bars.
+func pairCandles(n int, spikes map[int]float64) (own, other []float64) {
+	own, other = make([]float64, n), make([]float64, n)
+	for i := range n {
+		x := math.Log(2000) + 0.02*float64(i/2%5)
+		r := 0.001
+		if i%2 == 1 {
+			r = -0.001
+		}
+		if spike, ok := spikes[i]; ok {
+			r = spike
+		}
+		other[i] = math.Exp(x)
+		own[i] = math.Exp(math.Log(100) + 1.5*(x-math.Log(2000)) + r)
+	}
+	return own, other
+}
+
+func TestPairsSignals(t *testing.T) {
+	own, other := pairCandles(70, map[int]float64{
+		// Rich, then back to fair value.
+		24: 0.004, 25: 0,
+		// Cheap, then further out past the stop. The next bar is still past
+		// the entry but the pair waits for the spread to come back first.
+		50: -0.004, 51: -0.01, 52: -0.012, 53: 0,
+		54: -0.016,
+	})
+	strategy, err := NewPairsStrategy(map[string]any{"lookback": 20.0, "entryZ": 2.0, "exitZ": 0.5, "stopZ": 3.0, "positionSize": 1.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy.SetSymbolCandles("ETH", flatCandles(other...))
+	candles := flatCandles(own...)
+	signals, err := strategy.GenerateSignals(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series := strategy.series
+	if !math.IsNaN(series.Z[18]) {
+		t.Errorf("z-score %v before the lookback is filled", series.Z[18])
+	}
+	for i, z := range map[int]float64{19: -1, 20: 1, 21: -1} {
+		if math.Abs(series.Hedge[i]-1.5) > 1e-6 || math.Abs(series.Z[i]-z) > 1e-6 {
+			t.Errorf("bar %d: hedge %.4f, z %.4f, want 1.5 and %v", i, series.Hedge[i], series.Z[i], z)
+		}
+	}
+	if math.Abs(series.Z[50]+2.6) > 1e-6 {
+		t.Errorf("z-score %.4f at the first cheap bar, want -2.6", series.Z[50])
+	}
+
+	want := []struct {
+		index  int
+		typ    SignalType
+		reason string
+	}{
+		{24, SignalShort, "Spread z 2.93"},
+		{25, SignalClose, "Spread Reverted"},
+		{50, SignalLong, "Spread z -2.60"},
+		{51, SignalClose, "Spread Stop"},
+		{54, SignalLong, "Spread z -3.10"},
+		{55, SignalClose, "Spread Reverted"},
+	}
+	if len(signals) != len(want) {
+		t.Fatalf("signals = %+v", signals)
+	}
+	for i, w := range want {
+		if s := signals[i]; s.Index != w.index || s.Type != w.typ || s.Reason != w.reason {
+			t.Errorf("signal %d = %d %v %q, want %d %v %q", i, s.Index, s.Type, s.Reason, w.index, w.typ, w.reason)
+		}
+	}
+
+	// The short spread sells the strategy's symbol and buys 1.5 times its
+	// notional of the pair.
+	output, err := strategy.Backtest(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legs := output.Positions[0].Legs
+	if legs[0].Side != "short" || legs[1].Side != "long" || legs[1].Symbol != "ETH" {
+		t.Fatalf("legs = %+v", legs)
+	}
+	if ratio := legs[1].Size * legs[1].EntryPrice / (legs[0].Size * legs[0].EntryPrice); math.Abs(ratio-series.Hedge[24]) > 1e-9 {
+		t.Errorf("pair leg notional ratio %.4f, want the hedge ratio %.4f", ratio, series.Hedge[24])
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"time"
+)
+
+// PerformanceMetrics summarises closed trades. Backtests and live strategies
+// compute it with calculatePerformance so their numbers compare directly.
+// Drawdown is measured on the realized equity curve and is negative.
+type PerformanceMetrics struct {
+	TotalPnL           float64
+	TotalPnLPercent    float64
+	WinRate            float64
+	TotalTrades        int
+	WinningTrades      int
+	LosingTrades       int
+	AverageWin         float64
+	AverageLoss        float64
+	ProfitFactor       float64
+	MaxDrawdown        float64
+	MaxDrawdownPercent float64
+	SharpeRatio        float64
+	LongestWinStreak   int
+	LongestLossStreak  int
+	AverageHoldTime    time.Duration
+	FeesPaid           float64
+}
+
+func calculatePerformance(positions []Position) PerformanceMetrics {
+	var result PerformanceMetrics
+	var totalWin, totalLoss float64
+	var currentWinStreak, currentLossStreak int
+	var totalHoldTime time.Duration
+	var totalCapitalInvested float64
+	var equity, peak float64
+
+	for _, pos := range positions {
+		if pos.IsOpen {
+			continue
+		}
+
+		result.TotalTrades++
+		result.TotalPnL += pos.PnL
+		result.FeesPaid += pos.Fees
+		totalCapitalInvested += pos.Size * pos.EntryPrice
+
+		if pos.PnL > 0 {
+			result.WinningTrades++
+			totalWin += pos.PnL
+			currentWinStreak++
+			currentLossStreak = 0
+			result.LongestWinStreak = max(result.LongestWinStreak, currentWinStreak)
+		} else {
+			result.LosingTrades++
+			totalLoss += -pos.PnL
+			currentLossStreak++
+			currentWinStreak = 0
+			result.LongestLossStreak = max(result.LongestLossStreak, currentLossStreak)
+		}
+
+		equity += pos.PnL
+		peak = max(peak, equity)
+		result.MaxDrawdown = min(result.MaxDrawdown, equity-peak)
+
+		totalHoldTime += time.Duration(pos.ExitTime-pos.EntryTime) * time.Millisecond
+	}
+
+	if result.TotalTrades > 0 {
+		result.WinRate = (float64(result.WinningTrades) / float64(result.TotalTrades)) * 100
+		result.AverageHoldTime = totalHoldTime / time.Duration(result.TotalTrades)
+
+		avgCapitalInvested := totalCapitalInvested / float64(result.TotalTrades)
+		if avgCapitalInvested > 0 {
+			result.TotalPnLPercent = (result.TotalPnL / avgCapitalInvested) * 100
+			result.MaxDrawdownPercent = (result.MaxDrawdown / avgCapitalInvested) * 100
+		}
+	}
+
+	if result.WinningTrades > 0 {
+		result.AverageWin = totalWin / float64(result.WinningTrades)
+	}
+	if result.LosingTrades > 0 {
+		result.AverageLoss = totalLoss / float64(result.LosingTrades)
+	}
+	if totalLoss > 0 {
+		result.ProfitFactor = totalWin / totalLoss
+	}
+
+	return result
+}
+
+// settlePosition closes position at exitPrice, adding exitFee to its fees and
+// computing net PnL. It is shared by backtests and live trading.
+func settlePosition(position *Position, exitPrice, exitFee float64, reason string) {
+	position.ExitPrice = exitPrice
+	position.IsOpen = false
+	position.ExitReason = reason
+
+	priceDiff := 0.0
+	if position.Side == "long" {
+		priceDiff = exitPrice - position.EntryPrice
+	} else {
+		priceDiff = position.EntryPrice - exitPrice
+	}
+
+	position.Fees += exitFee
+	position.PnL = position.Size*priceDiff - position.Fees
+	position.PnLPercentage = position.PnL / (position.Size * position.EntryPrice) * 100
+}
+
+// settleLegs closes a multi-symbol position whose legs all have an exit
+// price. Its PnL is the legs' net of fees and its percentage is relative to
+// their combined entry notional.
+func settleLegs(position *Position, exitFee float64, reason string) {
+	position.IsOpen = false
+	position.ExitReason = reason
+	position.ExitPrice = position.Legs[0].ExitPrice
+	position.Fees += exitFee
+
+	pnl, notional := 0.0, 0.0
+	for _, leg := range position.Legs {
+		pnl += legPnL(leg, leg.ExitPrice)
+		notional += leg.Size * leg.EntryPrice
+	}
+	position.PnL = pnl - position.Fees
+	if notional > 0 {
+		position.PnLPercentage = position.PnL / notional * 100
+	}
+}
+
+// legPnL is the gross PnL of leg at price.
+func legPnL(leg PositionLeg, price float64) float64 {
+	if leg.Side == "long" {
+		return leg.Size * (price - leg.EntryPrice)
+	}
+	return leg.Size * (leg.EntryPrice - price)
+}
+
+// legsPnL is the gross PnL of a multi-symbol position at its legs' marks.
+func legsPnL(position *Position) float64 {
+	pnl := 0.0
+	for _, leg := range position.Legs {
+		if leg.MarkPrice > 0 {
+			pnl += legPnL(leg, leg.MarkPrice)
+		}
+	}
+	return pnl
+}
+
+// liveFee estimates the fee of a live fill. Order responses carry no fee, so
+// post-only fills are charged the maker rate and everything else the taker
+// rate.
+func (s *LiveStrategy) liveFee(size, price float64) float64 {
+	exec := s.Config.Execution
+	return size * price * exec.feePercent(exec.Mode == ExecutionPostOnly) / 100
+}
+
+// markPosition revalues the open position at price and tracks its best and
+// worst excursion.
+func (s *LiveStrategy) markPosition(price float64) {
+	s.MarkPrice = price
+	s.UnrealizedPnL = 0
+	if s.Position == nil || !s.Position.IsOpen {
+		return
+	}
+	pnl := positionPnL(s.Position, price)
+	if len(s.Position.Legs) > 0 {
+		s.Position.Legs[0].MarkPrice = price
+		pnl = legsPnL(s.Position)
+	}
+	s.UnrealizedPnL = pnl
+	s.Position.MaxProfit = max(s.Position.MaxProfit, pnl)
+	s.Position.MaxDrawdown = min(s.Position.MaxDrawdown, pnl)
+}
+
+// recordClose settles the open position and appends it to the trade journal.
+// An exitPrice of zero (unknown fill) falls back to the last mark price. The
+// legs of a multi-symbol position still open exit at exitPrice for the first
+// and at their last mark for the others.
+func (s *LiveStrategy) recordClose(exitPrice float64, reason string) {
+	position := s.Position
+	if exitPrice <= 0 {
+		exitPrice = s.MarkPrice
+	}
+	if exitPrice <= 0 {
+		exitPrice = position.EntryPrice
+	}
+	position.ExitTime = time.Now().UnixMilli()
+	if len(position.Legs) > 0 {
+		exitFee := 0.0
+		for i := range position.Legs {
+			leg := &position.Legs[i]
+			if leg.ExitPrice <= 0 && i == 0 {
+				leg.ExitPrice = exitPrice
+			}
+			if leg.ExitPrice <= 0 {
+				leg.ExitPrice = leg.MarkPrice
+			}
+			if leg.ExitPrice <= 0 {
+				leg.ExitPrice = leg.EntryPrice
+			}
+			exitFee += s.liveFee(leg.Size, leg.ExitPrice)
+		}
+		settleLegs(position, exitFee, reason)
+	} else {
+		settlePosition(position, exitPrice, s.liveFee(position.Size, exitPrice), reason)
+	}
+	s.Trades = append(s.Trades, *position)
+	s.UnrealizedPnL = 0
+}
@@ -0,0 +1,158 @@
+package pine
+
+// Nodes are compared by pointer: the interpreter keys per-call-site state
+// (indicator streams, history, user function instances) on them.
+
+type expr interface{ line() int }
+
+type stmt interface{ line() int }
+
+type pos struct{ ln int }
+
+func (p pos) line() int { return p.ln }
+
+type numberLit struct {
+	pos
+	value float64
+}
+
+type stringLit struct {
+	pos
+	value string
+}
+
+type boolLit struct {
+	pos
+	value bool
+}
+
+type colorLit struct {
+	pos
+	value string
+}
+
+// ident is a name, possibly dotted ("close", "ta.tr", "color.red").
+type ident struct {
+	pos
+	name string
+}
+
+type namedArg struct {
+	name  string
+	value expr
+}
+
+// call is a function call. fn is dotted; when its first part is a variable
+// the call is a method on that variable's value.
+type call struct {
+	pos
+	fn    string
+	args  []expr
+	named []namedArg
+}
+
+// methodCall is a method on the result of an arbitrary expression.
+type methodCall struct {
+	pos
+	recv   expr
+	method string
+	args   []expr
+	named  []namedArg
+}
+
+// history is the [] operator: x[offset] is x offset bars ago.
+type history struct {
+	pos
+	x      expr
+	offset expr
+}
+
+type unary struct {
+	pos
+	op string
+	x  expr
+}
+
+type binary struct {
+	pos
+	op   string
+	l, r expr
+}
+
+type ternary struct {
+	pos
+	cond, then, els expr
+}
+
+type tupleLit struct {
+	pos
+	items []expr
+}
+
+type varDecl struct {
+	pos
+	mode  string // "", "var" or "varip"
+	name  string
+	value expr
+}
+
+type tupleDecl struct {
+	pos
+	names []string
+	value expr
+}
+
+type assign struct {
+	pos
+	name  string
+	op    string
+	value expr
+}
+
+type exprStmt struct {
+	pos
+	x expr
+}
+
+type ifStmt struct {
+	pos
+	cond expr
+	then []stmt
+	els  []stmt
+}
+
+type forStmt struct {
+	pos
+	name           string
+	from, to, step expr
+	body           []stmt
+}
+
+type forInStmt struct {
+	pos
+	index, name string
+	x           expr
+	body        []stmt
+}
+
+type whileStmt struct {
+	pos
+	cond expr
+	body []stmt
+}
+
+type breakStmt struct{ pos }
+
+type continueStmt struct{ pos }
+
+type param struct {
+	name string
+	def  expr
+}
+
+type funcDef struct {
+	pos
+	name   string
+	params []param
+	body   []stmt
+}
@@ -0,0 +1,718 @@
+package pine
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type builtin func(m *machine, n expr, a args) (any, error)
+
+// builtins is filled in init: the array functions dispatch through
+// machine.method, which refers back to this map.
+var builtins map[string]builtin
+
+func init() {
+	builtins = make(map[string]builtin)
+	for _, table := range []map[string]builtin{mathBuiltins, colorBuiltins, strBuiltins, arrayBuiltins, taBuiltins, scriptBuiltins} {
+		for name, f := range table {
+			builtins[name] = f
+		}
+	}
+}
+
+// drawingNamespaces are drawing objects. Their functions return na and
+// their methods on na are no-ops, so scripts that draw still run.
+var drawingNamespaces = map[string]bool{
+	"line": true, "label": true, "box": true, "table": true, "linefill": true, "polyline": true,
+}
+
+// ignoredCalls are declarations and visual-only calls with no effect on
+// plots or orders.
+var ignoredCalls = map[string]bool{
+	"indicator": true, "study": true, "strategy": true, "plotshape": true, "plotchar": true,
+	"plotarrow": true, "plotcandle": true, "plotbar": true, "fill": true, "bgcolor": true,
+	"barcolor": true, "hline": true, "alertcondition": true, "alert": true, "max_bars_back": true,
+	"strategy.cancel": true, "strategy.cancel_all": true, "log.info": true, "log.warning": true,
+	"log.error": true,
+}
+
+var unsupportedCalls = map[string]bool{
+	"request.security": true, "request.security_lower_tf": true, "request.financial": true,
+	"strategy.exit": true, "strategy.order": true, "matrix.new": true, "map.new": true,
+}
+
+// decorationNamespaces hold style constants; they evaluate to their own name.
+var decorationNamespaces = map[string]bool{
+	"size": true, "shape": true, "location": true, "style": true, "display": true, "extend": true,
+	"position": true, "text": true, "font": true, "xloc": true, "yloc": true, "plot": true,
+	"hline": true, "format": true, "barmerge": true, "order": true, "adjustment": true,
+	"currency": true, "scale": true, "alert": true,
+}
+
+var namedColors = map[string]string{
+	"aqua": "#00bcd4", "black": "#363a45", "blue": "#2196f3", "fuchsia": "#e040fb",
+	"gray": "#787b86", "green": "#4caf50", "lime": "#00e676", "maroon": "#880e4f",
+	"navy": "#311b92", "olive": "#808000", "orange": "#ff9800", "purple": "#9c27b0",
+	"red": "#f23645", "silver": "#b2b5be", "teal": "#089981", "white": "#ffffff",
+	"yellow": "#fdd835",
+}
+
+// barVar returns the price and time variables, which have history at any
+// bar without being recorded.
+func (m *machine) barVar(name string, bar int) (any, bool) {
+	if bar < 0 || bar >= len(m.bars) {
+		switch name {
+		case "open", "high", "low", "close", "volume", "hl2", "hlc3", "ohlc4", "hlcc4", "time", "bar_index":
+			return math.NaN(), true
+		}
+		return nil, false
+	}
+	b := m.bars[bar]
+	switch name {
+	case "open":
+		return b.Open, true
+	case "high":
+		return b.High, true
+	case "low":
+		return b.Low, true
+	case "close":
+		return b.Close, true
+	case "volume":
+		return b.Volume, true
+	case "hl2":
+		return (b.High + b.Low) / 2, true
+	case "hlc3":
+		return (b.High + b.Low + b.Close) / 3, true
+	case "ohlc4":
+		return (b.Open + b.High + b.Low + b.Close) / 4, true
+	case "hlcc4":
+		return (b.High + b.Low + 2*b.Close) / 4, true
+	case "time":
+		return float64(b.Time), true
+	case "bar_index":
+		return float64(bar), true
+	}
+	return nil, false
+}
+
+func (m *machine) builtinVar(n expr, name string, bar int) (any, bool, error) {
+	if v, ok := m.barVar(name, bar); ok {
+		return v, true, nil
+	}
+	switch name {
+	case "na":
+		return math.NaN(), true, nil
+	case "last_bar_index":
+		return float64(len(m.bars) - 1), true, nil
+	case "barstate.isfirst":
+		return bar == 0, true, nil
+	case "barstate.islast", "barstate.isrealtime":
+		return bar == len(m.bars)-1, true, nil
+	case "barstate.ishistory":
+		return bar < len(m.bars)-1, true, nil
+	case "barstate.isconfirmed", "barstate.isnew":
+		return true, true, nil
+	case "strategy.long":
+		return "long", true, nil
+	case "strategy.short":
+		return "short", true, nil
+	case "strategy.position_size":
+		return m.position, true, nil
+	case "math.pi":
+		return math.Pi, true, nil
+	case "math.e":
+		return math.E, true, nil
+	case "chart.fg_color":
+		return "#d1d4dc", true, nil
+	case "chart.bg_color":
+		return "#131722", true, nil
+	case "syminfo.mintick":
+		return 0.01, true, nil
+	case "syminfo.ticker", "syminfo.tickerid", "timeframe.period":
+		return "", true, nil
+	case "ta.tr":
+		return trueRange(m, bar, false), true, nil
+	case "ta.vwap":
+		v, err := taBuiltins["ta.vwap"](m, n, args{})
+		return v, true, err
+	case "ta.obv":
+		v, err := taBuiltins["ta.obv"](m, n, args{})
+		return v, true, err
+	}
+	ns, rest, _ := strings.Cut(name, ".")
+	if ns == "color" {
+		if c, ok := namedColors[rest]; ok {
+			return c, true, nil
+		}
+	}
+	if decorationNamespaces[ns] || drawingNamespaces[ns] && rest != "" {
+		return name, true, nil
+	}
+	return nil, false, nil
+}
+
+func number(f func(float64) float64) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		return f(a.float(0, "number", math.NaN())), nil
+	}
+}
+
+var mathBuiltins = map[string]builtin{
+	"math.abs":   number(math.Abs),
+	"math.floor": number(math.Floor),
+	"math.ceil":  number(math.Ceil),
+	"math.sqrt":  number(math.Sqrt),
+	"math.exp":   number(math.Exp),
+	"math.log":   number(math.Log),
+	"math.log10": number(math.Log10),
+	"math.sin":   number(math.Sin),
+	"math.cos":   number(math.Cos),
+	"math.tan":   number(math.Tan),
+	"math.asin":  number(math.Asin),
+	"math.acos":  number(math.Acos),
+	"math.atan":  number(math.Atan),
+	"math.toradians": number(func(x float64) float64 {
+		return x * math.Pi / 180
+	}),
+	"math.todegrees": number(func(x float64) float64 {
+		return x * 180 / math.Pi
+	}),
+	"math.sign": number(func(x float64) float64 {
+		switch {
+		case x > 0:
+			return 1
+		case x < 0:
+			return -1
+		}
+		return x
+	}),
+	"math.round": func(m *machine, n expr, a args) (any, error) {
+		x := a.float(0, "number", math.NaN())
+		p := math.Pow(10, a.float(1, "precision", 0))
+		return math.Round(x*p) / p, nil
+	},
+	"math.pow": func(m *machine, n expr, a args) (any, error) {
+		return math.Pow(a.float(0, "base", math.NaN()), a.float(1, "exponent", math.NaN())), nil
+	},
+	"math.max": func(m *machine, n expr, a args) (any, error) {
+		return extremeOf(a.pos, math.Max), nil
+	},
+	"math.min": func(m *machine, n expr, a args) (any, error) {
+		return extremeOf(a.pos, math.Min), nil
+	},
+	"math.avg": func(m *machine, n expr, a args) (any, error) {
+		sum := 0.0
+		for _, v := range a.pos {
+			sum += toFloat(v)
+		}
+		return sum / float64(len(a.pos)), nil
+	},
+	"nz": func(m *machine, n expr, a args) (any, error) {
+		v, _ := a.get(0, "source")
+		if isNa(v) {
+			if r, ok := a.get(1, "replacement"); ok {
+				return r, nil
+			}
+			return 0.0, nil
+		}
+		return v, nil
+	},
+	"na": func(m *machine, n expr, a args) (any, error) {
+		v, _ := a.get(0, "x")
+		return isNa(v), nil
+	},
+	"fixnan": func(m *machine, n expr, a args) (any, error) {
+		v, _ := a.get(0, "source")
+		if !isNa(v) {
+			m.inst.states[n] = v
+			return v, nil
+		}
+		if last, ok := m.inst.states[n]; ok {
+			return last, nil
+		}
+		return math.NaN(), nil
+	},
+}
+
+func extremeOf(values []any, pick func(a, b float64) float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	out := toFloat(values[0])
+	for _, v := range values[1:] {
+		out = pick(out, toFloat(v))
+	}
+	return out
+}
+
+// parseColor splits "#rrggbb" or "#rrggbbaa" into channels and alpha.
+func parseColor(c string) (r, g, b, alpha uint8, ok bool) {
+	if len(c) != 7 && len(c) != 9 || c[0] != '#' {
+		return 0, 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(c[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, 0, false
+	}
+	if len(c) == 7 {
+		v = v<<8 | 0xff
+	}
+	return uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v), true
+}
+
+// formatColor renders a color, dropping the alpha when it is opaque.
+func formatColor(r, g, b uint8, transp float64) string {
+	if math.IsNaN(transp) || transp <= 0 {
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+	alpha := math.Round(255 * (100 - math.Min(transp, 100)) / 100)
+	return fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, uint8(alpha))
+}
+
+func channel(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+var colorBuiltins = map[string]builtin{
+	"color.new": func(m *machine, n expr, a args) (any, error) {
+		c, _ := a.get(0, "color")
+		s, ok := c.(string)
+		if !ok {
+			return math.NaN(), nil
+		}
+		r, g, b, _, ok := parseColor(s)
+		if !ok {
+			return nil, fail(n, "invalid color %q", s)
+		}
+		return formatColor(r, g, b, a.float(1, "transp", 0)), nil
+	},
+	"color.rgb": func(m *machine, n expr, a args) (any, error) {
+		r, g, b := a.float(0, "red", 0), a.float(1, "green", 0), a.float(2, "blue", 0)
+		return formatColor(channel(r), channel(g), channel(b), a.float(3, "transp", 0)), nil
+	},
+	"color.r": colorChannel(0),
+	"color.g": colorChannel(1),
+	"color.b": colorChannel(2),
+	"color.t": colorChannel(3),
+}
+
+func colorChannel(i int) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		c, _ := a.get(0, "color")
+		s, _ := c.(string)
+		r, g, b, alpha, ok := parseColor(s)
+		if !ok {
+			return math.NaN(), nil
+		}
+		if i == 3 {
+			return math.Round(100 - float64(alpha)*100/255), nil
+		}
+		return float64([]uint8{r, g, b}[i]), nil
+	}
+}
+
+// formatNumber renders v like str.tostring. format is a format.* constant
+// or a pattern such as "#.##".
+func formatNumber(v float64, format string) string {
+	if math.IsNaN(v) {
+		return "NaN"
+	}
+	switch format {
+	case "format.percent":
+		return strconv.FormatFloat(v, 'f', 2, 64) + "%"
+	case "format.mintick", "format.price":
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case "format.volume":
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	decimals := 8
+	if _, frac, ok := strings.Cut(format, "."); ok {
+		decimals = len(frac)
+	} else if format != "" && !strings.HasPrefix(format, "format.") {
+		decimals = 0
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if strings.Contains(s, ".") && !strings.Contains(format, ".0") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+var strBuiltins = map[string]builtin{
+	"str.tostring": func(m *machine, n expr, a args) (any, error) {
+		v, _ := a.get(0, "value")
+		if f, ok := v.(float64); ok {
+			return formatNumber(f, a.str(1, "format")), nil
+		}
+		return toString(v), nil
+	},
+	"str.tonumber": func(m *machine, n expr, a args) (any, error) {
+		f, err := strconv.ParseFloat(strings.TrimSpace(a.str(0, "string")), 64)
+		if err != nil {
+			return math.NaN(), nil
+		}
+		return f, nil
+	},
+	"str.length": func(m *machine, n expr, a args) (any, error) {
+		return float64(len([]rune(a.str(0, "string")))), nil
+	},
+	"str.contains": func(m *machine, n expr, a args) (any, error) {
+		return strings.Contains(a.str(0, "source"), a.str(1, "str")), nil
+	},
+	"str.startswith": func(m *machine, n expr, a args) (any, error) {
+		return strings.HasPrefix(a.str(0, "source"), a.str(1, "str")), nil
+	},
+	"str.endswith": func(m *machine, n expr, a args) (any, error) {
+		return strings.HasSuffix(a.str(0, "source"), a.str(1, "str")), nil
+	},
+	"str.upper": func(m *machine, n expr, a args) (any, error) {
+		return strings.ToUpper(a.str(0, "source")), nil
+	},
+	"str.lower": func(m *machine, n expr, a args) (any, error) {
+		return strings.ToLower(a.str(0, "source")), nil
+	},
+	"str.replace_all": func(m *machine, n expr, a args) (any, error) {
+		return strings.ReplaceAll(a.str(0, "source"), a.str(1, "target"), a.str(2, "replacement")), nil
+	},
+	"str.format": func(m *machine, n expr, a args) (any, error) {
+		s := a.str(0, "formatString")
+		for i, v := range a.pos[min(1, len(a.pos)):] {
+			s = strings.ReplaceAll(s, fmt.Sprintf("{%d}", i), toString(v))
+		}
+		return s, nil
+	},
+}
+
+// array is a Pine array. Arrays are references: a var array is the same
+// array on every bar.
+type array struct {
+	vals []any
+}
+
+func arrayArg(n expr, a args) (*array, error) {
+	if len(a.pos) > 0 {
+		if arr, ok := a.pos[0].(*array); ok {
+			return arr, nil
+		}
+	}
+	return nil, fail(n, "expected an array")
+}
+
+func arrayFunc(f func(arr *array, a args) (any, error)) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		arr, err := arrayArg(n, a)
+		if err != nil {
+			return nil, err
+		}
+		a.pos = a.pos[1:]
+		v, err := f(arr, a)
+		if err != nil {
+			return nil, fail(n, "%v", err)
+		}
+		return v, nil
+	}
+}
+
+func (arr *array) index(i float64) (int, error) {
+	idx := int(i)
+	if idx < 0 {
+		idx += len(arr.vals)
+	}
+	if math.IsNaN(i) || idx < 0 || idx >= len(arr.vals) {
+		return 0, fmt.Errorf("array index %v is out of bounds, size %d", i, len(arr.vals))
+	}
+	return idx, nil
+}
+
+func (arr *array) floats() []float64 {
+	out := make([]float64, 0, len(arr.vals))
+	for _, v := range arr.vals {
+		if f := toFloat(v); !math.IsNaN(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func newArray(m *machine, n expr, a args) (any, error) {
+	size := a.float(0, "size", 0)
+	if math.IsNaN(size) || size < 0 {
+		return nil, fail(n, "invalid array size")
+	}
+	initial, ok := a.get(1, "initial_value")
+	if !ok {
+		initial = math.NaN()
+	}
+	arr := &array{vals: make([]any, int(size))}
+	for i := range arr.vals {
+		arr.vals[i] = initial
+	}
+	return arr, nil
+}
+
+var arrayBuiltins = map[string]builtin{
+	"array.new":        newArray,
+	"array.new_float":  newArray,
+	"array.new_int":    newArray,
+	"array.new_bool":   newArray,
+	"array.new_string": newArray,
+	"array.new_color":  newArray,
+	"array.new_line":   newArray,
+	"array.new_label":  newArray,
+	"array.new_box":    newArray,
+	"array.from": func(m *machine, n expr, a args) (any, error) {
+		return &array{vals: slices.Clone(a.pos)}, nil
+	},
+	"array.size": arrayFunc(func(arr *array, a args) (any, error) {
+		return float64(len(arr.vals)), nil
+	}),
+	"array.push": arrayFunc(func(arr *array, a args) (any, error) {
+		v, _ := a.get(0, "value")
+		arr.vals = append(arr.vals, v)
+		return math.NaN(), nil
+	}),
+	"array.unshift": arrayFunc(func(arr *array, a args) (any, error) {
+		v, _ := a.get(0, "value")
+		arr.vals = append([]any{v}, arr.vals...)
+		return math.NaN(), nil
+	}),
+	"array.pop": arrayFunc(func(arr *array, a args) (any, error) {
+		if len(arr.vals) == 0 {
+			return nil, fmt.Errorf("pop from an empty array")
+		}
+		v := arr.vals[len(arr.vals)-1]
+		arr.vals = arr.vals[:len(arr.vals)-1]
+		return v, nil
+	}),
+	"array.shift": arrayFunc(func(arr *array, a args) (any, error) {
+		if len(arr.vals) == 0 {
+			return nil, fmt.Errorf("shift from an empty array")
+		}
+		v := arr.vals[0]
+		arr.vals = arr.vals[1:]
+		return v, nil
+	}),
+	"array.get": arrayFunc(func(arr *array, a args) (any, error) {
+		i, err := arr.index(a.float(0, "index", math.NaN()))
+		if err != nil {
+			return nil, err
+		}
+		return arr.vals[i], nil
+	}),
+	"array.set": arrayFunc(func(arr *array, a args) (any, error) {
+		i, err := arr.index(a.float(0, "index", math.NaN()))
+		if err != nil {
+			return nil, err
+		}
+		arr.vals[i], _ = a.get(1, "value")
+		return math.NaN(), nil
+	}),
+	"array.insert": arrayFunc(func(arr *array, a args) (any, error) {
+		i := int(a.float(0, "index", math.NaN()))
+		if i < 0 || i > len(arr.vals) {
+			return nil, fmt.Errorf("array index %d is out of bounds, size %d", i, len(arr.vals))
+		}
+		v, _ := a.get(1, "value")
+		arr.vals = slices.Insert(arr.vals, i, v)
+		return math.NaN(), nil
+	}),
+	"array.remove": arrayFunc(func(arr *array, a args) (any, error) {
+		i, err := arr.index(a.float(0, "index", math.NaN()))
+		if err != nil {
+			return nil, err
+		}
+		v := arr.vals[i]
+		arr.vals = slices.Delete(arr.vals, i, i+1)
+		return v, nil
+	}),
+	"array.clear": arrayFunc(func(arr *array, a args) (any, error) {
+		arr.vals = arr.vals[:0]
+		return math.NaN(), nil
+	}),
+	"array.first": arrayFunc(func(arr *array, a args) (any, error) {
+		if len(arr.vals) == 0 {
+			return nil, fmt.Errorf("first of an empty array")
+		}
+		return arr.vals[0], nil
+	}),
+	"array.last": arrayFunc(func(arr *array, a args) (any, error) {
+		if len(arr.vals) == 0 {
+			return nil, fmt.Errorf("last of an empty array")
+		}
+		return arr.vals[len(arr.vals)-1], nil
+	}),
+	"array.copy": arrayFunc(func(arr *array, a args) (any, error) {
+		return &array{vals: slices.Clone(arr.vals)}, nil
+	}),
+	"array.reverse": arrayFunc(func(arr *array, a args) (any, error) {
+		slices.Reverse(arr.vals)
+		return math.NaN(), nil
+	}),
+	"array.sort": arrayFunc(func(arr *array, a args) (any, error) {
+		desc := a.str(0, "order") == "order.descending"
+		slices.SortStableFunc(arr.vals, func(x, y any) int {
+			c := 0
+			switch fx, fy := toFloat(x), toFloat(y); {
+			case fx < fy:
+				c = -1
+			case fx > fy:
+				c = 1
+			}
+			if desc {
+				return -c
+			}
+			return c
+		})
+		return math.NaN(), nil
+	}),
+	"array.includes": arrayFunc(func(arr *array, a args) (any, error) {
+		v, _ := a.get(0, "value")
+		return slices.ContainsFunc(arr.vals, func(x any) bool { return equal(x, v) }), nil
+	}),
+	"array.indexof": arrayFunc(func(arr *array, a args) (any, error) {
+		v, _ := a.get(0, "value")
+		return float64(slices.IndexFunc(arr.vals, func(x any) bool { return equal(x, v) })), nil
+	}),
+	"array.lastindexof": arrayFunc(func(arr *array, a args) (any, error) {
+		v, _ := a.get(0, "value")
+		for i := len(arr.vals) - 1; i >= 0; i-- {
+			if equal(arr.vals[i], v) {
+				return float64(i), nil
+			}
+		}
+		return -1.0, nil
+	}),
+	"array.max": arrayFunc(func(arr *array, a args) (any, error) {
+		values := arr.floats()
+		if len(values) == 0 {
+			return math.NaN(), nil
+		}
+		return slices.Max(values), nil
+	}),
+	"array.min": arrayFunc(func(arr *array, a args) (any, error) {
+		values := arr.floats()
+		if len(values) == 0 {
+			return math.NaN(), nil
+		}
+		return slices.Min(values), nil
+	}),
+	"array.sum": arrayFunc(func(arr *array, a args) (any, error) {
+		sum := 0.0
+		for _, v := range arr.floats() {
+			sum += v
+		}
+		return sum, nil
+	}),
+	"array.avg": arrayFunc(func(arr *array, a args) (any, error) {
+		values := arr.floats()
+		if len(values) == 0 {
+			return math.NaN(), nil
+		}
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), nil
+	}),
+}
+
+func cast(f func(v any) any) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		v, _ := a.get(0, "x")
+		return f(v), nil
+	}
+}
+
+func identity(v any) any { return v }
+
+var scriptBuiltins = map[string]builtin{
+	"int": cast(func(v any) any { return math.Trunc(toFloat(v)) }),
+	"float": cast(func(v any) any {
+		return toFloat(v)
+	}),
+	"bool":   cast(func(v any) any { return toBool(v) }),
+	"string": cast(identity),
+	"color":  cast(identity),
+	"line":   cast(identity),
+	"label":  cast(identity),
+	"box":    cast(identity),
+	"table":  cast(identity),
+	"runtime.error": func(m *machine, n expr, a args) (any, error) {
+		return nil, fail(n, "%s", a.str(0, "message"))
+	},
+	"plot": func(m *machine, n expr, a args) (any, error) {
+		c := n.(*call)
+		i, ok := m.plots[c]
+		if !ok {
+			title := a.str(1, "title")
+			if title == "" {
+				title = fmt.Sprintf("Plot %d", len(m.result.Plots)+1)
+			}
+			plot := Plot{Title: title, Values: make([]float64, len(m.bars)), Colors: make([]string, len(m.bars))}
+			for j := range plot.Values {
+				plot.Values[j] = math.NaN()
+			}
+			i = len(m.result.Plots)
+			m.plots[c] = i
+			m.result.Plots = append(m.result.Plots, plot)
+		}
+		plot := &m.result.Plots[i]
+		plot.Values[m.bar] = a.float(0, "series", math.NaN())
+		plot.Colors[m.bar] = "#2962ff"
+		if color, ok := a.get(2, "color"); ok {
+			plot.Colors[m.bar], _ = color.(string)
+		}
+		return math.NaN(), nil
+	},
+	"strategy.entry": func(m *machine, n expr, a args) (any, error) {
+		if when, ok := a.get(-1, "when"); ok && !toBool(when) {
+			return math.NaN(), nil
+		}
+		side := a.str(1, "direction")
+		if side != "long" && side != "short" {
+			return nil, fail(n, "strategy.entry direction must be strategy.long or strategy.short")
+		}
+		// Entries in the direction of the open position are ignored, as
+		// with Pine's default pyramiding of 0.
+		size := 1.0
+		if side == "short" {
+			size = -1
+		}
+		if m.position == size {
+			return math.NaN(), nil
+		}
+		m.position, m.entryID = size, a.str(0, "id")
+		m.order(side, m.entryID, a.str(-1, "comment"))
+		return math.NaN(), nil
+	},
+	"strategy.close": func(m *machine, n expr, a args) (any, error) {
+		if when, ok := a.get(-1, "when"); ok && !toBool(when) {
+			return math.NaN(), nil
+		}
+		id := a.str(0, "id")
+		if m.position == 0 || id != m.entryID {
+			return math.NaN(), nil
+		}
+		m.position = 0
+		m.order("close", id, a.str(1, "comment"))
+		return math.NaN(), nil
+	},
+	"strategy.close_all": func(m *machine, n expr, a args) (any, error) {
+		if when, ok := a.get(-1, "when"); ok && !toBool(when) {
+			return math.NaN(), nil
+		}
+		if m.position == 0 {
+			return math.NaN(), nil
+		}
+		m.position = 0
+		m.order("close", m.entryID, a.str(0, "comment"))
+		return math.NaN(), nil
+	},
+}
+
+func (m *machine) order(action, id, comment string) {
+	m.result.Orders = append(m.result.Orders, Order{Bar: m.bar, Action: action, ID: id, Comment: comment})
+}
@@ -0,0 +1,847 @@
+package pine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Values are float64 (NaN is na), bool, string (colors are hex strings),
+// []any for tuples and *array. nil is treated as na.
+
+// series is the history of one variable, indexed by bar.
+type series struct {
+	start int
+	vals  []any
+}
+
+func (s *series) set(bar int, v any) {
+	if len(s.vals) == 0 {
+		s.start = bar
+	}
+	for s.start+len(s.vals) <= bar {
+		s.vals = append(s.vals, nil)
+	}
+	s.vals[bar-s.start] = v
+}
+
+func (s *series) get(bar int) any {
+	i := bar - s.start
+	if i < 0 || i >= len(s.vals) {
+		return nil
+	}
+	return s.vals[i]
+}
+
+func (s *series) last() any {
+	if len(s.vals) == 0 {
+		return nil
+	}
+	return s.vals[len(s.vals)-1]
+}
+
+// instance holds the state of one execution context: the script itself or
+// one call site of a user function. Keys are AST nodes, so every
+// declaration and every ta.* call keeps its own history and indicator state.
+type instance struct {
+	vars     map[any]*series
+	states   map[any]any
+	children map[*call]*instance
+}
+
+func newInstance() *instance {
+	return &instance{
+		vars:     make(map[any]*series),
+		states:   make(map[any]any),
+		children: make(map[*call]*instance),
+	}
+}
+
+func (in *instance) series(key any) *series {
+	s, ok := in.vars[key]
+	if !ok {
+		s = &series{}
+		in.vars[key] = s
+	}
+	return s
+}
+
+type tupleKey struct {
+	decl *tupleDecl
+	i    int
+}
+
+type paramKey int
+
+type scope struct {
+	vars   map[string]*series
+	parent *scope
+}
+
+func (s *scope) lookup(name string) *series {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+type control int
+
+const (
+	ctrlNone control = iota
+	ctrlBreak
+	ctrlContinue
+)
+
+type machine struct {
+	script   *Script
+	bars     []Bar
+	inputs   map[string]any
+	bar      int
+	root     *instance
+	inst     *instance
+	scope    *scope
+	globals  *scope
+	result   *Result
+	plots    map[*call]int
+	position float64
+	entryID  string
+	loops    int
+	depth    int
+}
+
+func newMachine(script *Script, bars []Bar, inputs map[string]any) *machine {
+	root := newInstance()
+	return &machine{
+		script: script,
+		bars:   bars,
+		inputs: inputs,
+		root:   root,
+		inst:   root,
+		result: &Result{Plots: []Plot{}, Orders: []Order{}},
+		plots:  make(map[*call]int),
+	}
+}
+
+func (m *machine) beginBar(bar int) {
+	m.bar = bar
+	m.inst = m.root
+	m.globals = &scope{vars: make(map[string]*series)}
+	m.scope = m.globals
+	m.loops = 0
+}
+
+func (m *machine) runBar(bar int) error {
+	m.beginBar(bar)
+	_, _, err := m.execBlock(m.script.body, false)
+	return err
+}
+
+func fail(n interface{ line() int }, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", n.line(), fmt.Sprintf(format, args...))
+}
+
+// execBlock runs body, in a new scope unless it is the script body. It
+// returns the value of the last statement, which is a function's result.
+func (m *machine) execBlock(body []stmt, nested bool) (control, any, error) {
+	if nested {
+		m.scope = &scope{vars: make(map[string]*series), parent: m.scope}
+		defer func(parent *scope) { m.scope = parent }(m.scope.parent)
+	}
+	var last any
+	for _, st := range body {
+		ctrl, v, err := m.exec(st)
+		if err != nil || ctrl != ctrlNone {
+			return ctrl, nil, err
+		}
+		last = v
+	}
+	return ctrlNone, last, nil
+}
+
+func (m *machine) exec(st stmt) (control, any, error) {
+	switch st := st.(type) {
+	case *exprStmt:
+		v, err := m.eval(st.x)
+		return ctrlNone, v, err
+
+	case *varDecl:
+		s := m.inst.series(st)
+		if st.mode != "" && len(s.vals) > 0 {
+			s.set(m.bar, s.last())
+		} else {
+			v, err := m.eval(st.value)
+			if err != nil {
+				return ctrlNone, nil, err
+			}
+			if _, ok := v.([]any); ok {
+				return ctrlNone, nil, fail(st, "cannot assign a tuple to %s", st.name)
+			}
+			s.set(m.bar, v)
+		}
+		m.scope.vars[st.name] = s
+		return ctrlNone, s.get(m.bar), nil
+
+	case *tupleDecl:
+		v, err := m.eval(st.value)
+		if err != nil {
+			return ctrlNone, nil, err
+		}
+		items, ok := v.([]any)
+		if !ok || len(items) != len(st.names) {
+			return ctrlNone, nil, fail(st, "expected a tuple of %d values", len(st.names))
+		}
+		for i, name := range st.names {
+			s := m.inst.series(tupleKey{st, i})
+			s.set(m.bar, items[i])
+			if name != "_" {
+				m.scope.vars[name] = s
+			}
+		}
+		return ctrlNone, v, nil
+
+	case *assign:
+		s := m.scope.lookup(st.name)
+		if s == nil {
+			return ctrlNone, nil, fail(st, "undeclared variable %s", st.name)
+		}
+		v, err := m.eval(st.value)
+		if err != nil {
+			return ctrlNone, nil, err
+		}
+		if st.op != ":=" {
+			if v, err = arith(st, st.op[:1], s.get(m.bar), v); err != nil {
+				return ctrlNone, nil, err
+			}
+		}
+		s.set(m.bar, v)
+		return ctrlNone, v, nil
+
+	case *ifStmt:
+		cond, err := m.eval(st.cond)
+		if err != nil {
+			return ctrlNone, nil, err
+		}
+		if toBool(cond) {
+			return m.execBlock(st.then, true)
+		}
+		if st.els != nil {
+			return m.execBlock(st.els, true)
+		}
+		return ctrlNone, nil, nil
+
+	case *forStmt:
+		return m.execFor(st)
+
+	case *forInStmt:
+		v, err := m.eval(st.x)
+		if err != nil {
+			return ctrlNone, nil, err
+		}
+		arr, ok := v.(*array)
+		if !ok {
+			return ctrlNone, nil, fail(st, "for...in needs an array")
+		}
+		var last any
+		for i, item := range append([]any(nil), arr.vals...) {
+			if err := m.step(st); err != nil {
+				return ctrlNone, nil, err
+			}
+			m.scope = &scope{vars: make(map[string]*series), parent: m.scope}
+			if st.index != "" {
+				idx := &series{}
+				idx.set(m.bar, float64(i))
+				m.scope.vars[st.index] = idx
+			}
+			elem := &series{}
+			elem.set(m.bar, item)
+			m.scope.vars[st.name] = elem
+			ctrl, v, err := m.execBlock(st.body, false)
+			m.scope = m.scope.parent
+			if err != nil {
+				return ctrlNone, nil, err
+			}
+			if ctrl == ctrlBreak {
+				break
+			}
+			last = v
+		}
+		return ctrlNone, last, nil
+
+	case *whileStmt:
+		var last any
+		for {
+			cond, err := m.eval(st.cond)
+			if err != nil {
+				return ctrlNone, nil, err
+			}
+			if !toBool(cond) {
+				break
+			}
+			if err := m.step(st); err != nil {
+				return ctrlNone, nil, err
+			}
+			ctrl, v, err := m.execBlock(st.body, true)
+			if err != nil {
+				return ctrlNone, nil, err
+			}
+			if ctrl == ctrlBreak {
+				break
+			}
+			last = v
+		}
+		return ctrlNone, last, nil
+
+	case *breakStmt:
+		return ctrlBreak, nil, nil
+	case *continueStmt:
+		return ctrlContinue, nil, nil
+	}
+	return ctrlNone, nil, fail(st, "unsupported statement")
+}
+
+func (m *machine) execFor(st *forStmt) (control, any, error) {
+	from, err := m.eval(st.from)
+	if err != nil {
+		return ctrlNone, nil, err
+	}
+	to, err := m.eval(st.to)
+	if err != nil {
+		return ctrlNone, nil, err
+	}
+	start, end := toFloat(from), toFloat(to)
+	if math.IsNaN(start) || math.IsNaN(end) {
+		return ctrlNone, nil, nil
+	}
+	step := 1.0
+	if end < start {
+		step = -1
+	}
+	if st.step != nil {
+		v, err := m.eval(st.step)
+		if err != nil {
+			return ctrlNone, nil, err
+		}
+		step = math.Abs(toFloat(v))
+		if end < start {
+			step = -step
+		}
+		if step == 0 || math.IsNaN(step) {
+			return ctrlNone, nil, fail(st, "for loop step must not be zero")
+		}
+	}
+
+	counter := m.inst.series(st)
+	var last any
+	for i := start; (step > 0 && i <= end) || (step < 0 && i >= end); i += step {
+		if err := m.step(st); err != nil {
+			return ctrlNone, nil, err
+		}
+		m.scope = &scope{vars: map[string]*series{st.name: counter}, parent: m.scope}
+		counter.set(m.bar, i)
+		ctrl, v, err := m.execBlock(st.body, false)
+		m.scope = m.scope.parent
+		if err != nil {
+			return ctrlNone, nil, err
+		}
+		if ctrl == ctrlBreak {
+			break
+		}
+		last = v
+	}
+	return ctrlNone, last, nil
+}
+
+func (m *machine) step(n stmt) error {
+	m.loops++
+	if m.loops > maxLoopIterations {
+		return fail(n, "loops ran more than %d iterations on one bar", maxLoopIterations)
+	}
+	return nil
+}
+
+func (m *machine) eval(x expr) (any, error) {
+	switch x := x.(type) {
+	case *numberLit:
+		return x.value, nil
+	case *stringLit:
+		return x.value, nil
+	case *boolLit:
+		return x.value, nil
+	case *colorLit:
+		return x.value, nil
+
+	case *ident:
+		if s := m.scope.lookup(x.name); s != nil {
+			return s.get(m.bar), nil
+		}
+		v, ok, err := m.builtinVar(x, x.name, m.bar)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fail(x, "undeclared identifier %s", x.name)
+		}
+		return v, nil
+
+	case *history:
+		return m.evalHistory(x)
+
+	case *unary:
+		v, err := m.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		switch x.op {
+		case "not":
+			return !toBool(v), nil
+		case "-":
+			return -toFloat(v), nil
+		}
+		return toFloat(v), nil
+
+	case *binary:
+		return m.evalBinary(x)
+
+	case *ternary:
+		cond, err := m.eval(x.cond)
+		if err != nil {
+			return nil, err
+		}
+		if toBool(cond) {
+			return m.eval(x.then)
+		}
+		return m.eval(x.els)
+
+	case *tupleLit:
+		items := make([]any, len(x.items))
+		for i, item := range x.items {
+			v, err := m.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+
+	case *call:
+		return m.evalCall(x)
+
+	case *methodCall:
+		recv, err := m.eval(x.recv)
+		if err != nil {
+			return nil, err
+		}
+		a, err := m.args(x.args, x.named)
+		if err != nil {
+			return nil, err
+		}
+		return m.method(x, recv, x.method, a)
+	}
+	return nil, fail(x, "unsupported expression")
+}
+
+func (m *machine) evalHistory(x *history) (any, error) {
+	off, err := m.eval(x.offset)
+	if err != nil {
+		return nil, err
+	}
+	offset := toFloat(off)
+	if math.IsNaN(offset) || offset < 0 {
+		return nil, fail(x, "history offset must be a non-negative number")
+	}
+	bar := m.bar - int(offset)
+
+	if id, ok := x.x.(*ident); ok {
+		if s := m.scope.lookup(id.name); s != nil {
+			return s.get(bar), nil
+		}
+		if v, ok := m.barVar(id.name, bar); ok {
+			return v, nil
+		}
+	}
+	// Any other expression is recorded every bar it is evaluated.
+	v, err := m.eval(x.x)
+	if err != nil {
+		return nil, err
+	}
+	s := m.inst.series(x)
+	s.set(m.bar, v)
+	return s.get(bar), nil
+}
+
+func (m *machine) evalBinary(x *binary) (any, error) {
+	l, err := m.eval(x.l)
+	if err != nil {
+		return nil, err
+	}
+	switch x.op {
+	case "and":
+		if !toBool(l) {
+			return false, nil
+		}
+		r, err := m.eval(x.r)
+		return toBool(r), err
+	case "or":
+		if toBool(l) {
+			return true, nil
+		}
+		r, err := m.eval(x.r)
+		return toBool(r), err
+	}
+	r, err := m.eval(x.r)
+	if err != nil {
+		return nil, err
+	}
+	switch x.op {
+	case "==", "!=":
+		eq := equal(l, r)
+		if x.op == "!=" {
+			return !eq, nil
+		}
+		return eq, nil
+	case "<", ">", "<=", ">=":
+		if ls, ok := l.(string); ok {
+			if rs, ok := r.(string); ok {
+				c := strings.Compare(ls, rs)
+				return compare(x.op, float64(c), 0), nil
+			}
+		}
+		return compare(x.op, toFloat(l), toFloat(r)), nil
+	}
+	return arith(x, x.op, l, r)
+}
+
+func compare(op string, a, b float64) bool {
+	switch op {
+	case "<":
+		return a < b
+	case ">":
+		return a > b
+	case "<=":
+		return a <= b
+	}
+	return a >= b
+}
+
+func equal(l, r any) bool {
+	if ls, ok := l.(string); ok {
+		rs, ok := r.(string)
+		return ok && ls == rs
+	}
+	if lb, ok := l.(bool); ok {
+		return lb == toBool(r)
+	}
+	if rb, ok := r.(bool); ok {
+		return rb == toBool(l)
+	}
+	if la, ok := l.(*array); ok {
+		return la == r
+	}
+	return toFloat(l) == toFloat(r)
+}
+
+func arith(n interface{ line() int }, op string, l, r any) (any, error) {
+	if op == "+" {
+		_, ls := l.(string)
+		_, rs := r.(string)
+		if ls || rs {
+			return toString(l) + toString(r), nil
+		}
+	}
+	a, b := toFloat(l), toFloat(r)
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return math.NaN(), nil
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return math.NaN(), nil
+		}
+		return math.Mod(a, b), nil
+	}
+	return nil, fail(n, "unknown operator %s", op)
+}
+
+// args are evaluated call arguments.
+type args struct {
+	pos   []any
+	named map[string]any
+}
+
+func (m *machine) args(positional []expr, named []namedArg) (args, error) {
+	a := args{pos: make([]any, len(positional))}
+	for i, x := range positional {
+		v, err := m.eval(x)
+		if err != nil {
+			return a, err
+		}
+		a.pos[i] = v
+	}
+	if len(named) > 0 {
+		a.named = make(map[string]any, len(named))
+		for _, arg := range named {
+			v, err := m.eval(arg.value)
+			if err != nil {
+				return a, err
+			}
+			a.named[arg.name] = v
+		}
+	}
+	return a, nil
+}
+
+// get returns the argument at position i or named name.
+func (a args) get(i int, name string) (any, bool) {
+	if v, ok := a.named[name]; ok {
+		return v, true
+	}
+	if i >= 0 && i < len(a.pos) {
+		return a.pos[i], true
+	}
+	return nil, false
+}
+
+func (a args) float(i int, name string, def float64) float64 {
+	if v, ok := a.get(i, name); ok {
+		return toFloat(v)
+	}
+	return def
+}
+
+func (a args) str(i int, name string) string {
+	v, _ := a.get(i, name)
+	if v == nil {
+		return ""
+	}
+	return toString(v)
+}
+
+func (a args) length(i int, name string, def int) int {
+	v := a.float(i, name, float64(def))
+	if math.IsNaN(v) || v < 1 {
+		return def
+	}
+	return int(v)
+}
+
+func (m *machine) evalCall(c *call) (any, error) {
+	if f, ok := m.script.funcs[c.fn]; ok {
+		return m.callUser(c, f)
+	}
+	if c.fn == "input" || strings.HasPrefix(c.fn, "input.") {
+		if input, ok := m.script.inputs[c]; ok {
+			if v, ok := m.inputValue(c, input); ok {
+				return v, nil
+			}
+		}
+		if def := argExpr(c, 0, "defval"); def != nil {
+			return m.eval(def)
+		}
+		return math.NaN(), nil
+	}
+
+	a, err := m.args(c.args, c.named)
+	if err != nil {
+		return nil, err
+	}
+	if name, method, ok := strings.Cut(c.fn, "."); ok {
+		if s := m.scope.lookup(name); s != nil {
+			return m.method(c, s.get(m.bar), method, a)
+		}
+	}
+	if f, ok := builtins[c.fn]; ok {
+		return f(m, c, a)
+	}
+	if ns, _, ok := strings.Cut(c.fn, "."); ok && drawingNamespaces[ns] {
+		return math.NaN(), nil
+	}
+	if ignoredCalls[c.fn] {
+		return math.NaN(), nil
+	}
+	if unsupportedCalls[c.fn] {
+		return nil, fail(c, "%s is not supported", c.fn)
+	}
+	return nil, fail(c, "unknown function %s", c.fn)
+}
+
+func (m *machine) callUser(c *call, f *funcDef) (any, error) {
+	if len(c.args) > len(f.params) {
+		return nil, fail(c, "%s takes %d arguments", f.name, len(f.params))
+	}
+	if m.depth > 50 {
+		return nil, fail(c, "%s: call depth exceeded", f.name)
+	}
+	values := make([]any, len(f.params))
+	for i, p := range f.params {
+		x := argExpr(c, i, p.name)
+		if x == nil {
+			x = p.def
+		}
+		if x == nil {
+			return nil, fail(c, "%s: missing argument %s", f.name, p.name)
+		}
+		v, err := m.eval(x)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	child, ok := m.inst.children[c]
+	if !ok {
+		child = newInstance()
+		m.inst.children[c] = child
+	}
+	fnScope := &scope{vars: make(map[string]*series), parent: m.globals}
+	for i, p := range f.params {
+		s := child.series(paramKey(i))
+		s.set(m.bar, values[i])
+		fnScope.vars[p.name] = s
+	}
+
+	inst, sc := m.inst, m.scope
+	m.inst, m.scope = child, fnScope
+	m.depth++
+	_, v, err := m.execBlock(f.body, false)
+	m.depth--
+	m.inst, m.scope = inst, sc
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (m *machine) inputValue(c *call, input *Input) (any, bool) {
+	v, ok := m.inputs[input.Name]
+	if !ok || v == nil {
+		return nil, false
+	}
+	switch input.Type {
+	case "number":
+		f := toFloat(v)
+		if s, ok := v.(string); ok {
+			if _, err := fmt.Sscan(s, &f); err != nil {
+				return nil, false
+			}
+		}
+		if math.IsNaN(f) {
+			return nil, false
+		}
+		return f, true
+	case "bool":
+		if s, ok := v.(string); ok {
+			return s == "true", true
+		}
+		return toBool(v), true
+	case "select":
+		if c.fn == "input.source" {
+			src, ok := m.barVar(toString(v), m.bar)
+			return src, ok
+		}
+	}
+	return toString(v), true
+}
+
+func (m *machine) method(n expr, recv any, name string, a args) (any, error) {
+	switch recv := recv.(type) {
+	case *array:
+		f, ok := builtins["array."+name]
+		if !ok {
+			return nil, fail(n, "unknown array method %s", name)
+		}
+		a.pos = append([]any{recv}, a.pos...)
+		return f(m, n, a)
+	case string:
+		if f, ok := builtins["str."+name]; ok {
+			a.pos = append([]any{recv}, a.pos...)
+			return f(m, n, a)
+		}
+	case nil, float64:
+		// Drawings are na: setters and getters on them are no-ops.
+		if isNa(recv) {
+			return math.NaN(), nil
+		}
+	}
+	return nil, fail(n, "%s is not a method of %s", name, typeName(recv))
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case float64:
+		return "float"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case []any:
+		return "tuple"
+	case *array:
+		return "array"
+	}
+	return "na"
+}
+
+func isNa(v any) bool {
+	if v == nil {
+		return true
+	}
+	f, ok := v.(float64)
+	return ok && math.IsNaN(f)
+}
+
+func toFloat(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case int:
+		return float64(v)
+	}
+	return math.NaN()
+}
+
+func toBool(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return !math.IsNaN(v) && v != 0
+	case string:
+		return v != ""
+	case *array:
+		return v != nil
+	}
+	return false
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return formatNumber(v, "")
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case nil:
+		return "NaN"
+	}
+	return fmt.Sprint(v)
+}
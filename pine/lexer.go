@@ -0,0 +1,177 @@
+package pine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokColor
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	line int
+}
+
+// logicalLine is one statement's tokens. Physical lines indented by a
+// non-multiple of four, or inside brackets, continue the previous line.
+type logicalLine struct {
+	indent int
+	toks   []token
+	num    int
+}
+
+var operators = []string{
+	":=", "=>", "==", "!=", "<=", ">=", "+=", "-=", "*=", "/=", "%=",
+	"+", "-", "*", "/", "%", "<", ">", "=", "?", ":", "(", ")", "[", "]", ",", ".",
+}
+
+// splitLines tokenizes src into logical lines.
+func splitLines(src string) ([]logicalLine, error) {
+	var lines []logicalLine
+	depth := 0
+	lineNum := 0
+	for _, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		lineNum++
+		toks, err := tokenize(raw, lineNum)
+		if err != nil {
+			return nil, err
+		}
+		if len(toks) == 0 {
+			continue
+		}
+		indent := indentOf(raw)
+		if len(lines) > 0 && (depth > 0 || indent%4 != 0) {
+			last := &lines[len(lines)-1]
+			last.toks = append(last.toks, toks...)
+		} else {
+			lines = append(lines, logicalLine{indent: indent / 4, toks: toks, num: lineNum})
+		}
+		for _, t := range toks {
+			if t.kind != tokOp {
+				continue
+			}
+			switch t.text {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				depth--
+			}
+		}
+	}
+	return lines, nil
+}
+
+func indentOf(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+func tokenize(line string, num int) ([]token, error) {
+	var toks []token
+	src := []rune(line)
+	for i := 0; i < len(src); {
+		r := src[i]
+		switch {
+		case r == ' ' || r == '\t':
+			i++
+		case r == '/' && i+1 < len(src) && src[i+1] == '/':
+			return toks, nil
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(src) && unicode.IsDigit(src[i+1])):
+			j := i
+			for j < len(src) && (unicode.IsDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			if j < len(src) && (src[j] == 'e' || src[j] == 'E') {
+				j++
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				for j < len(src) && unicode.IsDigit(src[j]) {
+					j++
+				}
+			}
+			v, err := strconv.ParseFloat(string(src[i:j]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid number %q", num, string(src[i:j]))
+			}
+			toks = append(toks, token{kind: tokNumber, text: string(src[i:j]), num: v, line: num})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(src) && (unicode.IsLetter(src[j]) || unicode.IsDigit(src[j]) || src[j] == '_') {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: string(src[i:j]), line: num})
+			i = j
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != r; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+					switch src[j] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 't':
+						sb.WriteRune('\t')
+					default:
+						sb.WriteRune(src[j])
+					}
+					continue
+				}
+				sb.WriteRune(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", num)
+			}
+			toks = append(toks, token{kind: tokString, text: sb.String(), line: num})
+			i = j + 1
+		case r == '#':
+			j := i + 1
+			for j < len(src) && strings.ContainsRune("0123456789abcdefABCDEF", src[j]) {
+				j++
+			}
+			if n := j - i - 1; n != 6 && n != 8 {
+				return nil, fmt.Errorf("line %d: invalid color literal %q", num, string(src[i:j]))
+			}
+			toks = append(toks, token{kind: tokColor, text: strings.ToLower(string(src[i:j])), line: num})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(src[i:min(i+2, len(src))]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("line %d: unexpected character %q", num, r)
+			}
+			toks = append(toks, token{kind: tokOp, text: op, line: num})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
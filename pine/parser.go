@@ -0,0 +1,642 @@
+package pine
+
+import (
+	"fmt"
+	"slices"
+)
+
+var typeKeywords = []string{"int", "float", "bool", "string", "color", "line", "label", "box", "table", "linefill", "array", "matrix", "map"}
+
+var qualifiers = []string{"const", "simple", "series", "input"}
+
+type parser struct {
+	lines []logicalLine
+	pos   int
+	funcs map[string]*funcDef
+}
+
+// tokens is a cursor over one logical line.
+type tokens struct {
+	toks []token
+	i    int
+	ln   int
+}
+
+func parse(src string) ([]stmt, map[string]*funcDef, error) {
+	lines, err := splitLines(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &parser{lines: lines, funcs: make(map[string]*funcDef)}
+	body, err := p.block(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return body, p.funcs, nil
+}
+
+// block parses consecutive lines at indent.
+func (p *parser) block(indent int) ([]stmt, error) {
+	var body []stmt
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+		s, err := p.statement(indent)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			body = append(body, s)
+		}
+	}
+	return body, nil
+}
+
+// body parses the indented block following a header line.
+func (p *parser) body(indent, ln int) ([]stmt, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+		return nil, fmt.Errorf("line %d: expected an indented block", ln)
+	}
+	return p.block(indent + 1)
+}
+
+func (p *parser) statement(indent int) (stmt, error) {
+	line := p.lines[p.pos]
+	p.pos++
+	t := &tokens{toks: line.toks, ln: line.num}
+	first := t.peek()
+
+	switch {
+	case first.kind == tokIdent && first.text == "if":
+		return p.ifStatement(t, indent)
+	case first.kind == tokIdent && first.text == "for":
+		return p.forStatement(t, indent)
+	case first.kind == tokIdent && first.text == "while":
+		t.next()
+		cond, err := t.expression()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.end(); err != nil {
+			return nil, err
+		}
+		body, err := p.body(indent, line.num)
+		if err != nil {
+			return nil, err
+		}
+		return &whileStmt{pos: pos{line.num}, cond: cond, body: body}, nil
+	case first.kind == tokIdent && first.text == "break":
+		t.next()
+		return &breakStmt{pos{line.num}}, t.end()
+	case first.kind == tokIdent && first.text == "continue":
+		t.next()
+		return &continueStmt{pos{line.num}}, t.end()
+	case first.kind == tokIdent && first.text == "else":
+		return nil, fmt.Errorf("line %d: else without if", line.num)
+	case first.kind == tokIdent && slices.Contains([]string{"switch", "import", "export", "type", "method"}, first.text):
+		return nil, fmt.Errorf("line %d: %q is not supported", line.num, first.text)
+	case first.kind == tokIdent && t.isFuncDef():
+		return nil, p.funcDef(t, indent)
+	case first.kind == tokOp && first.text == "[" && t.isTupleDecl():
+		return t.tupleDecl()
+	}
+	return t.simpleStatement()
+}
+
+func (p *parser) ifStatement(t *tokens, indent int) (stmt, error) {
+	ln := t.ln
+	t.next()
+	cond, err := t.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.end(); err != nil {
+		return nil, err
+	}
+	then, err := p.body(indent, ln)
+	if err != nil {
+		return nil, err
+	}
+	s := &ifStmt{pos: pos{ln}, cond: cond, then: then}
+
+	if p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		next := p.lines[p.pos]
+		if next.toks[0].kind == tokIdent && next.toks[0].text == "else" {
+			p.pos++
+			et := &tokens{toks: next.toks, ln: next.num}
+			et.next()
+			if !et.done() && et.peek().text == "if" {
+				elseIf, err := p.ifStatement(et, indent)
+				if err != nil {
+					return nil, err
+				}
+				s.els = []stmt{elseIf}
+				return s, nil
+			}
+			if err := et.end(); err != nil {
+				return nil, err
+			}
+			if s.els, err = p.body(indent, next.num); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) forStatement(t *tokens, indent int) (stmt, error) {
+	ln := t.ln
+	t.next()
+	if t.peek().text == "[" {
+		t.next()
+		index, err := t.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect(","); err != nil {
+			return nil, err
+		}
+		name, err := t.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect("]"); err != nil {
+			return nil, err
+		}
+		return p.forIn(t, indent, ln, index, name)
+	}
+	name, err := t.name()
+	if err != nil {
+		return nil, err
+	}
+	if t.peek().text == "in" {
+		return p.forIn(t, indent, ln, "", name)
+	}
+	if err := t.expect("="); err != nil {
+		return nil, err
+	}
+	s := &forStmt{pos: pos{ln}, name: name}
+	if s.from, err = t.expression(); err != nil {
+		return nil, err
+	}
+	if err := t.expect("to"); err != nil {
+		return nil, err
+	}
+	if s.to, err = t.expression(); err != nil {
+		return nil, err
+	}
+	if !t.done() && t.peek().text == "by" {
+		t.next()
+		if s.step, err = t.expression(); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.end(); err != nil {
+		return nil, err
+	}
+	if s.body, err = p.body(indent, ln); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (p *parser) forIn(t *tokens, indent, ln int, index, name string) (stmt, error) {
+	if err := t.expect("in"); err != nil {
+		return nil, err
+	}
+	x, err := t.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.end(); err != nil {
+		return nil, err
+	}
+	body, err := p.body(indent, ln)
+	if err != nil {
+		return nil, err
+	}
+	return &forInStmt{pos: pos{ln}, index: index, name: name, x: x, body: body}, nil
+}
+
+func (p *parser) funcDef(t *tokens, indent int) error {
+	ln := t.ln
+	name, _ := t.name()
+	t.next() // (
+	f := &funcDef{pos: pos{ln}, name: name}
+	for t.peek().text != ")" {
+		t.skipType()
+		pname, err := t.name()
+		if err != nil {
+			return err
+		}
+		prm := param{name: pname}
+		if t.peek().text == "=" {
+			t.next()
+			if prm.def, err = t.expression(); err != nil {
+				return err
+			}
+		}
+		f.params = append(f.params, prm)
+		if t.peek().text == "," {
+			t.next()
+		}
+	}
+	t.next() // )
+	t.next() // =>
+	if t.done() {
+		body, err := p.body(indent, ln)
+		if err != nil {
+			return err
+		}
+		f.body = body
+	} else {
+		x, err := t.expression()
+		if err != nil {
+			return err
+		}
+		if err := t.end(); err != nil {
+			return err
+		}
+		f.body = []stmt{&exprStmt{pos: pos{ln}, x: x}}
+	}
+	if _, exists := p.funcs[name]; exists {
+		return fmt.Errorf("line %d: function %s is already defined", ln, name)
+	}
+	p.funcs[name] = f
+	return nil
+}
+
+func (t *tokens) simpleStatement() (stmt, error) {
+	ln := t.ln
+	mode := ""
+	if w := t.peek().text; w == "var" || w == "varip" {
+		mode = w
+		t.next()
+	}
+	declared := t.skipType()
+	if t.peek().kind == tokIdent && t.i+1 < len(t.toks) {
+		op := t.toks[t.i+1]
+		if op.kind == tokOp && (op.text == "=" || mode != "" || declared) {
+			name, _ := t.name()
+			if err := t.expect("="); err != nil {
+				return nil, err
+			}
+			value, err := t.expression()
+			if err != nil {
+				return nil, err
+			}
+			return &varDecl{pos: pos{ln}, mode: mode, name: name, value: value}, t.end()
+		}
+		if op.kind == tokOp && slices.Contains([]string{":=", "+=", "-=", "*=", "/=", "%="}, op.text) {
+			name, _ := t.name()
+			t.next()
+			value, err := t.expression()
+			if err != nil {
+				return nil, err
+			}
+			return &assign{pos: pos{ln}, name: name, op: op.text, value: value}, t.end()
+		}
+	}
+	if mode != "" || declared {
+		return nil, fmt.Errorf("line %d: expected a declaration", ln)
+	}
+	x, err := t.expression()
+	if err != nil {
+		return nil, err
+	}
+	return &exprStmt{pos: pos{ln}, x: x}, t.end()
+}
+
+func (t *tokens) tupleDecl() (stmt, error) {
+	ln := t.ln
+	t.next()
+	var names []string
+	for t.peek().text != "]" {
+		name, err := t.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if t.peek().text == "," {
+			t.next()
+		}
+	}
+	t.next()
+	if err := t.expect("="); err != nil {
+		return nil, err
+	}
+	value, err := t.expression()
+	if err != nil {
+		return nil, err
+	}
+	return &tupleDecl{pos: pos{ln}, names: names, value: value}, t.end()
+}
+
+// isFuncDef reports whether the line is "name(params) => ...".
+func (t *tokens) isFuncDef() bool {
+	if len(t.toks) < 3 || t.toks[1].text != "(" {
+		return false
+	}
+	depth := 0
+	for i := 1; i < len(t.toks); i++ {
+		switch t.toks[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i+1 < len(t.toks) && t.toks[i+1].text == "=>"
+			}
+		}
+	}
+	return false
+}
+
+func (t *tokens) isTupleDecl() bool {
+	depth := 0
+	for i, tok := range t.toks {
+		switch tok.text {
+		case "[":
+			depth++
+		case "]":
+			depth--
+			if depth == 0 {
+				return i+1 < len(t.toks) && t.toks[i+1].text == "="
+			}
+		}
+	}
+	return false
+}
+
+// skipType consumes optional qualifiers and a type ("series float",
+// "array<float>", "float[]") before a declared name.
+func (t *tokens) skipType() bool {
+	start := t.i
+	for !t.done() && t.peek().kind == tokIdent && slices.Contains(qualifiers, t.peek().text) {
+		t.next()
+	}
+	if t.done() || t.peek().kind != tokIdent || !slices.Contains(typeKeywords, t.peek().text) {
+		t.i = start
+		return false
+	}
+	typeStart := t.i
+	t.next()
+	if !t.done() && t.peek().text == "<" {
+		for !t.done() && t.next().text != ">" {
+		}
+	}
+	if !t.done() && t.peek().text == "[" && t.i+1 < len(t.toks) && t.toks[t.i+1].text == "]" {
+		t.next()
+		t.next()
+	}
+	// A type keyword must be followed by the declared name; otherwise it was
+	// the name itself ("color = ...") or a namespace ("color.red").
+	if t.done() || t.peek().kind != tokIdent {
+		t.i = typeStart
+		return t.i != start
+	}
+	return true
+}
+
+func (t *tokens) expression() (expr, error) {
+	cond, err := t.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if t.done() || t.peek().text != "?" {
+		return cond, nil
+	}
+	ln := t.next().line
+	then, err := t.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := t.expression()
+	if err != nil {
+		return nil, err
+	}
+	return &ternary{pos: pos{ln}, cond: cond, then: then, els: els}, nil
+}
+
+var precedence = [][]string{
+	{"or"},
+	{"and"},
+	{"==", "!="},
+	{"<", ">", "<=", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (t *tokens) binary(level int) (expr, error) {
+	if level == len(precedence) {
+		return t.unary()
+	}
+	l, err := t.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for !t.done() && slices.Contains(precedence[level], t.peek().text) && t.peek().kind != tokString {
+		op := t.next()
+		r, err := t.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = &binary{pos: pos{op.line}, op: op.text, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (t *tokens) unary() (expr, error) {
+	if !t.done() && t.peek().kind != tokString {
+		switch op := t.peek().text; op {
+		case "-", "+", "not":
+			ln := t.next().line
+			x, err := t.unary()
+			if err != nil {
+				return nil, err
+			}
+			return &unary{pos: pos{ln}, op: op, x: x}, nil
+		}
+	}
+	return t.postfix()
+}
+
+func (t *tokens) postfix() (expr, error) {
+	x, err := t.primary()
+	if err != nil {
+		return nil, err
+	}
+	for !t.done() {
+		switch t.peek().text {
+		case "[":
+			ln := t.next().line
+			offset, err := t.expression()
+			if err != nil {
+				return nil, err
+			}
+			if err := t.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &history{pos: pos{ln}, x: x, offset: offset}
+		case ".":
+			ln := t.next().line
+			method, err := t.name()
+			if err != nil {
+				return nil, err
+			}
+			args, named, err := t.arguments()
+			if err != nil {
+				return nil, err
+			}
+			x = &methodCall{pos: pos{ln}, recv: x, method: method, args: args, named: named}
+		default:
+			return x, nil
+		}
+	}
+	return x, nil
+}
+
+func (t *tokens) primary() (expr, error) {
+	if t.done() {
+		return nil, fmt.Errorf("line %d: unexpected end of line", t.ln)
+	}
+	tok := t.next()
+	ln := pos{tok.line}
+	switch tok.kind {
+	case tokNumber:
+		return &numberLit{pos: ln, value: tok.num}, nil
+	case tokString:
+		return &stringLit{pos: ln, value: tok.text}, nil
+	case tokColor:
+		return &colorLit{pos: ln, value: tok.text}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &boolLit{pos: ln, value: tok.text == "true"}, nil
+		case "if", "switch":
+			return nil, fmt.Errorf("line %d: %s expressions are not supported", tok.line, tok.text)
+		}
+		name := tok.text
+		for !t.done() && t.peek().text == "." && t.i+1 < len(t.toks) && t.toks[t.i+1].kind == tokIdent {
+			t.next()
+			name += "." + t.next().text
+		}
+		// Generic type arguments: array.new<float>(...).
+		if !t.done() && t.peek().text == "<" && t.i+2 < len(t.toks) && t.toks[t.i+2].text == ">" {
+			t.i += 3
+		}
+		if !t.done() && t.peek().text == "(" {
+			args, named, err := t.arguments()
+			if err != nil {
+				return nil, err
+			}
+			return &call{pos: ln, fn: name, args: args, named: named}, nil
+		}
+		return &ident{pos: ln, name: name}, nil
+	}
+	switch tok.text {
+	case "(":
+		x, err := t.expression()
+		if err != nil {
+			return nil, err
+		}
+		return x, t.expect(")")
+	case "[":
+		var items []expr
+		for !t.done() && t.peek().text != "]" {
+			item, err := t.expression()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if !t.done() && t.peek().text == "," {
+				t.next()
+			}
+		}
+		return &tupleLit{pos: ln, items: items}, t.expect("]")
+	}
+	return nil, fmt.Errorf("line %d: unexpected %q", tok.line, tok.text)
+}
+
+func (t *tokens) arguments() ([]expr, []namedArg, error) {
+	if err := t.expect("("); err != nil {
+		return nil, nil, err
+	}
+	var args []expr
+	var named []namedArg
+	for !t.done() && t.peek().text != ")" {
+		if t.peek().kind == tokIdent && t.i+1 < len(t.toks) && t.toks[t.i+1].text == "=" {
+			name := t.next().text
+			t.next()
+			value, err := t.expression()
+			if err != nil {
+				return nil, nil, err
+			}
+			named = append(named, namedArg{name: name, value: value})
+		} else {
+			if len(named) > 0 {
+				return nil, nil, fmt.Errorf("line %d: positional argument after named argument", t.ln)
+			}
+			value, err := t.expression()
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, value)
+		}
+		if !t.done() && t.peek().text == "," {
+			t.next()
+		}
+	}
+	return args, named, t.expect(")")
+}
+
+func (t *tokens) done() bool { return t.i >= len(t.toks) }
+
+func (t *tokens) peek() token {
+	if t.done() {
+		return token{kind: tokOp, line: t.ln}
+	}
+	return t.toks[t.i]
+}
+
+func (t *tokens) next() token {
+	tok := t.peek()
+	t.i++
+	return tok
+}
+
+func (t *tokens) name() (string, error) {
+	tok := t.next()
+	if tok.kind != tokIdent {
+		return "", fmt.Errorf("line %d: expected a name, got %q", tok.line, tok.text)
+	}
+	return tok.text, nil
+}
+
+func (t *tokens) expect(text string) error {
+	if tok := t.next(); tok.text != text || tok.kind == tokString {
+		if tok.text == "" {
+			return fmt.Errorf("line %d: expected %q at end of line", t.ln, text)
+		}
+		return fmt.Errorf("line %d: expected %q, got %q", tok.line, text, tok.text)
+	}
+	return nil
+}
+
+func (t *tokens) end() error {
+	if !t.done() {
+		return fmt.Errorf("line %d: unexpected %q", t.peek().line, t.peek().text)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package pine interprets a practical subset of TradingView Pine Script
+// (v5/v6) so that indicators and strategies written for TradingView can run
+// through the backtest and live engines without being ported to Go.
+//
+// A script runs once per bar, oldest first, with Pine's series semantics:
+// every variable keeps its history for the [] operator, var variables are
+// initialised on the first bar only, and each ta.* call site keeps its own
+// indicator state, backed by the streams of the indicator package.
+//
+// Supported: var/varip declarations, :=, compound assignment, if/else,
+// for, for...in, while, user functions (including tuple returns), arrays,
+// input.*, ta.*, math.*, str.*, color.*, nz/na/fixnan, plot and
+// strategy.entry/close/close_all. Drawing objects (line, label, box,
+// table, ...) are accepted and ignored, as are plotshape, fill, bgcolor and
+// other purely visual calls. request.security, strategy.exit, switch,
+// user-defined types and methods are not supported.
+package pine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bar is one OHLCV candle. Time is the open time in milliseconds.
+type Bar struct {
+	Time                           int64
+	Open, High, Low, Close, Volume float64
+}
+
+// Input is a script input declared with input.*. Name is the variable it
+// is assigned to, which is also the key of its value in Run's inputs. Type
+// is "number", "bool", "string", "select" (one of Options) or "color".
+type Input struct {
+	Name    string
+	Title   string
+	Type    string
+	Default any
+	Min     float64
+	Max     float64
+	Step    float64
+	Options []string
+}
+
+// Plot is the series drawn by one plot call. Values are NaN where the
+// plotted value is na; Colors are hex colors, empty where na.
+type Plot struct {
+	Title  string
+	Values []float64
+	Colors []string
+}
+
+// Order is an order placed by strategy.entry, strategy.close or
+// strategy.close_all. Action is "long", "short" or "close".
+type Order struct {
+	Bar     int
+	Action  string
+	ID      string
+	Comment string
+}
+
+type Result struct {
+	Plots  []Plot
+	Orders []Order
+}
+
+// Script is a compiled Pine script. It holds no per-run state and may be run
+// concurrently.
+type Script struct {
+	Title      string
+	IsStrategy bool
+	Inputs     []Input
+
+	body   []stmt
+	funcs  map[string]*funcDef
+	inputs map[*call]*Input
+}
+
+// maxLoopIterations bounds the loop iterations of a single bar, so a runaway
+// while loop fails the run instead of hanging the engine.
+const maxLoopIterations = 100_000
+
+// Compile parses src and extracts its title and inputs.
+func Compile(src string) (*Script, error) {
+	body, funcs, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	s := &Script{body: body, funcs: funcs, inputs: make(map[*call]*Input)}
+
+	for _, st := range body {
+		switch st := st.(type) {
+		case *exprStmt:
+			c, ok := st.x.(*call)
+			if !ok {
+				continue
+			}
+			switch c.fn {
+			case "indicator", "study", "strategy":
+				s.IsStrategy = s.IsStrategy || c.fn == "strategy"
+				if lit, ok := argExpr(c, 0, "title").(*stringLit); ok && s.Title == "" {
+					s.Title = lit.value
+				}
+			}
+		case *varDecl:
+			c, ok := st.value.(*call)
+			if !ok || (c.fn != "input" && !strings.HasPrefix(c.fn, "input.")) {
+				continue
+			}
+			input, err := s.compileInput(st.name, c)
+			if err != nil {
+				return nil, err
+			}
+			s.inputs[c] = input
+			s.Inputs = append(s.Inputs, *input)
+		}
+	}
+	if s.Title == "" {
+		return nil, fmt.Errorf("script has no indicator() or strategy() declaration")
+	}
+	return s, nil
+}
+
+// Run executes the script over bars. inputs overrides input defaults by
+// name; missing inputs keep their defaults.
+func (s *Script) Run(bars []Bar, inputs map[string]any) (*Result, error) {
+	m := newMachine(s, bars, inputs)
+	for bar := range bars {
+		if err := m.runBar(bar); err != nil {
+			return nil, fmt.Errorf("bar %d: %w", bar, err)
+		}
+	}
+	return m.result, nil
+}
+
+var sourceNames = []string{"open", "high", "low", "close", "hl2", "hlc3", "ohlc4", "hlcc4", "volume"}
+
+func (s *Script) compileInput(name string, c *call) (*Input, error) {
+	input := &Input{Name: name, Title: name}
+	if lit, ok := argExpr(c, 1, "title").(*stringLit); ok {
+		input.Title = lit.value
+	}
+
+	def := argExpr(c, 0, "defval")
+	kind := strings.TrimPrefix(c.fn, "input.")
+	switch kind {
+	case "input", "float", "int", "price":
+		input.Type = "number"
+		if kind == "int" {
+			input.Step = 1
+		}
+	case "bool":
+		input.Type = "bool"
+	case "color":
+		input.Type = "color"
+	case "string", "timeframe", "symbol", "session", "text_area":
+		input.Type = "string"
+	case "source":
+		input.Type = "select"
+		input.Options = sourceNames
+		if id, ok := def.(*ident); ok {
+			input.Default = id.name
+		}
+	default:
+		return nil, fmt.Errorf("line %d: input.%s is not supported", c.line(), kind)
+	}
+
+	if input.Type != "select" && def != nil {
+		v, err := constant(def)
+		if err != nil {
+			return nil, err
+		}
+		input.Default = v
+		if c.fn == "input" {
+			switch v.(type) {
+			case bool:
+				input.Type = "bool"
+			case string:
+				input.Type = "string"
+			}
+		}
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *float64
+	}{{"minval", &input.Min}, {"maxval", &input.Max}, {"step", &input.Step}} {
+		if x := argExpr(c, -1, bound.name); x != nil {
+			v, err := constant(x)
+			if err != nil {
+				return nil, err
+			}
+			*bound.dst = toFloat(v)
+		}
+	}
+	if x := argExpr(c, -1, "options"); x != nil {
+		v, err := constant(x)
+		if err != nil {
+			return nil, err
+		}
+		items, _ := v.([]any)
+		for _, item := range items {
+			input.Options = append(input.Options, toString(item))
+		}
+		input.Type = "select"
+	}
+	return input, nil
+}
+
+// argExpr returns the argument of c at position i or named name, or nil.
+func argExpr(c *call, i int, name string) expr {
+	for _, arg := range c.named {
+		if arg.name == name {
+			return arg.value
+		}
+	}
+	if i >= 0 && i < len(c.args) {
+		return c.args[i]
+	}
+	return nil
+}
+
+// constant evaluates an input default such as 2.5, "Close" or
+// color.rgb(28, 194, 216) outside of any bar.
+func constant(x expr) (any, error) {
+	m := newMachine(&Script{}, []Bar{{}}, nil)
+	m.beginBar(0)
+	v, err := m.eval(x)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := v.(float64); ok && f != f {
+		return nil, nil
+	}
+	return v, nil
+}
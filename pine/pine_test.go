@@ -0,0 +1,120 @@
+package pine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"no declaration", "a = 1", "script has no indicator() or strategy() declaration"},
+		{"unexpected indentation", "indicator(\"x\")\n    a = 1", "line 2: unexpected indentation"},
+		{"empty if", "indicator(\"x\")\nif close > open\na = 1", "line 2: expected an indented block"},
+		{"stray else", "indicator(\"x\")\nelse\n    a = 1", "line 2: else without if"},
+		{"switch", "indicator(\"x\")\nswitch close\n    1 => 2", `line 2: "switch" is not supported`},
+		{"duplicate function", "indicator(\"x\")\nf(x) => x\nf(x) => x + 1", "line 3: function f is already defined"},
+		{"unclosed paren", "indicator(\"x\")\na = (1 + 2", `line 2: expected ")" at end of line`},
+		{"dangling operator", "indicator(\"x\")\na = 1 +", "line 2: unexpected end of line"},
+		{"bad number", "indicator(\"x\")\na = 1.2.3", `line 2: invalid number "1.2.3"`},
+		{"unterminated string", "indicator(\"x\")\na = \"abc", "line 2: unterminated string"},
+		{"bad color", "indicator(\"x\")\na = #12", `line 2: invalid color literal "#12"`},
+		{"bad character", "indicator(\"x\")\na = 1 @ 2", "line 2: unexpected character '@'"},
+		{"positional after named", "indicator(\"x\")\nplot(title = \"a\", close)", "line 2: positional argument after named argument"},
+		{"var without name", "indicator(\"x\")\nvar = 1", "line 2: expected a declaration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Compile error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"request.security", "indicator(\"x\")\na = request.security(\"BTC\", \"1D\", close)", "bar 0: line 2: request.security is not supported"},
+		{"undeclared identifier", "indicator(\"x\")\nplot(foo)", "bar 0: line 2: undeclared identifier foo"},
+		{"unknown function", "indicator(\"x\")\nplot(ta.nope(close))", "bar 0: line 2: unknown function ta.nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := Compile(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := script.Run(testBars(3), nil); err == nil || err.Error() != tt.err {
+				t.Errorf("Run error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// testBars rises for n bars from a close of 1.
+func testBars(n int) []Bar {
+	bars := make([]Bar, n)
+	for i := range bars {
+		c := float64(i + 1)
+		bars[i] = Bar{Time: int64(i) * 60_000, Open: c - 0.5, High: c + 1, Low: c - 1, Close: c, Volume: 1}
+	}
+	return bars
+}
+
+func TestRunSeriesAndOrders(t *testing.T) {
+	script, err := Compile(`strategy("Cross", overlay = true)
+length = input.int(3, "Length", minval = 1)
+avg = ta.sma(close, length)
+var count = 0
+count += 1
+plot(avg, "SMA")
+plot(close[1] - close, "Change")
+if ta.crossover(close, 2.5)
+    strategy.entry("L", strategy.long)
+if count == 4
+    strategy.close("L")
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script.Title != "Cross" || !script.IsStrategy {
+		t.Errorf("Title %q IsStrategy %v", script.Title, script.IsStrategy)
+	}
+	if len(script.Inputs) != 1 || script.Inputs[0].Name != "length" || script.Inputs[0].Default != 3.0 {
+		t.Fatalf("Inputs = %+v", script.Inputs)
+	}
+
+	result, err := script.Run(testBars(5), map[string]any{"length": 2.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nan := math.NaN()
+	wantPlots := map[string][]float64{
+		"SMA":    {nan, 1.5, 2.5, 3.5, 4.5},
+		"Change": {nan, -1, -1, -1, -1},
+	}
+	if len(result.Plots) != len(wantPlots) {
+		t.Fatalf("%d plots, want %d", len(result.Plots), len(wantPlots))
+	}
+	for _, p := range result.Plots {
+		want := wantPlots[p.Title]
+		for i, v := range p.Values {
+			if math.IsNaN(v) != math.IsNaN(want[i]) || (!math.IsNaN(v) && v != want[i]) {
+				t.Errorf("%s[%d] = %v, want %v", p.Title, i, v, want[i])
+			}
+		}
+	}
+	wantOrders := []Order{{Bar: 2, Action: "long", ID: "L"}, {Bar: 3, Action: "close", ID: "L"}}
+	if len(result.Orders) != len(wantOrders) {
+		t.Fatalf("Orders = %+v, want %+v", result.Orders, wantOrders)
+	}
+	for i, o := range result.Orders {
+		if o != wantOrders[i] {
+			t.Errorf("order %d = %+v, want %+v", i, o, wantOrders[i])
+		}
+	}
+}
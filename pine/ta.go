@@ -0,0 +1,259 @@
+package pine
+
+import (
+	"math"
+
+	"terminal/indicator"
+)
+
+// stateOf returns the state of the call site n in the current instance,
+// creating it on first use.
+func stateOf[T any](m *machine, n expr, create func() T) T {
+	if s, ok := m.inst.states[n]; ok {
+		return s.(T)
+	}
+	s := create()
+	m.inst.states[n] = s
+	return s
+}
+
+// argKey is the recorded history of argument i at call site n.
+type argKey struct {
+	n expr
+	i int
+}
+
+// record stores v as argument i of call site n on this bar, for functions
+// that look back at their own inputs.
+func (m *machine) record(n expr, i int, v float64) *series {
+	s := m.inst.series(argKey{n, i})
+	s.set(m.bar, v)
+	return s
+}
+
+func (m *machine) recorded(s *series, bar int) float64 {
+	return toFloat(s.get(bar))
+}
+
+type stream interface{ Update(v float64) float64 }
+
+func smoothing(create func(length int) stream) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		length := a.length(1, "length", 1)
+		s := stateOf(m, n, func() stream { return create(length) })
+		return s.Update(a.float(0, "source", math.NaN())), nil
+	}
+}
+
+func trueRange(m *machine, bar int, handleNa bool) float64 {
+	b := m.bars[bar]
+	if bar == 0 {
+		if handleNa {
+			return b.High - b.Low
+		}
+		return math.NaN()
+	}
+	prev := m.bars[bar-1].Close
+	return math.Max(b.High-b.Low, math.Max(math.Abs(b.High-prev), math.Abs(b.Low-prev)))
+}
+
+func (m *machine) hlc() (high, low, close float64) {
+	b := m.bars[m.bar]
+	return b.High, b.Low, b.Close
+}
+
+// extreme handles ta.highest and ta.lowest, whose source defaults to high
+// or low when only a length is given.
+func extreme(def string, create func(length int) *indicator.ExtremeStream) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		src, _ := m.barVar(def, m.bar)
+		length := a.length(1, "length", 1)
+		if _, named := a.named["source"]; len(a.pos) == 1 && !named {
+			length = a.length(0, "length", 1)
+		} else {
+			src, _ = a.get(0, "source")
+		}
+		s := stateOf(m, n, func() *indicator.ExtremeStream { return create(length) })
+		return s.Update(toFloat(src)), nil
+	}
+}
+
+func change(m *machine, n expr, a args) (any, error) {
+	length := a.length(1, "length", 1)
+	s := m.record(n, 0, a.float(0, "source", math.NaN()))
+	return m.recorded(s, m.bar) - m.recorded(s, m.bar-length), nil
+}
+
+func crossing(over, under bool) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		x := m.record(n, 0, a.float(0, "source1", math.NaN()))
+		y := m.record(n, 1, a.float(1, "source2", math.NaN()))
+		a0, b0 := m.recorded(x, m.bar), m.recorded(y, m.bar)
+		a1, b1 := m.recorded(x, m.bar-1), m.recorded(y, m.bar-1)
+		return (over && a0 > b0 && a1 <= b1) || (under && a0 < b0 && a1 >= b1), nil
+	}
+}
+
+// monotonic handles ta.rising and ta.falling: the source is beyond every
+// value of the previous length bars.
+func monotonic(beyond func(cur, prev float64) bool) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		length := a.length(1, "length", 1)
+		s := m.record(n, 0, a.float(0, "source", math.NaN()))
+		cur := m.recorded(s, m.bar)
+		for i := 1; i <= length; i++ {
+			prev := m.recorded(s, m.bar-i)
+			if math.IsNaN(prev) || !beyond(cur, prev) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// pivot handles ta.pivothigh and ta.pivotlow: the value rightbars ago is a
+// pivot when it beats the leftbars before and the rightbars after it.
+func pivot(def string, beats func(v, other float64) bool) builtin {
+	return func(m *machine, n expr, a args) (any, error) {
+		src, _ := m.barVar(def, m.bar)
+		left, right := a.length(1, "leftbars", 1), a.length(2, "rightbars", 1)
+		if _, named := a.named["source"]; len(a.pos) == 2 && !named {
+			left, right = a.length(0, "leftbars", 1), a.length(1, "rightbars", 1)
+		} else {
+			src, _ = a.get(0, "source")
+		}
+		s := m.record(n, 0, toFloat(src))
+		center := m.bar - right
+		v := m.recorded(s, center)
+		if math.IsNaN(v) || center-left < 0 {
+			return math.NaN(), nil
+		}
+		for i := center - left; i <= m.bar; i++ {
+			if other := m.recorded(s, i); i != center && (math.IsNaN(other) || !beats(v, other)) {
+				return math.NaN(), nil
+			}
+		}
+		return v, nil
+	}
+}
+
+type valueWhen struct {
+	values []any
+}
+
+type barsSince struct {
+	last  int
+	found bool
+}
+
+var taBuiltins = map[string]builtin{
+	"ta.sma":   smoothing(func(length int) stream { return indicator.NewSMAStream(length) }),
+	"ta.ema":   smoothing(func(length int) stream { return indicator.NewEMAStream(length) }),
+	"ta.rma":   smoothing(func(length int) stream { return indicator.NewRMAStream(length) }),
+	"ta.wma":   smoothing(func(length int) stream { return indicator.NewWMAStream(length) }),
+	"ta.hma":   smoothing(func(length int) stream { return indicator.NewHMAStream(length) }),
+	"ta.stdev": smoothing(func(length int) stream { return indicator.NewStdevStream(length) }),
+	"ta.rsi":   smoothing(func(length int) stream { return indicator.NewRSIStream(length) }),
+
+	"ta.highest":    extreme("high", indicator.NewHighestStream),
+	"ta.lowest":     extreme("low", indicator.NewLowestStream),
+	"ta.change":     change,
+	"ta.mom":        change,
+	"ta.crossover":  crossing(true, false),
+	"ta.crossunder": crossing(false, true),
+	"ta.cross":      crossing(true, true),
+	"ta.rising":     monotonic(func(cur, prev float64) bool { return cur > prev }),
+	"ta.falling":    monotonic(func(cur, prev float64) bool { return cur < prev }),
+	"ta.pivothigh":  pivot("high", func(v, other float64) bool { return v > other }),
+	"ta.pivotlow":   pivot("low", func(v, other float64) bool { return v < other }),
+
+	"ta.tr": func(m *machine, n expr, a args) (any, error) {
+		handleNa, _ := a.get(0, "handle_na")
+		return trueRange(m, m.bar, toBool(handleNa)), nil
+	},
+	"ta.atr": func(m *machine, n expr, a args) (any, error) {
+		length := a.length(0, "length", 14)
+		s := stateOf(m, n, func() *indicator.ATRStream { return indicator.NewATRStream(length) })
+		return s.Update(m.hlc()), nil
+	},
+	"ta.macd": func(m *machine, n expr, a args) (any, error) {
+		fast, slow, signal := a.length(1, "fastlen", 12), a.length(2, "slowlen", 26), a.length(3, "siglen", 9)
+		s := stateOf(m, n, func() *indicator.MACDStream { return indicator.NewMACDStream(fast, slow, signal) })
+		macd, sig, hist := s.Update(a.float(0, "source", math.NaN()))
+		return []any{macd, sig, hist}, nil
+	},
+	"ta.stoch": func(m *machine, n expr, a args) (any, error) {
+		length := a.length(3, "length", 14)
+		s := stateOf(m, n, func() *indicator.StochasticStream { return indicator.NewStochasticStream(length, 1, 1) })
+		k, _ := s.Update(a.float(0, "source", math.NaN()), a.float(1, "high", math.NaN()), a.float(2, "low", math.NaN()))
+		return k, nil
+	},
+	"ta.supertrend": func(m *machine, n expr, a args) (any, error) {
+		factor, period := a.float(0, "factor", 3), a.length(1, "atrPeriod", 10)
+		s := stateOf(m, n, func() *indicator.SupertrendStream { return indicator.NewSupertrendStream(factor, period) })
+		line, direction := s.Update(m.hlc())
+		return []any{line, float64(direction)}, nil
+	},
+	"ta.dmi": func(m *machine, n expr, a args) (any, error) {
+		diLength, smoothing := a.length(0, "diLength", 14), a.length(1, "adxSmoothing", 14)
+		s := stateOf(m, n, func() *indicator.ADXStream { return indicator.NewADXStream(diLength, smoothing) })
+		plus, minus, adx := s.Update(m.hlc())
+		return []any{plus, minus, adx}, nil
+	},
+	"ta.bb": func(m *machine, n expr, a args) (any, error) {
+		length, mult := a.length(1, "length", 20), a.float(2, "mult", 2)
+		s := stateOf(m, n, func() *indicator.BollingerStream { return indicator.NewBollingerStream(length, mult) })
+		middle, upper, lower := s.Update(a.float(0, "series", math.NaN()))
+		return []any{middle, upper, lower}, nil
+	},
+	"ta.kc": func(m *machine, n expr, a args) (any, error) {
+		length, mult := a.length(1, "length", 20), a.float(2, "mult", 2)
+		s := stateOf(m, n, func() *indicator.KeltnerStream { return indicator.NewKeltnerStream(length, mult) })
+		high, low, _ := m.hlc()
+		middle, upper, lower := s.Update(a.float(0, "series", math.NaN()), high, low)
+		return []any{middle, upper, lower}, nil
+	},
+	"ta.vwap": func(m *machine, n expr, a args) (any, error) {
+		src, _ := m.barVar("hlc3", m.bar)
+		if v, ok := a.get(0, "source"); ok {
+			src = v
+		}
+		s := stateOf(m, n, indicator.NewVWAPStream)
+		b := m.bars[m.bar]
+		return s.Update(toFloat(src), b.Volume, b.Time), nil
+	},
+	"ta.obv": func(m *machine, n expr, a args) (any, error) {
+		s := stateOf(m, n, indicator.NewOBVStream)
+		b := m.bars[m.bar]
+		return s.Update(b.Close, b.Volume), nil
+	},
+	"ta.cum": func(m *machine, n expr, a args) (any, error) {
+		sum := stateOf(m, n, func() *float64 { return new(float64) })
+		if v := a.float(0, "source", math.NaN()); !math.IsNaN(v) {
+			*sum += v
+		}
+		return *sum, nil
+	},
+	"ta.barssince": func(m *machine, n expr, a args) (any, error) {
+		s := stateOf(m, n, func() *barsSince { return &barsSince{} })
+		if cond, _ := a.get(0, "condition"); toBool(cond) {
+			s.last, s.found = m.bar, true
+		}
+		if !s.found {
+			return math.NaN(), nil
+		}
+		return float64(m.bar - s.last), nil
+	},
+	"ta.valuewhen": func(m *machine, n expr, a args) (any, error) {
+		s := stateOf(m, n, func() *valueWhen { return &valueWhen{} })
+		if cond, _ := a.get(0, "condition"); toBool(cond) {
+			v, _ := a.get(1, "source")
+			s.values = append(s.values, v)
+		}
+		occurrence := int(a.float(2, "occurrence", 0))
+		if occurrence < 0 || occurrence >= len(s.values) {
+			return math.NaN(), nil
+		}
+		return s.values[len(s.values)-1-occurrence], nil
+	},
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/pine"
+)
+
+// PineStrategy runs a Pine script as a Strategy. Strategy scripts trade
+// their strategy.entry/close orders; indicator scripts have no orders and
+// trade the close crossing their first plot, long above it and short below.
+// The first plot is charted as the trend line.
+type PineStrategy struct {
+	Config StrategyConfig
+	name   string
+	script *pine.Script
+	output *StrategyOutput
+}
+
+func NewPineStrategy(name string, script *pine.Script, params map[string]any) *PineStrategy {
+	strategy := &PineStrategy{name: name, script: script}
+	strategy.Config = strategy.BuildConfig(params)
+	return strategy
+}
+
+func (s *PineStrategy) GetName() string {
+	return s.name
+}
+
+func (s *PineStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	return buildStrategyConfig(params)
+}
+
+func (s *PineStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) {
+	return s.run(candles)
+}
+
+func (s *PineStrategy) GetVisualizationData(candles hyperliquid.Candles) *StrategyOutput {
+	if _, err := s.run(candles); err != nil {
+		return nil
+	}
+	return s.output
+}
+
+func (s *PineStrategy) Run(candles hyperliquid.Candles) (*StrategyOutput, error) {
+	if _, err := s.run(candles); err != nil {
+		return nil, err
+	}
+	return s.output, nil
+}
+
+func (s *PineStrategy) Backtest(candles hyperliquid.Candles) (*BacktestOutput, error) {
+	signals, err := s.GenerateSignals(candles)
+	if err != nil {
+		return nil, err
+	}
+	return newBacktestOutput(s, s.Config, candles, signals, s.output), nil
+}
+
+// run executes the script over candles, builds the chart output from its
+// first plot and returns its signals.
+func (s *PineStrategy) run(candles hyperliquid.Candles) ([]Signal, error) {
+	bars := make([]pine.Bar, len(candles))
+	for i, candle := range candles {
+		bars[i] = pine.Bar{
+			Time:   candle.Timestamp,
+			Open:   parseFloat(candle.Open),
+			High:   parseFloat(candle.High),
+			Low:    parseFloat(candle.Low),
+			Close:  parseFloat(candle.Close),
+			Volume: parseFloat(candle.Volume),
+		}
+	}
+	result, err := s.script.Run(bars, s.Config.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.name, err)
+	}
+
+	n := len(candles)
+	s.output = &StrategyOutput{
+		TrendLines:  make([]float64, n),
+		TrendColors: make([]string, n),
+		Labels:      []Label{},
+		Lines:       []TrendLine{},
+		FillColors:  make([]string, n),
+	}
+	if len(result.Plots) > 0 {
+		plot := result.Plots[0]
+		for i, v := range plot.Values {
+			// The chart skips zero values; JSON has no NaN.
+			if !math.IsNaN(v) {
+				s.output.TrendLines[i] = v
+			}
+			s.output.TrendColors[i] = plot.Colors[i]
+		}
+	}
+
+	signals := []Signal{}
+	signal := func(i int, signalType SignalType, reason string) {
+		signals = append(signals, Signal{
+			Index:  i,
+			Type:   signalType,
+			Price:  parseFloat(candles[i].Close),
+			Time:   candles[i].Timestamp,
+			Reason: reason,
+		})
+	}
+
+	if s.script.IsStrategy {
+		for _, order := range result.Orders {
+			reason := order.ID
+			if order.Comment != "" {
+				reason = order.Comment
+			}
+			switch order.Action {
+			case "long":
+				signal(order.Bar, SignalLong, reason)
+			case "short":
+				signal(order.Bar, SignalShort, reason)
+			case "close":
+				signal(order.Bar, SignalClose, reason)
+			}
+		}
+		s.output.Directions = positionDirections(n, signals)
+		return signals, nil
+	}
+
+	s.output.Directions = make([]int, n)
+	for i, line := range s.output.TrendLines {
+		switch {
+		case line == 0:
+		case bars[i].Close >= line:
+			s.output.Directions[i] = -1
+		default:
+			s.output.Directions[i] = 1
+		}
+		prev, curr := 0, s.output.Directions[i]
+		if i > 0 {
+			prev = s.output.Directions[i-1]
+		}
+		switch {
+		case prev == curr || prev == 0:
+		case curr == -1:
+			signal(i, SignalLong, "Cross Above")
+		case curr == 1:
+			signal(i, SignalShort, "Cross Below")
+		}
+	}
+	return signals, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+
+	"terminal/pine"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// trendingCandles is a seeded hourly random walk whose drift changes every
+// 150 bars, so trend strategies see several reversals.
+func trendingCandles(n int) hyperliquid.Candles {
+	r := rand.New(rand.NewSource(7))
+	format := func(x float64) string { return strconv.FormatFloat(x, 'f', 4, 64) }
+	candles := make(hyperliquid.Candles, n)
+	price, drift := 100.0, 0.0
+	for i := range n {
+		if i%150 == 0 {
+			drift = (r.Float64() - 0.5) * 0.6
+		}
+		open := price
+		price += drift + r.NormFloat64()*0.8
+		high := max(open, price) + r.Float64()*0.6
+		low := min(open, price) - r.Float64()*0.6
+		candles[i] = hyperliquid.Candle{
+			Time:      int64(i) * 3_600_000,
+			Timestamp: int64(i+1)*3_600_000 - 1,
+			Open:      format(open),
+			High:      format(high),
+			Low:       format(low),
+			Close:     format(price),
+			Volume:    "1",
+		}
+	}
+	return candles
+}
+
+// TestPineMaxTrendMatchesGo runs the TradingView source of Max Trend Points
+// (pine.txt) through the interpreter and checks that it signals on the same
+// bars as the Go port.
+func TestPineMaxTrendMatchesGo(t *testing.T) {
+	src, err := os.ReadFile("pine.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := pine.Compile(string(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	candles := trendingCandles(1500)
+	params := map[string]any{"factor": 2.5}
+
+	got, err := NewPineStrategy(script.Title, script, params).GenerateSignals(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := NewMaxTrendPointsStrategy(params).GenerateSignals(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := []struct {
+		index int
+		typ   SignalType
+	}{
+		{303, SignalLong}, {449, SignalShort}, {607, SignalLong}, {799, SignalShort},
+		{806, SignalLong}, {928, SignalShort}, {945, SignalLong}, {958, SignalShort},
+		{1052, SignalLong}, {1087, SignalShort}, {1109, SignalLong}, {1161, SignalShort},
+		{1211, SignalLong}, {1265, SignalShort}, {1279, SignalLong}, {1345, SignalShort},
+		{1376, SignalLong}, {1465, SignalShort}, {1489, SignalLong},
+	}
+	for name, signals := range map[string][]Signal{"pine": got, "go": want} {
+		if len(signals) != len(golden) {
+			t.Fatalf("%s: %d signals, want %d", name, len(signals), len(golden))
+		}
+		for i, g := range golden {
+			if signals[i].Index != g.index || signals[i].Type != g.typ {
+				t.Errorf("%s signal %d: bar %d type %v, want bar %d type %v",
+					name, i, signals[i].Index, signals[i].Type, g.index, g.typ)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StrategyParameter describes one input of a strategy; the strategy form in
+// the UI is rendered from these. Type is "number", "bool", "string" or
+// "select" (a string from Options).
+type StrategyParameter struct {
+	Name    string
+	Label   string
+	Type    string
+	Default any
+	Min     float64
+	Max     float64
+	Step    float64
+	Options []string
+}
+
+// StrategyInfo describes a strategy that can be backtested and run live.
+// Source is "builtin" for Go strategies, otherwise the kind of definition it
+// was compiled from.
+type StrategyInfo struct {
+	ID          string
+	Name        string
+	Description string
+	Source      string
+	Parameters  []StrategyParameter
+}
+
+// StrategyFactory creates a strategy from parameters, which already have the
+// defaults of StrategyInfo.Parameters filled in.
+type StrategyFactory func(params map[string]any) (Strategy, error)
+
+type registeredStrategy struct {
+	info    StrategyInfo
+	factory StrategyFactory
+}
+
+// StrategyRegistry maps strategy IDs to factories. Backtests, live strategies
+// and resumed strategies all create their Strategy through it.
+type StrategyRegistry struct {
+	mu      sync.RWMutex
+	entries map[string]registeredStrategy
+}
+
+// NewStrategyRegistry returns a registry holding the built-in Go strategies.
+func NewStrategyRegistry() *StrategyRegistry {
+	r := &StrategyRegistry{entries: make(map[string]registeredStrategy)}
+	r.Register(StrategyInfo{
+		ID:          "max-trend",
+		Name:        "Max Trend Points",
+		Description: "Trend-following strategy using Hull Moving Average",
+		Source:      "builtin",
+		Parameters: []StrategyParameter{
+			{Name: "factor", Label: "Factor", Type: "number", Default: 2.5, Min: 0.1, Max: 10, Step: 0.1},
+		},
+	}, func(params map[string]any) (Strategy, error) {
+		return NewMaxTrendPointsStrategy(params), nil
+	})
+	r.Register(StrategyInfo{
+		ID:          "grid",
+		Name:        "Grid",
+		Description: "Ladder of resting limit orders between two prices; each level trades the position size",
+		Source:      "builtin",
+		Parameters: []StrategyParameter{
+			{Name: "lowerPrice", Label: "Lower Price (0 = auto)", Type: "number", Default: 0.0, Min: 0, Step: 0.01},
+			{Name: "upperPrice", Label: "Upper Price (0 = auto)", Type: "number", Default: 0.0, Min: 0, Step: 0.01},
+			{Name: "rangePercent", Label: "Auto Range %", Type: "number", Default: 10.0, Min: 0.1, Max: 99, Step: 0.1},
+			{Name: "gridLevels", Label: "Levels", Type: "number", Default: 10.0, Min: 3, Max: 200, Step: 1},
+			{Name: "gridSpacing", Label: "Spacing", Type: "select", Default: "arithmetic", Options: []string{"arithmetic", "geometric"}},
+			{Name: "rangeExit", Label: "On Range Exit", Type: "select", Default: "flatten", Options: []string{"flatten", "stop"}},
+		},
+	}, func(params map[string]any) (Strategy, error) {
+		return NewGridStrategy(params)
+	})
+	r.Register(StrategyInfo{
+		ID:          "pairs",
+		Name:        "Pairs",
+		Description: "Market-neutral spread trade against a second symbol on the z-score of a rolling hedge regression",
+		Source:      "builtin",
+		Parameters: []StrategyParameter{
+			{Name: "pairSymbol", Label: "Pair Symbol", Type: "string", Default: "ETH"},
+			{Name: "lookback", Label: "Lookback", Type: "number", Default: 100.0, Min: 10, Max: 200, Step: 1},
+			{Name: "entryZ", Label: "Entry Z", Type: "number", Default: 2.0, Min: 0.1, Max: 10, Step: 0.1},
+			{Name: "exitZ", Label: "Exit Z", Type: "number", Default: 0.5, Min: 0, Max: 10, Step: 0.1},
+			{Name: "stopZ", Label: "Stop Z (0 = off)", Type: "number", Default: 4.0, Min: 0, Max: 20, Step: 0.1},
+		},
+	}, func(params map[string]any) (Strategy, error) {
+		return NewPairsStrategy(params)
+	})
+	return r
+}
+
+// Register adds or replaces a strategy.
+func (r *StrategyRegistry) Register(info StrategyInfo, factory StrategyFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[info.ID] = registeredStrategy{info: info, factory: factory}
+}
+
+func (r *StrategyRegistry) Unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, id)
+}
+
+func (r *StrategyRegistry) Info(id string) (StrategyInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.entries[id]
+	return entry.info, ok
+}
+
+// List returns every registered strategy, built-ins first, then by name.
+func (r *StrategyRegistry) List() []StrategyInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	infos := make([]StrategyInfo, 0, len(r.entries))
+	for _, entry := range r.entries {
+		infos = append(infos, entry.info)
+	}
+	slices.SortFunc(infos, func(a, b StrategyInfo) int {
+		if (a.Source == "builtin") != (b.Source == "builtin") {
+			if a.Source == "builtin" {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return infos
+}
+
+// New creates the strategy registered under id. Parameters missing from
+// params take their declared defaults; params itself is not modified.
+func (r *StrategyRegistry) New(id string, params map[string]any) (Strategy, map[string]any, error) {
+	r.mu.RLock()
+	entry, ok := r.entries[id]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown strategy %q", id)
+	}
+
+	merged := maps.Clone(params)
+	if merged == nil {
+		merged = make(map[string]any)
+	}
+	for _, param := range entry.info.Parameters {
+		if _, set := merged[param.Name]; !set && param.Default != nil {
+			merged[param.Name] = param.Default
+		}
+	}
+	strategy, err := entry.factory(merged)
+	if err != nil {
+		return nil, nil, fmt.Errorf("strategy %s: %w", id, err)
+	}
+	return strategy, merged, nil
+}
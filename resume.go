@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// resumeStrategies restarts saved strategies for the current network. It is
+// called at startup and again whenever trading becomes possible (wallet
+// unlocked, mainnet confirmed, network switched), and skips strategies that
+// are already running or whose account cannot trade yet.
+func (a *App) resumeStrategies() {
+	a.mu.RLock()
+	enabled := a.config.Settings.ResumeStrategies
+	a.mu.RUnlock()
+	if a.store == nil || !enabled || a.requireTradingConfirmed() != nil {
+		return
+	}
+	if err := a.ResumeSavedStrategies(); err != nil {
+		slog.Error("resume strategies failed", "error", err)
+	}
+}
+
+func (a *App) GetSavedStrategies() ([]StrategyRecord, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("strategy storage is not available")
+	}
+	return a.store.LoadStrategies()
+}
+
+func (a *App) ResumeSavedStrategies() error {
+	if a.store == nil {
+		return fmt.Errorf("strategy storage is not available")
+	}
+	if a.risk.IsKilled() {
+		return fmt.Errorf("kill switch active")
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if err := a.tradingConfirmed(); err != nil {
+		return err
+	}
+
+	records, err := a.store.LoadStrategies()
+	if err != nil {
+		return err
+	}
+	running := make(map[string]bool)
+	for _, strategy := range a.engine.GetRunningStrategies() {
+		running[strategy.ID] = true
+	}
+
+	for _, rec := range records {
+		if rec.Network != a.config.Network.Name || running[rec.ID] {
+			continue
+		}
+		logger := slog.With("strategy", rec.ID, "account", rec.AccountID, "symbol", rec.Symbol, "interval", rec.Interval)
+		account, err := a.accounts.Get(rec.AccountID)
+		if err != nil {
+			logger.Error("resume failed", "error", err)
+			continue
+		}
+		if account.IsReadOnly() {
+			continue
+		}
+
+		impl, params, err := a.strategies.New(rec.Kind, rec.Params)
+		if err != nil {
+			logger.Error("resume failed", "kind", rec.Kind, "error", err)
+			continue
+		}
+		strategy := NewLiveStrategy(rec.Kind, impl, params)
+		strategy.ID = rec.ID
+		strategy.Symbol = rec.Symbol
+		strategy.Interval = rec.Interval
+		strategy.AccountID = account.ID()
+		strategy.account = account
+		strategy.LastCandleTime = rec.LastCandleTime
+		strategy.Position = rec.Position
+		strategy.Trades = rec.Trades
+		strategy.Grid = rec.Grid
+		if rec.Paused {
+			strategy.State = StrategyPaused
+		}
+		if err := a.reconcilePosition(strategy, records); err != nil {
+			strategy.logger().Error("reconcile position failed", "error", err)
+			continue
+		}
+
+		if err := a.engine.StartStrategy(rec.ID, strategy); err != nil {
+			strategy.logger().Error("resume failed", "error", err)
+			continue
+		}
+		running[rec.ID] = true
+		strategy.logger().Info("strategy resumed")
+	}
+	return nil
+}
+
+func (a *App) DiscardSavedStrategy(id string) error {
+	if a.store == nil {
+		return fmt.Errorf("strategy storage is not available")
+	}
+	for _, strategy := range a.strategyEngine().GetRunningStrategies() {
+		if strategy.ID == id {
+			return fmt.Errorf("strategy %s is running, stop it instead", id)
+		}
+	}
+	return a.store.DeleteStrategy(id)
+}
+
+// reconcilePosition compares the saved position with the exchange. A saved
+// position that is gone is settled and journaled; an exchange position on the
+// same coin that no other saved strategy claims is re-attached.
+func (a *App) reconcilePosition(strategy *LiveStrategy, records []StrategyRecord) error {
+	positions, err := strategy.account.GetActivePositions()
+	if err != nil {
+		return fmt.Errorf("failed to fetch positions: %w", err)
+	}
+	var live *ActivePosition
+	for i := range positions {
+		if positions[i].Coin == strategy.Symbol {
+			live = &positions[i]
+			break
+		}
+	}
+
+	saved := strategy.Position
+	if saved != nil && saved.IsOpen && len(saved.Legs) > 0 {
+		a.settleOfflineLegs(strategy, positions)
+		return nil
+	}
+	if saved != nil && saved.IsOpen {
+		if live == nil || live.Side != saved.Side {
+			a.settleOffline(strategy)
+			return nil
+		}
+		saved.Size = min(saved.Size, parseFloatSafe(live.Size))
+		return nil
+	}
+
+	if live == nil {
+		return nil
+	}
+	for _, rec := range records {
+		if rec.AccountID == strategy.AccountID && rec.Symbol == strategy.Symbol &&
+			rec.Position != nil && rec.Position.IsOpen {
+			return nil
+		}
+	}
+	strategy.Position = &Position{
+		EntryPrice: parseFloatSafe(live.EntryPrice),
+		EntryTime:  time.Now().UnixMilli(),
+		Side:       live.Side,
+		Size:       parseFloatSafe(live.Size),
+		IsOpen:     true,
+	}
+	strategy.logger().Info("re-attached exchange position", "side", live.Side, "size", strategy.Position.Size)
+	return nil
+}
+
+// settleOffline settles the strategy's position, closed while the app was not
+// running, at the average of the closing fills since it opened, or at the
+// current mid price when they cannot be found, and journals it.
+func (a *App) settleOffline(strategy *LiveStrategy) {
+	position := strategy.Position
+	price, at, err := strategy.account.closingFill(strategy.Symbol, position.Side, position.EntryTime)
+	if err != nil {
+		strategy.logger().Warn("closing fills not found", "error", err)
+	}
+	if price == 0 {
+		if strategy.MarkPrice, err = strategy.account.midPrice(strategy.Symbol); err != nil {
+			strategy.logger().Warn("mid price not found", "error", err)
+		}
+	}
+	strategy.recordClose(price, "Closed While Offline")
+	if at > 0 {
+		position.ExitTime = at
+		strategy.Trades[len(strategy.Trades)-1].ExitTime = at
+	}
+}
+
+// settleOfflineLegs handles a multi-symbol position with legs closed while
+// the app was not running. Those legs exit at their closing fills, or at the
+// current mid price, and the legs still open are closed, since the spread is
+// gone; the position is then journaled. A position with every leg open is
+// left as it is.
+func (a *App) settleOfflineLegs(strategy *LiveStrategy, positions []ActivePosition) {
+	position := strategy.Position
+	missing := false
+	for i := range position.Legs {
+		leg := &position.Legs[i]
+		if leg.ExitPrice > 0 || hasPosition(positions, leg.Symbol, leg.Side) {
+			continue
+		}
+		missing = true
+		price, _, err := strategy.account.closingFill(leg.Symbol, leg.Side, position.EntryTime)
+		if err != nil {
+			strategy.logger().Warn("closing fills not found", "coin", leg.Symbol, "error", err)
+		}
+		if price == 0 {
+			if price, err = strategy.account.midPrice(leg.Symbol); err != nil {
+				strategy.logger().Warn("mid price not found", "coin", leg.Symbol, "error", err)
+			}
+		}
+		leg.ExitPrice = price
+		if leg.ExitPrice <= 0 {
+			leg.ExitPrice = leg.EntryPrice
+		}
+	}
+	if missing {
+		strategy.closeLegs("Closed While Offline")
+	}
+}
+
+// hasPosition reports whether positions hold coin on side.
+func hasPosition(positions []ActivePosition, coin, side string) bool {
+	for _, position := range positions {
+		if position.Coin == coin && position.Side == side {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// newSpread returns a long BTC, short ETH position opened at 90 and 110.
+func newSpread() *Position {
+	return &Position{
+		Side:       "long",
+		Size:       1,
+		EntryPrice: 90,
+		EntryTime:  time.Now().Add(-time.Hour).UnixMilli(),
+		IsOpen:     true,
+		Legs: []PositionLeg{
+			{Symbol: "BTC", Side: "long", Size: 1, EntryPrice: 90},
+			{Symbol: "ETH", Side: "short", Size: 1, EntryPrice: 110},
+		},
+	}
+}
+
+func TestReconcileClosesSurvivingLegs(t *testing.T) {
+	fake := newFakeExchange(t, "BTC", "ETH")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	// The BTC leg was closed while the app was not running.
+	fake.positions["ETH"] = -1
+
+	strategy := newFlipStrategy(fake.account(ctx), "BTC")
+	strategy.Position = newSpread()
+	if err := (&App{}).reconcilePosition(strategy, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if fake.position("ETH") != 0 {
+		t.Errorf("ETH position %v, want the surviving leg closed", fake.position("ETH"))
+	}
+	position := strategy.Position
+	if position.IsOpen || position.ExitReason != "Closed While Offline" {
+		t.Fatalf("position open %v, reason %q", position.IsOpen, position.ExitReason)
+	}
+	// Both legs exit at the mid of 100: +10 on BTC and +10 on ETH.
+	if gross := position.PnL + position.Fees; math.Abs(gross-20) > 1e-9 {
+		t.Errorf("gross pnl %.4f, want 20", gross)
+	}
+	if len(strategy.Trades) != 1 || strategy.Trades[0].PnL != position.PnL {
+		t.Errorf("trades = %+v, want the settled position", strategy.Trades)
+	}
+}
+
+func TestRecordCloseSettlesLegs(t *testing.T) {
+	strategy := NewLiveStrategy("flip", flipStrategy{}, map[string]any{})
+	strategy.Position = newSpread()
+	strategy.Position.Legs[1].MarkPrice = 105
+
+	strategy.recordClose(95, "Halted")
+	legs := strategy.Position.Legs
+	if legs[0].ExitPrice != 95 || legs[1].ExitPrice != 105 {
+		t.Errorf("exit prices %v and %v, want 95 and 105", legs[0].ExitPrice, legs[1].ExitPrice)
+	}
+	if gross := strategy.Position.PnL + strategy.Position.Fees; math.Abs(gross-10) > 1e-9 {
+		t.Errorf("gross pnl %.4f, want 10", gross)
+	}
+	if len(strategy.Trades) != 1 || strategy.Trades[0].IsOpen {
+		t.Errorf("trades = %+v, want the settled position", strategy.Trades)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const maxRiskEvents = 200
+
+// RiskLimits configures the pre-trade checks. A zero value disables the
+// corresponding limit.
+type RiskLimits struct {
+	MaxSymbolNotional    float64 `yaml:"maxSymbolNotional" toml:"maxSymbolNotional"`
+	MaxTotalNotional     float64 `yaml:"maxTotalNotional" toml:"maxTotalNotional"`
+	MaxDailyLoss         float64 `yaml:"maxDailyLoss" toml:"maxDailyLoss"`
+	MaxConsecutiveLosses int     `yaml:"maxConsecutiveLosses" toml:"maxConsecutiveLosses"`
+	MaxOrdersPerMinute   int     `yaml:"maxOrdersPerMinute" toml:"maxOrdersPerMinute"`
+}
+
+func (l RiskLimits) Validate() error {
+	if l.MaxSymbolNotional < 0 || l.MaxTotalNotional < 0 || l.MaxDailyLoss < 0 ||
+		l.MaxConsecutiveLosses < 0 || l.MaxOrdersPerMinute < 0 {
+		return fmt.Errorf("risk limits must not be negative")
+	}
+	return nil
+}
+
+type RiskEvent struct {
+	Time   int64
+	Coin   string
+	Reason string
+}
+
+type RiskStatus struct {
+	Limits            RiskLimits
+	KillSwitch        bool
+	KillSwitchReason  string
+	KillSwitchTime    int64
+	DailyRealizedPnL  float64
+	ConsecutiveLosses int
+	OrdersLastMinute  int
+	Rejections        []RiskEvent
+}
+
+// RiskManager sits between strategies and the Account. Every order that
+// increases exposure goes through CheckOrder, the realized results of the
+// accounts' closing fills are fed back through RecordRealizedPnL, and
+// breaching the loss limits trips the kill switch.
+type RiskManager struct {
+	mu                sync.Mutex
+	limits            RiskLimits
+	killed            bool
+	killReason        string
+	killTime          int64
+	day               string
+	dailyPnL          float64
+	consecutiveLosses int
+	orderTimes        []time.Time
+	rejections        []RiskEvent
+	onTrip            func(reason string)
+}
+
+func NewRiskManager(limits RiskLimits) *RiskManager {
+	return &RiskManager{
+		limits: limits,
+		day:    time.Now().UTC().Format("2006-01-02"),
+	}
+}
+
+func (r *RiskManager) SetOnTrip(fn func(reason string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onTrip = fn
+}
+
+func (r *RiskManager) SetLimits(limits RiskLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = limits
+	return nil
+}
+
+func (r *RiskManager) Limits() RiskLimits {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.limits
+}
+
+// CheckOrder validates an order that opens or increases a position.
+// symbolNotional and totalNotional are the current absolute exposures before
+// the order is placed.
+func (r *RiskManager) CheckOrder(coin string, notional, symbolNotional, totalNotional float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.rollDay(now)
+
+	if r.killed {
+		return r.reject(coin, "kill switch active: "+r.killReason)
+	}
+	if r.limits.MaxDailyLoss > 0 && -r.dailyPnL >= r.limits.MaxDailyLoss {
+		return r.reject(coin, fmt.Sprintf("daily loss %.2f reached limit %.2f", -r.dailyPnL, r.limits.MaxDailyLoss))
+	}
+	if r.limits.MaxConsecutiveLosses > 0 && r.consecutiveLosses >= r.limits.MaxConsecutiveLosses {
+		return r.reject(coin, fmt.Sprintf("%d consecutive losses reached limit %d", r.consecutiveLosses, r.limits.MaxConsecutiveLosses))
+	}
+	if r.limits.MaxSymbolNotional > 0 && symbolNotional+notional > r.limits.MaxSymbolNotional {
+		return r.reject(coin, fmt.Sprintf("%s notional %.2f would exceed limit %.2f", coin, symbolNotional+notional, r.limits.MaxSymbolNotional))
+	}
+	if r.limits.MaxTotalNotional > 0 && totalNotional+notional > r.limits.MaxTotalNotional {
+		return r.reject(coin, fmt.Sprintf("total notional %.2f would exceed limit %.2f", totalNotional+notional, r.limits.MaxTotalNotional))
+	}
+	if err := r.checkRate(coin, now); err != nil {
+		return err
+	}
+
+	r.orderTimes = append(r.orderTimes, now)
+	return nil
+}
+
+// CheckReduceOrder validates an order that only reduces exposure. Reducing
+// orders are never blocked by the kill switch or exposure limits, only by the
+// order rate limit while the kill switch is off.
+func (r *RiskManager) CheckReduceOrder(coin string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if !r.killed {
+		if err := r.checkRate(coin, now); err != nil {
+			return err
+		}
+	}
+	r.orderTimes = append(r.orderTimes, now)
+	return nil
+}
+
+// RecordRealizedPnL feeds the result of a closed trade back into the daily
+// loss and loss streak counters and trips the kill switch when either limit
+// is breached.
+func (r *RiskManager) RecordRealizedPnL(coin string, pnl float64) {
+	r.mu.Lock()
+	r.rollDay(time.Now())
+	r.dailyPnL += pnl
+	if pnl < 0 {
+		r.consecutiveLosses++
+	} else {
+		r.consecutiveLosses = 0
+	}
+
+	reason := ""
+	if r.limits.MaxDailyLoss > 0 && -r.dailyPnL >= r.limits.MaxDailyLoss {
+		reason = fmt.Sprintf("daily loss %.2f reached limit %.2f", -r.dailyPnL, r.limits.MaxDailyLoss)
+	} else if r.limits.MaxConsecutiveLosses > 0 && r.consecutiveLosses >= r.limits.MaxConsecutiveLosses {
+		reason = fmt.Sprintf("%d consecutive losses reached limit %d", r.consecutiveLosses, r.limits.MaxConsecutiveLosses)
+	}
+	onTrip := r.trip(coin, reason)
+	r.mu.Unlock()
+
+	if onTrip != nil {
+		go onTrip(reason)
+	}
+}
+
+// Trip activates the kill switch and runs the trip handler synchronously.
+func (r *RiskManager) Trip(reason string) {
+	if reason == "" {
+		reason = "manual"
+	}
+	r.mu.Lock()
+	onTrip := r.trip("", reason)
+	r.mu.Unlock()
+
+	if onTrip != nil {
+		onTrip(reason)
+	}
+}
+
+// Reset clears the kill switch and the loss counters, the day's realized PnL
+// included, so the next losing trade does not trip the switch again.
+func (r *RiskManager) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.killed = false
+	r.killReason = ""
+	r.killTime = 0
+	r.dailyPnL = 0
+	r.consecutiveLosses = 0
+	slog.Info("kill switch reset")
+}
+
+func (r *RiskManager) IsKilled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.killed
+}
+
+func (r *RiskManager) Status() RiskStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.rollDay(now)
+	r.pruneOrders(now)
+
+	rejections := make([]RiskEvent, len(r.rejections))
+	copy(rejections, r.rejections)
+
+	return RiskStatus{
+		Limits:            r.limits,
+		KillSwitch:        r.killed,
+		KillSwitchReason:  r.killReason,
+		KillSwitchTime:    r.killTime,
+		DailyRealizedPnL:  r.dailyPnL,
+		ConsecutiveLosses: r.consecutiveLosses,
+		OrdersLastMinute:  len(r.orderTimes),
+		Rejections:        rejections,
+	}
+}
+
+func (r *RiskManager) trip(coin, reason string) func(string) {
+	if reason == "" || r.killed {
+		return nil
+	}
+	r.killed = true
+	r.killReason = reason
+	r.killTime = time.Now().UnixMilli()
+	r.record(coin, "kill switch tripped: "+reason)
+	slog.Error("kill switch tripped", "coin", coin, "reason", reason)
+	return r.onTrip
+}
+
+func (r *RiskManager) checkRate(coin string, now time.Time) error {
+	if r.limits.MaxOrdersPerMinute <= 0 {
+		return nil
+	}
+	r.pruneOrders(now)
+	if len(r.orderTimes) >= r.limits.MaxOrdersPerMinute {
+		return r.reject(coin, fmt.Sprintf("order rate limit of %d per minute reached", r.limits.MaxOrdersPerMinute))
+	}
+	return nil
+}
+
+func (r *RiskManager) pruneOrders(now time.Time) {
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(r.orderTimes) && r.orderTimes[i].Before(cutoff) {
+		i++
+	}
+	r.orderTimes = r.orderTimes[i:]
+}
+
+func (r *RiskManager) rollDay(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if day != r.day {
+		r.day = day
+		r.dailyPnL = 0
+	}
+}
+
+func (r *RiskManager) reject(coin, reason string) error {
+	r.record(coin, reason)
+	slog.Warn("risk check rejected order", "coin", coin, "reason", reason)
+	return fmt.Errorf("risk check failed: %s", reason)
+}
+
+func (r *RiskManager) record(coin, reason string) {
+	r.rejections = append(r.rejections, RiskEvent{
+		Time:   time.Now().UnixMilli(),
+		Coin:   coin,
+		Reason: reason,
+	})
+	if len(r.rejections) > maxRiskEvents {
+		r.rejections = r.rejections[len(r.rejections)-maxRiskEvents:]
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCheckOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   RiskLimits
+		setup    func(r *RiskManager)
+		notional float64
+		symbol   float64
+		total    float64
+		err      string
+	}{
+		{"no limits", RiskLimits{}, nil, 1e9, 1e9, 1e9, ""},
+		{"within symbol limit", RiskLimits{MaxSymbolNotional: 100}, nil, 40, 60, 500, ""},
+		{"symbol limit", RiskLimits{MaxSymbolNotional: 100}, nil, 41, 60, 60, "BTC notional 101.00 would exceed limit 100.00"},
+		{"total limit", RiskLimits{MaxTotalNotional: 100}, nil, 30, 0, 80, "total notional 110.00 would exceed limit 100.00"},
+		{"daily loss", RiskLimits{MaxDailyLoss: 50}, func(r *RiskManager) { r.dailyPnL = -50 }, 1, 0, 0,
+			"daily loss 50.00 reached limit 50.00"},
+		{"loss streak", RiskLimits{MaxConsecutiveLosses: 3}, func(r *RiskManager) { r.consecutiveLosses = 3 }, 1, 0, 0,
+			"3 consecutive losses reached limit 3"},
+		{"rate", RiskLimits{MaxOrdersPerMinute: 2}, func(r *RiskManager) {
+			r.CheckOrder("BTC", 1, 0, 0)
+			r.CheckReduceOrder("BTC")
+		}, 1, 0, 0, "order rate limit of 2 per minute reached"},
+		{"kill switch", RiskLimits{}, func(r *RiskManager) { r.Trip("test") }, 1, 0, 0, "kill switch active: test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRiskManager(tt.limits)
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+			err := r.CheckOrder("BTC", tt.notional, tt.symbol, tt.total)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("CheckOrder error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != "risk check failed: "+tt.err {
+				t.Errorf("CheckOrder error = %v, want %q", err, tt.err)
+			}
+			if status := r.Status(); len(status.Rejections) == 0 || !strings.HasSuffix(status.Rejections[len(status.Rejections)-1].Reason, tt.err) {
+				t.Errorf("rejection not recorded: %+v", status.Rejections)
+			}
+		})
+	}
+}
+
+func TestCheckReduceOrder(t *testing.T) {
+	r := NewRiskManager(RiskLimits{MaxOrdersPerMinute: 1, MaxDailyLoss: 10})
+	r.dailyPnL = -20
+	if err := r.CheckReduceOrder("BTC"); err != nil {
+		t.Errorf("reduce order past the loss limit: %v", err)
+	}
+	if err := r.CheckReduceOrder("BTC"); err == nil {
+		t.Error("reduce order past the rate limit was allowed")
+	}
+	// With the kill switch tripped, closing is never blocked.
+	r.Trip("test")
+	for range 3 {
+		if err := r.CheckReduceOrder("BTC"); err != nil {
+			t.Errorf("reduce order with the kill switch tripped: %v", err)
+		}
+	}
+}
+
+func TestRecordRealizedPnL(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits RiskLimits
+		pnls   []float64
+		reason string
+	}{
+		{"daily loss", RiskLimits{MaxDailyLoss: 100}, []float64{-60, 30, -70}, "daily loss 100.00 reached limit 100.00"},
+		{"under the daily loss", RiskLimits{MaxDailyLoss: 100}, []float64{-60, 30, -69}, ""},
+		{"loss streak", RiskLimits{MaxConsecutiveLosses: 3}, []float64{-1, -1, -1}, "3 consecutive losses reached limit 3"},
+		{"a win breaks the streak", RiskLimits{MaxConsecutiveLosses: 3}, []float64{-1, -1, 0.5, -1, -1}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRiskManager(tt.limits)
+			tripped := make(chan string, 1)
+			r.SetOnTrip(func(reason string) { tripped <- reason })
+			for _, pnl := range tt.pnls {
+				r.RecordRealizedPnL("BTC", pnl)
+			}
+			status := r.Status()
+			if status.KillSwitch != (tt.reason != "") || status.KillSwitchReason != tt.reason {
+				t.Fatalf("kill switch %v %q, want %q", status.KillSwitch, status.KillSwitchReason, tt.reason)
+			}
+			if tt.reason != "" {
+				if reason := <-tripped; reason != tt.reason {
+					t.Errorf("trip handler got %q", reason)
+				}
+			}
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	r := NewRiskManager(RiskLimits{MaxDailyLoss: 100, MaxConsecutiveLosses: 2})
+	r.RecordRealizedPnL("BTC", -60)
+	r.RecordRealizedPnL("BTC", -60)
+	if !r.IsKilled() {
+		t.Fatal("kill switch not tripped")
+	}
+
+	r.Reset()
+	status := r.Status()
+	if status.KillSwitch || status.DailyRealizedPnL != 0 || status.ConsecutiveLosses != 0 {
+		t.Fatalf("status after reset = %+v", status)
+	}
+	if err := r.CheckOrder("BTC", 1, 0, 0); err != nil {
+		t.Errorf("CheckOrder after reset: %v", err)
+	}
+	r.RecordRealizedPnL("BTC", -10)
+	if r.IsKilled() {
+		t.Error("a small loss after reset tripped the kill switch again")
+	}
+}
+
+func TestPollFills(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	account := fake.account(ctx)
+	risk := NewRiskManager(RiskLimits{MaxConsecutiveLosses: 2})
+	account.SetRiskManager(risk)
+
+	// A resting order fills across two polls, and another order closes
+	// with a loss on the second poll.
+	if _, err := account.PlaceOrder(OrderTicket{Coin: "BTC", Kind: OrderLimit, Size: 0.2, Price: 110, Tif: "gtc"}); err != nil {
+		t.Fatal(err)
+	}
+	oid := fake.restingOrders()[110]
+	seen := make(map[int64]bool)
+	pending := make(map[int64]*fillTrade)
+
+	fake.addFill("BTC", oid, 1, 1000, -4, 0.5)
+	fake.addFill("BTC", oid, 2, 1000, 0, 0.5) // opening fills do not count
+	since, err := account.pollFills(ctx, 0, seen, pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := risk.Status(); status.ConsecutiveLosses != 0 || status.DailyRealizedPnL != 0 {
+		t.Errorf("an open order was recorded: %+v", status)
+	}
+
+	fake.addFill("BTC", oid, 3, 2000, -3, 0.5)
+	fake.cancelOrder(oid)
+	if since, err = account.pollFills(ctx, since, seen, pending); err != nil {
+		t.Fatal(err)
+	}
+	status := risk.Status()
+	if status.ConsecutiveLosses != 1 || status.DailyRealizedPnL != -8 || status.KillSwitch {
+		t.Errorf("status = %+v, want one trade losing 8", status)
+	}
+
+	// Polling again from the last fill's time records nothing twice.
+	fake.addFill("BTC", oid+100, 4, 2000, -1, 0)
+	if _, err = account.pollFills(ctx, since, seen, pending); err != nil {
+		t.Fatal(err)
+	}
+	if status := risk.Status(); status.ConsecutiveLosses != 2 || status.DailyRealizedPnL != -9 || !status.KillSwitch {
+		t.Errorf("status = %+v, want two losing trades and the kill switch tripped", status)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending = %v", pending)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/rules"
+)
+
+// RuleStrategy runs a declarative rule definition as a Strategy. Its first
+// plot is charted as the trend line, colored by the position held.
+type RuleStrategy struct {
+	Config StrategyConfig
+	def    *rules.Definition
+	output *StrategyOutput
+}
+
+// NewRuleStrategy builds a strategy from def. The definition's sizing and
+// TP/SL apply where params do not set them.
+func NewRuleStrategy(def *rules.Definition, params map[string]any) *RuleStrategy {
+	merged := def.Defaults()
+	for k, v := range params {
+		merged[k] = v
+	}
+	strategy := &RuleStrategy{def: def}
+	strategy.Config = strategy.BuildConfig(merged)
+	return strategy
+}
+
+func (s *RuleStrategy) GetName() string {
+	return s.def.Name
+}
+
+func (s *RuleStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	return buildStrategyConfig(params)
+}
+
+func (s *RuleStrategy) WarmUp(interval string) (int, error) {
+	return s.def.WarmUp(s.Config.Parameters)
+}
+
+func (s *RuleStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) {
+	return s.run(candles)
+}
+
+func (s *RuleStrategy) GetVisualizationData(candles hyperliquid.Candles) *StrategyOutput {
+	if _, err := s.run(candles); err != nil {
+		return nil
+	}
+	return s.output
+}
+
+func (s *RuleStrategy) Run(candles hyperliquid.Candles) (*StrategyOutput, error) {
+	if _, err := s.run(candles); err != nil {
+		return nil, err
+	}
+	return s.output, nil
+}
+
+func (s *RuleStrategy) Backtest(candles hyperliquid.Candles) (*BacktestOutput, error) {
+	signals, err := s.GenerateSignals(candles)
+	if err != nil {
+		return nil, err
+	}
+	return newBacktestOutput(s, s.Config, candles, signals, s.output), nil
+}
+
+// run evaluates the definition over candles, builds the chart output and
+// returns its signals.
+func (s *RuleStrategy) run(candles hyperliquid.Candles) ([]Signal, error) {
+	bars := make([]rules.Bar, len(candles))
+	for i, candle := range candles {
+		bars[i] = rules.Bar{
+			Time:   candle.Timestamp,
+			Open:   parseFloat(candle.Open),
+			High:   parseFloat(candle.High),
+			Low:    parseFloat(candle.Low),
+			Close:  parseFloat(candle.Close),
+			Volume: parseFloat(candle.Volume),
+		}
+	}
+	result, err := s.def.Run(bars, s.Config.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.def.Name, err)
+	}
+
+	signals := make([]Signal, 0, len(result.Signals))
+	for _, rs := range result.Signals {
+		signal := Signal{
+			Index:  rs.Bar,
+			Price:  bars[rs.Bar].Close,
+			Time:   bars[rs.Bar].Time,
+			Reason: rs.Reason,
+		}
+		switch rs.Action {
+		case "long":
+			signal.Type = SignalLong
+		case "short":
+			signal.Type = SignalShort
+		default:
+			signal.Type = SignalClose
+		}
+		signals = append(signals, signal)
+	}
+
+	n := len(candles)
+	s.output = &StrategyOutput{
+		TrendLines:  make([]float64, n),
+		TrendColors: make([]string, n),
+		Directions:  positionDirections(n, signals),
+		Labels:      []Label{},
+		Lines:       []TrendLine{},
+		FillColors:  make([]string, n),
+	}
+	for i, direction := range s.output.Directions {
+		if direction == -1 {
+			s.output.TrendColors[i] = "#1cc2d8"
+		} else {
+			s.output.TrendColors[i] = "#e49013"
+		}
+	}
+	if len(result.Plots) > 0 {
+		for i, v := range result.Plots[0].Values {
+			// The chart skips zero values; JSON has no NaN.
+			if !math.IsNaN(v) {
+				s.output.TrendLines[i] = v
+			}
+		}
+	}
+	return signals, nil
+}
@@ -0,0 +1,186 @@
+// Package rules compiles declarative strategy definitions, written in YAML
+// or JSON, into signals over candles.
+//
+// A definition names indicators computed from prices or from each other,
+// entry and exit conditions per side, filters every entry must pass, and
+// default sizing and TP/SL:
+//
+//	name: EMA Cross
+//	parameters:
+//	  - {name: fastLength, default: 9, min: 2, max: 50}
+//	indicators:
+//	  - {name: fast, type: ema, length: fastLength}
+//	  - {name: slow, type: ema, length: 21}
+//	  - {name: rsi, type: rsi, length: 14}
+//	entry:
+//	  long:
+//	    all:
+//	      - crossover: [fast, slow]
+//	      - below: [rsi, 70]
+//	  short:
+//	    crossunder: [fast, slow]
+//	exit:
+//	  long:
+//	    above: [rsi, 80]
+//	filters:
+//	  - above: [volume, 0]
+//	sizing: {positionSize: 0.01}
+//	risk: {takeProfitPercent: 3, stopLossPercent: 1.5, tradeDirection: both}
+//	plot: [fast, slow]
+//
+// Operands are numbers, parameter names, price sources (open, high, low,
+// close, volume, hl2, hlc3, ohlc4) or indicators, optionally with an output
+// such as macd.signal or bb.upper.
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Definition struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description"`
+	Parameters  []Parameter `yaml:"parameters"`
+	Indicators  []Indicator `yaml:"indicators"`
+	Entry       Sides       `yaml:"entry"`
+	Exit        Sides       `yaml:"exit"`
+	Filters     []Condition `yaml:"filters"`
+	Sizing      Sizing      `yaml:"sizing"`
+	Risk        Risk        `yaml:"risk"`
+	Plot        []Value     `yaml:"plot"`
+}
+
+// Parameter is a number the definition's operands and indicator settings
+// can refer to by name, exposed as a strategy parameter.
+type Parameter struct {
+	Name    string  `yaml:"name"`
+	Label   string  `yaml:"label"`
+	Default float64 `yaml:"default"`
+	Min     float64 `yaml:"min"`
+	Max     float64 `yaml:"max"`
+	Step    float64 `yaml:"step"`
+}
+
+// Indicator is a named indicator. Settings not given take the usual
+// defaults of its type; see indicatorTypes.
+type Indicator struct {
+	Name      string `yaml:"name"`
+	Type      string `yaml:"type"`
+	Source    string `yaml:"source"`
+	Length    *Value `yaml:"length"`
+	Fast      *Value `yaml:"fast"`
+	Slow      *Value `yaml:"slow"`
+	Signal    *Value `yaml:"signal"`
+	Mult      *Value `yaml:"mult"`
+	Factor    *Value `yaml:"factor"`
+	Smoothing *Value `yaml:"smoothing"`
+}
+
+type Sides struct {
+	Long  *Condition `yaml:"long"`
+	Short *Condition `yaml:"short"`
+}
+
+// Condition is exactly one of its fields. The comparisons take two
+// operands; rising and falling take an operand and a number of bars.
+type Condition struct {
+	All        []Condition `yaml:"all"`
+	Any        []Condition `yaml:"any"`
+	Not        *Condition  `yaml:"not"`
+	CrossOver  []Value     `yaml:"crossover"`
+	CrossUnder []Value     `yaml:"crossunder"`
+	Cross      []Value     `yaml:"cross"`
+	Above      []Value     `yaml:"above"`
+	Below      []Value     `yaml:"below"`
+	Rising     []Value     `yaml:"rising"`
+	Falling    []Value     `yaml:"falling"`
+}
+
+type Sizing struct {
+	PositionSize float64 `yaml:"positionSize"`
+}
+
+type Risk struct {
+	TakeProfitPercent float64 `yaml:"takeProfitPercent"`
+	StopLossPercent   float64 `yaml:"stopLossPercent"`
+	TradeDirection    string  `yaml:"tradeDirection"`
+}
+
+// Value is a number or a reference by name.
+type Value struct {
+	Ref string
+	Num float64
+}
+
+func (v *Value) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a number or a name", node.Line)
+	}
+	if node.Tag == "!!int" || node.Tag == "!!float" {
+		n, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid number %q", node.Line, node.Value)
+		}
+		*v = Value{Num: n}
+		return nil
+	}
+	*v = Value{Ref: node.Value}
+	return nil
+}
+
+func (v Value) String() string {
+	if v.Ref != "" {
+		return v.Ref
+	}
+	return strconv.FormatFloat(v.Num, 'f', -1, 64)
+}
+
+// Compile decodes a YAML or JSON definition and checks that every
+// reference resolves. Unknown fields are errors, so typos do not silently
+// drop a condition.
+func Compile(src string) (*Definition, error) {
+	var def Definition
+	dec := yaml.NewDecoder(bytes.NewReader([]byte(src)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("invalid definition: %w", err)
+	}
+	if def.Name == "" {
+		return nil, fmt.Errorf("definition has no name")
+	}
+	if def.Entry.Long == nil && def.Entry.Short == nil {
+		return nil, fmt.Errorf("definition has no entry conditions")
+	}
+	switch def.Risk.TradeDirection {
+	case "", "both", "long", "short":
+	default:
+		return nil, fmt.Errorf("tradeDirection must be both, long or short, got %q", def.Risk.TradeDirection)
+	}
+	if _, err := def.Run(nil, nil); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Defaults returns the sizing and TP/SL the definition sets, keyed like the
+// strategy parameters they default.
+func (d *Definition) Defaults() map[string]any {
+	defaults := make(map[string]any)
+	if d.Sizing.PositionSize > 0 {
+		defaults["positionSize"] = d.Sizing.PositionSize
+	}
+	if d.Risk.TakeProfitPercent > 0 {
+		defaults["takeProfitPercent"] = d.Risk.TakeProfitPercent
+	}
+	if d.Risk.StopLossPercent > 0 {
+		defaults["stopLossPercent"] = d.Risk.StopLossPercent
+	}
+	if d.Risk.TradeDirection != "" {
+		defaults["tradeDirection"] = d.Risk.TradeDirection
+	}
+	return defaults
+}
@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// flatBars has one bar per close, with every price equal to the close.
+func flatBars(closes ...float64) []Bar {
+	bars := make([]Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = Bar{Time: int64(i) * 60_000, Open: c, High: c, Low: c, Close: c, Volume: 1}
+	}
+	return bars
+}
+
+func TestConditions(t *testing.T) {
+	closes := []float64{1, 3, 2, 2, 4, 1}
+	tests := []struct {
+		cond string
+		want []int
+	}{
+		{"crossover: [close, 2]", []int{1, 4}},
+		{"crossover: [close, level]", []int{1, 4}},
+		{"crossunder: [close, 2]", []int{5}},
+		{"cross: [close, 2]", []int{1, 4, 5}},
+		// The SMA is na on bar 0, so bar 1 is not a crossover.
+		{"crossover: [close, sma]", []int{4}},
+		{"crossunder: [close, sma]", []int{2, 5}},
+		{"above: [close, 2]", []int{1, 4}},
+		{"below: [close, level]", []int{0, 5}},
+		{"above: [2, close]", []int{0, 5}},
+		{"rising: [close, 1]", []int{1, 4}},
+		{"rising: [close, 2]", nil},
+		{"falling: [close, 1]", []int{2, 5}},
+		{"all: [{above: [close, 1.5]}, {below: [close, 3.5]}]", []int{1, 2, 3}},
+		{"any: [{below: [close, 1.5]}, {above: [close, 3.5]}]", []int{0, 4, 5}},
+		{"all: []", []int{0, 1, 2, 3, 4, 5}},
+		{"any: []", nil},
+		{"not: {above: [close, 2]}", []int{0, 2, 3, 5}},
+		{"all: [{any: [{crossover: [close, 2]}, {crossunder: [close, 2]}]}, {not: {above: [close, 3]}}]", []int{1, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cond, func(t *testing.T) {
+			var c Condition
+			if err := yaml.Unmarshal([]byte(tt.cond), &c); err != nil {
+				t.Fatal(err)
+			}
+			e := &evaluator{n: len(closes), params: map[string]float64{"level": 2}, series: make(map[string][]float64)}
+			e.addPrices(flatBars(closes...))
+			if err := e.compute(Indicator{Name: "sma", Type: "sma", Length: &Value{Num: 2}}, nil); err != nil {
+				t.Fatal(err)
+			}
+			test, err := e.condition(c, "cond")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for i := range closes {
+				if test(i) {
+					got = append(got, i)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("true on bars %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	def, err := Compile(`
+name: Threshold
+parameters:
+  - {name: level, default: 2}
+entry:
+  long: {crossover: [close, level]}
+  short: {crossunder: [close, level]}
+exit:
+  long: {above: [close, 3.5]}
+filters:
+  - above: [volume, 0]
+plot: [close, level]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := def.Run(flatBars(1, 3, 2, 2, 4, 1, 3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Signal{
+		{Bar: 1, Action: "long", Reason: "Entry Long"},
+		{Bar: 4, Action: "close", Reason: "Exit Long"},
+		{Bar: 4, Action: "long", Reason: "Entry Long"},
+		{Bar: 5, Action: "short", Reason: "Entry Short"},
+		{Bar: 6, Action: "long", Reason: "Entry Long"},
+	}
+	if !slices.Equal(result.Signals, want) {
+		t.Errorf("Signals = %+v, want %+v", result.Signals, want)
+	}
+	if len(result.Plots) != 2 || result.Plots[1].Title != "level" || result.Plots[1].Values[0] != 2 {
+		t.Errorf("Plots = %+v", result.Plots)
+	}
+
+	// A parameter override moves the threshold.
+	result, err = def.Run(flatBars(1, 3, 2, 2, 4, 1, 3), map[string]any{"level": 3.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Signal{{Bar: 4, Action: "long", Reason: "Entry Long"}, {Bar: 5, Action: "short", Reason: "Entry Short"}}
+	if !slices.Equal(result.Signals, want) {
+		t.Errorf("Signals with level 3.5 = %+v, want %+v", result.Signals, want)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"no name", "entry: {long: {above: [close, 1]}}", "definition has no name"},
+		{"no entry", "name: x", "definition has no entry conditions"},
+		{"bad direction", "name: x\nentry: {long: {above: [close, 1]}}\nrisk: {tradeDirection: up}",
+			`tradeDirection must be both, long or short, got "up"`},
+		{"unknown field", "name: x\nentry:\n  long: {abvoe: [close, 1]}",
+			"invalid definition: yaml: unmarshal errors:\n  line 3: field abvoe not found in type rules.Condition"},
+		{"bad yaml", "name: x\nentry: {long: {above: [close, 1]}",
+			"invalid definition: yaml: line 1: did not find expected ',' or '}'"},
+		{"list operand", "name: x\nentry:\n  long: {above: [[1], 2]}", "invalid definition: line 3: expected a number or a name"},
+		{"two kinds", "name: x\nentry: {long: {above: [close, 1], below: [close, 2]}}",
+			"entry.long: a condition needs exactly one of all, any, not, crossover, crossunder, cross, above, below, rising or falling"},
+		{"empty condition", "name: x\nentry: {long: {}}",
+			"entry.long: a condition needs exactly one of all, any, not, crossover, crossunder, cross, above, below, rising or falling"},
+		{"one operand", "name: x\nentry: {long: {crossover: [close]}}", "entry.long.crossover: expected two operands"},
+		{"unknown operand", "name: x\nentry: {short: {crossunder: [close, foo]}}", `entry.short.crossunder: unknown operand "foo"`},
+		{"nested path", "name: x\nentry: {long: {all: [{above: [close, 1]}, {any: [{below: [foo, 1]}]}]}}",
+			`entry.long.all[1].any[0].below: unknown operand "foo"`},
+		{"exit path", "name: x\nentry: {long: {above: [close, 1]}}\nexit: {long: {not: {above: [bar, 1]}}}",
+			`exit.long.not.above: unknown operand "bar"`},
+		{"filter path", "name: x\nentry: {long: {above: [close, 1]}}\nfilters: [{below: [close, 1]}, {above: [vol, 0]}]",
+			`filters[1].above: unknown operand "vol"`},
+		{"bad rising", "name: x\nentry: {long: {rising: [close, n]}}", "entry.long.rising: expected [operand, bars]"},
+		{"unknown type", "name: x\nindicators: [{name: a, type: nope}]\nentry: {long: {above: [close, a]}}", `indicator a: unknown type "nope"`},
+		{"missing length", "name: x\nindicators: [{name: a, type: sma}]\nentry: {long: {above: [close, a]}}", "indicator a: length is required"},
+		{"fractional length", "name: x\nindicators: [{name: a, type: ema, length: 2.5}]\nentry: {long: {above: [close, a]}}",
+			"indicator a: length must be a whole number of at least 1, got 2.5"},
+		{"unknown parameter", "name: x\nindicators: [{name: a, type: ema, length: n}]\nentry: {long: {above: [close, a]}}",
+			`indicator a: length: unknown parameter "n"`},
+		{"unknown source", "name: x\nindicators: [{name: a, type: ema, length: 3, source: b}]\nentry: {long: {above: [close, a]}}",
+			`indicator a: unknown source "b"`},
+		{"duplicate name", "name: x\nindicators: [{name: close, type: ema, length: 3}]\nentry: {long: {above: [close, 1]}}",
+			"indicator close: name is already used"},
+		{"unknown plot", "name: x\nentry: {long: {above: [close, 1]}}\nplot: [fast]", `plot: unknown operand "fast"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Compile error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	tests := []struct {
+		name, src string
+		params    map[string]any
+		want      int
+	}{
+		{"prices only", "name: x\nentry: {long: {above: [close, 1]}}", nil, 1},
+		{"longest indicator", "name: x\nindicators: [{name: fast, type: ema, length: 9}, {name: slow, type: ema, length: 21}]\nentry: {long: {crossover: [fast, slow]}}", nil, 22},
+		{"chain", "name: x\nindicators: [{name: rsi, type: rsi}, {name: smooth, type: sma, length: 5, source: rsi}]\nentry: {long: {above: [smooth, 50]}}", nil, 20},
+		{"macd output", "name: x\nindicators: [{name: m, type: macd}, {name: s, type: ema, length: 3, source: m.signal}]\nentry: {long: {above: [s, 0]}}", nil, 39},
+		{"adx and lookback", "name: x\nindicators: [{name: a, type: adx, length: 10}]\nentry: {long: {rising: [a, 4]}}", nil, 28},
+		{"parameter", "name: x\nparameters: [{name: n, default: 9}]\nindicators: [{name: e, type: ema, length: n}]\nentry: {long: {above: [close, e]}}",
+			map[string]any{"n": 50.0}, 51},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := Compile(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := def.WarmUp(tt.params); err != nil || got != tt.want {
+				t.Errorf("WarmUp = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,566 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"terminal/indicator"
+)
+
+// Bar is one OHLCV candle. Time is the open time in milliseconds.
+type Bar struct {
+	Time                           int64
+	Open, High, Low, Close, Volume float64
+}
+
+// Signal is an entry or exit on a bar. Action is "long", "short" or
+// "close".
+type Signal struct {
+	Bar    int
+	Action string
+	Reason string
+}
+
+// Plot is a series listed under plot; Values are NaN where it is na.
+type Plot struct {
+	Title  string
+	Values []float64
+}
+
+type Result struct {
+	Signals []Signal
+	Plots   []Plot
+}
+
+type indicatorType struct {
+	outputs  []string // the first is the indicator's own value
+	defaults map[string]float64
+}
+
+// indicatorTypes lists the supported indicators, their outputs and the
+// defaults of their settings. A setting without a default is required.
+var indicatorTypes = map[string]indicatorType{
+	"sma":        {outputs: []string{""}},
+	"ema":        {outputs: []string{""}},
+	"rma":        {outputs: []string{""}},
+	"wma":        {outputs: []string{""}},
+	"hma":        {outputs: []string{""}},
+	"stdev":      {outputs: []string{""}},
+	"rsi":        {outputs: []string{""}, defaults: map[string]float64{"length": 14}},
+	"atr":        {outputs: []string{""}, defaults: map[string]float64{"length": 14}},
+	"highest":    {outputs: []string{""}},
+	"lowest":     {outputs: []string{""}},
+	"change":     {outputs: []string{""}, defaults: map[string]float64{"length": 1}},
+	"macd":       {outputs: []string{"macd", "signal", "histogram"}, defaults: map[string]float64{"fast": 12, "slow": 26, "signal": 9}},
+	"bollinger":  {outputs: []string{"middle", "upper", "lower"}, defaults: map[string]float64{"length": 20, "mult": 2}},
+	"keltner":    {outputs: []string{"middle", "upper", "lower"}, defaults: map[string]float64{"length": 20, "mult": 2}},
+	"donchian":   {outputs: []string{"middle", "upper", "lower"}, defaults: map[string]float64{"length": 20}},
+	"supertrend": {outputs: []string{"line", "direction"}, defaults: map[string]float64{"factor": 3, "length": 10}},
+	"adx":        {outputs: []string{"adx", "plus", "minus"}, defaults: map[string]float64{"length": 14, "smoothing": 14}},
+	"vwap":       {outputs: []string{""}},
+	"obv":        {outputs: []string{""}},
+}
+
+var smoothers = map[string]func(src []float64, length int) []float64{
+	"sma":     indicator.SMA,
+	"ema":     indicator.EMA,
+	"rma":     indicator.RMA,
+	"wma":     indicator.WMA,
+	"hma":     indicator.HMA,
+	"stdev":   indicator.Stdev,
+	"rsi":     indicator.RSI,
+	"highest": indicator.Highest,
+	"lowest":  indicator.Lowest,
+	"change":  indicator.Change,
+}
+
+type evaluator struct {
+	n      int
+	params map[string]float64
+	series map[string][]float64
+}
+
+// Run evaluates the definition over bars. params overrides parameter
+// defaults by name.
+func (d *Definition) Run(bars []Bar, params map[string]any) (*Result, error) {
+	e, err := d.evaluator(len(bars), params)
+	if err != nil {
+		return nil, err
+	}
+	e.addPrices(bars)
+	for _, ind := range d.Indicators {
+		if err := e.compute(ind, bars); err != nil {
+			return nil, fmt.Errorf("indicator %s: %w", ind.Name, err)
+		}
+	}
+
+	cond := func(c *Condition, path string) (func(int) bool, error) {
+		if c == nil {
+			return nil, nil
+		}
+		return e.condition(*c, path)
+	}
+	entryLong, err := cond(d.Entry.Long, "entry.long")
+	if err != nil {
+		return nil, err
+	}
+	entryShort, err := cond(d.Entry.Short, "entry.short")
+	if err != nil {
+		return nil, err
+	}
+	exitLong, err := cond(d.Exit.Long, "exit.long")
+	if err != nil {
+		return nil, err
+	}
+	exitShort, err := cond(d.Exit.Short, "exit.short")
+	if err != nil {
+		return nil, err
+	}
+	filters, err := e.combine(d.Filters, "filters", false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Signals: []Signal{}, Plots: []Plot{}}
+	for _, v := range d.Plot {
+		values, err := e.operand(v)
+		if err != nil {
+			return nil, fmt.Errorf("plot: %w", err)
+		}
+		result.Plots = append(result.Plots, Plot{Title: v.String(), Values: values})
+	}
+
+	// position is 1 long, -1 short, 0 flat. Entries in the direction already
+	// held are ignored and opposite entries reverse.
+	position := 0
+	for i := 0; i < e.n; i++ {
+		if position == 1 && exitLong != nil && exitLong(i) {
+			result.Signals = append(result.Signals, Signal{Bar: i, Action: "close", Reason: "Exit Long"})
+			position = 0
+		}
+		if position == -1 && exitShort != nil && exitShort(i) {
+			result.Signals = append(result.Signals, Signal{Bar: i, Action: "close", Reason: "Exit Short"})
+			position = 0
+		}
+		long := entryLong != nil && entryLong(i)
+		short := entryShort != nil && entryShort(i)
+		if long == short || !filters(i) {
+			continue
+		}
+		if long && position != 1 {
+			result.Signals = append(result.Signals, Signal{Bar: i, Action: "long", Reason: "Entry Long"})
+			position = 1
+		}
+		if short && position != -1 {
+			result.Signals = append(result.Signals, Signal{Bar: i, Action: "short", Reason: "Entry Short"})
+			position = -1
+		}
+	}
+	return result, nil
+}
+
+// evaluator resolves the definition's parameters, overridden by params,
+// for a run over n bars.
+func (d *Definition) evaluator(n int, params map[string]any) (*evaluator, error) {
+	e := &evaluator{n: n, params: make(map[string]float64), series: make(map[string][]float64)}
+	for _, p := range d.Parameters {
+		if p.Name == "" {
+			return nil, fmt.Errorf("parameter without a name")
+		}
+		e.params[p.Name] = p.Default
+		switch v := params[p.Name].(type) {
+		case float64:
+			e.params[p.Name] = v
+		case int:
+			e.params[p.Name] = float64(v)
+		}
+	}
+	return e, nil
+}
+
+// WarmUp is the number of bars the definition needs before its signals are
+// valid: the longest chain of indicator lengths, where an indicator of an
+// indicator adds up both, plus the bars its conditions look back.
+func (d *Definition) WarmUp(params map[string]any) (int, error) {
+	e, err := d.evaluator(0, params)
+	if err != nil {
+		return 0, err
+	}
+	warm := make(map[string]int)
+	longest := 0
+	for _, ind := range d.Indicators {
+		t, ok := indicatorTypes[ind.Type]
+		if !ok {
+			return 0, fmt.Errorf("indicator %s: unknown type %q", ind.Name, ind.Type)
+		}
+		var bars int
+		switch ind.Type {
+		case "vwap", "obv":
+			bars = 1
+		case "macd":
+			slow, err := e.length(t, "slow", ind.Slow)
+			if err != nil {
+				return 0, fmt.Errorf("indicator %s: %w", ind.Name, err)
+			}
+			signal, err := e.length(t, "signal", ind.Signal)
+			if err != nil {
+				return 0, fmt.Errorf("indicator %s: %w", ind.Name, err)
+			}
+			bars = slow + signal
+		default:
+			if bars, err = e.length(t, "length", ind.Length); err != nil {
+				return 0, fmt.Errorf("indicator %s: %w", ind.Name, err)
+			}
+			if ind.Type == "adx" {
+				smoothing, err := e.length(t, "smoothing", ind.Smoothing)
+				if err != nil {
+					return 0, fmt.Errorf("indicator %s: %w", ind.Name, err)
+				}
+				bars += smoothing
+			}
+		}
+		source, _, _ := strings.Cut(ind.Source, ".")
+		warm[ind.Name] = bars + warm[source]
+		longest = max(longest, warm[ind.Name])
+	}
+
+	lookback := 1
+	var walk func(c *Condition)
+	walk = func(c *Condition) {
+		if c == nil {
+			return
+		}
+		for _, operands := range [][]Value{c.Rising, c.Falling} {
+			if len(operands) == 2 {
+				lookback = max(lookback, int(operands[1].Num))
+			}
+		}
+		for i := range c.All {
+			walk(&c.All[i])
+		}
+		for i := range c.Any {
+			walk(&c.Any[i])
+		}
+		walk(c.Not)
+	}
+	for _, c := range []*Condition{d.Entry.Long, d.Entry.Short, d.Exit.Long, d.Exit.Short} {
+		walk(c)
+	}
+	for i := range d.Filters {
+		walk(&d.Filters[i])
+	}
+	return longest + lookback, nil
+}
+
+func (e *evaluator) addPrices(bars []Bar) {
+	for _, name := range []string{"open", "high", "low", "close", "volume", "hl2", "hlc3", "ohlc4"} {
+		e.series[name] = make([]float64, e.n)
+	}
+	for i, b := range bars {
+		e.series["open"][i] = b.Open
+		e.series["high"][i] = b.High
+		e.series["low"][i] = b.Low
+		e.series["close"][i] = b.Close
+		e.series["volume"][i] = b.Volume
+		e.series["hl2"][i] = (b.High + b.Low) / 2
+		e.series["hlc3"][i] = (b.High + b.Low + b.Close) / 3
+		e.series["ohlc4"][i] = (b.Open + b.High + b.Low + b.Close) / 4
+	}
+}
+
+// setting resolves an indicator setting, falling back to the type's
+// default.
+func (e *evaluator) setting(t indicatorType, name string, v *Value) (float64, error) {
+	if v == nil {
+		def, ok := t.defaults[name]
+		if !ok {
+			return 0, fmt.Errorf("%s is required", name)
+		}
+		return def, nil
+	}
+	if v.Ref == "" {
+		return v.Num, nil
+	}
+	p, ok := e.params[v.Ref]
+	if !ok {
+		return 0, fmt.Errorf("%s: unknown parameter %q", name, v.Ref)
+	}
+	return p, nil
+}
+
+func (e *evaluator) length(t indicatorType, name string, v *Value) (int, error) {
+	f, err := e.setting(t, name, v)
+	if err != nil {
+		return 0, err
+	}
+	if f < 1 || f != math.Trunc(f) {
+		return 0, fmt.Errorf("%s must be a whole number of at least 1, got %v", name, f)
+	}
+	return int(f), nil
+}
+
+func (e *evaluator) compute(ind Indicator, bars []Bar) error {
+	t, ok := indicatorTypes[ind.Type]
+	if !ok {
+		return fmt.Errorf("unknown type %q", ind.Type)
+	}
+	if ind.Name == "" || strings.Contains(ind.Name, ".") {
+		return fmt.Errorf("name must be set and must not contain a dot")
+	}
+	if _, taken := e.series[ind.Name]; taken {
+		return fmt.Errorf("name is already used")
+	}
+	if _, taken := e.params[ind.Name]; taken {
+		return fmt.Errorf("name is already used by a parameter")
+	}
+
+	sourceName := ind.Source
+	switch {
+	case sourceName != "":
+	case ind.Type == "highest":
+		sourceName = "high"
+	case ind.Type == "lowest":
+		sourceName = "low"
+	case ind.Type == "vwap":
+		sourceName = "hlc3"
+	default:
+		sourceName = "close"
+	}
+	src, ok := e.series[sourceName]
+	if !ok {
+		return fmt.Errorf("unknown source %q", sourceName)
+	}
+	high, low, closes := e.series["high"], e.series["low"], e.series["close"]
+
+	var outputs [][]float64
+	switch ind.Type {
+	case "atr":
+		length, err := e.length(t, "length", ind.Length)
+		if err != nil {
+			return err
+		}
+		outputs = [][]float64{indicator.ATR(high, low, closes, length)}
+	case "macd":
+		fast, err := e.length(t, "fast", ind.Fast)
+		if err != nil {
+			return err
+		}
+		slow, err := e.length(t, "slow", ind.Slow)
+		if err != nil {
+			return err
+		}
+		signal, err := e.length(t, "signal", ind.Signal)
+		if err != nil {
+			return err
+		}
+		macd, signalLine, histogram := indicator.MACD(src, fast, slow, signal)
+		outputs = [][]float64{macd, signalLine, histogram}
+	case "bollinger", "keltner", "donchian":
+		length, err := e.length(t, "length", ind.Length)
+		if err != nil {
+			return err
+		}
+		var middle, upper, lower []float64
+		switch ind.Type {
+		case "donchian":
+			upper, lower, middle = indicator.Donchian(high, low, length)
+		default:
+			mult, err := e.setting(t, "mult", ind.Mult)
+			if err != nil {
+				return err
+			}
+			if ind.Type == "bollinger" {
+				middle, upper, lower = indicator.Bollinger(src, length, mult)
+			} else {
+				middle, upper, lower = indicator.Keltner(src, high, low, length, mult)
+			}
+		}
+		outputs = [][]float64{middle, upper, lower}
+	case "supertrend":
+		factor, err := e.setting(t, "factor", ind.Factor)
+		if err != nil {
+			return err
+		}
+		length, err := e.length(t, "length", ind.Length)
+		if err != nil {
+			return err
+		}
+		line, direction := indicator.Supertrend(high, low, closes, factor, length)
+		dir := make([]float64, len(direction))
+		for i, d := range direction {
+			dir[i] = float64(d)
+		}
+		outputs = [][]float64{line, dir}
+	case "adx":
+		length, err := e.length(t, "length", ind.Length)
+		if err != nil {
+			return err
+		}
+		smoothing, err := e.length(t, "smoothing", ind.Smoothing)
+		if err != nil {
+			return err
+		}
+		plus, minus, adx := indicator.ADX(high, low, closes, length, smoothing)
+		outputs = [][]float64{adx, plus, minus}
+	case "vwap":
+		times := make([]int64, len(bars))
+		for i, b := range bars {
+			times[i] = b.Time
+		}
+		outputs = [][]float64{indicator.VWAP(src, e.series["volume"], times)}
+	case "obv":
+		outputs = [][]float64{indicator.OBV(src, e.series["volume"])}
+	default:
+		length, err := e.length(t, "length", ind.Length)
+		if err != nil {
+			return err
+		}
+		outputs = [][]float64{smoothers[ind.Type](src, length)}
+	}
+
+	e.series[ind.Name] = outputs[0]
+	for i, name := range t.outputs {
+		if name != "" {
+			e.series[ind.Name+"."+name] = outputs[i]
+		}
+	}
+	return nil
+}
+
+// operand resolves v to a series: a constant, a parameter, a price or an
+// indicator output.
+func (e *evaluator) operand(v Value) ([]float64, error) {
+	constant := v.Num
+	if v.Ref != "" {
+		if s, ok := e.series[v.Ref]; ok {
+			return s, nil
+		}
+		p, ok := e.params[v.Ref]
+		if !ok {
+			return nil, fmt.Errorf("unknown operand %q", v.Ref)
+		}
+		constant = p
+	}
+	s := make([]float64, e.n)
+	for i := range s {
+		s[i] = constant
+	}
+	return s, nil
+}
+
+func (e *evaluator) condition(c Condition, path string) (func(int) bool, error) {
+	type comparison struct {
+		name     string
+		operands []Value
+		test     func(a, b []float64, i int) bool
+	}
+	comparisons := []comparison{
+		{"crossover", c.CrossOver, func(a, b []float64, i int) bool {
+			return i > 0 && a[i] > b[i] && a[i-1] <= b[i-1]
+		}},
+		{"crossunder", c.CrossUnder, func(a, b []float64, i int) bool {
+			return i > 0 && a[i] < b[i] && a[i-1] >= b[i-1]
+		}},
+		{"cross", c.Cross, func(a, b []float64, i int) bool {
+			return i > 0 && ((a[i] > b[i] && a[i-1] <= b[i-1]) || (a[i] < b[i] && a[i-1] >= b[i-1]))
+		}},
+		{"above", c.Above, func(a, b []float64, i int) bool { return a[i] > b[i] }},
+		{"below", c.Below, func(a, b []float64, i int) bool { return a[i] < b[i] }},
+	}
+
+	set := 0
+	for _, isSet := range []bool{c.All != nil, c.Any != nil, c.Not != nil, c.Rising != nil, c.Falling != nil} {
+		if isSet {
+			set++
+		}
+	}
+	for _, cmp := range comparisons {
+		if cmp.operands != nil {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("%s: a condition needs exactly one of all, any, not, crossover, crossunder, cross, above, below, rising or falling", path)
+	}
+
+	switch {
+	case c.All != nil:
+		return e.combine(c.All, path+".all", false)
+	case c.Any != nil:
+		return e.combine(c.Any, path+".any", true)
+
+	case c.Not != nil:
+		test, err := e.condition(*c.Not, path+".not")
+		if err != nil {
+			return nil, err
+		}
+		return func(i int) bool { return !test(i) }, nil
+
+	case c.Rising != nil || c.Falling != nil:
+		operands, name := c.Rising, "rising"
+		if c.Falling != nil {
+			operands, name = c.Falling, "falling"
+		}
+		if len(operands) != 2 || operands[1].Ref != "" || operands[1].Num < 1 {
+			return nil, fmt.Errorf("%s.%s: expected [operand, bars]", path, name)
+		}
+		s, err := e.operand(operands[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", path, name, err)
+		}
+		bars, rising := int(operands[1].Num), c.Rising != nil
+		return func(i int) bool {
+			if i < bars {
+				return false
+			}
+			for j := i - bars; j < i; j++ {
+				if (rising && !(s[j+1] > s[j])) || (!rising && !(s[j+1] < s[j])) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+
+	for _, cmp := range comparisons {
+		if cmp.operands == nil {
+			continue
+		}
+		if len(cmp.operands) != 2 {
+			return nil, fmt.Errorf("%s.%s: expected two operands", path, cmp.name)
+		}
+		a, err := e.operand(cmp.operands[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", path, cmp.name, err)
+		}
+		b, err := e.operand(cmp.operands[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", path, cmp.name, err)
+		}
+		test := cmp.test
+		return func(i int) bool { return test(a, b, i) }, nil
+	}
+	return nil, fmt.Errorf("%s: empty condition", path)
+}
+
+// combine is true when all conditions are, or with any when one of them
+// is. No conditions are all true.
+func (e *evaluator) combine(conds []Condition, path string, any bool) (func(int) bool, error) {
+	tests := make([]func(int) bool, len(conds))
+	for i, c := range conds {
+		test, err := e.condition(c, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		tests[i] = test
+	}
+	return func(i int) bool {
+		for _, test := range tests {
+			if test(i) == any {
+				return any
+			}
+		}
+		return !any
+	}, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import "math"
+
+// ScalingConfig adds to an open position. In "dca" mode a safety order is
+// added every StepPercent the price moves against the first entry; in
+// "pyramid" mode a repeated signal in the position's direction adds. Either
+// adds at most Orders times, the nth add being SizeMultiplier^n times the
+// position size. While scaling is on, the whole position is taken off at
+// TakeProfitPercent from its average entry.
+type ScalingConfig struct {
+	Mode           string // "off", "dca" or "pyramid"
+	Orders         int
+	StepPercent    float64
+	SizeMultiplier float64
+}
+
+func buildScalingConfig(params map[string]any) ScalingConfig {
+	config := ScalingConfig{Mode: "off", Orders: 3, StepPercent: 2, SizeMultiplier: 1}
+	if mode, ok := params["scaleMode"].(string); ok {
+		config.Mode = mode
+	}
+	if orders, ok := params["scaleOrders"].(float64); ok {
+		config.Orders = int(orders)
+	}
+	if step, ok := params["scaleStepPercent"].(float64); ok && step > 0 {
+		config.StepPercent = step
+	}
+	if multiplier, ok := params["scaleSizeMultiplier"].(float64); ok && multiplier > 0 {
+		config.SizeMultiplier = multiplier
+	}
+	return config
+}
+
+func (c ScalingConfig) enabled() bool {
+	return c.Mode == "dca" || c.Mode == "pyramid"
+}
+
+// canAdd reports whether position may take another add in mode.
+func (c ScalingConfig) canAdd(mode string, position *Position) bool {
+	return c.Mode == mode && position.adds() < c.Orders
+}
+
+// addSize is the size of position's next add for an entry size of base.
+func (c ScalingConfig) addSize(base float64, position *Position) float64 {
+	return base * math.Pow(c.SizeMultiplier, float64(position.adds()+1))
+}
+
+// safetyPrice is the price of position's next DCA safety order.
+func (c ScalingConfig) safetyPrice(position *Position) float64 {
+	base := position.BasePrice
+	if base == 0 {
+		base = position.EntryPrice
+	}
+	step := float64(position.adds()+1) * c.StepPercent / 100
+	if position.Side == "long" {
+		return base * (1 - step)
+	}
+	return base * (1 + step)
+}
+
+// takeProfitPrice is the combined take-profit of a scaled position.
+func (c StrategyConfig) takeProfitPrice(position *Position) float64 {
+	if position.Side == "long" {
+		return position.EntryPrice * (1 + c.TakeProfitPercent/100)
+	}
+	return position.EntryPrice * (1 - c.TakeProfitPercent/100)
+}
+
+// adds is the number of fills after the first.
+func (p *Position) adds() int {
+	return max(p.Entries-1, 0)
+}
+
+// addEntry averages a fill into the position.
+func (p *Position) addEntry(price, size, fee float64) {
+	if p.Entries == 0 {
+		p.Entries = 1
+	}
+	if p.BasePrice == 0 {
+		p.BasePrice = p.EntryPrice
+	}
+	p.EntryPrice = (p.EntryPrice*p.Size + price*size) / (p.Size + size)
+	p.Size += size
+	p.Fees += fee
+	p.Entries++
+}
+
+// beyond reports whether price has reached level moving against a position
+// on side when adverse is set, and in its favor otherwise.
+func beyond(side string, price, level float64, adverse bool) bool {
+	if (side == "long") == adverse {
+		return price <= level
+	}
+	return price >= level
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+)
+
+// ohlcCandles returns hourly candles with the given open, high, low and
+// close prices.
+func ohlcCandles(bars ...[4]float64) hyperliquid.Candles {
+	format := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	candles := make(hyperliquid.Candles, len(bars))
+	for i, bar := range bars {
+		candles[i] = hyperliquid.Candle{
+			Time:      int64(i) * 3_600_000,
+			Timestamp: int64(i+1)*3_600_000 - 1,
+			Open:      format(bar[0]),
+			High:      format(bar[1]),
+			Low:       format(bar[2]),
+			Close:     format(bar[3]),
+			Volume:    "1",
+		}
+	}
+	return candles
+}
+
+// flatCandles returns candles that open, trade and close at each price.
+func flatCandles(prices ...float64) hyperliquid.Candles {
+	bars := make([][4]float64, len(prices))
+	for i, price := range prices {
+		bars[i] = [4]float64{price, price, price, price}
+	}
+	return ohlcCandles(bars...)
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBacktestDCA(t *testing.T) {
+	config := StrategyConfig{
+		PositionSize:      1,
+		TakeProfitPercent: 2,
+		Scaling:           ScalingConfig{Mode: "dca", Orders: 2, StepPercent: 5, SizeMultiplier: 2},
+	}
+	candles := ohlcCandles(
+		[4]float64{100, 100, 100, 100},
+		// Down through the first safety order at 95, then the second at 90.
+		[4]float64{100, 100, 94, 95},
+		[4]float64{95, 95, 89, 90},
+		// Up through the take-profit 2% above the average entry.
+		[4]float64{90, 96, 90, 96},
+		[4]float64{96, 96, 96, 96},
+	)
+	positions := backtestSignals(config, candles, []Signal{{Index: 0, Type: SignalLong}}, nil)
+	if len(positions) != 1 {
+		t.Fatalf("%d positions, want 1", len(positions))
+	}
+	position := positions[0]
+	// 1 at 100, 2 at 95 and 4 at 90.
+	average := (100 + 2*95 + 4*90) / 7.0
+	if position.Entries != 3 || position.Size != 7 || position.BasePrice != 100 || !approx(position.EntryPrice, average) {
+		t.Errorf("entries %d, size %v, base %v, entry %v, want 3, 7, 100, %v",
+			position.Entries, position.Size, position.BasePrice, position.EntryPrice, average)
+	}
+	if position.ExitReason != "Take Profit" || position.ExitIndex != 3 || !approx(position.ExitPrice, average*1.02) {
+		t.Errorf("exit %q at %d, %v, want the take-profit at 3, %v", position.ExitReason, position.ExitIndex, position.ExitPrice, average*1.02)
+	}
+}
+
+func TestBacktestPyramid(t *testing.T) {
+	config := StrategyConfig{
+		PositionSize:      1,
+		TakeProfitPercent: 5,
+		Scaling:           ScalingConfig{Mode: "pyramid", Orders: 2, StepPercent: 2, SizeMultiplier: 1},
+	}
+	candles := ohlcCandles(
+		[4]float64{100, 100, 100, 100},
+		[4]float64{102, 102, 102, 102},
+		[4]float64{104, 104, 104, 104},
+		[4]float64{106, 106, 106, 106},
+		[4]float64{106, 108, 106, 108},
+	)
+	var signals []Signal
+	for i := range 4 {
+		signals = append(signals, Signal{Index: i, Type: SignalLong})
+	}
+	positions := backtestSignals(config, candles, signals, nil)
+	if len(positions) != 1 {
+		t.Fatalf("%d positions, want 1", len(positions))
+	}
+	// The fourth signal is past the two adds.
+	position := positions[0]
+	if position.Entries != 3 || position.Size != 3 || position.BasePrice != 100 || !approx(position.EntryPrice, 102) {
+		t.Errorf("entries %d, size %v, base %v, entry %v, want 3, 3, 100, 102",
+			position.Entries, position.Size, position.BasePrice, position.EntryPrice)
+	}
+	if position.ExitReason != "Take Profit" || position.ExitIndex != 4 || !approx(position.ExitPrice, 102*1.05) {
+		t.Errorf("exit %q at %d, %v, want the take-profit at 4, %v", position.ExitReason, position.ExitIndex, position.ExitPrice, 102*1.05)
+	}
+}
+
+func TestLiveScaling(t *testing.T) {
+	fake := newFakeExchange(t, "BTC")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	strategy := newFlipStrategy(fake.account(ctx), "BTC")
+	strategy.Config.PositionSize = 0.2
+	strategy.Config.TakeProfitPercent = 2
+	strategy.Config.Execution.TakerFeePercent = 0
+
+	t.Run("pyramid", func(t *testing.T) {
+		strategy.Config.Scaling = ScalingConfig{Mode: "pyramid", Orders: 1, SizeMultiplier: 2}
+		candle := flatCandles(fakeMid)[0]
+		for range 3 {
+			strategy.HandleSignal(Signal{Type: SignalLong}, candle)
+		}
+		position := strategy.Position
+		if position.Entries != 2 || !approx(position.Size, 0.6) || fake.position("BTC") != 0.6 {
+			t.Errorf("entries %d, size %v, exchange %v, want 2, 0.6, 0.6", position.Entries, position.Size, fake.position("BTC"))
+		}
+		strategy.ClosePosition("done")
+	})
+
+	t.Run("dca", func(t *testing.T) {
+		strategy.Config.Scaling = ScalingConfig{Mode: "dca", Orders: 2, StepPercent: 2, SizeMultiplier: 1}
+		// A long opened at 104: the mid of 100 is past the first safety order
+		// at 101.92 but not the second at 99.84.
+		fake.positions["BTC"] = 0.2
+		strategy.Position = &Position{Side: "long", Size: 0.2, EntryPrice: 104, BasePrice: 104, Entries: 1, IsOpen: true}
+		for range 2 {
+			strategy.managePosition(flatCandles(fakeMid))
+		}
+		position := strategy.Position
+		if position.Entries != 2 || !approx(position.Size, 0.4) || position.BasePrice != 104 || !approx(position.EntryPrice, 102) {
+			t.Errorf("entries %d, size %v, base %v, entry %v, want 2, 0.4, 104, 102",
+				position.Entries, position.Size, position.BasePrice, position.EntryPrice)
+		}
+		if fake.position("BTC") != 0.4 {
+			t.Errorf("exchange position %v, want 0.4", fake.position("BTC"))
+		}
+
+		// The take-profit is 2% above the average entry, at 104.04.
+		if !strategy.managePosition(flatCandles(105)) || position.IsOpen || position.ExitReason != "Take Profit" {
+			t.Errorf("open %v, reason %q, want the take-profit", position.IsOpen, position.ExitReason)
+		}
+		if fake.position("BTC") != 0 {
+			t.Errorf("exchange position %v, want flat", fake.position("BTC"))
+		}
+	})
+}
@@ -0,0 +1,489 @@
+package script
+
+import (
+	"fmt"
+	"math"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"terminal/indicator"
+)
+
+// run is the state of one Run, reached by the builtins through the thread.
+type run struct {
+	n      int
+	result *Result
+}
+
+const runKey = "run"
+
+func runOf(thread *starlark.Thread) (*run, error) {
+	r, _ := thread.Local(runKey).(*run)
+	if r == nil {
+		return nil, fmt.Errorf("only available while generate runs")
+	}
+	return r, nil
+}
+
+func barsValue(bars []Bar) starlark.Value {
+	open := make([]float64, len(bars))
+	high := make([]float64, len(bars))
+	low := make([]float64, len(bars))
+	closes := make([]float64, len(bars))
+	volume := make([]float64, len(bars))
+	times := make(starlark.Tuple, len(bars))
+	for i, bar := range bars {
+		open[i], high[i], low[i], closes[i], volume[i] = bar.Open, bar.High, bar.Low, bar.Close, bar.Volume
+		times[i] = starlark.MakeInt64(bar.Time)
+	}
+	return starlarkstruct.FromStringDict(starlark.String("bars"), starlark.StringDict{
+		"open":   seriesValue(open),
+		"high":   seriesValue(high),
+		"low":    seriesValue(low),
+		"close":  seriesValue(closes),
+		"volume": seriesValue(volume),
+		"time":   times,
+	})
+}
+
+func seriesValue(values []float64) starlark.Tuple {
+	t := make(starlark.Tuple, len(values))
+	for i, v := range values {
+		t[i] = starlark.Float(v)
+	}
+	return t
+}
+
+// series converts a sequence of numbers, with None as NaN, to a series.
+func series(name string, v starlark.Value) ([]float64, error) {
+	seq, ok := v.(starlark.Indexable)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a series, got %s", name, v.Type())
+	}
+	out := make([]float64, seq.Len())
+	for i := range out {
+		x := seq.Index(i)
+		if x == starlark.None {
+			out[i] = math.NaN()
+			continue
+		}
+		f, ok := starlark.AsFloat(x)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] is %s, not a number", name, i, x.Type())
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// operand is a series, or a number repeated n times.
+func operand(name string, v starlark.Value, n int) ([]float64, error) {
+	if f, ok := starlark.AsFloat(v); ok {
+		out := make([]float64, n)
+		for i := range out {
+			out[i] = f
+		}
+		return out, nil
+	}
+	return series(name, v)
+}
+
+func number(name string, v starlark.Value) (float64, error) {
+	f, ok := starlark.AsFloat(v)
+	if !ok {
+		return 0, fmt.Errorf("%s must be a number, got %s", name, v.Type())
+	}
+	return f, nil
+}
+
+func length(name string, v starlark.Value) (int, error) {
+	f, err := number(name, v)
+	if err != nil {
+		return 0, err
+	}
+	if f < 1 {
+		return 0, fmt.Errorf("%s must be at least 1", name)
+	}
+	return int(f), nil
+}
+
+// smoother wraps an indicator of a source and a length as ta.<name>(src,
+// length).
+func smoother(name string, f func(src []float64, length int) []float64) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var srcArg, lengthArg starlark.Value
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "src", &srcArg, "length", &lengthArg); err != nil {
+			return nil, err
+		}
+		src, err := series("src", srcArg)
+		if err != nil {
+			return nil, err
+		}
+		n, err := length("length", lengthArg)
+		if err != nil {
+			return nil, err
+		}
+		return seriesValue(f(src, n)), nil
+	})
+}
+
+// hlc unpacks the high, low and close series that lead the arguments of
+// the range-based indicators.
+func hlc(highArg, lowArg, closeArg starlark.Value) (high, low, closes []float64, err error) {
+	if high, err = series("high", highArg); err != nil {
+		return
+	}
+	if low, err = series("low", lowArg); err != nil {
+		return
+	}
+	closes, err = series("close", closeArg)
+	return
+}
+
+func taATR(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var highArg, lowArg, closeArg starlark.Value
+	var lengthArg starlark.Value = starlark.MakeInt(14)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "high", &highArg, "low", &lowArg, "close", &closeArg, "length?", &lengthArg); err != nil {
+		return nil, err
+	}
+	high, low, closes, err := hlc(highArg, lowArg, closeArg)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length("length", lengthArg)
+	if err != nil {
+		return nil, err
+	}
+	return seriesValue(indicator.ATR(high, low, closes, n)), nil
+}
+
+func taMACD(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var srcArg starlark.Value
+	var fastArg, slowArg, signalArg starlark.Value = starlark.MakeInt(12), starlark.MakeInt(26), starlark.MakeInt(9)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "src", &srcArg, "fast?", &fastArg, "slow?", &slowArg, "signal?", &signalArg); err != nil {
+		return nil, err
+	}
+	src, err := series("src", srcArg)
+	if err != nil {
+		return nil, err
+	}
+	fast, err := length("fast", fastArg)
+	if err != nil {
+		return nil, err
+	}
+	slow, err := length("slow", slowArg)
+	if err != nil {
+		return nil, err
+	}
+	signal, err := length("signal", signalArg)
+	if err != nil {
+		return nil, err
+	}
+	macd, signalLine, histogram := indicator.MACD(src, fast, slow, signal)
+	return starlark.Tuple{seriesValue(macd), seriesValue(signalLine), seriesValue(histogram)}, nil
+}
+
+func taBollinger(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var srcArg starlark.Value
+	var lengthArg, multArg starlark.Value = starlark.MakeInt(20), starlark.MakeInt(2)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "src", &srcArg, "length?", &lengthArg, "mult?", &multArg); err != nil {
+		return nil, err
+	}
+	src, err := series("src", srcArg)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length("length", lengthArg)
+	if err != nil {
+		return nil, err
+	}
+	mult, err := number("mult", multArg)
+	if err != nil {
+		return nil, err
+	}
+	middle, upper, lower := indicator.Bollinger(src, n, mult)
+	return starlark.Tuple{seriesValue(middle), seriesValue(upper), seriesValue(lower)}, nil
+}
+
+func taKeltner(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var highArg, lowArg, closeArg starlark.Value
+	var lengthArg, multArg starlark.Value = starlark.MakeInt(20), starlark.MakeInt(2)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "high", &highArg, "low", &lowArg, "close", &closeArg, "length?", &lengthArg, "mult?", &multArg); err != nil {
+		return nil, err
+	}
+	high, low, closes, err := hlc(highArg, lowArg, closeArg)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length("length", lengthArg)
+	if err != nil {
+		return nil, err
+	}
+	mult, err := number("mult", multArg)
+	if err != nil {
+		return nil, err
+	}
+	middle, upper, lower := indicator.Keltner(closes, high, low, n, mult)
+	return starlark.Tuple{seriesValue(middle), seriesValue(upper), seriesValue(lower)}, nil
+}
+
+func taDonchian(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var highArg, lowArg starlark.Value
+	var lengthArg starlark.Value = starlark.MakeInt(20)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "high", &highArg, "low", &lowArg, "length?", &lengthArg); err != nil {
+		return nil, err
+	}
+	high, err := series("high", highArg)
+	if err != nil {
+		return nil, err
+	}
+	low, err := series("low", lowArg)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length("length", lengthArg)
+	if err != nil {
+		return nil, err
+	}
+	upper, lower, middle := indicator.Donchian(high, low, n)
+	return starlark.Tuple{seriesValue(middle), seriesValue(upper), seriesValue(lower)}, nil
+}
+
+func taSupertrend(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var highArg, lowArg, closeArg starlark.Value
+	var factorArg, lengthArg starlark.Value = starlark.MakeInt(3), starlark.MakeInt(10)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "high", &highArg, "low", &lowArg, "close", &closeArg, "factor?", &factorArg, "length?", &lengthArg); err != nil {
+		return nil, err
+	}
+	high, low, closes, err := hlc(highArg, lowArg, closeArg)
+	if err != nil {
+		return nil, err
+	}
+	factor, err := number("factor", factorArg)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length("length", lengthArg)
+	if err != nil {
+		return nil, err
+	}
+	line, direction := indicator.Supertrend(high, low, closes, factor, n)
+	dir := make(starlark.Tuple, len(direction))
+	for i, d := range direction {
+		dir[i] = starlark.MakeInt(d)
+	}
+	return starlark.Tuple{seriesValue(line), dir}, nil
+}
+
+func taADX(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var highArg, lowArg, closeArg starlark.Value
+	var lengthArg, smoothingArg starlark.Value = starlark.MakeInt(14), starlark.MakeInt(14)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "high", &highArg, "low", &lowArg, "close", &closeArg, "length?", &lengthArg, "smoothing?", &smoothingArg); err != nil {
+		return nil, err
+	}
+	high, low, closes, err := hlc(highArg, lowArg, closeArg)
+	if err != nil {
+		return nil, err
+	}
+	n, err := length("length", lengthArg)
+	if err != nil {
+		return nil, err
+	}
+	smoothing, err := length("smoothing", smoothingArg)
+	if err != nil {
+		return nil, err
+	}
+	plus, minus, adx := indicator.ADX(high, low, closes, n, smoothing)
+	return starlark.Tuple{seriesValue(adx), seriesValue(plus), seriesValue(minus)}, nil
+}
+
+func taVWAP(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var srcArg, volumeArg, timeArg starlark.Value
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "src", &srcArg, "volume", &volumeArg, "time", &timeArg); err != nil {
+		return nil, err
+	}
+	src, err := series("src", srcArg)
+	if err != nil {
+		return nil, err
+	}
+	volume, err := series("volume", volumeArg)
+	if err != nil {
+		return nil, err
+	}
+	t, err := series("time", timeArg)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]int64, len(t))
+	for i, v := range t {
+		times[i] = int64(v)
+	}
+	return seriesValue(indicator.VWAP(src, volume, times)), nil
+}
+
+func taOBV(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var closeArg, volumeArg starlark.Value
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "close", &closeArg, "volume", &volumeArg); err != nil {
+		return nil, err
+	}
+	closes, err := series("close", closeArg)
+	if err != nil {
+		return nil, err
+	}
+	volume, err := series("volume", volumeArg)
+	if err != nil {
+		return nil, err
+	}
+	return seriesValue(indicator.OBV(closes, volume)), nil
+}
+
+// crossing wraps a comparison of two operands on consecutive bars as
+// ta.<name>(a, b), a series of bools.
+func crossing(name string, crossed func(prevA, prevB, a, b float64) bool) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var aArg, bArg starlark.Value
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "a", &aArg, "b", &bArg); err != nil {
+			return nil, err
+		}
+		n := 0
+		for _, v := range []starlark.Value{aArg, bArg} {
+			if seq, ok := v.(starlark.Indexable); ok {
+				n = max(n, seq.Len())
+			}
+		}
+		a, err := operand("a", aArg, n)
+		if err != nil {
+			return nil, err
+		}
+		b, err := operand("b", bArg, n)
+		if err != nil {
+			return nil, err
+		}
+		out := make(starlark.Tuple, n)
+		for i := range out {
+			out[i] = starlark.Bool(i > 0 && i < len(a) && i < len(b) && crossed(a[i-1], b[i-1], a[i], b[i]))
+		}
+		return out, nil
+	})
+}
+
+func crossedOver(prevA, prevB, a, b float64) bool  { return prevA <= prevB && a > b }
+func crossedUnder(prevA, prevB, a, b float64) bool { return prevA >= prevB && a < b }
+
+var taModule = &starlarkstruct.Module{
+	Name: "ta",
+	Members: starlark.StringDict{
+		"sma":        smoother("sma", indicator.SMA),
+		"ema":        smoother("ema", indicator.EMA),
+		"rma":        smoother("rma", indicator.RMA),
+		"wma":        smoother("wma", indicator.WMA),
+		"hma":        smoother("hma", indicator.HMA),
+		"stdev":      smoother("stdev", indicator.Stdev),
+		"rsi":        smoother("rsi", indicator.RSI),
+		"highest":    smoother("highest", indicator.Highest),
+		"lowest":     smoother("lowest", indicator.Lowest),
+		"change":     smoother("change", indicator.Change),
+		"atr":        starlark.NewBuiltin("atr", taATR),
+		"macd":       starlark.NewBuiltin("macd", taMACD),
+		"bollinger":  starlark.NewBuiltin("bollinger", taBollinger),
+		"keltner":    starlark.NewBuiltin("keltner", taKeltner),
+		"donchian":   starlark.NewBuiltin("donchian", taDonchian),
+		"supertrend": starlark.NewBuiltin("supertrend", taSupertrend),
+		"adx":        starlark.NewBuiltin("adx", taADX),
+		"vwap":       starlark.NewBuiltin("vwap", taVWAP),
+		"obv":        starlark.NewBuiltin("obv", taOBV),
+		"crossover":  crossing("crossover", crossedOver),
+		"crossunder": crossing("crossunder", crossedUnder),
+		"cross": crossing("cross", func(prevA, prevB, a, b float64) bool {
+			return crossedOver(prevA, prevB, a, b) || crossedUnder(prevA, prevB, a, b)
+		}),
+	},
+}
+
+// signal wraps strategy.<action>(bar, reason=""), which places a signal on
+// a bar.
+func signal(action string) *starlark.Builtin {
+	return starlark.NewBuiltin(action, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var bar int
+		var reason string
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "bar", &bar, "reason?", &reason); err != nil {
+			return nil, err
+		}
+		r, err := runOf(thread)
+		if err != nil {
+			return nil, err
+		}
+		if bar < 0 || bar >= r.n {
+			return nil, fmt.Errorf("bar %d out of range [0, %d)", bar, r.n)
+		}
+		r.result.Signals = append(r.result.Signals, Signal{Bar: bar, Action: action, Reason: reason})
+		return starlark.None, nil
+	})
+}
+
+var strategyModule = &starlarkstruct.Module{
+	Name: "strategy",
+	Members: starlark.StringDict{
+		"long":  signal("long"),
+		"short": signal("short"),
+		"close": signal("close"),
+	},
+}
+
+// plot(values, title="") draws a series; the first plotted series is
+// charted as the strategy's trend line.
+func plot(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var valuesArg starlark.Value
+	var title string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "values", &valuesArg, "title?", &title); err != nil {
+		return nil, err
+	}
+	r, err := runOf(thread)
+	if err != nil {
+		return nil, err
+	}
+	values, err := series("values", valuesArg)
+	if err != nil {
+		return nil, err
+	}
+	p := Plot{Title: title, Values: make([]float64, r.n)}
+	for i := range p.Values {
+		p.Values[i] = math.NaN()
+		if i < len(values) {
+			p.Values[i] = values[i]
+		}
+	}
+	r.result.Plots = append(r.result.Plots, p)
+	return starlark.None, nil
+}
+
+// na(x) reports whether x is None or NaN.
+func na(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x starlark.Value
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &x); err != nil {
+		return nil, err
+	}
+	if x == starlark.None {
+		return starlark.True, nil
+	}
+	f, ok := x.(starlark.Float)
+	return starlark.Bool(ok && math.IsNaN(float64(f))), nil
+}
+
+// nz(x, replacement=0) is x, or replacement where x is None or NaN.
+func nz(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x starlark.Value
+	var replacement starlark.Value = starlark.MakeInt(0)
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &x, &replacement); err != nil {
+		return nil, err
+	}
+	missing, err := na(thread, fn, starlark.Tuple{x}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if missing == starlark.True {
+		return replacement, nil
+	}
+	return x, nil
+}
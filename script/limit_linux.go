@@ -0,0 +1,32 @@
+//go:build linux && !race
+
+package script
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// limitAddressSpace caps the address space of the process at its current
+// size plus limit. The watchdog of ServeWorker cannot run while a single
+// large copy holds the only CPU; the kernel refuses the allocation instead,
+// and the runtime exits out of memory.
+func limitAddressSpace(limit uint64) error {
+	statm, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) == 0 {
+		return fmt.Errorf("unexpected /proc/self/statm %q", statm)
+	}
+	pages, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return err
+	}
+	size := pages*uint64(os.Getpagesize()) + limit
+	return syscall.Setrlimit(syscall.RLIMIT_AS, &syscall.Rlimit{Cur: size, Max: size})
+}
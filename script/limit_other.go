@@ -0,0 +1,10 @@
+//go:build !linux || race
+
+package script
+
+// limitAddressSpace is a no-op where the address space cannot be capped,
+// or under the race detector, whose shadow memory needs address space of
+// its own; the watchdog of ServeWorker bounds the memory alone.
+func limitAddressSpace(limit uint64) error {
+	return nil
+}
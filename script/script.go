@@ -0,0 +1,344 @@
+// Package script runs strategies written in Starlark, a small Python
+// dialect, so strategies can be added without rebuilding the app.
+//
+// A script sets a name, optionally a description and parameters, and
+// defines generate(bars, params), which places signals over the bars:
+//
+//	name = "RSI Reversal"
+//	parameters = [
+//	    {"name": "length", "label": "RSI Length", "default": 14, "min": 2, "max": 100},
+//	]
+//
+//	def generate(bars, params):
+//	    rsi = ta.rsi(bars.close, params["length"])
+//	    for i in range(1, len(bars.close)):
+//	        if rsi[i - 1] < 30 and rsi[i] >= 30:
+//	            strategy.long(i, "RSI Oversold")
+//	        elif rsi[i - 1] > 70 and rsi[i] <= 70:
+//	            strategy.short(i, "RSI Overbought")
+//	    plot(ta.ema(bars.close, 50), "EMA 50")
+//
+// bars has open, high, low, close, volume and time series, oldest first.
+// Series are tuples of floats, NaN where a value is not available yet.
+// Besides the Starlark built-ins, scripts have ta (indicators over series),
+// strategy (long, short and close signals), plot, na, nz and math.
+//
+// Scripts are sandboxed: they cannot load other files or reach the file
+// system or network. Each compile and run happens in a child process (see
+// ServeWorker) bounded by maxSteps, timeout and memoryLimit, so a runaway
+// script fails instead of hanging or exhausting the app.
+package script
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	starlarkmath "go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Bar is one OHLCV candle. Time is the open time in milliseconds.
+type Bar struct {
+	Time                           int64
+	Open, High, Low, Close, Volume float64
+}
+
+// Parameter is an entry of the script's parameters list. Type is
+// "number", "bool", "string" or "select" (one of Options), after the
+// type of its default.
+type Parameter struct {
+	Name    string
+	Label   string
+	Type    string
+	Default any
+	Min     float64
+	Max     float64
+	Step    float64
+	Options []string
+}
+
+// Signal is an entry or exit on a bar. Action is "long", "short" or
+// "close".
+type Signal struct {
+	Bar    int
+	Action string
+	Reason string
+}
+
+// Plot is a series drawn by plot; Values are NaN where it is na.
+type Plot struct {
+	Title  string
+	Values []float64
+}
+
+type Result struct {
+	Signals []Signal
+	Plots   []Plot
+}
+
+// Script is a compiled script. It holds only its source and declarations,
+// so it may be run concurrently.
+type Script struct {
+	Name        string
+	Description string
+	Parameters  []Parameter
+
+	source string
+}
+
+// maxSteps bounds the Starlark computation steps of one compile or run,
+// timeout its wall time and memoryLimit the heap of its process. They are
+// variables so tests can lower them.
+var (
+	maxSteps    uint64 = 200_000_000
+	timeout            = 10 * time.Second
+	memoryLimit uint64 = 512 << 20
+)
+
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+}
+
+// Compile executes the script's top level in a script process and reads
+// its declarations.
+func Compile(src string) (*Script, error) {
+	r, err := execute(job{Source: src})
+	if err != nil {
+		return nil, err
+	}
+	return &Script{Name: r.Name, Description: r.Description, Parameters: r.Parameters, source: src}, nil
+}
+
+// compile executes the script's top level on thread and reads its
+// declarations and generate function.
+func compile(thread *starlark.Thread, src string) (*Script, starlark.Callable, error) {
+	globals, err := starlark.ExecFileOptions(fileOptions, thread, "script.star", src, predeclared)
+	if err != nil {
+		return nil, nil, scriptError(err)
+	}
+
+	s := &Script{source: src}
+	name, ok := globals["name"].(starlark.String)
+	if !ok || name == "" {
+		return nil, nil, fmt.Errorf("script must set name to a string")
+	}
+	s.Name = string(name)
+	if v, ok := globals["description"]; ok {
+		description, ok := v.(starlark.String)
+		if !ok {
+			return nil, nil, fmt.Errorf("description must be a string")
+		}
+		s.Description = string(description)
+	}
+	generate, ok := globals["generate"].(starlark.Callable)
+	if !ok {
+		return nil, nil, fmt.Errorf("script must define generate(bars, params)")
+	}
+	if v, ok := globals["parameters"]; ok {
+		if s.Parameters, err = parameters(v); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s, generate, nil
+}
+
+func parameters(v starlark.Value) ([]Parameter, error) {
+	list, ok := v.(*starlark.List)
+	if !ok {
+		return nil, fmt.Errorf("parameters must be a list of dicts")
+	}
+	params := make([]Parameter, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		dict, ok := list.Index(i).(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("parameters[%d] must be a dict", i)
+		}
+		var p Parameter
+		for _, item := range dict.Items() {
+			key, _ := starlark.AsString(item[0])
+			value := item[1]
+			var err error
+			switch key {
+			case "name", "label":
+				s, ok := starlark.AsString(value)
+				if !ok {
+					err = fmt.Errorf("must be a string")
+				} else if key == "name" {
+					p.Name = s
+				} else {
+					p.Label = s
+				}
+			case "default":
+				p.Default, p.Type, err = parameterDefault(value)
+			case "min", "max", "step":
+				f, ok := starlark.AsFloat(value)
+				if !ok {
+					err = fmt.Errorf("must be a number")
+				}
+				switch key {
+				case "min":
+					p.Min = f
+				case "max":
+					p.Max = f
+				default:
+					p.Step = f
+				}
+			case "options":
+				p.Options, err = stringList(value)
+			default:
+				err = fmt.Errorf("unknown key")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("parameters[%d].%s: %w", i, key, err)
+			}
+		}
+		if p.Name == "" {
+			return nil, fmt.Errorf("parameters[%d] has no name", i)
+		}
+		if p.Type == "" {
+			return nil, fmt.Errorf("parameter %s has no default", p.Name)
+		}
+		if len(p.Options) > 0 {
+			p.Type = "select"
+		}
+		if p.Label == "" {
+			p.Label = p.Name
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+func parameterDefault(v starlark.Value) (any, string, error) {
+	switch v := v.(type) {
+	case starlark.Bool:
+		return bool(v), "bool", nil
+	case starlark.String:
+		return string(v), "string", nil
+	case starlark.Int, starlark.Float:
+		f, _ := starlark.AsFloat(v)
+		return f, "number", nil
+	}
+	return nil, "", fmt.Errorf("must be a number, bool or string")
+}
+
+func stringList(v starlark.Value) ([]string, error) {
+	list, ok := v.(*starlark.List)
+	if !ok {
+		return nil, fmt.Errorf("must be a list of strings")
+	}
+	out := make([]string, list.Len())
+	for i := range out {
+		s, ok := starlark.AsString(list.Index(i))
+		if !ok {
+			return nil, fmt.Errorf("must be a list of strings")
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// Run calls generate over bars in a script process. params overrides
+// parameter defaults by name.
+func (s *Script) Run(bars []Bar, params map[string]any) (*Result, error) {
+	values := make(map[string]any, len(s.Parameters))
+	for _, p := range s.Parameters {
+		if v, ok := params[p.Name]; ok {
+			values[p.Name] = v
+		}
+	}
+	r, err := execute(job{Source: s.source, Run: true, Bars: bars, Params: values})
+	if err != nil {
+		return nil, err
+	}
+	// gob drops empty slices; keep them empty rather than nil.
+	if r.Result.Signals == nil {
+		r.Result.Signals = []Signal{}
+	}
+	if r.Result.Plots == nil {
+		r.Result.Plots = []Plot{}
+	}
+	return r.Result, nil
+}
+
+// run calls generate over bars within l.
+func (s *Script) run(generate starlark.Callable, bars []Bar, params map[string]any, l limits, print func(string)) (*Result, error) {
+	r := &run{n: len(bars), result: &Result{Signals: []Signal{}, Plots: []Plot{}}}
+	thread, stop := newThread(s.Name, r, l, print)
+	defer stop()
+
+	dict := starlark.NewDict(len(s.Parameters))
+	for _, p := range s.Parameters {
+		value := p.Default
+		if v, ok := params[p.Name]; ok {
+			value = v
+		}
+		sv, err := toValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		dict.SetKey(starlark.String(p.Name), sv)
+	}
+	dict.Freeze()
+
+	if _, err := starlark.Call(thread, generate, starlark.Tuple{barsValue(bars), dict}, nil); err != nil {
+		return nil, scriptError(err)
+	}
+	return r.result, nil
+}
+
+// newThread returns a sandboxed thread: load is unavailable, print goes
+// to print, and it is cancelled after l's steps or timeout. stop releases
+// the timer.
+func newThread(name string, r *run, l limits, print func(string)) (*starlark.Thread, func()) {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			print(msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(l.Steps)
+	thread.SetLocal(runKey, r)
+	timeout := l.Timeout
+	timer := time.AfterFunc(timeout, func() {
+		thread.Cancel(fmt.Sprintf("time limit of %s exceeded", timeout))
+	})
+	return thread, func() { timer.Stop() }
+}
+
+// scriptError includes the script's backtrace in evaluation errors.
+func scriptError(err error) error {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		return errors.New(evalErr.Backtrace())
+	}
+	return err
+}
+
+func toValue(v any) (starlark.Value, error) {
+	switch v := v.(type) {
+	case float64:
+		return starlark.Float(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	}
+	return nil, fmt.Errorf("unsupported value %v", v)
+}
+
+var predeclared = starlark.StringDict{
+	"ta":       taModule,
+	"strategy": strategyModule,
+	"plot":     starlark.NewBuiltin("plot", plot),
+	"na":       starlark.NewBuiltin("na", na),
+	"nz":       starlark.NewBuiltin("nz", nz),
+	"math":     starlarkmath.Module,
+}
@@ -0,0 +1,187 @@
+package script
+
+import (
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary serve as the script process, as main does
+// for the app.
+func TestMain(m *testing.M) {
+	if ServeWorker() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+const crossScript = `
+name = "Cross"
+description = "Close crossing a level"
+parameters = [
+    {"name": "level", "label": "Level", "default": 2.5, "min": 0},
+    {"name": "side", "default": "long", "options": ["long", "short"]},
+]
+
+def generate(bars, params):
+    close = bars.close
+    print("bars", len(close))
+    for i in range(1, len(close)):
+        if close[i - 1] <= params["level"] and close[i] > params["level"]:
+            if params["side"] == "long":
+                strategy.long(i, "Cross Up")
+            else:
+                strategy.short(i, "Cross Up")
+        elif close[i - 1] >= params["level"] and close[i] < params["level"]:
+            strategy.close(i, "Cross Down")
+    plot(ta.sma(close, 2), "SMA")
+`
+
+func testBars(closes ...float64) []Bar {
+	bars := make([]Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = Bar{Time: int64(i) * 60_000, Open: c, High: c, Low: c, Close: c, Volume: 1}
+	}
+	return bars
+}
+
+func TestCompileAndRun(t *testing.T) {
+	s, err := Compile(crossScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "Cross" || s.Description != "Close crossing a level" {
+		t.Errorf("Name %q Description %q", s.Name, s.Description)
+	}
+	want := []Parameter{
+		{Name: "level", Label: "Level", Type: "number", Default: 2.5},
+		{Name: "side", Label: "side", Type: "select", Default: "long", Options: []string{"long", "short"}},
+	}
+	if len(s.Parameters) != len(want) {
+		t.Fatalf("Parameters = %+v", s.Parameters)
+	}
+	for i, p := range s.Parameters {
+		if p.Name != want[i].Name || p.Label != want[i].Label || p.Type != want[i].Type ||
+			p.Default != want[i].Default || !slices.Equal(p.Options, want[i].Options) {
+			t.Errorf("parameter %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+
+	bars := testBars(1, 3, 2, 4, 1)
+	result, err := s.Run(bars, map[string]any{"side": "short", "positionSize": 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signals := []Signal{
+		{Bar: 1, Action: "short", Reason: "Cross Up"},
+		{Bar: 2, Action: "close", Reason: "Cross Down"},
+		{Bar: 3, Action: "short", Reason: "Cross Up"},
+		{Bar: 4, Action: "close", Reason: "Cross Down"},
+	}
+	if !slices.Equal(result.Signals, signals) {
+		t.Errorf("Signals = %+v, want %+v", result.Signals, signals)
+	}
+	if len(result.Plots) != 1 || result.Plots[0].Title != "SMA" {
+		t.Fatalf("Plots = %+v", result.Plots)
+	}
+	sma := result.Plots[0].Values
+	if !math.IsNaN(sma[0]) || sma[1] != 2 || sma[4] != 2.5 {
+		t.Errorf("SMA = %v", sma)
+	}
+
+	// A script without signals or plots still has empty, not nil, results.
+	s, err = Compile("name = \"Empty\"\ndef generate(bars, params):\n    pass\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err = s.Run(bars, nil); err != nil || result.Signals == nil || result.Plots == nil {
+		t.Errorf("Run = %+v, %v", result, err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name, src, err string
+	}{
+		{"no name", "def generate(bars, params):\n    pass", "script must set name to a string"},
+		{"no generate", `name = "x"`, "script must define generate(bars, params)"},
+		{"bad description", "name = \"x\"\ndescription = 1\ndef generate(bars, params):\n    pass", "description must be a string"},
+		{"parameter without default", "name = \"x\"\nparameters = [{\"name\": \"a\"}]\ndef generate(bars, params):\n    pass",
+			"parameter a has no default"},
+		{"unknown parameter key", "name = \"x\"\nparameters = [{\"name\": \"a\", \"default\": 1, \"mni\": 0}]\ndef generate(bars, params):\n    pass",
+			"parameters[0].mni: unknown key"},
+		{"syntax error", "name = \"x\"\ndef generate(bars, params)\n    pass", "script.star:3:1: got newline, want ':'"},
+		{"load", "load(\"other.star\", \"f\")\nname = \"x\"", "Error: load not implemented by this application"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Compile error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// setLimit lowers a limit for the rest of the test.
+func setLimit[T any](t *testing.T, limit *T, value T) {
+	old := *limit
+	*limit = value
+	t.Cleanup(func() { *limit = old })
+}
+
+func TestLimits(t *testing.T) {
+	run := func(t *testing.T, body string) error {
+		s, err := Compile("name = \"x\"\ndef generate(bars, params):\n" + body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.Run(testBars(1, 2, 3), nil)
+		return err
+	}
+
+	t.Run("steps", func(t *testing.T) {
+		setLimit(t, &maxSteps, 10_000)
+		err := run(t, "    while True:\n        pass\n")
+		if err == nil || !strings.Contains(err.Error(), "too many steps") {
+			t.Errorf("Run error = %v, want too many steps", err)
+		}
+	})
+
+	t.Run("time", func(t *testing.T) {
+		setLimit(t, &timeout, 200*time.Millisecond)
+		start := time.Now()
+		err := run(t, "    while True:\n        pass\n")
+		if err == nil || !strings.Contains(err.Error(), "time limit of 200ms exceeded") {
+			t.Errorf("Run error = %v, want time limit exceeded", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Run took %s", elapsed)
+		}
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		setLimit(t, &memoryLimit, 64<<20)
+		for name, body := range map[string]string{
+			"one allocation": "    x = [0] * 100000000\n",
+			"growth":         "    x = []\n    for i in range(100000000):\n        x.append(str(i))\n",
+			"string":         "    s = \"x\" * 900000000\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				err := run(t, body)
+				if err == nil || err.Error() != "script exceeded the memory limit of 64 MiB" {
+					t.Errorf("Run error = %v, want memory limit exceeded", err)
+				}
+			})
+		}
+	})
+
+	// The limits apply to the top level too.
+	setLimit(t, &maxSteps, 10_000)
+	if _, err := Compile("name = \"x\"\nwhile True:\n    pass\n"); err == nil || !strings.Contains(err.Error(), "too many steps") {
+		t.Errorf("Compile error = %v, want too many steps", err)
+	}
+}
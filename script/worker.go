@@ -0,0 +1,169 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scripts compile and run in a child process: the app's own executable,
+// started with workerEnv set, which handles one job read from stdin and
+// writes its reply to stdout. Starlark cannot bound the memory of a single
+// operation, so [0] * 10**9 would take the whole app down in process; in
+// a child, the address space limit or the watchdog of ServeWorker ends the
+// process instead.
+const workerEnv = "TERMINAL_SCRIPT_WORKER"
+
+// killGrace is how long past its timeout a script process may take to
+// start and reply before it is killed.
+const killGrace = 5 * time.Second
+
+// limits bounds one compile or run.
+type limits struct {
+	Steps   uint64
+	Timeout time.Duration
+	Memory  uint64
+}
+
+// job compiles Source and, when Run is set, runs it over Bars.
+type job struct {
+	Source string
+	Run    bool
+	Bars   []Bar
+	Params map[string]any
+	Limits limits
+}
+
+type reply struct {
+	Name        string
+	Description string
+	Parameters  []Parameter
+	Result      *Result
+	Output      []string // print calls, in order
+	Err         string
+}
+
+// execute runs j in a script process.
+func execute(j job) (*reply, error) {
+	j.Limits = limits{Steps: maxSteps, Timeout: timeout, Memory: memoryLimit}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("starting script process: %w", err)
+	}
+	var stdin bytes.Buffer
+	if err := gob.NewEncoder(&stdin).Encode(j); err != nil {
+		return nil, fmt.Errorf("encoding script job: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), j.Limits.Timeout+killGrace)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, exe)
+	cmd.Env = append(os.Environ(), workerEnv+"=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = &stdin, &stdout, &stderr
+	runErr := cmd.Run()
+
+	var r reply
+	if err := gob.NewDecoder(&stdout).Decode(&r); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("script process killed after %s", j.Limits.Timeout+killGrace)
+		}
+		if strings.Contains(stderr.String(), "out of memory") {
+			return nil, memoryError(j.Limits.Memory)
+		}
+		if line, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); line != "" {
+			return nil, fmt.Errorf("script process failed: %s", line)
+		}
+		return nil, fmt.Errorf("script process failed: %v", runErr)
+	}
+	for _, msg := range r.Output {
+		slog.Info("script output", "script", r.Name, "message", msg)
+	}
+	if r.Err != "" {
+		return nil, errors.New(r.Err)
+	}
+	return &r, nil
+}
+
+// ServeWorker handles a script job when the process was started as a
+// script process, and reports whether it was. main must call it before
+// anything else and return when it reports true.
+func ServeWorker() bool {
+	if os.Getenv(workerEnv) == "" {
+		return false
+	}
+	var (
+		once sync.Once
+		out  = gob.NewEncoder(os.Stdout)
+	)
+	send := func(r *reply) {
+		once.Do(func() { out.Encode(r) })
+	}
+
+	var j job
+	if err := gob.NewDecoder(os.Stdin).Decode(&j); err != nil {
+		send(&reply{Err: fmt.Sprintf("reading script job: %v", err)})
+		return true
+	}
+	if err := limitAddressSpace(j.Limits.Memory); err != nil {
+		send(&reply{Err: fmt.Sprintf("limiting script memory: %v", err)})
+		return true
+	}
+	debug.SetMemoryLimit(int64(j.Limits.Memory))
+	go watchMemory(j.Limits.Memory, func() {
+		send(&reply{Err: memoryError(j.Limits.Memory).Error()})
+		os.Exit(1)
+	})
+	send(serve(j))
+	return true
+}
+
+func memoryError(limit uint64) error {
+	return fmt.Errorf("script exceeded the memory limit of %d MiB", limit>>20)
+}
+
+// watchMemory calls exceeded once the heap grows past limit. The runtime
+// accounts a large allocation before it is filled, so a single oversized
+// list is caught before it is paged in.
+func watchMemory(limit uint64, exceeded func()) {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	for range time.Tick(5 * time.Millisecond) {
+		metrics.Read(sample)
+		if sample[0].Value.Uint64() > limit {
+			exceeded()
+			return
+		}
+	}
+}
+
+func serve(j job) *reply {
+	r := &reply{}
+	print := func(msg string) { r.Output = append(r.Output, msg) }
+
+	thread, stop := newThread("compile", nil, j.Limits, print)
+	s, generate, err := compile(thread, j.Source)
+	stop()
+	if err != nil {
+		r.Err = err.Error()
+		return r
+	}
+	r.Name, r.Description, r.Parameters = s.Name, s.Description, s.Parameters
+	if !j.Run {
+		return r
+	}
+
+	if r.Result, err = s.run(generate, j.Bars, j.Params, j.Limits, print); err != nil {
+		r.Err = err.Error()
+	}
+	return r
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/script"
+)
+
+// ScriptStrategy runs a Starlark script as a Strategy. Its first plot is
+// charted as the trend line, colored by the position held.
+type ScriptStrategy struct {
+	Config StrategyConfig
+	script *script.Script
+	output *StrategyOutput
+}
+
+func NewScriptStrategy(s *script.Script, params map[string]any) *ScriptStrategy {
+	strategy := &ScriptStrategy{script: s}
+	strategy.Config = strategy.BuildConfig(params)
+	return strategy
+}
+
+func (s *ScriptStrategy) GetName() string {
+	return s.script.Name
+}
+
+func (s *ScriptStrategy) BuildConfig(params map[string]any) StrategyConfig {
+	return buildStrategyConfig(params)
+}
+
+func (s *ScriptStrategy) GenerateSignals(candles hyperliquid.Candles) ([]Signal, error) {
+	return s.run(candles)
+}
+
+func (s *ScriptStrategy) GetVisualizationData(candles hyperliquid.Candles) *StrategyOutput {
+	if _, err := s.run(candles); err != nil {
+		return nil
+	}
+	return s.output
+}
+
+func (s *ScriptStrategy) Run(candles hyperliquid.Candles) (*StrategyOutput, error) {
+	if _, err := s.run(candles); err != nil {
+		return nil, err
+	}
+	return s.output, nil
+}
+
+func (s *ScriptStrategy) Backtest(candles hyperliquid.Candles) (*BacktestOutput, error) {
+	signals, err := s.GenerateSignals(candles)
+	if err != nil {
+		return nil, err
+	}
+	return newBacktestOutput(s, s.Config, candles, signals, s.output), nil
+}
+
+// run runs the script over candles, builds the chart output and
+// returns its signals.
+func (s *ScriptStrategy) run(candles hyperliquid.Candles) ([]Signal, error) {
+	bars := make([]script.Bar, len(candles))
+	for i, candle := range candles {
+		bars[i] = script.Bar{
+			Time:   candle.Timestamp,
+			Open:   parseFloat(candle.Open),
+			High:   parseFloat(candle.High),
+			Low:    parseFloat(candle.Low),
+			Close:  parseFloat(candle.Close),
+			Volume: parseFloat(candle.Volume),
+		}
+	}
+	result, err := s.script.Run(bars, s.Config.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.script.Name, err)
+	}
+
+	signals := make([]Signal, 0, len(result.Signals))
+	for _, rs := range result.Signals {
+		signal := Signal{
+			Index:  rs.Bar,
+			Price:  bars[rs.Bar].Close,
+			Time:   bars[rs.Bar].Time,
+			Reason: rs.Reason,
+		}
+		switch rs.Action {
+		case "long":
+			signal.Type = SignalLong
+		case "short":
+			signal.Type = SignalShort
+		default:
+			signal.Type = SignalClose
+		}
+		signals = append(signals, signal)
+	}
+
+	n := len(candles)
+	s.output = &StrategyOutput{
+		TrendLines:  make([]float64, n),
+		TrendColors: make([]string, n),
+		Directions:  positionDirections(n, signals),
+		Labels:      []Label{},
+		Lines:       []TrendLine{},
+		FillColors:  make([]string, n),
+	}
+	for i, direction := range s.output.Directions {
+		if direction == -1 {
+			s.output.TrendColors[i] = "#1cc2d8"
+		} else {
+			s.output.TrendColors[i] = "#e49013"
+		}
+	}
+	if len(result.Plots) > 0 {
+		for i, v := range result.Plots[0].Values {
+			// The chart skips zero values; JSON has no NaN.
+			if !math.IsNaN(v) {
+				s.output.TrendLines[i] = v
+			}
+		}
+	}
+	return signals, nil
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configPathEnv = "HYPERTERMINAL_CONFIG"
+	redisURLEnv   = "HYPERTERMINAL_REDIS_URL"
+	dbPathEnv     = "HYPERTERMINAL_DB_PATH"
+	logLevelEnv   = "HYPERTERMINAL_LOG_LEVEL"
+	logFileEnv    = "HYPERTERMINAL_LOG_FILE"
+	symbolEnv     = "HYPERTERMINAL_SYMBOL"
+	intervalEnv   = "HYPERTERMINAL_INTERVAL"
+	shutdownEnv   = "HYPERTERMINAL_SHUTDOWN_MODE"
+
+	shutdownFlatten = "flatten"
+	shutdownDetach  = "detach"
+)
+
+var (
+	defaultConfigFiles = []string{"hyperterminal.yaml", "hyperterminal.yml", "hyperterminal.toml"}
+	validIntervals     = []string{"1m", "5m", "15m", "1h", "4h", "1d"}
+	validLogLevels     = []string{"debug", "info", "warn", "error"}
+)
+
+type TradingDefaults struct {
+	Symbol   string   `yaml:"symbol" toml:"symbol"`
+	Interval string   `yaml:"interval" toml:"interval"`
+	Symbols  []string `yaml:"symbols" toml:"symbols"`
+}
+
+// LoggingConfig controls the JSON log file. An empty File logs to
+// hyperterminal.log in the app data directory; the file is rotated once it
+// reaches MaxSizeMB, keeping MaxFiles files in total.
+type LoggingConfig struct {
+	Level     string `yaml:"level" toml:"level"`
+	File      string `yaml:"file" toml:"file"`
+	MaxSizeMB int    `yaml:"maxSizeMb" toml:"maxSizeMb"`
+	MaxFiles  int    `yaml:"maxFiles" toml:"maxFiles"`
+}
+
+// Settings is the non-secret configuration. It is layered, lowest priority
+// first: built-in defaults, the config file, saved user preferences,
+// environment variables and command line flags. Keys and passphrases never
+// live here.
+type Settings struct {
+	Network      Network         `yaml:"network" toml:"network"`
+	RedisURL     string          `yaml:"redisUrl" toml:"redisUrl"`
+	DatabasePath string          `yaml:"databasePath" toml:"databasePath"`
+	KeystorePath string          `yaml:"keystorePath" toml:"keystorePath"`
+	Defaults     TradingDefaults `yaml:"defaults" toml:"defaults"`
+	Risk         RiskLimits      `yaml:"risk" toml:"risk"`
+	Logging      LoggingConfig   `yaml:"logging" toml:"logging"`
+	Accounts     []AccountConfig `yaml:"accounts" toml:"accounts"`
+	// ShutdownMode is "flatten" to close strategy positions on quit or
+	// "detach" to leave them open for the next start.
+	ShutdownMode     string `yaml:"shutdownMode" toml:"shutdownMode"`
+	ResumeStrategies bool   `yaml:"resumeStrategies" toml:"resumeStrategies"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Network:      NetworkTestnet,
+		RedisURL:     "localhost:6379",
+		DatabasePath: "hyperterminal.db",
+		KeystorePath: defaultKeystorePath,
+		Defaults: TradingDefaults{
+			Symbol:   "BTC",
+			Interval: "5m",
+			Symbols:  []string{"BTC", "ETH", "SOL", "XRP", "LTC"},
+		},
+		Risk: RiskLimits{
+			MaxDailyLoss:         500,
+			MaxConsecutiveLosses: 5,
+			MaxOrdersPerMinute:   30,
+		},
+		Logging: LoggingConfig{
+			Level:     "info",
+			MaxSizeMB: defaultLogMaxSizeMB,
+			MaxFiles:  defaultLogMaxFiles,
+		},
+		ShutdownMode:     shutdownFlatten,
+		ResumeStrategies: true,
+	}
+}
+
+func (s Settings) Validate() error {
+	if _, err := networkProfile(s.Network); err != nil {
+		return err
+	}
+	if s.RedisURL == "" {
+		return fmt.Errorf("redisUrl is required")
+	}
+	if s.DatabasePath == "" {
+		return fmt.Errorf("databasePath is required")
+	}
+	if s.KeystorePath == "" {
+		return fmt.Errorf("keystorePath is required")
+	}
+	if s.Defaults.Symbol == "" {
+		return fmt.Errorf("defaults.symbol is required")
+	}
+	if !slices.Contains(validIntervals, s.Defaults.Interval) {
+		return fmt.Errorf("defaults.interval %q must be one of %s", s.Defaults.Interval, strings.Join(validIntervals, ", "))
+	}
+	if !slices.Contains(validLogLevels, s.Logging.Level) {
+		return fmt.Errorf("logging.level %q must be one of %s", s.Logging.Level, strings.Join(validLogLevels, ", "))
+	}
+	if s.Logging.MaxSizeMB < 0 || s.Logging.MaxFiles < 0 {
+		return fmt.Errorf("logging.maxSizeMb and logging.maxFiles must not be negative")
+	}
+	if s.ShutdownMode != shutdownFlatten && s.ShutdownMode != shutdownDetach {
+		return fmt.Errorf("shutdownMode %q must be %s or %s", s.ShutdownMode, shutdownFlatten, shutdownDetach)
+	}
+	if err := s.Risk.Validate(); err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(s.Accounts))
+	for _, ac := range s.Accounts {
+		if err := ac.Validate(); err != nil {
+			return err
+		}
+		if seen[ac.ID] {
+			return fmt.Errorf("duplicate account id %q", ac.ID)
+		}
+		seen[ac.ID] = true
+	}
+	return nil
+}
+
+// clone copies settings without sharing their lists.
+func (s Settings) clone() Settings {
+	s.Defaults.Symbols = slices.Clone(s.Defaults.Symbols)
+	s.Accounts = slices.Clone(s.Accounts)
+	return s
+}
+
+// LoadSettings resolves every configuration layer and validates the result.
+// It also returns the settings from the defaults and config file alone, which
+// preferences are saved against, and the config file that was read, if any.
+func LoadSettings(args []string) (Settings, Settings, string, error) {
+	fs := flag.NewFlagSet("hyperterminal", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to a YAML or TOML config file")
+	network := fs.String("network", "", "network to use: mainnet, testnet or local")
+	redisURL := fs.String("redis", "", "redis address")
+	dbPath := fs.String("db", "", "SQLite database path")
+	keystorePath := fs.String("keystore", "", "encrypted keystore path")
+	logLevel := fs.String("log-level", "", "log level: debug, info, warn or error")
+	logFile := fs.String("log-file", "", "file to write logs to")
+	shutdownMode := fs.String("shutdown", "", "on quit, flatten strategy positions or detach and keep them open")
+	if err := fs.Parse(args); err != nil {
+		return Settings{}, Settings{}, "", err
+	}
+
+	settings := DefaultSettings()
+
+	path := *configPath
+	if path == "" {
+		path = os.Getenv(configPathEnv)
+	}
+	if path == "" {
+		for _, name := range defaultConfigFiles {
+			if _, err := os.Stat(name); err == nil {
+				path = name
+				break
+			}
+		}
+	}
+	if path != "" {
+		if err := readSettingsFile(path, &settings); err != nil {
+			return Settings{}, Settings{}, "", err
+		}
+	}
+	base := settings.clone()
+
+	if prefs, err := preferencesPath(); err == nil {
+		if _, err := os.Stat(prefs); err == nil {
+			if err := readSettingsFile(prefs, &settings); err != nil {
+				log.Printf("config: ignoring preferences: %v", err)
+			}
+		}
+	}
+
+	overrides := []struct {
+		env  string
+		flag string
+		dst  *string
+	}{
+		{networkEnv, *network, (*string)(&settings.Network)},
+		{redisURLEnv, *redisURL, &settings.RedisURL},
+		{dbPathEnv, *dbPath, &settings.DatabasePath},
+		{keystoreEnv, *keystorePath, &settings.KeystorePath},
+		{logLevelEnv, *logLevel, &settings.Logging.Level},
+		{logFileEnv, *logFile, &settings.Logging.File},
+		{symbolEnv, "", &settings.Defaults.Symbol},
+		{intervalEnv, "", &settings.Defaults.Interval},
+		{shutdownEnv, *shutdownMode, &settings.ShutdownMode},
+	}
+	for _, o := range overrides {
+		if v := os.Getenv(o.env); v != "" {
+			*o.dst = v
+		}
+		if o.flag != "" {
+			*o.dst = o.flag
+		}
+	}
+	settings.Network = Network(strings.ToLower(string(settings.Network)))
+	settings.Logging.Level = strings.ToLower(settings.Logging.Level)
+
+	if err := settings.Validate(); err != nil {
+		return Settings{}, Settings{}, "", fmt.Errorf("invalid configuration: %w", err)
+	}
+	return settings, base, path, nil
+}
+
+func readSettingsFile(path string, settings *Settings) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, settings)
+	case ".toml":
+		err = toml.Unmarshal(data, settings)
+	default:
+		return fmt.Errorf("config %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+func preferencesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hyperterminal", "preferences.yaml"), nil
+}
+
+// SavePreferences records the settings the user changed from the UI: the
+// fields where settings differ from current, the settings the UI was showing.
+// Only those fields are written, so values from environment variables and
+// flags are not made permanent and later edits to the config file still
+// apply. A field changed back to its value in base, the defaults and config
+// file, is removed from the preferences.
+func SavePreferences(base, current, settings Settings) error {
+	path, err := preferencesPath()
+	if err != nil {
+		return err
+	}
+	prefs := make(map[string]any)
+	if data, err := os.ReadFile(path); err == nil {
+		var doc map[string]any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse preferences %s: %w", path, err)
+		}
+		flattenFields("", doc, prefs)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read preferences: %w", err)
+	}
+
+	fields := make([]map[string]any, 3)
+	for i, s := range []Settings{base, current, settings} {
+		if fields[i], err = settingsFields(s); err != nil {
+			return err
+		}
+	}
+	baseFields, currentFields, newFields := fields[0], fields[1], fields[2]
+	for key, value := range newFields {
+		if reflect.DeepEqual(value, currentFields[key]) {
+			continue
+		}
+		if reflect.DeepEqual(value, baseFields[key]) {
+			delete(prefs, key)
+		} else {
+			prefs[key] = value
+		}
+	}
+
+	if len(prefs) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to save preferences: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create preferences directory: %w", err)
+	}
+	data, err := yaml.Marshal(unflattenFields(prefs))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
+
+// settingsFields flattens settings to their YAML paths, such as
+// "risk.maxDailyLoss". A list is a single field.
+func settingsFields(settings Settings) (map[string]any, error) {
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]any)
+	flattenFields("", doc, fields)
+	return fields, nil
+}
+
+func flattenFields(prefix string, doc map[string]any, fields map[string]any) {
+	for key, value := range doc {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flattenFields(key, nested, fields)
+			continue
+		}
+		fields[key] = value
+	}
+}
+
+func unflattenFields(fields map[string]any) map[string]any {
+	doc := make(map[string]any)
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		parts := strings.Split(key, ".")
+		node := doc
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = fields[key]
+	}
+	return doc
+}
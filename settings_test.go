@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var settingsFiles = map[string]string{
+	"hyperterminal.yaml": `
+network: mainnet
+redisUrl: file:6379
+databasePath: file.db
+risk:
+  maxDailyLoss: 250
+logging:
+  level: warn
+`,
+	"hyperterminal.toml": `
+network = "mainnet"
+redisUrl = "file:6379"
+databasePath = "file.db"
+
+[risk]
+maxDailyLoss = 250
+
+[logging]
+level = "warn"
+`,
+}
+
+// withSettingsEnv points the preferences at a temporary directory and clears
+// the environment overrides, returning the preferences path.
+func withSettingsEnv(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, env := range []string{configPathEnv, networkEnv, redisURLEnv, dbPathEnv, keystoreEnv, logLevelEnv, logFileEnv, symbolEnv, intervalEnv, shutdownEnv} {
+		t.Setenv(env, "")
+	}
+	path, err := preferencesPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readPreferences(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]any)
+	flattenFields("", doc, fields)
+	return fields
+}
+
+func TestLoadSettingsPrecedence(t *testing.T) {
+	for name, data := range settingsFiles {
+		t.Run(name, func(t *testing.T) {
+			prefs := withSettingsEnv(t)
+			path := filepath.Join(t.TempDir(), name)
+			writeFile(t, path, data)
+			writeFile(t, prefs, "databasePath: prefs.db\nrisk:\n  maxConsecutiveLosses: 9\n")
+			t.Setenv(networkEnv, "local")
+			t.Setenv(redisURLEnv, "env:6379")
+
+			settings, base, loaded, err := LoadSettings([]string{"-config", path, "-network", "testnet"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded != path {
+				t.Errorf("config path %q, want %q", loaded, path)
+			}
+
+			// Flags beat the environment, which beats preferences, which
+			// beat the file, which beats the defaults.
+			if settings.Network != NetworkTestnet {
+				t.Errorf("network %q, want the flag", settings.Network)
+			}
+			if settings.RedisURL != "env:6379" {
+				t.Errorf("redisUrl %q, want the environment", settings.RedisURL)
+			}
+			if settings.DatabasePath != "prefs.db" || settings.Risk.MaxConsecutiveLosses != 9 {
+				t.Errorf("databasePath %q, maxConsecutiveLosses %d, want the preferences", settings.DatabasePath, settings.Risk.MaxConsecutiveLosses)
+			}
+			if settings.Risk.MaxDailyLoss != 250 || settings.Logging.Level != "warn" {
+				t.Errorf("maxDailyLoss %v, level %q, want the file", settings.Risk.MaxDailyLoss, settings.Logging.Level)
+			}
+			if settings.Risk.MaxOrdersPerMinute != 30 || settings.Defaults.Interval != "5m" {
+				t.Errorf("maxOrdersPerMinute %d, interval %q, want the defaults", settings.Risk.MaxOrdersPerMinute, settings.Defaults.Interval)
+			}
+
+			// The base is the defaults and the file only.
+			if base.Network != NetworkMainnet || base.RedisURL != "file:6379" || base.DatabasePath != "file.db" || base.Risk.MaxConsecutiveLosses != 5 {
+				t.Errorf("base = %+v", base)
+			}
+		})
+	}
+}
+
+func TestSavePreferences(t *testing.T) {
+	prefs := withSettingsEnv(t)
+	path := filepath.Join(t.TempDir(), "hyperterminal.yaml")
+	writeFile(t, path, settingsFiles["hyperterminal.yaml"])
+	writeFile(t, prefs, "databasePath: prefs.db\nrisk:\n  maxConsecutiveLosses: 9\n")
+	t.Setenv(redisURLEnv, "env:6379")
+
+	current, base, _, err := LoadSettings([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Change one field, set another back to the file's value and leave the
+	// environment override alone.
+	settings := current.clone()
+	settings.Risk.MaxOrdersPerMinute = 12
+	settings.DatabasePath = base.DatabasePath
+	if err := SavePreferences(base, current, settings); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"risk.maxConsecutiveLosses": 9, "risk.maxOrdersPerMinute": 12}
+	if got := readPreferences(t, prefs); !reflect.DeepEqual(got, want) {
+		t.Errorf("preferences = %v, want %v", got, want)
+	}
+
+	// Reloading applies the saved preferences on top of the file.
+	reloaded, _, _, err := LoadSettings([]string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Risk.MaxOrdersPerMinute != 12 || reloaded.DatabasePath != "file.db" {
+		t.Errorf("reloaded = %+v", reloaded)
+	}
+
+	// Setting everything back to the base removes the file.
+	current, settings = reloaded, reloaded.clone()
+	settings.Risk = base.Risk
+	if err := SavePreferences(base, current, settings); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(prefs); !os.IsNotExist(err) {
+		t.Errorf("preferences file still exists: %v", err)
+	}
+}
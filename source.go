@@ -17,6 +17,7 @@ func parseFloat(s string) float64 {
 }
 
 type Source struct {
+	network      Network
 	info         *hyperliquid.Info
 	ctx          context.Context
 	redisClient  *redis.Client
@@ -24,13 +25,21 @@ type Source struct {
 }
 
 func NewSource(config Config) *Source {
-	info := hyperliquid.NewInfo(context.Background(), hyperliquid.MainnetAPIURL, true, nil, nil)
+	info := hyperliquid.NewInfo(context.Background(), config.Network.APIURL, true, nil, nil)
 	return &Source{
-		info: info,
-		ctx:  context.Background(),
+		network: config.Network.Name,
+		info:    info,
+		ctx:     context.Background(),
 	}
 }
 
+// SetNetwork points the source at another network. Cache keys include the
+// network so candles from different networks never mix.
+func (s *Source) SetNetwork(profile NetworkProfile) {
+	s.network = profile.Name
+	s.info = hyperliquid.NewInfo(context.Background(), profile.APIURL, true, nil, nil)
+}
+
 func (s *Source) SetContext(ctx context.Context) {
 	s.ctx = ctx
 }
@@ -47,7 +56,7 @@ func (s *Source) SetRedis(client *redis.Client) {
 }
 
 func (s *Source) buildCacheKey(symbol, interval string, limit int) string {
-	return fmt.Sprintf("candles:%s:%s:%d", symbol, interval, limit)
+	return fmt.Sprintf("candles:%s:%s:%s:%d", s.network, symbol, interval, limit)
 }
 
 func (s *Source) getCacheTTL(interval string) time.Duration {
@@ -184,6 +193,71 @@ func (s *Source) fetchSingleBatch(symbol string, interval string, limit int, bef
 	return candles, nil
 }
 
+// fetchSymbolCandles fetches the candles of the other symbols a
+// MultiSymbolStrategy reads, keyed by symbol. It returns nil for strategies
+// trading a single symbol.
+func (s *Source) fetchSymbolCandles(strategy Strategy, interval string, limit int) (map[string]hyperliquid.Candles, error) {
+	multi, ok := strategy.(MultiSymbolStrategy)
+	if !ok {
+		return nil, nil
+	}
+	result := make(map[string]hyperliquid.Candles)
+	for _, symbol := range multi.Symbols() {
+		candles, err := s.FetchHistoricalCandles(symbol, interval, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s candles: %w", symbol, err)
+		}
+		result[symbol] = candles
+	}
+	return result, nil
+}
+
+// feedSymbolCandles passes the candles from fetchSymbolCandles to strategy,
+// aligned to candles.
+func feedSymbolCandles(strategy Strategy, candles hyperliquid.Candles, symbols map[string]hyperliquid.Candles) {
+	multi, ok := strategy.(MultiSymbolStrategy)
+	if !ok {
+		return
+	}
+	for symbol, other := range symbols {
+		multi.SetSymbolCandles(symbol, alignCandles(candles, other))
+	}
+}
+
+// alignCandles returns other's candle for each candle of base, matched by
+// open time. A candle missing from other is a flat candle at its previous
+// close, or a zero candle before its first one.
+func alignCandles(base, other hyperliquid.Candles) hyperliquid.Candles {
+	byTime := make(map[int64]hyperliquid.Candle, len(other))
+	for _, candle := range other {
+		byTime[candle.Time] = candle
+	}
+	aligned := make(hyperliquid.Candles, len(base))
+	var last *hyperliquid.Candle
+	for i, candle := range base {
+		if match, ok := byTime[candle.Time]; ok {
+			aligned[i] = match
+			last = &aligned[i]
+			continue
+		}
+		if last == nil {
+			continue
+		}
+		aligned[i] = hyperliquid.Candle{
+			Time:      candle.Time,
+			Timestamp: candle.Timestamp,
+			Symbol:    last.Symbol,
+			Interval:  last.Interval,
+			Open:      last.Close,
+			High:      last.Close,
+			Low:       last.Close,
+			Close:     last.Close,
+			Volume:    "0",
+		}
+	}
+	return aligned
+}
+
 func (s *Source) intervalDuration(interval string) time.Duration {
 	switch interval {
 	case "1m":
@@ -220,7 +294,7 @@ func (s *Source) InvalidateCacheForSymbol(symbol string) error {
 	if !s.cacheEnabled {
 		return nil
 	}
-	pattern := fmt.Sprintf("candles:%s:*", symbol)
+	pattern := fmt.Sprintf("candles:%s:%s:*", s.network, symbol)
 	ctx, cancel := context.WithTimeout(s.ctx, 2*time.Second)
 	defer cancel()
 	iter := s.redisClient.Scan(ctx, 0, pattern, 0).Iterator()
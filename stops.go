@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+
+	hyperliquid "github.com/sonirico/go-hyperliquid"
+
+	"terminal/indicator"
+)
+
+// StopConfig manages a position's protective stop. While it is on, every
+// position gets a stop StopLossPercent from its entry that then only moves
+// in the position's favor: trailing the best price since entry by
+// TrailPercent or by ATRMultiplier ATRs, following the strategy's trend
+// line, and moving to break even once the best price is BreakEvenPercent
+// past the average entry.
+type StopConfig struct {
+	Trailing         string // "off", "percent", "atr" or "trend"
+	TrailPercent     float64
+	ATRLength        int
+	ATRMultiplier    float64
+	BreakEvenPercent float64
+}
+
+func buildStopConfig(params map[string]any) StopConfig {
+	config := StopConfig{Trailing: "off", TrailPercent: 1.5, ATRLength: 14, ATRMultiplier: 3}
+	if trailing, ok := params["trailingStop"].(string); ok {
+		config.Trailing = trailing
+	}
+	if percent, ok := params["trailPercent"].(float64); ok && percent > 0 {
+		config.TrailPercent = percent
+	}
+	if length, ok := params["atrLength"].(float64); ok && length >= 1 {
+		config.ATRLength = int(length)
+	}
+	if multiplier, ok := params["atrMultiplier"].(float64); ok && multiplier > 0 {
+		config.ATRMultiplier = multiplier
+	}
+	if percent, ok := params["breakEvenPercent"].(float64); ok && percent > 0 {
+		config.BreakEvenPercent = percent
+	}
+	return config
+}
+
+func (c StopConfig) trailing() bool {
+	return c.Trailing == "percent" || c.Trailing == "atr" || c.Trailing == "trend"
+}
+
+func (c StopConfig) enabled() bool {
+	return c.trailing() || c.BreakEvenPercent > 0
+}
+
+// anchors is what the stop trails per candle: the ATR distance in "atr"
+// mode and the trend line in "trend" mode, NaN or zero where there is none.
+// The value of a candle applies once it has closed.
+func (c StopConfig) anchors(candles hyperliquid.Candles, trend []float64) []float64 {
+	switch c.Trailing {
+	case "atr":
+		n := len(candles)
+		high, low, close := make([]float64, n), make([]float64, n), make([]float64, n)
+		for i, candle := range candles {
+			high[i], low[i], close[i] = parseFloat(candle.High), parseFloat(candle.Low), parseFloat(candle.Close)
+		}
+		atr := indicator.ATR(high, low, close, c.ATRLength)
+		for i := range atr {
+			atr[i] *= c.ATRMultiplier
+		}
+		return atr
+	case "trend":
+		return trend
+	}
+	return nil
+}
+
+// updateStop tracks price in position's best price and raises its stop
+// accordingly. anchor is the anchors value of the last closed candle.
+func (c StrategyConfig) updateStop(position *Position, price, anchor float64) {
+	if !c.Stops.enabled() {
+		return
+	}
+	long := position.Side == "long"
+	sign := 1.0
+	if !long {
+		sign = -1
+	}
+	if position.BestPrice == 0 || (price-position.BestPrice)*sign > 0 {
+		position.BestPrice = price
+	}
+	best := position.BestPrice
+
+	raise := func(stop float64, reason string) {
+		if stop <= 0 || math.IsNaN(stop) {
+			return
+		}
+		if position.StopPrice == 0 || (stop-position.StopPrice)*sign > 0 {
+			position.StopPrice, position.StopReason = stop, reason
+		}
+	}
+	if position.StopPrice == 0 && c.StopLossPercent > 0 {
+		raise(position.EntryPrice*(1-sign*c.StopLossPercent/100), "Stop Loss")
+	}
+	switch c.Stops.Trailing {
+	case "percent":
+		raise(best*(1-sign*c.Stops.TrailPercent/100), "Trailing Stop")
+	case "atr":
+		if anchor > 0 {
+			raise(best-sign*anchor, "Trailing Stop")
+		}
+	case "trend":
+		raise(anchor, "Trailing Stop")
+	}
+	if c.Stops.BreakEvenPercent > 0 && (best/position.EntryPrice-1)*sign*100 >= c.Stops.BreakEvenPercent {
+		// Break even covers the fees of both legs.
+		fees := 2 * c.Execution.feePercent(false) / 100
+		raise(position.EntryPrice*(1+sign*fees), "Break Even")
+	}
+}
+
+// stopHit reports whether price has reached position's stop.
+func stopHit(position *Position, price float64) bool {
+	return position.StopPrice > 0 && beyond(position.Side, price, position.StopPrice, true)
+}
+
+// anchorAt is anchors[i], or NaN when i is out of range.
+func anchorAt(anchors []float64, i int) float64 {
+	if i < 0 || i >= len(anchors) {
+		return math.NaN()
+	}
+	return anchors[i]
+}
@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func TestUpdateStop(t *testing.T) {
+	tests := []struct {
+		name   string
+		side   string
+		stops  StopConfig
+		prices []float64
+		// want is the stop after each price.
+		want   []float64
+		reason string
+	}{
+		{"trailing long", "long", StopConfig{Trailing: "percent", TrailPercent: 1},
+			[]float64{100, 105, 102, 106}, []float64{99, 103.95, 103.95, 104.94}, "Trailing Stop"},
+		{"trailing short", "short", StopConfig{Trailing: "percent", TrailPercent: 1},
+			[]float64{100, 95, 98, 94}, []float64{101, 95.95, 95.95, 94.94}, "Trailing Stop"},
+		{"break even long", "long", StopConfig{Trailing: "off", BreakEvenPercent: 2},
+			[]float64{101, 102, 99}, []float64{98, 100.1, 100.1}, "Break Even"},
+		{"break even short", "short", StopConfig{Trailing: "off", BreakEvenPercent: 2},
+			[]float64{99, 98, 101}, []float64{102, 99.9, 99.9}, "Break Even"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := StrategyConfig{StopLossPercent: 2, Stops: tt.stops, Execution: ExecutionConfig{TakerFeePercent: 0.05}}
+			position := &Position{Side: tt.side, Size: 1, EntryPrice: 100, IsOpen: true}
+			for i, price := range tt.prices {
+				config.updateStop(position, price, 0)
+				if !approx(position.StopPrice, tt.want[i]) {
+					t.Fatalf("after %v: stop %v, want %v", price, position.StopPrice, tt.want[i])
+				}
+			}
+			if position.StopReason != tt.reason {
+				t.Errorf("stop reason %q, want %q", position.StopReason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestBacktestStopExit(t *testing.T) {
+	config := StrategyConfig{PositionSize: 1, Stops: StopConfig{Trailing: "percent", TrailPercent: 1}}
+	tests := []struct {
+		name string
+		// last is the candle that reaches the stop, raised to 108.9 by the
+		// high of 110 before it.
+		last  [4]float64
+		price float64
+	}{
+		{"through the stop", [4]float64{110, 110, 100, 101}, 108.9},
+		{"gap below the stop", [4]float64{105, 106, 100, 101}, 105},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := ohlcCandles(
+				[4]float64{100, 100, 100, 100},
+				[4]float64{100, 110, 100, 110},
+				tt.last,
+				[4]float64{101, 101, 101, 101},
+			)
+			positions := backtestSignals(config, candles, []Signal{{Index: 0, Type: SignalLong}}, nil)
+			if len(positions) != 1 {
+				t.Fatalf("%d positions, want 1", len(positions))
+			}
+			position := positions[0]
+			if position.ExitReason != "Trailing Stop" || position.ExitIndex != 2 || !approx(position.ExitPrice, tt.price) {
+				t.Errorf("exit %q at %d, %v, want the trailing stop at 2, %v", position.ExitReason, position.ExitIndex, position.ExitPrice, tt.price)
+			}
+		})
+	}
+}
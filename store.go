@@ -0,0 +1,257 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// storeMigrations are applied in order; PRAGMA user_version records how many
+// have run.
+var storeMigrations = []string{`
+CREATE TABLE IF NOT EXISTS strategies (
+	id         TEXT PRIMARY KEY,
+	network    TEXT NOT NULL,
+	account_id TEXT NOT NULL,
+	name       TEXT NOT NULL,
+	symbol     TEXT NOT NULL,
+	interval   TEXT NOT NULL,
+	params     TEXT NOT NULL,
+	position   TEXT,
+	last_candle_time INTEGER NOT NULL DEFAULT 0,
+	updated_at INTEGER NOT NULL
+);`,
+	`ALTER TABLE strategies ADD COLUMN paused INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE strategies ADD COLUMN trades TEXT NOT NULL DEFAULT '[]';`,
+	`ALTER TABLE strategies ADD COLUMN kind TEXT NOT NULL DEFAULT 'max-trend';`,
+	`
+CREATE TABLE IF NOT EXISTS definitions (
+	id         TEXT PRIMARY KEY,
+	kind       TEXT NOT NULL,
+	name       TEXT NOT NULL,
+	source     TEXT NOT NULL,
+	updated_at INTEGER NOT NULL
+);`,
+	`ALTER TABLE strategies ADD COLUMN grid TEXT;`,
+}
+
+// StrategyRecord is the persisted definition and runtime state of a live
+// strategy. A record exists for as long as the strategy should be running;
+// stopping it deletes the record, detaching it on shutdown keeps it.
+type StrategyRecord struct {
+	ID             string
+	Kind           string
+	Network        Network
+	AccountID      string
+	Name           string
+	Symbol         string
+	Interval       string
+	Params         map[string]any
+	Position       *Position
+	Trades         []Position
+	Grid           *GridState
+	LastCandleTime int64
+	Paused         bool
+	UpdatedAt      int64
+}
+
+// Store is the local SQLite database at Settings.DatabasePath.
+type Store struct {
+	db *sql.DB
+}
+
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	// SQLite allows one writer; a single connection avoids SQLITE_BUSY between
+	// strategy goroutines.
+	db.SetMaxOpenConns(1)
+	if err := migrateStore(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func migrateStore(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(storeMigrations); i++ {
+		if _, err := db.Exec(storeMigrations[i]); err != nil {
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) SaveStrategy(rec StrategyRecord) error {
+	params, err := json.Marshal(rec.Params)
+	if err != nil {
+		return fmt.Errorf("failed to encode params: %w", err)
+	}
+	var position []byte
+	if rec.Position != nil {
+		if position, err = json.Marshal(rec.Position); err != nil {
+			return fmt.Errorf("failed to encode position: %w", err)
+		}
+	}
+	trades, err := json.Marshal(rec.Trades)
+	if err != nil {
+		return fmt.Errorf("failed to encode trades: %w", err)
+	}
+	if rec.Trades == nil {
+		trades = []byte("[]")
+	}
+	var grid []byte
+	if rec.Grid != nil {
+		if grid, err = json.Marshal(rec.Grid); err != nil {
+			return fmt.Errorf("failed to encode grid: %w", err)
+		}
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO strategies (id, kind, network, account_id, name, symbol, interval, params, position, trades, grid, last_candle_time, paused, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			kind = excluded.kind,
+			network = excluded.network,
+			account_id = excluded.account_id,
+			name = excluded.name,
+			symbol = excluded.symbol,
+			interval = excluded.interval,
+			params = excluded.params,
+			position = excluded.position,
+			trades = excluded.trades,
+			grid = excluded.grid,
+			last_candle_time = excluded.last_candle_time,
+			paused = excluded.paused,
+			updated_at = excluded.updated_at`,
+		rec.ID, rec.Kind, rec.Network, rec.AccountID, rec.Name, rec.Symbol, rec.Interval,
+		string(params), nullableString(position), string(trades), nullableString(grid), rec.LastCandleTime, rec.Paused, time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save strategy %s: %w", rec.ID, err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteStrategy(id string) error {
+	if _, err := s.db.Exec(`DELETE FROM strategies WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *Store) LoadStrategies() ([]StrategyRecord, error) {
+	rows, err := s.db.Query(`
+		SELECT id, kind, network, account_id, name, symbol, interval, params, position, trades, grid, last_candle_time, paused, updated_at
+		FROM strategies ORDER BY updated_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load strategies: %w", err)
+	}
+	defer rows.Close()
+
+	var records []StrategyRecord
+	for rows.Next() {
+		var rec StrategyRecord
+		var params, trades string
+		var position, grid sql.NullString
+		if err := rows.Scan(&rec.ID, &rec.Kind, &rec.Network, &rec.AccountID, &rec.Name, &rec.Symbol, &rec.Interval,
+			&params, &position, &trades, &grid, &rec.LastCandleTime, &rec.Paused, &rec.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read strategy: %w", err)
+		}
+		if err := json.Unmarshal([]byte(params), &rec.Params); err != nil {
+			return nil, fmt.Errorf("strategy %s has invalid params: %w", rec.ID, err)
+		}
+		if err := json.Unmarshal([]byte(trades), &rec.Trades); err != nil {
+			return nil, fmt.Errorf("strategy %s has invalid trades: %w", rec.ID, err)
+		}
+		if position.Valid {
+			rec.Position = &Position{}
+			if err := json.Unmarshal([]byte(position.String), rec.Position); err != nil {
+				return nil, fmt.Errorf("strategy %s has invalid position: %w", rec.ID, err)
+			}
+		}
+		if grid.Valid {
+			rec.Grid = &GridState{}
+			if err := json.Unmarshal([]byte(grid.String), rec.Grid); err != nil {
+				return nil, fmt.Errorf("strategy %s has invalid grid: %w", rec.ID, err)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// StrategyDefinition is the source of a user-defined strategy, such as a
+// Pine script. Definitions are compiled and registered at startup.
+type StrategyDefinition struct {
+	ID        string
+	Kind      string
+	Name      string
+	Source    string
+	UpdatedAt int64
+}
+
+func (s *Store) SaveDefinition(def StrategyDefinition) error {
+	_, err := s.db.Exec(`
+		INSERT INTO definitions (id, kind, name, source, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			kind = excluded.kind,
+			name = excluded.name,
+			source = excluded.source,
+			updated_at = excluded.updated_at`,
+		def.ID, def.Kind, def.Name, def.Source, time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save definition %s: %w", def.ID, err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteDefinition(id string) error {
+	if _, err := s.db.Exec(`DELETE FROM definitions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete definition %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *Store) LoadDefinitions() ([]StrategyDefinition, error) {
+	rows, err := s.db.Query(`SELECT id, kind, name, source, updated_at FROM definitions ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var defs []StrategyDefinition
+	for rows.Next() {
+		var def StrategyDefinition
+		if err := rows.Scan(&def.ID, &def.Kind, &def.Name, &def.Source, &def.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read definition: %w", err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, rows.Err()
+}
+
+func nullableString(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
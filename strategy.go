@@ -12,6 +12,8 @@ const (
 	SignalNone SignalType = iota
 	SignalLong
 	SignalShort
+	// SignalClose flattens the open position without opening another.
+	SignalClose
 )
 
 type Signal struct {
@@ -33,10 +35,37 @@ type Position struct {
 	Size          float64
 	PnL           float64
 	PnLPercentage float64
+	Fees          float64
 	IsOpen        bool
 	ExitReason    string
 	MaxDrawdown   float64
 	MaxProfit     float64
+	// Entries counts the fills that built the position; EntryPrice is their
+	// size-weighted average and BasePrice the first one's price.
+	Entries   int
+	BasePrice float64
+	// StopPrice is the protective stop managed under StrategyConfig.Stops,
+	// zero when there is none, and StopReason what last moved it. BestPrice
+	// is the best price reached since entry.
+	StopPrice  float64
+	StopReason string
+	BestPrice  float64
+	// Legs are the per-symbol legs of a multi-symbol position, nil for a
+	// position in the strategy's symbol alone. The position's own price and
+	// size fields then mirror the first leg.
+	Legs []PositionLeg
+}
+
+// PositionLeg is one symbol of a multi-symbol position. MarkPrice is its last
+// known price while the position is open; ExitPrice is set once the leg is
+// closed.
+type PositionLeg struct {
+	Symbol     string
+	Side       string
+	Size       float64
+	EntryPrice float64
+	ExitPrice  float64
+	MarkPrice  float64
 }
 
 type StrategyConfig struct {
@@ -45,6 +74,9 @@ type StrategyConfig struct {
 	TakeProfitPercent   float64
 	StopLossPercent     float64
 	Interval            time.Duration
+	Execution           ExecutionConfig
+	Scaling             ScalingConfig
+	Stops               StopConfig
 	Parameters          map[string]any
 }
 
@@ -74,29 +106,17 @@ type StrategyOutput struct {
 }
 
 type BacktestOutput struct {
-	TrendLines         []float64
-	TrendColors        []string
-	Directions         []int
-	Labels             []Label
-	Signals            []Signal
-	Positions          []Position
-	StrategyName       string
-	StrategyVersion    string
-	TotalPnL           float64
-	TotalPnLPercent    float64
-	WinRate            float64
-	TotalTrades        int
-	WinningTrades      int
-	LosingTrades       int
-	AverageWin         float64
-	AverageLoss        float64
-	ProfitFactor       float64
-	MaxDrawdown        float64
-	MaxDrawdownPercent float64
-	SharpeRatio        float64
-	LongestWinStreak   int
-	LongestLossStreak  int
-	AverageHoldTime    time.Duration
+	TrendLines      []float64
+	TrendColors     []string
+	Directions      []int
+	Labels          []Label
+	Signals         []Signal
+	Positions       []Position
+	StrategyName    string
+	StrategyVersion string
+	// Grid is the final state of a grid strategy's backtest.
+	Grid *GridState
+	PerformanceMetrics
 }
 
 type Strategy interface {
@@ -113,3 +133,85 @@ type Strategy interface {
 	// Live trading: take candle data and give back the strategy output
 	Run(candles hyperliquid.Candles) (*StrategyOutput, error)
 }
+
+// LiveSignaler is implemented by strategies that evaluate the newest candle
+// from state kept between calls instead of regenerating signals over the
+// whole window. It returns the signal for the newest candle, if any, and the
+// current direction as in StrategyOutput.Directions (0 when it has none).
+type LiveSignaler interface {
+	LiveSignal(candles hyperliquid.Candles) (*Signal, int, error)
+}
+
+// buildStrategyConfig reads the parameters every strategy shares: sizing,
+// trade direction, TP/SL and execution.
+func buildStrategyConfig(params map[string]any) StrategyConfig {
+	config := StrategyConfig{
+		PositionSize:      0.005,
+		TradeDirection:    "both",
+		TakeProfitPercent: 5.0,
+		StopLossPercent:   2.0,
+		Execution:         buildExecutionConfig(params),
+		Scaling:           buildScalingConfig(params),
+		Stops:             buildStopConfig(params),
+		Parameters:        params,
+	}
+
+	if size, ok := params["positionSize"].(float64); ok {
+		config.PositionSize = size
+	}
+	if direction, ok := params["tradeDirection"].(string); ok {
+		config.TradeDirection = direction
+	}
+	if tp, ok := params["takeProfitPercent"].(float64); ok {
+		config.TakeProfitPercent = tp
+	}
+	if sl, ok := params["stopLossPercent"].(float64); ok {
+		config.StopLossPercent = sl
+	}
+
+	return config
+}
+
+// positionDirections is the direction each candle is charted with when a
+// strategy's signals define it: -1 while long, 1 otherwise.
+func positionDirections(n int, signals []Signal) []int {
+	directions := make([]int, n)
+	direction, next := 1, 0
+	for i := range directions {
+		for ; next < len(signals) && signals[next].Index == i; next++ {
+			if signals[next].Type == SignalLong {
+				direction = -1
+			} else {
+				direction = 1
+			}
+		}
+		directions[i] = direction
+	}
+	return directions
+}
+
+// OrderTrader is implemented by strategies that trade through resting orders
+// rather than signals. The engine calls Trade on every tick, before the
+// newest candle is processed, and CancelOrders when the strategy is stopped.
+// Trade reports whether the strategy's state changed and needs saving.
+type OrderTrader interface {
+	Trade(s *LiveStrategy, candles hyperliquid.Candles) (bool, error)
+	CancelOrders(s *LiveStrategy)
+}
+
+// MultiSymbolStrategy is implemented by strategies that read candles of
+// symbols besides the one they trade. Before each evaluation, backtests and
+// the engine fetch every symbol in Symbols at the strategy's interval and
+// pass it to SetSymbolCandles aligned to the strategy's candles by open time.
+type MultiSymbolStrategy interface {
+	Symbols() []string
+	SetSymbolCandles(symbol string, candles hyperliquid.Candles)
+}
+
+// WarmUpStrategy is implemented by strategies that know how many candles of
+// interval they need before their signals are valid. The engine fetches at
+// least that many each step and refuses to start a strategy that needs more
+// history than the exchange keeps.
+type WarmUpStrategy interface {
+	WarmUp(interval string) (int, error)
+}